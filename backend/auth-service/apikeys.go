@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/auth-service/infrastructure/store"
+	"github.com/insidechurch/observability/logger"
+	"github.com/insidechurch/observability/tracing"
+)
+
+// apiKeyStore registra as chaves de API das integrações, inicializado em main
+var apiKeyStore store.APIKeyStore
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`               // permissões "recurso:ação" concedidas à chave
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // sem validade quando omitido
+}
+
+// CreateAPIKeyResponse inclui a chave em texto, exibida uma única vez
+type CreateAPIKeyResponse struct {
+	*store.APIKey
+	Key string `json:"key"`
+}
+
+// authenticateAPIKey busca a chave ativa apresentada no header Authorization
+// e registra o seu uso
+func authenticateAPIKey(ctx context.Context, key string) (*store.APIKey, error) {
+	apiKey, err := apiKeyStore.FindActive(ctx, store.HashToken(key))
+	if err != nil {
+		return nil, err
+	}
+
+	// Falhas no registro do último uso não impedem a requisição
+	if err := apiKeyStore.Touch(ctx, apiKey.ID); err != nil {
+		log.Error("Erro ao registrar uso da chave de API", err,
+			logger.String("api_key_id", apiKey.ID),
+		)
+	}
+	return apiKey, nil
+}
+
+// createAPIKey cria uma chave para o usuário. Os escopos precisam estar entre
+// as permissões do papel do usuário, de modo que a chave nunca concede mais
+// do que o próprio dono pode fazer.
+func createAPIKey(ctx context.Context, w http.ResponseWriter, r *http.Request, userID, actorID string) error {
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "JSON inválido", http.StatusBadRequest)
+		return nil
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Scopes) == 0 {
+		http.Error(w, "Nome e escopos são obrigatórios", http.StatusBadRequest)
+		return nil
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		http.Error(w, "A validade deve ser uma data futura", http.StatusBadRequest)
+		return nil
+	}
+
+	permissions, err := userStore.Permissions(ctx, userID)
+	if errors.Is(err, store.ErrUserNotFound) {
+		http.Error(w, "Usuário não encontrado", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(w, "Erro ao consultar permissões", http.StatusInternalServerError)
+		return err
+	}
+	granted := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		granted[permission] = true
+	}
+	for _, scope := range req.Scopes {
+		if !granted[scope] {
+			http.Error(w, "Escopo não permitido para o usuário: "+scope, http.StatusBadRequest)
+			return nil
+		}
+	}
+
+	key, apiKey, err := store.NewAPIKey()
+	if err != nil {
+		http.Error(w, "Erro ao gerar chave de API", http.StatusInternalServerError)
+		return err
+	}
+	apiKey.UserID = userID
+	apiKey.Name = req.Name
+	apiKey.Scopes = req.Scopes
+	apiKey.ExpiresAt = req.ExpiresAt
+	apiKey.CreatedBy = actorID
+	if err := apiKeyStore.Create(ctx, apiKey); err != nil {
+		http.Error(w, "Erro ao gerar chave de API", http.StatusInternalServerError)
+		return err
+	}
+
+	auditLogger.Record(ctx, auditevent.Event{
+		Action:  auditevent.APIKeyCreated,
+		Target:  auditevent.Target(auditevent.TargetAPIKey, apiKey.ID),
+		Changes: auditevent.Diff(nil, apiKey),
+	})
+	log.Info("Chave de API criada",
+		logger.String("user_id", userID),
+		logger.String("api_key_id", apiKey.ID),
+		logger.String("actor_id", actorID),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CreateAPIKeyResponse{APIKey: apiKey, Key: key})
+	return nil
+}
+
+// listAPIKeys responde com as chaves ativas do usuário
+func listAPIKeys(ctx context.Context, w http.ResponseWriter, userID string) error {
+	keys, err := apiKeyStore.List(ctx, userID)
+	if errors.Is(err, store.ErrUserNotFound) {
+		http.Error(w, "Usuário não encontrado", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(w, "Erro ao listar chaves de API", http.StatusInternalServerError)
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+	return nil
+}
+
+// revokeAPIKey revoga a chave se ela pertencer ao usuário
+func revokeAPIKey(ctx context.Context, w http.ResponseWriter, userID, keyID, actorID string) error {
+	err := apiKeyStore.Revoke(ctx, userID, keyID)
+	if errors.Is(err, store.ErrAPIKeyNotFound) {
+		http.Error(w, "Chave de API não encontrada", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(w, "Erro ao revogar chave de API", http.StatusInternalServerError)
+		return err
+	}
+
+	auditLogger.Record(ctx, auditevent.Event{
+		Action: auditevent.APIKeyRevoked,
+		Target: auditevent.Target(auditevent.TargetAPIKey, keyID),
+	})
+	log.Info("Chave de API revogada",
+		logger.String("user_id", userID),
+		logger.String("api_key_id", keyID),
+		logger.String("actor_id", actorID),
+	)
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// apiKeysHandler atende GET e POST /auth/api-keys e DELETE /auth/api-keys/{id}
+// para o usuário autenticado. As chaves são gerenciadas apenas com uma sessão:
+// uma chave de API não cria nem revoga chaves.
+func apiKeysHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "api_keys", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		claims, ok := ctx.Value(claimsKey).(*Claims)
+		if !ok {
+			http.Error(w, "Acesso negado", http.StatusForbidden)
+			return nil
+		}
+
+		keyID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/auth/api-keys"), "/")
+		switch {
+		case r.Method == http.MethodGet && keyID == "":
+			return listAPIKeys(ctx, w, claims.UserID)
+		case r.Method == http.MethodPost && keyID == "":
+			return createAPIKey(ctx, w, r, claims.UserID, claims.UserID)
+		case r.Method == http.MethodDelete && keyID != "" && !strings.Contains(keyID, "/"):
+			return revokeAPIKey(ctx, w, claims.UserID, keyID, claims.UserID)
+		}
+
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler de chaves de API", err)
+	}
+}
+
+// adminAPIKeysHandler atende GET e POST /auth/admin/users/{userID}/api-keys e
+// DELETE /auth/admin/users/{userID}/api-keys/{id}, usados também para as
+// chaves de contas de serviço
+func adminAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "admin_api_keys", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		adminID, _ := ctx.Value(userIDKey).(string)
+
+		// Segmentos esperados: {userID}/api-keys[/{id}]
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/auth/admin/users/"), "/"), "/")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] != "api-keys" {
+			http.NotFound(w, r)
+			return nil
+		}
+		userID := parts[0]
+
+		switch {
+		case r.Method == http.MethodGet && len(parts) == 2:
+			return listAPIKeys(ctx, w, userID)
+		case r.Method == http.MethodPost && len(parts) == 2:
+			return createAPIKey(ctx, w, r, userID, adminID)
+		case r.Method == http.MethodDelete && len(parts) == 3 && parts[2] != "":
+			return revokeAPIKey(ctx, w, userID, parts[2], adminID)
+		}
+
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler administrativo de chaves de API", err)
+	}
+}
+
+// adminUsersHandler encaminha as rotas /auth/admin/users/{userID}/... para o
+// handler do recurso
+func adminUsersHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/auth/admin/users/"), "/"), "/")
+	if len(parts) < 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch parts[1] {
+	case "sessions":
+		adminSessionsHandler(w, r)
+	case "api-keys":
+		adminAPIKeysHandler(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -1,29 +1,137 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/auth-service/infrastructure/auditchain"
+	"github.com/insidechurch/auth-service/infrastructure/store"
+	"github.com/insidechurch/observability/logger"
+	"github.com/insidechurch/observability/redact"
+)
+
+// Arquivos do log de auditoria, dos checkpoints assinados e dos registros
+// que aguardam gravação quando a fila está cheia
+const (
+	auditLogPath        = "logs/audit.log"
+	auditCheckpointPath = "logs/audit.checkpoints"
+	auditSpillPath      = "logs/audit.spill"
+	auditStoreSpillPath = "logs/audit.store.spill"
+)
+
+// auditQueueSize é a capacidade da fila de registros de auditoria
+const auditQueueSize = 1000
+
+// Limite de cada gravação no banco e intervalo antes de tentar novamente
+// depois de uma falha
+const (
+	auditStoreTimeout = 2 * time.Second
+	auditStoreRetry   = 5 * time.Second
 )
 
-// AuditLog representa um registro de auditoria
+var (
+	// auditCheckpointInterval é o intervalo entre os checkpoints assinados do
+	// log de auditoria; zero desativa os checkpoints
+	auditCheckpointInterval = getEnv("AUDIT_CHECKPOINT_INTERVAL", "1h")
+
+	// auditEnqueueTimeout é o tempo que uma requisição aguarda por espaço na
+	// fila antes de desviar o registro para o arquivo de espera
+	auditEnqueueTimeout = getEnv("AUDIT_ENQUEUE_TIMEOUT", "250ms")
+)
+
+// AuditLog representa um registro de auditoria. Action é o evento de domínio
+// (ver auditevent), UserID quem o executou e Resource a entidade afetada, no
+// formato "tipo:id". Em requisições com personificação, UserID é o membro
+// personificado e ActorID o administrador que agiu em seu nome. Details
+// guarda o motivo da falha ou o complemento da ação.
+//
+// Sequence, PrevHash e Hash encadeiam os registros gravados (ver auditchain);
+// novos campos devem ser omitidos quando vazios, para que a serialização dos
+// registros antigos não mude.
 type AuditLog struct {
-	UserID    string    `json:"user_id"`
-	Action    string    `json:"action"`
-	Resource  string    `json:"resource"`
-	Timestamp time.Time `json:"timestamp"`
-	IP        string    `json:"ip"`
-	Details   string    `json:"details,omitempty"`
+	Sequence      uint64              `json:"seq"`
+	PrevHash      string              `json:"prev_hash"`
+	UserID        string              `json:"user_id"`
+	ActorID       string              `json:"actor_id,omitempty"`
+	Action        string              `json:"action"`
+	Resource      string              `json:"resource"`
+	Timestamp     time.Time           `json:"timestamp"`
+	IP            string              `json:"ip"`
+	Details       string              `json:"details,omitempty"`
+	Outcome       string              `json:"outcome,omitempty"`
+	Changes       []auditevent.Change `json:"changes,omitempty"`
+	UserAgent     string              `json:"user_agent,omitempty"`
+	CorrelationID string              `json:"correlation_id,omitempty"`
+	Hash          string              `json:"hash,omitempty"`
 }
 
-// AuditLogger gerencia os logs de auditoria
+// AuditLogger gerencia os logs de auditoria. Os registros são gravados um por
+// linha, encadeados pelo hash do registro anterior, e o último registro é
+// assinado periodicamente em um checkpoint.
+//
+// Nenhum registro é descartado: com a fila cheia, LogEntry aguarda até
+// enqueueTimeout e então grava o registro no arquivo de espera (spillPath),
+// que o worker incorpora ao log assim que a fila esvazia, a cada
+// retryInterval ou, após uma interrupção, na próxima inicialização. Registros desviados entram na cadeia
+// depois dos que estavam na fila; a ordem cronológica é dada pelo timestamp.
+//
+// Cada registro gravado no arquivo é copiado para o store, consultado em
+// GET /audit. O arquivo continua sendo a fonte íntegra: com o banco
+// indisponível, os registros aguardam em pending e, além da capacidade da
+// fila, em storeSpillPath, e são copiados depois. Na inicialização, o log é
+// conferido com o store e os registros que faltam são copiados.
 type AuditLogger struct {
 	mu    sync.Mutex
 	file  *os.File
 	queue chan AuditLog
+	head  auditchain.Head
+
+	// checkpoints recebe os checkpoints assinados com sign a cada
+	// checkpointInterval; lastCheckpoint evita repetir o mesmo registro
+	checkpoints        *os.File
+	checkpointInterval time.Duration
+	sign               func(jwt.Claims) (string, error)
+	lastCheckpoint     uint64
+
+	// Registros desviados são gravados quando a fila esvazia e, em um sistema
+	// sem novos registros, a cada retryInterval; zero desativa a repetição
+	enqueueTimeout time.Duration
+	retryInterval  time.Duration
+	spillPath      string
+	spillMu        sync.Mutex
+	spilled        atomic.Bool
+
+	// closeMu impede o envio para a fila durante e após o fechamento; done é
+	// fechado pelo worker depois de gravar todos os registros pendentes
+	closeMu sync.RWMutex
+	closed  bool
+	done    chan struct{}
+
+	// store recebe a cópia dos registros gravados; os demais campos são
+	// usados apenas pelo worker. backfill indica que o log em logPath deve
+	// ser conferido com o store.
+	store          store.AuditStore
+	pending        []AuditLog
+	retryAt        time.Time
+	storeSpillPath string
+	storeSpilled   bool
+	logPath        string
+	backfill       bool
+
+	// redactor trata os dados pessoais antes do encadeamento; nil usa
+	// redact.Default
+	redactor *redact.Redactor
 }
 
 var (
@@ -32,20 +140,65 @@ var (
 
 // Inicializa o logger de auditoria
 func initAuditLogger() error {
+	interval, err := time.ParseDuration(auditCheckpointInterval)
+	if err != nil || interval < 0 {
+		return fmt.Errorf("valor inválido para AUDIT_CHECKPOINT_INTERVAL: %q", auditCheckpointInterval)
+	}
+	enqueueTimeout, err := time.ParseDuration(auditEnqueueTimeout)
+	if err != nil || enqueueTimeout < 0 {
+		return fmt.Errorf("valor inválido para AUDIT_ENQUEUE_TIMEOUT: %q", auditEnqueueTimeout)
+	}
+
 	// Criar diretório de logs se não existir
 	if err := os.MkdirAll("logs", 0755); err != nil {
 		return fmt.Errorf("erro ao criar diretório de logs: %v", err)
 	}
 
+	// Retomar o encadeamento a partir do último registro gravado
+	head, err := readAuditHead(auditLogPath)
+	if err != nil {
+		return err
+	}
+
 	// Abrir arquivo de log
-	file, err := os.OpenFile("logs/audit.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	file, err := os.OpenFile(auditLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return fmt.Errorf("erro ao abrir arquivo de log: %v", err)
 	}
 
+	checkpoints, err := os.OpenFile(auditCheckpointPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("erro ao abrir arquivo de checkpoints: %v", err)
+	}
+
 	auditLogger = &AuditLogger{
-		file:  file,
-		queue: make(chan AuditLog, 1000), // Buffer de 1000 logs
+		file:               file,
+		queue:              make(chan AuditLog, auditQueueSize),
+		head:               head,
+		checkpoints:        checkpoints,
+		checkpointInterval: interval,
+		sign:               signingKeys.Sign,
+		lastCheckpoint:     head.Sequence,
+		enqueueTimeout:     enqueueTimeout,
+		retryInterval:      auditStoreRetry,
+		spillPath:          auditSpillPath,
+		done:               make(chan struct{}),
+		store:              auditStore,
+		storeSpillPath:     auditStoreSpillPath,
+		logPath:            auditLogPath,
+		backfill:           true,
+		redactor:           piiRedactor,
+	}
+	if _, err := os.Stat(auditStoreSpillPath); err == nil {
+		auditLogger.storeSpilled = true
+	}
+
+	// Registros desviados antes de uma interrupção são gravados primeiro
+	for _, path := range []string{auditSpillPath, auditLogger.drainPath()} {
+		if _, err := os.Stat(path); err == nil {
+			auditLogger.spilled.Store(true)
+		}
 	}
 
 	// Iniciar worker para processar logs
@@ -54,63 +207,581 @@ func initAuditLogger() error {
 	return nil
 }
 
-// Processa os logs em background
+// readAuditHead lê o último registro encadeado do log, se ele existir
+func readAuditHead(path string) (auditchain.Head, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return auditchain.Head{}, nil
+	}
+	if err != nil {
+		return auditchain.Head{}, fmt.Errorf("erro ao abrir arquivo de log: %v", err)
+	}
+	defer file.Close()
+
+	return auditchain.ReadHead(file)
+}
+
+// Processa os logs em background. Ao fechamento da fila, grava os registros
+// restantes, os desviados e um último checkpoint antes de sinalizar done.
 func (l *AuditLogger) processLogs() {
-	for log := range l.queue {
-		l.mu.Lock()
-		encoder := json.NewEncoder(l.file)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(log); err != nil {
-			fmt.Printf("Erro ao escrever log: %v\n", err)
+	defer close(l.done)
+
+	var ticks <-chan time.Time
+	if l.checkpointInterval > 0 {
+		ticker := time.NewTicker(l.checkpointInterval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+	var retries <-chan time.Time
+	if l.retryInterval > 0 {
+		ticker := time.NewTicker(l.retryInterval)
+		defer ticker.Stop()
+		retries = ticker.C
+	}
+
+	l.flushPending()
+	l.drainSpilled()
+	for {
+		select {
+		case record, ok := <-l.queue:
+			if !ok {
+				l.drainSpilled()
+				if err := l.checkpoint(time.Now()); err != nil {
+					log.Error("Erro ao gravar checkpoint de auditoria", err)
+				}
+				l.retryAt = time.Time{}
+				l.flushPending()
+				return
+			}
+			authMetrics.AuditQueueDepth.Set(float64(len(l.queue)))
+			if err := l.write(record); err != nil {
+				// O registro volta para o arquivo de espera e é regravado depois
+				log.Error("Erro ao gravar registro de auditoria", err)
+				l.spill(record)
+				continue
+			}
+			if len(l.queue) == 0 {
+				l.drainSpilled()
+			}
+		case <-ticks:
+			l.drainSpilled()
+			if err := l.checkpoint(time.Now()); err != nil {
+				log.Error("Erro ao gravar checkpoint de auditoria", err)
+			}
+			l.flushPending()
+		case <-retries:
+			// Sem novos registros, os desviados durante uma falha do disco
+			// ou do banco seriam gravados apenas no próximo evento
+			l.drainSpilled()
+			l.flushPending()
+		}
+	}
+}
+
+// write grava o registro no arquivo e o copia para o store
+func (l *AuditLogger) write(log AuditLog) error {
+	sealed, err := l.appendRecord(log)
+	if err != nil {
+		return err
+	}
+	l.persist(sealed)
+	return nil
+}
+
+// appendRecord encadeia o registro ao anterior, o grava em uma linha e retorna o
+// registro selado
+func (l *AuditLogger) appendRecord(log AuditLog) (AuditLog, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	log.Sequence = l.head.Next()
+	log.PrevHash = l.head.Hash
+	log.Hash = ""
+	log.Timestamp = log.Timestamp.UTC()
+
+	data, err := json.Marshal(log)
+	if err != nil {
+		return log, err
+	}
+	sealed, hash, err := auditchain.Seal(data)
+	if err != nil {
+		return log, err
+	}
+	if _, err := l.file.Write(append(sealed, '\n')); err != nil {
+		return log, err
+	}
+
+	l.head = auditchain.Head{Sequence: log.Sequence, Hash: hash}
+	log.Hash = hash
+	return log, nil
+}
+
+// persist copia o registro selado para o store. Enquanto o banco estiver
+// indisponível, os registros aguardam em pending, limitados a auditQueueSize;
+// os seguintes aguardam no arquivo de espera do banco (storeSpillPath), na
+// ordem em que foram gravados, e são copiados depois dos pendentes.
+func (l *AuditLogger) persist(record AuditLog) {
+	if l.store == nil {
+		return
+	}
+
+	if l.storeSpilled || len(l.pending) >= auditQueueSize {
+		if err := appendSpill(l.storeSpillPath, record); err != nil {
+			// O registro continua no arquivo de log e é copiado pela conferência
+			log.Error("Erro ao desviar cópia de auditoria para o banco", err)
+			l.backfill = true
+		} else {
+			l.storeSpilled = true
+			authMetrics.AuditStoreDeferred.Inc()
+		}
+	} else {
+		l.pending = append(l.pending, record)
+	}
+	l.flushPending()
+}
+
+// flushPending copia para o store os registros pendentes, os do arquivo de
+// espera do banco e, se necessário, os do log que ainda faltam, em ordem, até
+// a primeira falha; após uma falha, aguarda auditStoreRetry antes de tentar de
+// novo, para não atrasar a gravação do arquivo
+func (l *AuditLogger) flushPending() {
+	if l.store == nil || time.Now().Before(l.retryAt) {
+		return
+	}
+
+	for len(l.pending) > 0 {
+		if err := l.appendStore(l.pending[0]); err != nil {
+			l.storeFailed(err)
+			return
+		}
+		l.pending = l.pending[1:]
+	}
+
+	if l.storeSpilled {
+		if err := l.flushStoreSpill(); err != nil {
+			l.storeFailed(err)
+			return
+		}
+		l.storeSpilled = false
+	}
+
+	if l.backfill {
+		if err := l.backfillStore(); err != nil {
+			l.storeFailed(err)
+			return
+		}
+		l.backfill = false
+	}
+}
+
+// appendStore copia um registro para o store
+func (l *AuditLogger) appendStore(record AuditLog) error {
+	ctx, cancel := context.WithTimeout(context.Background(), auditStoreTimeout)
+	defer cancel()
+	return l.store.Append(ctx, auditEntry(record))
+}
+
+// storeFailed registra a falha do store e adia a próxima tentativa
+func (l *AuditLogger) storeFailed(err error) {
+	log.Error("Erro ao copiar registro de auditoria para o banco", err)
+	l.retryAt = time.Now().Add(auditStoreRetry)
+}
+
+// flushStoreSpill copia para o store os registros do arquivo de espera do
+// banco e o remove. Em caso de falha, os registros ainda não copiados
+// permanecem no arquivo.
+func (l *AuditLogger) flushStoreSpill() error {
+	data, err := os.ReadFile(l.storeSpillPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := decoder.InputOffset()
+
+		var record AuditLog
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			// Linha truncada por uma interrupção; o registro está no log e é
+			// copiado pela conferência
+			log.Error("Cópia de auditoria desviada ilegível descartada", err)
+			l.backfill = true
+			break
+		}
+
+		if err := l.appendStore(record); err != nil {
+			if err := os.WriteFile(l.storeSpillPath, data[offset:], 0644); err != nil {
+				log.Error("Erro ao regravar cópias de auditoria desviadas", err)
+			}
+			return err
+		}
+	}
+	return os.Remove(l.storeSpillPath)
+}
+
+// backfillStore confere o log com o store, em lotes de auditQueueSize, e copia
+// os registros que faltam, como os gravados no arquivo enquanto o serviço
+// parou com cópias pendentes. Append ignora registros já copiados.
+func (l *AuditLogger) backfillStore() error {
+	if l.logPath == "" {
+		return nil
+	}
+	file, err := os.Open(l.logPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	batch := make([]AuditLog, 0, auditQueueSize)
+	flush := func() error {
+		defer func() { batch = batch[:0] }()
+
+		hashes := make([]string, len(batch))
+		records := make(map[string]AuditLog, len(batch))
+		for i, record := range batch {
+			hashes[i] = record.Hash
+			records[record.Hash] = record
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), auditStoreTimeout)
+		missing, err := l.store.Missing(ctx, hashes)
+		cancel()
+		if err != nil {
+			return err
+		}
+		for _, hash := range missing {
+			if err := l.appendStore(records[hash]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(file)
+	for {
+		var record AuditLog
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			// Linha truncada ao final do log por uma interrupção
+			log.Error("Registro de auditoria ilegível ignorado na conferência com o banco", err)
+			break
+		}
+		// Registros anteriores ao encadeamento não têm hash e não são copiados
+		if record.Hash == "" {
+			continue
 		}
-		l.mu.Unlock()
+
+		batch = append(batch, record)
+		if len(batch) == auditQueueSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(batch) > 0 {
+		return flush()
+	}
+	return nil
+}
+
+// auditEntry converte o registro selado para o formato do store
+func auditEntry(log AuditLog) *store.AuditEntry {
+	return &store.AuditEntry{
+		Sequence:      log.Sequence,
+		PrevHash:      log.PrevHash,
+		Hash:          log.Hash,
+		UserID:        log.UserID,
+		ActorID:       log.ActorID,
+		Action:        log.Action,
+		Resource:      log.Resource,
+		Timestamp:     log.Timestamp,
+		IP:            log.IP,
+		Details:       log.Details,
+		Outcome:       log.Outcome,
+		Changes:       log.Changes,
+		UserAgent:     log.UserAgent,
+		CorrelationID: log.CorrelationID,
+	}
+}
+
+// checkpoint assina o último registro gravado, se houve registros desde o
+// checkpoint anterior
+func (l *AuditLogger) checkpoint(now time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.checkpoints == nil || l.head.Sequence == l.lastCheckpoint {
+		return nil
 	}
+
+	checkpoint, err := auditchain.NewCheckpoint(l.head, now, l.sign)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	if _, err := l.checkpoints.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	l.lastCheckpoint = checkpoint.Sequence
+	return nil
 }
 
-// Log registra uma nova entrada de auditoria
-func (l *AuditLogger) Log(userID, action, resource, ip, details string) {
-	log := AuditLog{
-		UserID:    userID,
-		Action:    action,
-		Resource:  resource,
-		Timestamp: time.Now(),
-		IP:        ip,
-		Details:   details,
+// spill grava o registro no arquivo de espera, sincronizado em disco antes de
+// retornar. Só falhas nessa gravação perdem registros, o que é contado em
+// audit_entries_dropped_total.
+func (l *AuditLogger) spill(record AuditLog) {
+	l.spillMu.Lock()
+	defer l.spillMu.Unlock()
+
+	if err := appendSpill(l.spillPath, record); err != nil {
+		authMetrics.AuditEntriesDropped.Inc()
+		log.Error("Erro ao desviar registro de auditoria, registro perdido", err,
+			logger.String("action", record.Action),
+			logger.String("user_id", record.UserID),
+			logger.String("resource", record.Resource),
+			logger.String("correlation_id", record.CorrelationID),
+		)
+		return
 	}
+	l.spilled.Store(true)
+	authMetrics.AuditEntriesSpilled.Inc()
+}
 
+// appendSpill acrescenta o registro ao arquivo de espera
+func appendSpill(path string, log AuditLog) error {
+	if path == "" {
+		return errors.New("arquivo de espera de auditoria não configurado")
+	}
+
+	data, err := json.Marshal(log)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// drainSpilled incorpora ao log os registros do arquivo de espera. O arquivo
+// é renomeado para o lote em gravação (drainPath) com spillMu adquirido e
+// gravado fora do lock, para que novos desvios não aguardem a gravação. Um
+// lote interrompido por uma falha é retomado antes do próximo.
+func (l *AuditLogger) drainSpilled() {
+	if !l.spilled.Load() {
+		return
+	}
+	if !l.drainFile(l.drainPath()) {
+		return
+	}
+
+	l.spillMu.Lock()
+	l.spilled.Store(false)
+	err := os.Rename(l.spillPath, l.drainPath())
+	l.spillMu.Unlock()
+
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		l.spilled.Store(true)
+		log.Error("Erro ao separar registros de auditoria desviados", err)
+		return
+	}
+	l.drainFile(l.drainPath())
+}
+
+// drainPath é o arquivo do lote de registros desviados em gravação
+func (l *AuditLogger) drainPath() string {
+	return l.spillPath + ".draining"
+}
+
+// drainFile grava no log os registros do arquivo e o remove. Em caso de
+// falha, os registros ainda não gravados permanecem no arquivo e drainFile
+// retorna false.
+func (l *AuditLogger) drainFile(path string) bool {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return true
+	}
+	if err != nil {
+		l.spilled.Store(true)
+		log.Error("Erro ao ler registros de auditoria desviados", err)
+		return false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := decoder.InputOffset()
+
+		var record AuditLog
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			// Linha truncada por uma interrupção durante a gravação
+			log.Error("Registro de auditoria desviado ilegível descartado", err)
+			authMetrics.AuditEntriesDropped.Inc()
+			break
+		}
+
+		if err := l.write(record); err != nil {
+			log.Error("Erro ao gravar registros de auditoria desviados", err)
+			if err := os.WriteFile(path, data[offset:], 0644); err != nil {
+				log.Error("Erro ao regravar registros de auditoria desviados", err)
+			}
+			l.spilled.Store(true)
+			return false
+		}
+	}
+
+	if err := os.Remove(path); err != nil {
+		log.Error("Erro ao remover arquivo de registros desviados", err)
+	}
+	return true
+}
+
+// LogEntry registra uma entrada de auditoria já montada. Com a fila cheia,
+// aguarda até enqueueTimeout por espaço e então desvia o registro para o
+// arquivo de espera; após o fechamento, os registros vão direto para ele.
+func (l *AuditLogger) LogEntry(log AuditLog) {
+	log = l.redact(log)
+
+	l.closeMu.RLock()
+	defer l.closeMu.RUnlock()
+
+	if !l.closed && l.enqueue(log) {
+		authMetrics.AuditQueueDepth.Set(float64(len(l.queue)))
+		return
+	}
+	l.spill(log)
+}
+
+// redact aplica a política de dados pessoais ao registro. Acontece antes do
+// encadeamento, para que o hash cubra o valor efetivamente gravado.
+func (l *AuditLogger) redact(log AuditLog) AuditLog {
+	log.IP, _ = l.redactor.String("ip", log.IP)
+	log.UserAgent, _ = l.redactor.String("user_agent", log.UserAgent)
+	log.Resource = l.redactor.Scrub(log.Resource)
+	log.Details = l.redactor.Scrub(log.Details)
+
+	if len(log.Changes) > 0 {
+		changes := make([]auditevent.Change, len(log.Changes))
+		for i, change := range log.Changes {
+			if !change.Redacted {
+				before, keepBefore := l.redactor.Value(change.Field, change.Before)
+				after, keepAfter := l.redactor.Value(change.Field, change.After)
+				if keepBefore && keepAfter {
+					change.Before, change.After = before, after
+				} else {
+					change = auditevent.Change{Field: change.Field, Redacted: true}
+				}
+			}
+			changes[i] = change
+		}
+		log.Changes = changes
+	}
+	return log
+}
+
+// enqueue envia o registro para a fila, aguardando até enqueueTimeout
+func (l *AuditLogger) enqueue(log AuditLog) bool {
 	select {
 	case l.queue <- log:
-		// Log enviado com sucesso
+		return true
 	default:
-		// Buffer cheio, log perdido
-		fmt.Printf("Buffer de logs cheio, log perdido: %+v\n", log)
+	}
+	if l.enqueueTimeout <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(l.enqueueTimeout)
+	defer timer.Stop()
+	select {
+	case l.queue <- log:
+		return true
+	case <-timer.C:
+		return false
 	}
 }
 
-// Fecha o logger de auditoria
+// Fecha o logger de auditoria, aguardando a gravação de todos os registros
+// pendentes
 func (l *AuditLogger) Close() error {
+	l.closeMu.Lock()
+	if l.closed {
+		l.closeMu.Unlock()
+		return nil
+	}
+	l.closed = true
 	close(l.queue)
+	l.closeMu.Unlock()
+
+	if l.done != nil {
+		<-l.done
+	}
+	if l.checkpoints != nil {
+		l.checkpoints.Close()
+	}
 	return l.file.Close()
 }
 
-// Middleware para registrar logs de auditoria
+// Record registra um evento de auditoria completado com a origem da
+// requisição em ctx; implementa auditevent.Recorder
+func (l *AuditLogger) Record(ctx context.Context, event auditevent.Event) {
+	entry := auditevent.NewEntry(ctx, event, time.Now())
+	l.LogEntry(AuditLog{
+		UserID:        entry.UserID,
+		ActorID:       entry.ActorID,
+		Action:        entry.Action,
+		Resource:      entry.Target,
+		Timestamp:     entry.Timestamp,
+		IP:            entry.IP,
+		Details:       entry.Reason,
+		Outcome:       string(entry.Outcome),
+		Changes:       entry.Changes,
+		UserAgent:     entry.UserAgent,
+		CorrelationID: entry.CorrelationID,
+	})
+}
+
+// auditMiddleware registra no contexto a origem da requisição usada nos
+// eventos de auditoria: o IP, o User-Agent e o ID de correlação, recebido no
+// header X-Request-ID ou gerado aqui e devolvido na resposta. O authMiddleware,
+// executado depois, acrescenta as identidades; os eventos são emitidos pelos
+// handlers.
 func auditMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Obter o ID do usuário do contexto (se disponível)
-		userID := "anonymous"
-		if claims, ok := r.Context().Value(userIDKey).(string); ok {
-			userID = claims
-		}
-
-		// Registrar o acesso
-		auditLogger.Log(
-			userID,
-			r.Method,
-			r.URL.Path,
-			r.RemoteAddr,
-			fmt.Sprintf("User-Agent: %s", r.UserAgent()),
-		)
+		correlationID := auditevent.CorrelationID(r.Header.Get(auditevent.CorrelationHeader))
+		w.Header().Set(auditevent.CorrelationHeader, correlationID)
 
-		next.ServeHTTP(w, r)
+		ctx := auditevent.WithOrigin(r.Context(), auditevent.Origin{
+			IP:            clientIP(r),
+			UserAgent:     r.UserAgent(),
+			CorrelationID: correlationID,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/auth-service/infrastructure/store"
+	"github.com/insidechurch/observability/tracing"
+)
+
+// auditStore guarda a cópia consultável dos registros de auditoria,
+// inicializado em main
+var auditStore store.AuditStore
+
+// Tamanho das páginas de GET /audit em JSON
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+)
+
+// auditExportFlushSize é o número de registros enviados ao cliente de cada vez
+// nas exportações
+const auditExportFlushSize = 100
+
+// Formatos de resposta de GET /audit. JSON é paginado; CSV e NDJSON são
+// exportações transmitidas à medida que os registros são lidos.
+const (
+	auditFormatJSON   = "json"
+	auditFormatCSV    = "csv"
+	auditFormatNDJSON = "ndjson"
+)
+
+// auditCSVHeader são as colunas da exportação em CSV
+var auditCSVHeader = []string{
+	"id", "seq", "timestamp", "source", "user_id", "actor_id", "action", "resource", "outcome",
+	"ip", "user_agent", "correlation_id", "details", "changes", "prev_hash", "hash",
+}
+
+// AuditPage é uma página de registros de auditoria. NextCursor, quando
+// presente, é passado em cursor para obter a página seguinte.
+type AuditPage struct {
+	Entries    []*store.AuditEntry `json:"entries"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// parseAuditQuery lê os filtros, o formato e o limite da consulta. Em JSON o
+// limite padrão é defaultAuditPageSize; nas exportações, sem limite, todos os
+// registros a partir do cursor são transmitidos.
+func parseAuditQuery(r *http.Request) (store.AuditFilter, string, error) {
+	query := r.URL.Query()
+	filter := store.AuditFilter{
+		UserID:        query.Get("user_id"),
+		Action:        query.Get("action"),
+		Resource:      query.Get("resource"),
+		Outcome:       query.Get("outcome"),
+		CorrelationID: query.Get("correlation_id"),
+		After:         query.Get("cursor"),
+	}
+
+	// Os IPs são gravados com a redação aplicada; o filtro recebe a mesma
+	// máscara ou hash para encontrá-los
+	if ip := query.Get("ip"); ip != "" {
+		redacted, ok := piiRedactor.String("ip", ip)
+		if !ok {
+			return filter, "", errors.New("ip não é guardado nos registros de auditoria")
+		}
+		filter.IP = redacted
+	}
+
+	format := auditFormat(query, r.Header.Get("Accept"))
+	if format == "" {
+		return filter, "", errors.New("format deve ser json, csv ou ndjson")
+	}
+
+	var err error
+	if filter.From, err = parseAuditTime(query, "from"); err != nil {
+		return filter, "", err
+	}
+	if filter.Until, err = parseAuditTime(query, "to"); err != nil {
+		return filter, "", err
+	}
+	if !filter.From.IsZero() && !filter.Until.IsZero() && !filter.From.Before(filter.Until) {
+		return filter, "", errors.New("from deve ser anterior a to")
+	}
+
+	if value := query.Get("limit"); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil || filter.Limit <= 0 {
+			return filter, "", errors.New("limit deve ser um número positivo")
+		}
+	}
+	if format == auditFormatJSON {
+		if filter.Limit == 0 {
+			filter.Limit = defaultAuditPageSize
+		}
+		if filter.Limit > maxAuditPageSize {
+			filter.Limit = maxAuditPageSize
+		}
+	}
+
+	return filter, format, nil
+}
+
+// auditFormat escolhe o formato pelo parâmetro format ou, sem ele, pelo
+// header Accept; retorna vazio para formatos desconhecidos
+func auditFormat(query url.Values, accept string) string {
+	switch query.Get("format") {
+	case auditFormatJSON:
+		return auditFormatJSON
+	case auditFormatCSV:
+		return auditFormatCSV
+	case auditFormatNDJSON:
+		return auditFormatNDJSON
+	case "":
+	default:
+		return ""
+	}
+
+	switch accept {
+	case "text/csv":
+		return auditFormatCSV
+	case "application/x-ndjson":
+		return auditFormatNDJSON
+	}
+	return auditFormatJSON
+}
+
+// parseAuditTime lê um parâmetro de data no formato RFC 3339
+func parseAuditTime(query url.Values, name string) (time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s deve estar no formato RFC 3339", name)
+	}
+	return parsed, nil
+}
+
+// auditHandler atende GET /audit para administradores: lista os registros de
+// auditoria filtrados por user_id, action, resource, outcome, ip,
+// correlation_id, from e to, paginados por cursor, ou os exporta em CSV ou
+// NDJSON. A própria consulta é registrada na trilha.
+func auditHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "audit", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			return nil
+		}
+
+		filter, format, err := parseAuditQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil
+		}
+
+		auditLogger.Record(ctx, auditevent.Event{
+			Action: auditevent.AuditQueried,
+			Reason: r.URL.RawQuery,
+		})
+
+		switch format {
+		case auditFormatCSV:
+			return exportAuditCSV(ctx, w, filter)
+		case auditFormatNDJSON:
+			return exportAuditNDJSON(ctx, w, filter)
+		}
+		return listAudit(ctx, w, filter)
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler de auditoria", err)
+	}
+}
+
+// listAudit responde com uma página de registros. Um registro além do limite
+// é lido apenas para saber se há página seguinte.
+func listAudit(ctx context.Context, w http.ResponseWriter, filter store.AuditFilter) error {
+	pageSize := filter.Limit
+	filter.Limit++
+
+	page := AuditPage{Entries: make([]*store.AuditEntry, 0, pageSize)}
+	err := auditStore.Scan(ctx, filter, func(entry *store.AuditEntry) error {
+		page.Entries = append(page.Entries, entry)
+		return nil
+	})
+	if errors.Is(err, store.ErrInvalidAuditCursor) {
+		http.Error(w, "Cursor inválido", http.StatusBadRequest)
+		return nil
+	}
+	if err != nil {
+		http.Error(w, "Erro ao consultar auditoria", http.StatusInternalServerError)
+		return err
+	}
+
+	if len(page.Entries) > pageSize {
+		page.Entries = page.Entries[:pageSize]
+		page.NextCursor = page.Entries[pageSize-1].ID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+	return nil
+}
+
+// exportAuditCSV transmite os registros em CSV, com cabeçalho
+func exportAuditCSV(ctx context.Context, w http.ResponseWriter, filter store.AuditFilter) error {
+	writer := csv.NewWriter(w)
+	begin := func() error {
+		writer.Write(auditCSVHeader)
+		writer.Flush()
+		return writer.Error()
+	}
+	return exportAudit(ctx, w, filter, "text/csv; charset=utf-8", "csv", begin, func(entry *store.AuditEntry) error {
+		writer.Write([]string{
+			entry.ID,
+			strconv.FormatUint(entry.Sequence, 10),
+			entry.Timestamp.UTC().Format(time.RFC3339Nano),
+			entry.Source,
+			csvCell(entry.UserID),
+			csvCell(entry.ActorID),
+			csvCell(entry.Action),
+			csvCell(entry.Resource),
+			entry.Outcome,
+			csvCell(entry.IP),
+			csvCell(entry.UserAgent),
+			csvCell(entry.CorrelationID),
+			csvCell(entry.Details),
+			auditChangesCell(entry.Changes),
+			entry.PrevHash,
+			entry.Hash,
+		})
+		writer.Flush()
+		return writer.Error()
+	})
+}
+
+// csvCell impede que planilhas interpretem como fórmula um valor vindo da
+// requisição, como o User-Agent
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// auditChangesCell serializa as alterações de um registro em JSON numa única
+// coluna
+func auditChangesCell(changes []auditevent.Change) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// exportAuditNDJSON transmite os registros em NDJSON, um objeto por linha
+func exportAuditNDJSON(ctx context.Context, w http.ResponseWriter, filter store.AuditFilter) error {
+	encoder := json.NewEncoder(w)
+	return exportAudit(ctx, w, filter, "application/x-ndjson", "ndjson", nil, func(entry *store.AuditEntry) error {
+		return encoder.Encode(entry)
+	})
+}
+
+// exportAudit transmite os registros escritos por write, precedidos do que
+// begin escrever. Os cabeçalhos só são enviados com o primeiro registro ou ao
+// final da consulta, de modo que um cursor inválido ou uma falha na consulta
+// ainda resultam em status de erro; uma falha no meio da transmissão apenas
+// interrompe a resposta.
+func exportAudit(ctx context.Context, w http.ResponseWriter, filter store.AuditFilter, contentType, extension string, begin func() error, write func(*store.AuditEntry) error) error {
+	started := false
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="audit.%s"`, extension))
+		w.WriteHeader(http.StatusOK)
+		if begin != nil {
+			return begin()
+		}
+		return nil
+	}
+
+	flusher, _ := w.(http.Flusher)
+	written := 0
+	err := auditStore.Scan(ctx, filter, func(entry *store.AuditEntry) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := write(entry); err != nil {
+			return err
+		}
+		if written++; flusher != nil && written%auditExportFlushSize == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+
+	switch {
+	case started:
+		return err
+	case errors.Is(err, store.ErrInvalidAuditCursor):
+		http.Error(w, "Cursor inválido", http.StatusBadRequest)
+		return nil
+	case err != nil:
+		http.Error(w, "Erro ao consultar auditoria", http.StatusInternalServerError)
+		return err
+	}
+	return start()
+}
@@ -0,0 +1,99 @@
+// Comando audit-verify confere o encadeamento do log de auditoria do
+// auth-service e, opcionalmente, os checkpoints assinados:
+//
+//	audit-verify -log logs/audit.log
+//	audit-verify -log logs/audit.log -checkpoints logs/audit.checkpoints -public-keys jwt.pub
+//
+// Termina com código 1 ao encontrar o primeiro registro alterado, removido ou
+// fora de sequência, e com código 2 em erros de uso ou leitura.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/insidechurch/keys"
+
+	"github.com/insidechurch/auth-service/infrastructure/auditchain"
+)
+
+func main() {
+	logPath := flag.String("log", "logs/audit.log", "arquivo do log de auditoria")
+	checkpointPath := flag.String("checkpoints", "", "arquivo de checkpoints assinados (opcional)")
+	publicKeys := flag.String("public-keys", "", "chaves públicas PEM do auth-service, separadas por vírgula, para conferir os checkpoints")
+	flag.Parse()
+
+	var checkpoints []auditchain.Checkpoint
+	if *checkpointPath != "" {
+		var err error
+		checkpoints, err = readCheckpoints(*checkpointPath, *publicKeys)
+		if err != nil {
+			fail(2, err)
+		}
+	}
+
+	file, err := os.Open(*logPath)
+	if err != nil {
+		fail(2, fmt.Errorf("erro ao abrir log de auditoria: %w", err))
+	}
+	defer file.Close()
+
+	report, err := auditchain.Verify(file, checkpoints)
+
+	fmt.Printf("Registros encadeados verificados: %d\n", report.Records)
+	if report.Legacy > 0 {
+		fmt.Printf("Registros anteriores ao encadeamento (não verificáveis): %d\n", report.Legacy)
+	}
+	if len(checkpoints) > 0 {
+		fmt.Printf("Checkpoints assinados conferidos: %d de %d\n", report.Checkpoints, len(checkpoints))
+	}
+
+	var breakErr *auditchain.BreakError
+	if errors.As(err, &breakErr) {
+		fail(1, breakErr)
+	}
+	if err != nil {
+		fail(2, err)
+	}
+
+	fmt.Printf("Log íntegro até a seq %d (hash %s)\n", report.Head.Sequence, report.Head.Hash)
+}
+
+// readCheckpoints lê os checkpoints e confere suas assinaturas
+func readCheckpoints(path, publicKeys string) ([]auditchain.Checkpoint, error) {
+	var files []string
+	for _, file := range strings.Split(publicKeys, ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("-public-keys é obrigatório para conferir os checkpoints")
+	}
+
+	public, err := keys.LoadPublicKeys(files...)
+	if err != nil {
+		return nil, err
+	}
+	keySet, err := keys.NewVerificationSet(public...)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir checkpoints: %w", err)
+	}
+	defer file.Close()
+
+	return auditchain.ReadCheckpoints(file, keySet.Keyfunc, keySet.Methods())
+}
+
+// fail informa o erro e encerra com o código indicado
+func fail(code int, err error) {
+	fmt.Fprintf(os.Stderr, "Erro: %v\n", err)
+	os.Exit(code)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/auth-service/infrastructure/store"
+	"github.com/insidechurch/observability/logger"
+	"github.com/insidechurch/observability/tracing"
+)
+
+// Tokens de personificação ("agir como membro"): não têm refresh token e
+// valem por pouco tempo
+const (
+	impersonationTokenType = "impersonation"
+	impersonationTokenTTL  = 10 * time.Minute
+)
+
+type ImpersonateRequest struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"` // motivo registrado na auditoria
+}
+
+type ImpersonateResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	UserID      string `json:"user_id"`
+}
+
+// impersonated verifica se a requisição usa um token de personificação
+func impersonated(ctx context.Context) bool {
+	claims, ok := ctx.Value(claimsKey).(*Claims)
+	return ok && claims.ActorID != ""
+}
+
+// denyImpersonation bloqueia ações sensíveis, como encerrar sessões ou criar
+// credenciais, quando a requisição é feita em nome de outro usuário. Deve ser
+// usado após authMiddleware.
+func denyImpersonation(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if impersonated(r.Context()) {
+			http.Error(w, "Ação não permitida durante a personificação", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// auditImpersonatedRequest registra cada requisição feita com um token de
+// personificação, com o método, o caminho e o resultado, para que a trilha
+// mostre tudo o que o administrador consultou em nome do membro, e não apenas
+// as ações que emitem eventos
+func auditImpersonatedRequest(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		outcome := auditevent.OutcomeSuccess
+		switch {
+		case sw.status == http.StatusUnauthorized || sw.status == http.StatusForbidden:
+			outcome = auditevent.OutcomeDenied
+		case sw.status >= http.StatusBadRequest:
+			outcome = auditevent.OutcomeFailure
+		}
+
+		userID, _ := r.Context().Value(userIDKey).(string)
+		auditLogger.Record(r.Context(), auditevent.Event{
+			Action:  auditevent.ImpersonatedRequest,
+			Target:  auditevent.Target(auditevent.TargetUser, userID),
+			Outcome: outcome,
+			Reason:  r.Method + " " + r.URL.Path,
+		})
+	}
+}
+
+// statusWriter guarda o status da resposta
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// impersonateHandler emite para um administrador um token que age em nome do
+// membro informado. O token carrega as duas identidades e é vinculado à sessão
+// do administrador: encerrar essa sessão também o invalida.
+func impersonateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "impersonate", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			return nil
+		}
+
+		admin, ok := ctx.Value(claimsKey).(*Claims)
+		if !ok {
+			http.Error(w, "Token inválido", http.StatusUnauthorized)
+			return nil
+		}
+
+		var req ImpersonateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return nil
+		}
+		req.Reason = strings.TrimSpace(req.Reason)
+		if req.UserID == "" || req.Reason == "" {
+			http.Error(w, "Usuário e motivo são obrigatórios", http.StatusBadRequest)
+			return nil
+		}
+		if req.UserID == admin.UserID {
+			http.Error(w, "Não é possível personificar a si mesmo", http.StatusBadRequest)
+			return nil
+		}
+
+		user, err := userStore.FindByID(ctx, req.UserID)
+		if err != nil {
+			if errors.Is(err, store.ErrUserNotFound) {
+				http.Error(w, "Usuário não encontrado", http.StatusNotFound)
+				return nil
+			}
+			http.Error(w, "Erro ao buscar usuário", http.StatusInternalServerError)
+			return err
+		}
+
+		// Personificar outro administrador daria acesso às suas rotas
+		if user.Role == adminRole {
+			http.Error(w, "Administradores não podem ser personificados", http.StatusForbidden)
+			return nil
+		}
+
+		now := time.Now()
+		token, err := signingKeys.Sign(&Claims{
+			UserID:    user.ID,
+			Type:      impersonationTokenType,
+			SessionID: admin.SessionID,
+			ActorID:   admin.UserID,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        uuid.NewString(),
+				ExpiresAt: jwt.NewNumericDate(now.Add(impersonationTokenTTL)),
+				IssuedAt:  jwt.NewNumericDate(now),
+			},
+		})
+		if err != nil {
+			http.Error(w, "Erro ao gerar token", http.StatusInternalServerError)
+			return err
+		}
+
+		authMetrics.TokenGenerations.WithLabelValues(impersonationTokenType).Inc()
+		auditLogger.Record(ctx, auditevent.Event{
+			Action: auditevent.ImpersonationStarted,
+			Target: auditevent.Target(auditevent.TargetUser, user.ID),
+			Reason: req.Reason,
+		})
+		log.Info("Personificação iniciada",
+			logger.String("actor_id", admin.UserID),
+			logger.String("user_id", user.ID),
+			logger.String("reason", req.Reason),
+		)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ImpersonateResponse{
+			AccessToken: token,
+			ExpiresIn:   int(impersonationTokenTTL.Seconds()),
+			UserID:      user.ID,
+		})
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler de personificação", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Package auditchain encadeia os registros do log de auditoria para tornar
+// evidente qualquer alteração ou remoção. Cada registro é um objeto JSON com
+// o número de sequência ("seq"), o hash do registro anterior ("prev_hash") e,
+// como último campo, o próprio hash ("hash"): o SHA-256 dos bytes do registro
+// sem esse campo. Como o hash é calculado sobre os bytes gravados, e não
+// sobre uma nova serialização, novos campos podem ser acrescentados ao
+// registro sem invalidar o log existente.
+package auditchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidRecord indica um registro que não é um objeto JSON encadeável
+var ErrInvalidRecord = errors.New("registro de auditoria inválido")
+
+// Head identifica o último registro do encadeamento
+type Head struct {
+	Sequence uint64 `json:"seq"`
+	Hash     string `json:"hash"`
+}
+
+// Next retorna a sequência do próximo registro
+func (h Head) Next() uint64 {
+	return h.Sequence + 1
+}
+
+// link são os campos de encadeamento lidos de cada registro
+type link struct {
+	Sequence uint64 `json:"seq"`
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+// Seal acrescenta o hash ao registro serializado, que já deve conter "seq" e
+// "prev_hash" e não pode conter "hash". Retorna o registro selado e o hash.
+func Seal(unsealed []byte) ([]byte, string, error) {
+	unsealed = bytes.TrimSpace(unsealed)
+	if len(unsealed) < 2 || unsealed[0] != '{' || unsealed[len(unsealed)-1] != '}' {
+		return nil, "", ErrInvalidRecord
+	}
+
+	hash := hashOf(unsealed)
+	sealed := make([]byte, 0, len(unsealed)+len(hashSuffix(hash)))
+	sealed = append(sealed, unsealed[:len(unsealed)-1]...)
+	sealed = append(sealed, hashSuffix(hash)...)
+	return sealed, hash, nil
+}
+
+// hashOf calcula o hash hexadecimal dos bytes do registro
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashSuffix é o trecho acrescentado por Seal ao final do objeto JSON
+func hashSuffix(hash string) string {
+	return `,"hash":"` + hash + `"}`
+}
+
+// unseal reconstrói os bytes sobre os quais o hash do registro foi calculado
+func unseal(sealed []byte, hash string) ([]byte, bool) {
+	suffix := hashSuffix(hash)
+	if !bytes.HasSuffix(sealed, []byte(suffix)) {
+		return nil, false
+	}
+	unsealed := make([]byte, 0, len(sealed)-len(suffix)+1)
+	unsealed = append(unsealed, sealed[:len(sealed)-len(suffix)]...)
+	return append(unsealed, '}'), true
+}
+
+// ReadHead percorre o log e retorna o último registro encadeado, sem validar
+// o encadeamento. Registros anteriores ao encadeamento são ignorados; um log
+// vazio retorna a posição inicial.
+func ReadHead(r io.Reader) (Head, error) {
+	var head Head
+	decoder := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == io.EOF {
+			return head, nil
+		} else if err != nil {
+			return head, fmt.Errorf("erro ao ler log de auditoria: %w", err)
+		}
+
+		var l link
+		if err := json.Unmarshal(raw, &l); err != nil {
+			return head, fmt.Errorf("erro ao ler log de auditoria: %w", err)
+		}
+		if l.Sequence > 0 {
+			head = Head{Sequence: l.Sequence, Hash: l.Hash}
+		}
+	}
+}
+
+// BreakError descreve o primeiro ponto em que o encadeamento foi rompido.
+// Record é a posição do registro no arquivo, a partir de 1.
+type BreakError struct {
+	Record   int
+	Sequence uint64
+	Reason   string
+}
+
+func (e *BreakError) Error() string {
+	return fmt.Sprintf("encadeamento rompido no registro %d (seq %d): %s", e.Record, e.Sequence, e.Reason)
+}
+
+// Report resume a verificação do log
+type Report struct {
+	// Records é o total de registros encadeados verificados
+	Records int
+	// Legacy conta os registros anteriores ao encadeamento, que não podem
+	// ser verificados
+	Legacy int
+	// Checkpoints conta os checkpoints conferidos com o log
+	Checkpoints int
+	Head        Head
+}
+
+// Verify percorre o log e confere o hash de cada registro, a continuidade da
+// sequência e o hash do registro anterior, parando no primeiro problema, que
+// é retornado como *BreakError. Os checkpoints informados, em ordem de
+// sequência, são conferidos com os registros correspondentes; um checkpoint
+// além do fim do log indica que registros finais foram removidos.
+func Verify(r io.Reader, checkpoints []Checkpoint) (*Report, error) {
+	report := &Report{}
+	decoder := json.NewDecoder(r)
+	position := 0
+
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return report, &BreakError{
+				Record:   position + 1,
+				Sequence: report.Head.Next(),
+				Reason:   fmt.Sprintf("registro ilegível: %v", err),
+			}
+		}
+		position++
+
+		var l link
+		if err := json.Unmarshal(raw, &l); err != nil {
+			return report, &BreakError{Record: position, Sequence: report.Head.Next(), Reason: "registro não é um objeto JSON"}
+		}
+
+		if l.Sequence == 0 {
+			if report.Records > 0 {
+				return report, &BreakError{Record: position, Sequence: report.Head.Next(), Reason: "registro sem encadeamento após o início da cadeia"}
+			}
+			report.Legacy++
+			continue
+		}
+
+		fail := func(reason string) (*Report, error) {
+			return report, &BreakError{Record: position, Sequence: l.Sequence, Reason: reason}
+		}
+
+		unsealed, ok := unseal(raw, l.Hash)
+		if !ok || hashOf(unsealed) != l.Hash {
+			return fail("hash não confere, o registro foi alterado")
+		}
+		if l.Sequence != report.Head.Next() {
+			return fail(fmt.Sprintf("sequência interrompida, esperava %d", report.Head.Next()))
+		}
+		if l.PrevHash != report.Head.Hash {
+			return fail("hash anterior não confere, um registro foi alterado ou removido")
+		}
+
+		report.Records++
+		report.Head = Head{Sequence: l.Sequence, Hash: l.Hash}
+
+		for len(checkpoints) > 0 && checkpoints[0].Sequence <= l.Sequence {
+			if checkpoints[0].Sequence == l.Sequence && checkpoints[0].Hash != l.Hash {
+				return fail("registro diverge do checkpoint assinado")
+			}
+			report.Checkpoints++
+			checkpoints = checkpoints[1:]
+		}
+	}
+
+	if len(checkpoints) > 0 {
+		return report, &BreakError{
+			Record:   position,
+			Sequence: checkpoints[0].Sequence,
+			Reason:   fmt.Sprintf("o log termina na seq %d, antes do checkpoint assinado", report.Head.Sequence),
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,194 @@
+package auditchain
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type record struct {
+	Sequence uint64 `json:"seq"`
+	PrevHash string `json:"prev_hash"`
+	Action   string `json:"action"`
+}
+
+// buildLog grava n registros encadeados, um por linha
+func buildLog(t *testing.T, n int) ([]string, Head) {
+	t.Helper()
+
+	var head Head
+	lines := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		data, _ := json.Marshal(record{Sequence: head.Next(), PrevHash: head.Hash, Action: fmt.Sprintf("acao-%d", i)})
+		sealed, hash, err := Seal(data)
+		if err != nil {
+			t.Fatalf("Não esperava erro, obteve: %v", err)
+		}
+		lines = append(lines, string(sealed))
+		head = Head{Sequence: head.Next(), Hash: hash}
+	}
+	return lines, head
+}
+
+func verifyLines(lines []string, checkpoints []Checkpoint) (*Report, error) {
+	return Verify(strings.NewReader(strings.Join(lines, "\n")+"\n"), checkpoints)
+}
+
+func TestVerifyIntactLog(t *testing.T) {
+	lines, head := buildLog(t, 5)
+
+	report, err := verifyLines(lines, nil)
+	if err != nil {
+		t.Fatalf("Log íntegro deveria ser aceito: %v", err)
+	}
+	if report.Records != 5 || report.Head != head {
+		t.Errorf("Relatório inesperado: %+v", report)
+	}
+
+	resumed, err := ReadHead(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil || resumed != head {
+		t.Errorf("ReadHead deveria retornar %+v, obteve %+v (%v)", head, resumed, err)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	cases := map[string]struct {
+		tamper func([]string) []string
+		record int
+		reason string
+	}{
+		"registro alterado": {
+			tamper: func(lines []string) []string {
+				lines[2] = strings.Replace(lines[2], "acao-2", "acao-x", 1)
+				return lines
+			},
+			record: 3, reason: "hash não confere",
+		},
+		"registro removido": {
+			tamper: func(lines []string) []string {
+				return append(lines[:2], lines[3:]...)
+			},
+			record: 3, reason: "sequência interrompida",
+		},
+		"registro reordenado": {
+			tamper: func(lines []string) []string {
+				lines[1], lines[2] = lines[2], lines[1]
+				return lines
+			},
+			record: 2, reason: "sequência interrompida",
+		},
+		"primeiros registros removidos": {
+			tamper: func(lines []string) []string {
+				return lines[2:]
+			},
+			record: 1, reason: "esperava 1",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			lines, _ := buildLog(t, 5)
+			_, err := verifyLines(tc.tamper(lines), nil)
+
+			var breakErr *BreakError
+			if !errors.As(err, &breakErr) {
+				t.Fatalf("Esperava BreakError, obteve: %v", err)
+			}
+			if breakErr.Record != tc.record || !strings.Contains(breakErr.Reason, tc.reason) {
+				t.Errorf("Esperava registro %d com %q, obteve: %v", tc.record, tc.reason, breakErr)
+			}
+		})
+	}
+}
+
+func TestVerifyRehashedRecordBreaksNextLink(t *testing.T) {
+	lines, _ := buildLog(t, 3)
+
+	// Alterar o registro e recalcular o próprio hash não basta: o registro
+	// seguinte aponta para o hash original
+	var r record
+	json.Unmarshal([]byte(lines[1]), &r)
+	r.Action = "acao-forjada"
+	data, _ := json.Marshal(r)
+	forged, _, _ := Seal(data)
+	lines[1] = string(forged)
+
+	_, err := verifyLines(lines, nil)
+	var breakErr *BreakError
+	if !errors.As(err, &breakErr) || breakErr.Record != 3 || !strings.Contains(breakErr.Reason, "hash anterior") {
+		t.Errorf("Esperava quebra no registro 3, obteve: %v", err)
+	}
+}
+
+func TestVerifyLegacyRecords(t *testing.T) {
+	lines, _ := buildLog(t, 2)
+	legacy := "{\n  \"user_id\": \"anonymous\",\n  \"action\": \"GET\"\n}"
+
+	report, err := verifyLines(append([]string{legacy}, lines...), nil)
+	if err != nil {
+		t.Fatalf("Registros antigos antes da cadeia deveriam ser aceitos: %v", err)
+	}
+	if report.Legacy != 1 || report.Records != 2 {
+		t.Errorf("Relatório inesperado: %+v", report)
+	}
+
+	if _, err := verifyLines(append(lines, legacy), nil); err == nil {
+		t.Error("Registro sem encadeamento após o início da cadeia deveria ser recusado")
+	}
+}
+
+func TestCheckpoints(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	sign := func(claims jwt.Claims) (string, error) {
+		return jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims).SignedString(private)
+	}
+	keyfunc := func(*jwt.Token) (interface{}, error) { return public, nil }
+
+	lines, head := buildLog(t, 4)
+	checkpoint, err := NewCheckpoint(head, time.Now(), sign)
+	if err != nil {
+		t.Fatalf("Não esperava erro, obteve: %v", err)
+	}
+	data, _ := json.Marshal(checkpoint)
+
+	checkpoints, err := ReadCheckpoints(bytes.NewReader(data), keyfunc, []string{"EdDSA"})
+	if err != nil || len(checkpoints) != 1 {
+		t.Fatalf("Checkpoint assinado deveria ser aceito: %v", err)
+	}
+
+	report, err := verifyLines(lines, checkpoints)
+	if err != nil || report.Checkpoints != 1 {
+		t.Errorf("Log deveria conferir com o checkpoint: %+v, %v", report, err)
+	}
+
+	// Registros finais removidos só são detectados pelo checkpoint
+	_, err = verifyLines(lines[:3], checkpoints)
+	var breakErr *BreakError
+	if !errors.As(err, &breakErr) || breakErr.Sequence != 4 {
+		t.Errorf("Esperava quebra antes do checkpoint, obteve: %v", err)
+	}
+
+	// Checkpoint com sequência ou hash adulterados fora do token
+	checkpoint.Hash = strings.Repeat("0", 64)
+	data, _ = json.Marshal(checkpoint)
+	if _, err := ReadCheckpoints(bytes.NewReader(data), keyfunc, []string{"EdDSA"}); !errors.Is(err, ErrInvalidCheckpoint) {
+		t.Errorf("Esperava ErrInvalidCheckpoint, obteve: %v", err)
+	}
+
+	// Checkpoint assinado por outra chave
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	checkpoint, _ = NewCheckpoint(head, time.Now(), sign)
+	data, _ = json.Marshal(checkpoint)
+	otherKeyfunc := func(*jwt.Token) (interface{}, error) { return other, nil }
+	if _, err := ReadCheckpoints(bytes.NewReader(data), otherKeyfunc, []string{"EdDSA"}); !errors.Is(err, ErrInvalidCheckpoint) {
+		t.Errorf("Esperava ErrInvalidCheckpoint, obteve: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package auditchain
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// checkpointSubject identifica os tokens de checkpoint
+const checkpointSubject = "audit-checkpoint"
+
+// ErrInvalidCheckpoint indica um checkpoint com assinatura inválida ou cujo
+// conteúdo diverge do token assinado
+var ErrInvalidCheckpoint = errors.New("checkpoint de auditoria inválido")
+
+// Checkpoint atesta, com a assinatura do auth-service, o último registro do
+// log em um instante. Token é um JWS assinado com a chave publicada no JWKS,
+// de modo que auditores podem conferi-lo sem acesso à chave privada.
+type Checkpoint struct {
+	Sequence  uint64    `json:"seq"`
+	Hash      string    `json:"hash"`
+	Timestamp time.Time `json:"timestamp"`
+	Token     string    `json:"token"`
+}
+
+// checkpointClaims são as claims assinadas no token do checkpoint
+type checkpointClaims struct {
+	Sequence uint64 `json:"seq"`
+	Hash     string `json:"hash"`
+	jwt.RegisteredClaims
+}
+
+// NewCheckpoint assina o último registro do encadeamento com sign,
+// normalmente o Sign do conjunto de chaves do serviço
+func NewCheckpoint(head Head, now time.Time, sign func(jwt.Claims) (string, error)) (Checkpoint, error) {
+	now = now.UTC().Truncate(time.Second)
+	token, err := sign(&checkpointClaims{
+		Sequence: head.Sequence,
+		Hash:     head.Hash,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  checkpointSubject,
+			IssuedAt: jwt.NewNumericDate(now),
+		},
+	})
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("erro ao assinar checkpoint de auditoria: %w", err)
+	}
+
+	return Checkpoint{
+		Sequence:  head.Sequence,
+		Hash:      head.Hash,
+		Timestamp: now,
+		Token:     token,
+	}, nil
+}
+
+// ReadCheckpoints lê os checkpoints, um objeto JSON por linha, confere a
+// assinatura de cada um com keyfunc e os retorna em ordem de sequência
+func ReadCheckpoints(r io.Reader, keyfunc jwt.Keyfunc, methods []string) ([]Checkpoint, error) {
+	var checkpoints []Checkpoint
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var checkpoint Checkpoint
+		if err := json.Unmarshal(scanner.Bytes(), &checkpoint); err != nil {
+			return nil, fmt.Errorf("%w: linha %d ilegível: %v", ErrInvalidCheckpoint, line, err)
+		}
+
+		claims := &checkpointClaims{}
+		_, err := jwt.ParseWithClaims(checkpoint.Token, claims, keyfunc,
+			jwt.WithValidMethods(methods),
+			jwt.WithSubject(checkpointSubject),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: linha %d: %v", ErrInvalidCheckpoint, line, err)
+		}
+		if claims.Sequence != checkpoint.Sequence || claims.Hash != checkpoint.Hash {
+			return nil, fmt.Errorf("%w: linha %d diverge do token assinado", ErrInvalidCheckpoint, line)
+		}
+
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler checkpoints de auditoria: %w", err)
+	}
+
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].Sequence < checkpoints[j].Sequence
+	})
+	return checkpoints, nil
+}
@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/insidechurch/observability/tracing"
+)
+
+// Notification representa uma mensagem entregue pelo notification-service
+type Notification struct {
+	UserID  string `json:"user_id"`
+	Channel string `json:"channel,omitempty"`
+	To      string `json:"to,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	Message string `json:"message"`
+	// Sensitive indica uma mensagem com link de uso único, que o
+	// notification-service entrega sem guardar o conteúdo
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+// Notifier define o envio de notificações ao usuário
+type Notifier interface {
+	Send(ctx context.Context, notification Notification) error
+}
+
+// HTTPNotifier envia notificações ao endpoint POST /notifications do
+// notification-service, autenticado pelo token do serviço, propagando o
+// trace da requisição
+type HTTPNotifier struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewHTTPNotifier cria uma nova instância do HTTPNotifier
+func NewHTTPNotifier(baseURL, token string) *HTTPNotifier {
+	return &HTTPNotifier{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  tracing.NewClient(5 * time.Second),
+	}
+}
+
+// Send envia a notificação ao notification-service
+func (n *HTTPNotifier) Send(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar notificação: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.baseURL+"/notifications", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("erro ao criar requisição de notificação: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+n.token)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("erro ao enviar notificação: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification-service respondeu com status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix identifica as chaves de API no header Authorization, separando-as
+// dos JWTs; deve coincidir com o da API principal, que aceita as mesmas chaves
+const APIKeyPrefix = "ick_"
+
+// apiKeyDisplayLength é o tamanho do início da chave guardado em texto para
+// que o usuário a reconheça na listagem
+const apiKeyDisplayLength = 12
+
+var (
+	ErrAPIKeyNotFound = errors.New("chave de API não encontrada")
+)
+
+// APIKey representa uma chave de API de longa duração usada por integrações.
+// Apenas o hash SHA-256 da chave é persistido. Os escopos são permissões no
+// formato "recurso:ação" e nunca excedem as do papel do usuário dono da chave.
+type APIKey struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active indica se a chave não foi revogada nem expirou
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// HasScope verifica se a chave concede a permissão informada
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyStore define as operações de persistência das chaves de API
+type APIKeyStore interface {
+	// Create registra uma nova chave
+	Create(ctx context.Context, key *APIKey) error
+
+	// FindActive busca pelo hash uma chave não revogada e não expirada
+	FindActive(ctx context.Context, keyHash string) (*APIKey, error)
+
+	// List lista as chaves ativas do usuário, da mais recente para a mais antiga
+	List(ctx context.Context, userID string) ([]*APIKey, error)
+
+	// Revoke revoga a chave do usuário; chaves de outros usuários retornam
+	// ErrAPIKeyNotFound
+	Revoke(ctx context.Context, userID, id string) error
+
+	// Touch registra o último uso da chave
+	Touch(ctx context.Context, id string) error
+}
+
+// NewAPIKey gera uma chave aleatória. O valor em texto é exibido uma única
+// vez ao usuário e o APIKey retornado guarda apenas o hash.
+func NewAPIKey() (string, *APIKey, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return key, &APIKey{
+		Prefix:  key[:apiKeyDisplayLength],
+		KeyHash: HashToken(key),
+	}, nil
+}
+
+// IsAPIKey verifica se a credencial do header Authorization é uma chave de API
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryAPIKeyStore implementa APIKeyStore em memória, usado em testes
+type MemoryAPIKeyStore struct {
+	mu     sync.Mutex
+	nextID int
+	keys   map[string]*APIKey
+}
+
+// NewMemoryAPIKeyStore cria uma nova instância do MemoryAPIKeyStore
+func NewMemoryAPIKeyStore() *MemoryAPIKeyStore {
+	return &MemoryAPIKeyStore{
+		keys: make(map[string]*APIKey),
+	}
+}
+
+// Create registra uma nova chave
+func (s *MemoryAPIKeyStore) Create(ctx context.Context, key *APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	key.ID = strconv.Itoa(s.nextID)
+	key.CreatedAt = time.Now()
+	stored := *key
+	stored.Scopes = append([]string(nil), key.Scopes...)
+	s.keys[key.ID] = &stored
+	return nil
+}
+
+// FindActive busca pelo hash uma chave ativa
+func (s *MemoryAPIKeyStore) FindActive(ctx context.Context, keyHash string) (*APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.keys {
+		if key.KeyHash == keyHash && key.Active(time.Now()) {
+			found := *key
+			return &found, nil
+		}
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+// List lista as chaves ativas do usuário
+func (s *MemoryAPIKeyStore) List(ctx context.Context, userID string) ([]*APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	keys := make([]*APIKey, 0)
+	for _, key := range s.keys {
+		if key.UserID == userID && key.Active(now) {
+			found := *key
+			keys = append(keys, &found)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// Revoke revoga a chave do usuário
+func (s *MemoryAPIKeyStore) Revoke(ctx context.Context, userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, exists := s.keys[id]
+	if !exists || key.UserID != userID || key.RevokedAt != nil {
+		return ErrAPIKeyNotFound
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return nil
+}
+
+// Touch registra o último uso da chave
+func (s *MemoryAPIKeyStore) Touch(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return ErrAPIKeyNotFound
+	}
+	now := time.Now()
+	key.LastUsedAt = &now
+	return nil
+}
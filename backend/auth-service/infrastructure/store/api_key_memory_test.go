@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryAPIKeyStore(t *testing.T) {
+	s := NewMemoryAPIKeyStore()
+	ctx := context.Background()
+
+	key, apiKey, err := NewAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsAPIKey(key) || apiKey.KeyHash != HashToken(key) || apiKey.Prefix != key[:apiKeyDisplayLength] {
+		t.Fatalf("Chave gerada inesperada: %+v", apiKey)
+	}
+	apiKey.UserID = "1"
+	apiKey.Scopes = []string{"members:read"}
+	if err := s.Create(ctx, apiKey); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := s.FindActive(ctx, HashToken(key))
+	if err != nil || !found.HasScope("members:read") || found.HasScope("members:write") {
+		t.Fatalf("Esperava a chave registrada, obteve %+v (%v)", found, err)
+	}
+
+	_, expired, _ := NewAPIKey()
+	expiresAt := time.Now().Add(-time.Minute)
+	expired.UserID = "1"
+	expired.ExpiresAt = &expiresAt
+	s.Create(ctx, expired)
+	if _, err := s.FindActive(ctx, expired.KeyHash); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Chave expirada deveria ser recusada, obteve %v", err)
+	}
+	if keys, _ := s.List(ctx, "1"); len(keys) != 1 {
+		t.Errorf("Esperava apenas a chave ativa, obteve %d", len(keys))
+	}
+
+	// Chaves de outro usuário não podem ser revogadas
+	if err := s.Revoke(ctx, "2", apiKey.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Esperava ErrAPIKeyNotFound, obteve %v", err)
+	}
+	if err := s.Revoke(ctx, "1", apiKey.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindActive(ctx, HashToken(key)); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Chave revogada deveria ser recusada, obteve %v", err)
+	}
+}
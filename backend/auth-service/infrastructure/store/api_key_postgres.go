@@ -0,0 +1,182 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// PostgresAPIKeyStore implementa APIKeyStore usando a tabela api_keys
+type PostgresAPIKeyStore struct {
+	db *sql.DB
+}
+
+// NewPostgresAPIKeyStore cria uma nova instância do PostgresAPIKeyStore
+func NewPostgresAPIKeyStore(db *sql.DB) *PostgresAPIKeyStore {
+	return &PostgresAPIKeyStore{db: db}
+}
+
+// Create registra uma nova chave
+func (s *PostgresAPIKeyStore) Create(ctx context.Context, key *APIKey) error {
+	userID, err := strconv.ParseInt(key.UserID, 10, 64)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	createdBy, err := strconv.ParseInt(key.CreatedBy, 10, 64)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	var id int64
+	err = s.db.QueryRowContext(ctx, query, userID, key.Name, key.Prefix, key.KeyHash, scopes, key.ExpiresAt, createdBy).
+		Scan(&id, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar chave de API: %w", err)
+	}
+	key.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+// FindActive busca pelo hash uma chave ativa
+func (s *PostgresAPIKeyStore) FindActive(ctx context.Context, keyHash string) (*APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_by, created_at, revoked_at
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+	`, keyHash)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chave de API: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("erro ao buscar chave de API: %w", err)
+		}
+		return nil, ErrAPIKeyNotFound
+	}
+	return scanAPIKey(rows)
+}
+
+// List lista as chaves ativas do usuário
+func (s *PostgresAPIKeyStore) List(ctx context.Context, userID string) ([]*APIKey, error) {
+	numericID, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_by, created_at, revoked_at
+		FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+		ORDER BY created_at DESC
+	`, numericID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar chaves de API: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]*APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao listar chaves de API: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke revoga a chave do usuário
+func (s *PostgresAPIKeyStore) Revoke(ctx context.Context, userID, id string) error {
+	numericUserID, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return ErrAPIKeyNotFound
+	}
+	numericID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return ErrAPIKeyNotFound
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE api_keys
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, numericID, numericUserID)
+	if err != nil {
+		return fmt.Errorf("erro ao revogar chave de API: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// Touch registra o último uso da chave. A gravação é limitada a uma por
+// minuto, evitando um UPDATE a cada requisição das integrações.
+func (s *PostgresAPIKeyStore) Touch(ctx context.Context, id string) error {
+	numericID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return ErrAPIKeyNotFound
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE api_keys
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`, numericID)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar uso da chave de API: %w", err)
+	}
+	return nil
+}
+
+// scanAPIKey lê uma chave da linha atual do resultado
+func scanAPIKey(rows *sql.Rows) (*APIKey, error) {
+	var key APIKey
+	var id, userID, createdBy int64
+	var scopes []byte
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+	err := rows.Scan(&id, &userID, &key.Name, &key.Prefix, &key.KeyHash, &scopes,
+		&expiresAt, &lastUsedAt, &createdBy, &key.CreatedAt, &revokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("erro ao ler chave de API: %w", err)
+	}
+	if err := json.Unmarshal(scopes, &key.Scopes); err != nil {
+		return nil, fmt.Errorf("erro ao ler escopos da chave de API %d: %w", id, err)
+	}
+
+	key.ID = strconv.FormatInt(id, 10)
+	key.UserID = strconv.FormatInt(userID, 10)
+	key.CreatedBy = strconv.FormatInt(createdBy, 10)
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/insidechurch/auditevent"
+)
+
+var (
+	ErrInvalidAuditCursor = errors.New("cursor de auditoria inválido")
+)
+
+// AuditSourceAuthService identifica os registros gravados pelo auth-service
+const AuditSourceAuthService = "auth-service"
+
+// AuditEntry é um registro do log de auditoria gravado no banco para
+// consulta. Nos registros do auth-service, Sequence, PrevHash e Hash são os
+// do registro no arquivo de log, o que permite conferir a cópia no banco com
+// a cadeia original; registros de outros serviços, indicados em Source, não
+// são encadeados. ID é atribuído na gravação e define a ordem usada na
+// paginação.
+type AuditEntry struct {
+	ID            string              `json:"id"`
+	Source        string              `json:"source"`
+	Sequence      uint64              `json:"seq"`
+	PrevHash      string              `json:"prev_hash"`
+	Hash          string              `json:"hash"`
+	UserID        string              `json:"user_id"`
+	ActorID       string              `json:"actor_id,omitempty"`
+	Action        string              `json:"action"`
+	Resource      string              `json:"resource"`
+	Outcome       string              `json:"outcome,omitempty"`
+	Changes       []auditevent.Change `json:"changes,omitempty"`
+	Timestamp     time.Time           `json:"timestamp"`
+	IP            string              `json:"ip"`
+	UserAgent     string              `json:"user_agent,omitempty"`
+	CorrelationID string              `json:"correlation_id,omitempty"`
+	Details       string              `json:"details,omitempty"`
+}
+
+// AuditFilter seleciona os registros de auditoria consultados. Campos vazios
+// não filtram. UserID corresponde tanto ao usuário quanto ao administrador
+// que agiu em seu nome; Resource terminado em "*" filtra pelo prefixo.
+type AuditFilter struct {
+	UserID        string
+	Action        string
+	Resource      string
+	IP            string
+	Outcome       string
+	CorrelationID string
+
+	// From e Until delimitam o timestamp do registro; Until é exclusivo
+	From  time.Time
+	Until time.Time
+
+	// After é o cursor da paginação: o ID do último registro já lido
+	After string
+
+	// Limit é o máximo de registros percorridos; zero percorre todos
+	Limit int
+}
+
+// ResourcePrefix retorna o prefixo do filtro de recurso, quando ele termina
+// em "*"
+func (f AuditFilter) ResourcePrefix() (string, bool) {
+	if strings.HasSuffix(f.Resource, "*") {
+		return strings.TrimSuffix(f.Resource, "*"), true
+	}
+	return "", false
+}
+
+// AuditStore define as operações de persistência dos registros de auditoria.
+// Não há alteração nem remoção: registros gravados são imutáveis.
+type AuditStore interface {
+	// Append grava um registro e preenche seu ID. Gravar novamente um registro
+	// com o mesmo hash não o duplica, o que torna seguro repetir a gravação
+	Append(ctx context.Context, entry *AuditEntry) error
+
+	// Missing retorna, na ordem recebida, os hashes que ainda não foram
+	// gravados; usado para completar a cópia do log encadeado
+	Missing(ctx context.Context, hashes []string) ([]string, error)
+
+	// Scan percorre em ordem de gravação os registros que atendem ao filtro,
+	// chamando fn para cada um; um erro de fn interrompe e é retornado
+	Scan(ctx context.Context, filter AuditFilter, fn func(*AuditEntry) error) error
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MemoryAuditStore implementa AuditStore em memória, usado em testes
+type MemoryAuditStore struct {
+	mu      sync.Mutex
+	entries []*AuditEntry
+	hashes  map[string]string
+}
+
+// NewMemoryAuditStore cria uma nova instância do MemoryAuditStore
+func NewMemoryAuditStore() *MemoryAuditStore {
+	return &MemoryAuditStore{
+		hashes: make(map[string]string),
+	}
+}
+
+// Append grava um registro
+func (s *MemoryAuditStore) Append(ctx context.Context, entry *AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, exists := s.hashes[entry.Hash]; exists {
+		entry.ID = id
+		return nil
+	}
+
+	entry.ID = strconv.Itoa(len(s.entries) + 1)
+	if entry.Source == "" {
+		entry.Source = AuditSourceAuthService
+	}
+	stored := *entry
+	s.entries = append(s.entries, &stored)
+	s.hashes[entry.Hash] = entry.ID
+	return nil
+}
+
+// Missing retorna os hashes ainda não gravados
+func (s *MemoryAuditStore) Missing(ctx context.Context, hashes []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var missing []string
+	for _, hash := range hashes {
+		if _, exists := s.hashes[hash]; !exists {
+			missing = append(missing, hash)
+		}
+	}
+	return missing, nil
+}
+
+// Scan percorre os registros que atendem ao filtro
+func (s *MemoryAuditStore) Scan(ctx context.Context, filter AuditFilter, fn func(*AuditEntry) error) error {
+	after := 0
+	if filter.After != "" {
+		var err error
+		if after, err = strconv.Atoi(filter.After); err != nil {
+			return ErrInvalidAuditCursor
+		}
+	}
+
+	// Copiar os registros selecionados para não chamar fn com o lock
+	s.mu.Lock()
+	var selected []AuditEntry
+	for _, entry := range s.entries {
+		if id, _ := strconv.Atoi(entry.ID); id <= after || !matchesAuditFilter(entry, filter) {
+			continue
+		}
+		selected = append(selected, *entry)
+		if filter.Limit > 0 && len(selected) == filter.Limit {
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	for i := range selected {
+		if err := fn(&selected[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchesAuditFilter verifica se o registro atende aos campos do filtro
+func matchesAuditFilter(entry *AuditEntry, filter AuditFilter) bool {
+	if filter.UserID != "" && entry.UserID != filter.UserID && entry.ActorID != filter.UserID {
+		return false
+	}
+	if filter.Action != "" && entry.Action != filter.Action {
+		return false
+	}
+	if prefix, ok := filter.ResourcePrefix(); ok {
+		if !strings.HasPrefix(entry.Resource, prefix) {
+			return false
+		}
+	} else if filter.Resource != "" && entry.Resource != filter.Resource {
+		return false
+	}
+	if filter.IP != "" && entry.IP != filter.IP {
+		return false
+	}
+	if filter.Outcome != "" && entry.Outcome != filter.Outcome {
+		return false
+	}
+	if filter.CorrelationID != "" && entry.CorrelationID != filter.CorrelationID {
+		return false
+	}
+	if !filter.From.IsZero() && entry.Timestamp.Before(filter.From) {
+		return false
+	}
+	if !filter.Until.IsZero() && !entry.Timestamp.Before(filter.Until) {
+		return false
+	}
+	return true
+}
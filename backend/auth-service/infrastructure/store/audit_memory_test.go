@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryAuditStore(t *testing.T) {
+	s := NewMemoryAuditStore()
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, entry := range []*AuditEntry{
+		{Hash: "h1", UserID: "1", Action: "POST", Resource: "/auth/login", IP: "10.0.0.1", Timestamp: start},
+		{Hash: "h2", UserID: "2", ActorID: "1", Action: "GET", Resource: "/auth/sessions", IP: "10.0.0.2", Timestamp: start.Add(time.Hour)},
+		{Hash: "h3", UserID: "2", Action: "DELETE", Resource: "/auth/sessions/s1", IP: "10.0.0.2", Timestamp: start.Add(2 * time.Hour), Outcome: "denied", CorrelationID: "req-1"},
+		{Hash: "h4", UserID: "3", Action: "POST", Resource: "/auth/login", IP: "10.0.0.3", Timestamp: start.Add(3 * time.Hour)},
+	} {
+		entry.Sequence = uint64(i + 1)
+		if err := s.Append(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if entry := s.entries[0]; entry.Source != AuditSourceAuthService {
+		t.Errorf("Origem padrão inesperada: %q", entry.Source)
+	}
+
+	// Regravar o mesmo registro não o duplica
+	again := &AuditEntry{Hash: "h2"}
+	if err := s.Append(ctx, again); err != nil || again.ID != "2" {
+		t.Errorf("Registro repetido deveria manter o ID 2, obteve %q (%v)", again.ID, err)
+	}
+
+	if missing, err := s.Missing(ctx, []string{"h5", "h2", "h0"}); err != nil || strings.Join(missing, ",") != "h5,h0" {
+		t.Errorf("Esperava os hashes h5 e h0 ausentes, obteve %v (%v)", missing, err)
+	}
+
+	scan := func(filter AuditFilter) []string {
+		var hashes []string
+		if err := s.Scan(ctx, filter, func(entry *AuditEntry) error {
+			hashes = append(hashes, entry.Hash)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return hashes
+	}
+
+	cases := map[string]struct {
+		filter AuditFilter
+		want   []string
+	}{
+		"sem filtro":             {AuditFilter{}, []string{"h1", "h2", "h3", "h4"}},
+		"usuário ou ator":        {AuditFilter{UserID: "1"}, []string{"h1", "h2"}},
+		"ação":                   {AuditFilter{Action: "POST"}, []string{"h1", "h4"}},
+		"recurso exato":          {AuditFilter{Resource: "/auth/sessions"}, []string{"h2"}},
+		"prefixo do recurso":     {AuditFilter{Resource: "/auth/sessions*"}, []string{"h2", "h3"}},
+		"IP":                     {AuditFilter{IP: "10.0.0.2"}, []string{"h2", "h3"}},
+		"período":                {AuditFilter{From: start.Add(time.Hour), Until: start.Add(3 * time.Hour)}, []string{"h2", "h3"}},
+		"cursor e limite":        {AuditFilter{After: "1", Limit: 2}, []string{"h2", "h3"}},
+		"cursor após o último":   {AuditFilter{After: "4"}, nil},
+		"filtros combinados":     {AuditFilter{UserID: "2", Action: "DELETE"}, []string{"h3"}},
+		"resultado":              {AuditFilter{Outcome: "denied"}, []string{"h3"}},
+		"ID de correlação":       {AuditFilter{CorrelationID: "req-1"}, []string{"h3"}},
+		"nenhum registro atende": {AuditFilter{IP: "10.9.9.9"}, nil},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := scan(tc.filter)
+			if len(got) != len(tc.want) {
+				t.Fatalf("Esperava %v, obteve %v", tc.want, got)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("Esperava %v, obteve %v", tc.want, got)
+				}
+			}
+		})
+	}
+
+	if err := s.Scan(ctx, AuditFilter{After: "abc"}, func(*AuditEntry) error { return nil }); !errors.Is(err, ErrInvalidAuditCursor) {
+		t.Errorf("Esperava ErrInvalidAuditCursor, obteve %v", err)
+	}
+
+	// Um erro de fn interrompe a leitura
+	stop := errors.New("parar")
+	read := 0
+	err := s.Scan(ctx, AuditFilter{}, func(*AuditEntry) error {
+		read++
+		return stop
+	})
+	if !errors.Is(err, stop) || read != 1 {
+		t.Errorf("Esperava interrupção no primeiro registro, obteve %d registros (%v)", read, err)
+	}
+}
@@ -0,0 +1,179 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PostgresAuditStore implementa AuditStore usando a tabela audit_logs, que
+// recusa UPDATE, DELETE e TRUNCATE
+type PostgresAuditStore struct {
+	db *sql.DB
+}
+
+// NewPostgresAuditStore cria uma nova instância do PostgresAuditStore
+func NewPostgresAuditStore(db *sql.DB) *PostgresAuditStore {
+	return &PostgresAuditStore{db: db}
+}
+
+// Append grava um registro; um registro já gravado mantém o ID original
+func (s *PostgresAuditStore) Append(ctx context.Context, entry *AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+	if entry.Source == "" {
+		entry.Source = AuditSourceAuthService
+	}
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO audit_logs (source, seq, prev_hash, hash, user_id, actor_id, action, resource, outcome,
+				changes, ip, user_agent, correlation_id, details, occurred_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			ON CONFLICT (hash) DO NOTHING
+			RETURNING id
+		)
+		SELECT id FROM inserted
+		UNION ALL
+		SELECT id FROM audit_logs WHERE hash = $4
+		LIMIT 1
+	`
+
+	var id int64
+	err = s.db.QueryRowContext(ctx, query, entry.Source, int64(entry.Sequence), entry.PrevHash, entry.Hash,
+		entry.UserID, entry.ActorID, entry.Action, entry.Resource, entry.Outcome, changes, entry.IP,
+		entry.UserAgent, entry.CorrelationID, entry.Details, entry.Timestamp).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar registro de auditoria: %w", err)
+	}
+	entry.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+// Missing retorna os hashes ainda não gravados, na ordem recebida
+func (s *PostgresAuditStore) Missing(ctx context.Context, hashes []string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.hash
+		FROM unnest($1::text[]) WITH ORDINALITY AS t(hash, position)
+		WHERE NOT EXISTS (SELECT 1 FROM audit_logs WHERE audit_logs.hash = t.hash)
+		ORDER BY t.position
+	`, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar registros de auditoria: %w", err)
+	}
+	defer rows.Close()
+
+	var missing []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("erro ao ler registro de auditoria: %w", err)
+		}
+		missing = append(missing, hash)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao consultar registros de auditoria: %w", err)
+	}
+	return missing, nil
+}
+
+// Scan percorre os registros que atendem ao filtro. As linhas são lidas à
+// medida que fn as consome, sem carregar o resultado inteiro em memória.
+func (s *PostgresAuditStore) Scan(ctx context.Context, filter AuditFilter, fn func(*AuditEntry) error) error {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.After != "" {
+		after, err := strconv.ParseInt(filter.After, 10, 64)
+		if err != nil {
+			return ErrInvalidAuditCursor
+		}
+		where("id > $%d", after)
+	}
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("(user_id = $%d OR actor_id = $%[1]d)", len(args)))
+	}
+	if filter.Action != "" {
+		where("action = $%d", filter.Action)
+	}
+	if prefix, ok := filter.ResourcePrefix(); ok {
+		where(`resource LIKE $%d ESCAPE '\'`, escapeLike(prefix)+"%")
+	} else if filter.Resource != "" {
+		where("resource = $%d", filter.Resource)
+	}
+	if filter.IP != "" {
+		where("ip = $%d", filter.IP)
+	}
+	if filter.Outcome != "" {
+		where("outcome = $%d", filter.Outcome)
+	}
+	if filter.CorrelationID != "" {
+		where("correlation_id = $%d", filter.CorrelationID)
+	}
+	if !filter.From.IsZero() {
+		where("occurred_at >= $%d", filter.From)
+	}
+	if !filter.Until.IsZero() {
+		where("occurred_at < $%d", filter.Until)
+	}
+
+	query := `
+		SELECT id, source, seq, prev_hash, hash, user_id, actor_id, action, resource, outcome, changes,
+			ip, user_agent, correlation_id, details, occurred_at
+		FROM audit_logs
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("erro ao consultar registros de auditoria: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry AuditEntry
+		var id, seq int64
+		var changes []byte
+		err := rows.Scan(&id, &entry.Source, &seq, &entry.PrevHash, &entry.Hash, &entry.UserID, &entry.ActorID,
+			&entry.Action, &entry.Resource, &entry.Outcome, &changes, &entry.IP, &entry.UserAgent,
+			&entry.CorrelationID, &entry.Details, &entry.Timestamp)
+		if err != nil {
+			return fmt.Errorf("erro ao ler registro de auditoria: %w", err)
+		}
+		if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+			return fmt.Errorf("erro ao ler alterações do registro de auditoria %d: %w", id, err)
+		}
+		entry.ID = strconv.FormatInt(id, 10)
+		entry.Sequence = uint64(seq)
+
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao consultar registros de auditoria: %w", err)
+	}
+	return nil
+}
+
+// escapeLike protege os curingas do LIKE em um valor literal
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
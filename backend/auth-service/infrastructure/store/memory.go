@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryUserStore implementa UserStore em memória, usado em testes
+type MemoryUserStore struct {
+	mu      sync.RWMutex
+	nextID  int
+	byID    map[string]*User
+	byEmail map[string]string
+	// rolePermissions guarda as permissões de cada papel
+	rolePermissions map[string][]string
+}
+
+// NewMemoryUserStore cria uma nova instância do MemoryUserStore
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{
+		byID:            make(map[string]*User),
+		byEmail:         make(map[string]string),
+		rolePermissions: make(map[string][]string),
+	}
+}
+
+// Create cria um novo usuário
+func (s *MemoryUserStore) Create(ctx context.Context, user *User) error {
+	email := NormalizeEmail(user.Email)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.byEmail[email]; exists {
+		return ErrEmailAlreadyExists
+	}
+
+	s.nextID++
+	user.ID = strconv.Itoa(s.nextID)
+	user.Email = email
+	user.CreatedAt = time.Now()
+	if user.PasswordChangedAt == nil {
+		changedAt := user.CreatedAt
+		user.PasswordChangedAt = &changedAt
+	}
+
+	stored := *user
+	s.byID[user.ID] = &stored
+	s.byEmail[email] = user.ID
+	return nil
+}
+
+// FindByEmail busca um usuário pelo email
+func (s *MemoryUserStore) FindByEmail(ctx context.Context, email string) (*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, exists := s.byEmail[NormalizeEmail(email)]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	user := *s.byID[id]
+	return &user, nil
+}
+
+// FindByID busca um usuário pelo ID
+func (s *MemoryUserStore) FindByID(ctx context.Context, id string) (*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, exists := s.byID[id]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	user := *stored
+	return &user, nil
+}
+
+// UpdatePasswordHash substitui o hash da senha do usuário
+func (s *MemoryUserStore) UpdatePasswordHash(ctx context.Context, id, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, exists := s.byID[id]
+	if !exists {
+		return ErrUserNotFound
+	}
+	user.Password = hash
+	return nil
+}
+
+// GrantRolePermissions concede permissões ao papel
+func (s *MemoryUserStore) GrantRolePermissions(role string, permissions ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rolePermissions[role] = append(s.rolePermissions[role], permissions...)
+}
+
+// EnableMFA registra o cadastro do segundo fator, feito pela API principal
+func (s *MemoryUserStore) EnableMFA(id string, enabledAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, exists := s.byID[id]; exists {
+		user.MFAEnabledAt = &enabledAt
+	}
+}
+
+// Permissions lista as permissões do papel do usuário
+func (s *MemoryUserStore) Permissions(ctx context.Context, id string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, exists := s.byID[id]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	if user.Role == "" {
+		return []string{}, nil
+	}
+	return append([]string{}, s.rolePermissions[user.Role]...), nil
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestMemoryUserStoreCreate(t *testing.T) {
+	s := NewMemoryUserStore()
+	ctx := context.Background()
+
+	user := &User{Email: "Maria@Email.com", Password: "hash"}
+	if err := s.Create(ctx, user); err != nil {
+		t.Fatalf("Não esperava erro, obteve: %v", err)
+	}
+	if user.ID == "" {
+		t.Error("ID não foi gerado")
+	}
+
+	// Email duplicado, mesmo com capitalização diferente
+	err := s.Create(ctx, &User{Email: "maria@email.com", Password: "hash"})
+	if !errors.Is(err, ErrEmailAlreadyExists) {
+		t.Errorf("Esperava ErrEmailAlreadyExists, obteve: %v", err)
+	}
+
+	found, err := s.FindByEmail(ctx, " MARIA@email.com ")
+	if err != nil {
+		t.Fatalf("Não esperava erro, obteve: %v", err)
+	}
+	if found.ID != user.ID {
+		t.Errorf("ID esperado %s, obtido %s", user.ID, found.ID)
+	}
+
+	if _, err := s.FindByID(ctx, "999"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Esperava ErrUserNotFound, obteve: %v", err)
+	}
+}
+
+func TestMemoryUserStoreUpdatePasswordHash(t *testing.T) {
+	s := NewMemoryUserStore()
+	ctx := context.Background()
+
+	user := &User{Email: "maria@email.com", Password: "hash"}
+	if err := s.Create(ctx, user); err != nil {
+		t.Fatalf("Não esperava erro, obteve: %v", err)
+	}
+	if err := s.UpdatePasswordHash(ctx, user.ID, "novo-hash"); err != nil {
+		t.Fatalf("Não esperava erro, obteve: %v", err)
+	}
+
+	found, _ := s.FindByID(ctx, user.ID)
+	if found.Password != "novo-hash" {
+		t.Errorf("Hash esperado novo-hash, obtido %s", found.Password)
+	}
+	if !found.PasswordChangedAt.Equal(*user.PasswordChangedAt) {
+		t.Error("Data da troca de senha não deveria mudar")
+	}
+
+	if err := s.UpdatePasswordHash(ctx, "999", "hash"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Esperava ErrUserNotFound, obteve: %v", err)
+	}
+}
+
+func TestMemoryUserStoreConcurrentCreate(t *testing.T) {
+	s := NewMemoryUserStore()
+	ctx := context.Background()
+
+	const total = 50
+	ids := make(chan string, total)
+	var wg sync.WaitGroup
+
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			user := &User{Email: fmt.Sprintf("membro%d@email.com", i), Password: "hash"}
+			if err := s.Create(ctx, user); err != nil {
+				t.Errorf("Não esperava erro, obteve: %v", err)
+				return
+			}
+			ids <- user.ID
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("ID duplicado: %s", id)
+		}
+		seen[id] = true
+	}
+}
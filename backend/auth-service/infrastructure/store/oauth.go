@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+var (
+	ErrClientNotFound            = errors.New("cliente OAuth não encontrado")
+	ErrAuthorizationCodeNotFound = errors.New("código de autorização inválido ou expirado")
+)
+
+// Client representa uma aplicação registrada que usa o serviço como provedor
+// OpenID Connect. Clientes sem segredo são públicos (SPA, aplicativos) e
+// dependem apenas do PKCE.
+type Client struct {
+	ID           string    `json:"client_id"`
+	Name         string    `json:"name"`
+	SecretHash   string    `json:"-"` // hash SHA-256 do segredo, vazio para clientes públicos
+	RedirectURIs []string  `json:"redirect_uris"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Public indica se o cliente não possui segredo
+func (c *Client) Public() bool {
+	return c.SecretHash == ""
+}
+
+// AllowsRedirect verifica se a URI de retorno está registrada para o
+// cliente. A comparação é exata, sem normalização ou prefixos.
+func (c *Client) AllowsRedirect(uri string) bool {
+	for _, allowed := range c.RedirectURIs {
+		if allowed == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSecret compara o segredo informado com o hash registrado
+func (c *Client) CheckSecret(secret string) bool {
+	if c.Public() {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(HashToken(secret)), []byte(c.SecretHash)) == 1
+}
+
+// ClientStore define a consulta aos clientes registrados
+type ClientStore interface {
+	// FindClient busca um cliente pelo client_id
+	FindClient(ctx context.Context, id string) (*Client, error)
+}
+
+// AuthorizationCode representa um código emitido em /oauth/authorize e trocado
+// por tokens em /oauth/token. Apenas o hash do código é persistido.
+type AuthorizationCode struct {
+	CodeHash      string
+	ClientID      string
+	UserID        string
+	RedirectURI   string
+	Scope         string
+	Nonce         string
+	CodeChallenge string // S256 do code_verifier (PKCE)
+	AuthTime      time.Time
+	ExpiresAt     time.Time
+	CreatedAt     time.Time
+}
+
+// AuthorizationCodeStore define as operações de persistência dos códigos de autorização
+type AuthorizationCodeStore interface {
+	// Create registra um novo código
+	Create(ctx context.Context, code *AuthorizationCode) error
+
+	// Consume marca o código como usado e o retorna. Códigos inexistentes,
+	// expirados ou já usados retornam ErrAuthorizationCodeNotFound.
+	Consume(ctx context.Context, codeHash string) (*AuthorizationCode, error)
+}
+
+// NewAuthorizationCode gera um código aleatório. O valor em texto é enviado ao
+// cliente e o AuthorizationCode retornado guarda apenas o hash.
+func NewAuthorizationCode(ttl time.Duration) (string, *AuthorizationCode, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+
+	code := base64.RawURLEncoding.EncodeToString(raw)
+	return code, &AuthorizationCode{
+		CodeHash:  HashToken(code),
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryClientStore implementa ClientStore em memória, usado em testes
+type MemoryClientStore struct {
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+// NewMemoryClientStore cria uma nova instância do MemoryClientStore
+func NewMemoryClientStore() *MemoryClientStore {
+	return &MemoryClientStore{
+		clients: make(map[string]*Client),
+	}
+}
+
+// Register registra um cliente
+func (s *MemoryClientStore) Register(client *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client.CreatedAt = time.Now()
+	stored := *client
+	stored.RedirectURIs = append([]string(nil), client.RedirectURIs...)
+	s.clients[client.ID] = &stored
+}
+
+// FindClient busca um cliente pelo client_id
+func (s *MemoryClientStore) FindClient(ctx context.Context, id string) (*Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, exists := s.clients[id]
+	if !exists {
+		return nil, ErrClientNotFound
+	}
+	found := *client
+	return &found, nil
+}
+
+// MemoryAuthorizationCodeStore implementa AuthorizationCodeStore em memória, usado em testes
+type MemoryAuthorizationCodeStore struct {
+	mu    sync.Mutex
+	codes map[string]*AuthorizationCode
+}
+
+// NewMemoryAuthorizationCodeStore cria uma nova instância do MemoryAuthorizationCodeStore
+func NewMemoryAuthorizationCodeStore() *MemoryAuthorizationCodeStore {
+	return &MemoryAuthorizationCodeStore{
+		codes: make(map[string]*AuthorizationCode),
+	}
+}
+
+// Create registra um novo código
+func (s *MemoryAuthorizationCodeStore) Create(ctx context.Context, code *AuthorizationCode) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code.CreatedAt = time.Now()
+	stored := *code
+	s.codes[code.CodeHash] = &stored
+	return nil
+}
+
+// Consume remove o código e o retorna se ainda for válido
+func (s *MemoryAuthorizationCodeStore) Consume(ctx context.Context, codeHash string) (*AuthorizationCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code, exists := s.codes[codeHash]
+	if !exists {
+		return nil, ErrAuthorizationCodeNotFound
+	}
+	delete(s.codes, codeHash)
+
+	if !time.Now().Before(code.ExpiresAt) {
+		return nil, ErrAuthorizationCodeNotFound
+	}
+	return code, nil
+}
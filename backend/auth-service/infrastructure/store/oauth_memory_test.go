@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryAuthorizationCodeStore(t *testing.T) {
+	s := NewMemoryAuthorizationCodeStore()
+	ctx := context.Background()
+
+	code, authorization, err := NewAuthorizationCode(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authorization.CodeHash != HashToken(code) {
+		t.Fatal("Apenas o hash do código deveria ser guardado")
+	}
+	authorization.ClientID = "wiki"
+	authorization.UserID = "1"
+	if err := s.Create(ctx, authorization); err != nil {
+		t.Fatal(err)
+	}
+
+	consumed, err := s.Consume(ctx, HashToken(code))
+	if err != nil || consumed.ClientID != "wiki" || consumed.UserID != "1" {
+		t.Fatalf("Esperava o código registrado, obteve %+v (%v)", consumed, err)
+	}
+	if _, err := s.Consume(ctx, HashToken(code)); !errors.Is(err, ErrAuthorizationCodeNotFound) {
+		t.Errorf("Código já usado deveria ser recusado, obteve %v", err)
+	}
+
+	_, expired, _ := NewAuthorizationCode(-time.Second)
+	s.Create(ctx, expired)
+	if _, err := s.Consume(ctx, expired.CodeHash); !errors.Is(err, ErrAuthorizationCodeNotFound) {
+		t.Errorf("Código expirado deveria ser recusado, obteve %v", err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	client := &Client{
+		ID:           "louvor",
+		SecretHash:   HashToken("segredo"),
+		RedirectURIs: []string{"https://louvor.exemplo.com/callback"},
+	}
+
+	if !client.AllowsRedirect("https://louvor.exemplo.com/callback") {
+		t.Error("URI registrada deveria ser aceita")
+	}
+	for _, uri := range []string{"https://louvor.exemplo.com/callback/", "https://louvor.exemplo.com/callback?x=1", "https://louvor.exemplo.com"} {
+		if client.AllowsRedirect(uri) {
+			t.Errorf("URI %s não deveria ser aceita", uri)
+		}
+	}
+
+	if client.Public() || !client.CheckSecret("segredo") || client.CheckSecret("errado") {
+		t.Error("Segredo do cliente confidencial verificado incorretamente")
+	}
+	if (&Client{}).CheckSecret("") {
+		t.Error("Cliente público não possui segredo")
+	}
+}
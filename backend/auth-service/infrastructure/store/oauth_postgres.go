@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// PostgresClientStore implementa ClientStore usando a tabela oauth_clients
+type PostgresClientStore struct {
+	db *sql.DB
+}
+
+// NewPostgresClientStore cria uma nova instância do PostgresClientStore
+func NewPostgresClientStore(db *sql.DB) *PostgresClientStore {
+	return &PostgresClientStore{db: db}
+}
+
+// FindClient busca um cliente pelo client_id
+func (s *PostgresClientStore) FindClient(ctx context.Context, id string) (*Client, error) {
+	query := `
+		SELECT id, name, COALESCE(secret_hash, ''), redirect_uris, created_at
+		FROM oauth_clients
+		WHERE id = $1
+	`
+
+	var client Client
+	var redirectURIs []byte
+	err := s.db.QueryRowContext(ctx, query, id).
+		Scan(&client.ID, &client.Name, &client.SecretHash, &redirectURIs, &client.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrClientNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar cliente OAuth: %w", err)
+	}
+
+	if err := json.Unmarshal(redirectURIs, &client.RedirectURIs); err != nil {
+		return nil, fmt.Errorf("erro ao ler URIs de retorno do cliente %s: %w", client.ID, err)
+	}
+	return &client, nil
+}
+
+// PostgresAuthorizationCodeStore implementa AuthorizationCodeStore usando a
+// tabela oauth_authorization_codes
+type PostgresAuthorizationCodeStore struct {
+	db *sql.DB
+}
+
+// NewPostgresAuthorizationCodeStore cria uma nova instância do PostgresAuthorizationCodeStore
+func NewPostgresAuthorizationCodeStore(db *sql.DB) *PostgresAuthorizationCodeStore {
+	return &PostgresAuthorizationCodeStore{db: db}
+}
+
+// Create registra um novo código
+func (s *PostgresAuthorizationCodeStore) Create(ctx context.Context, code *AuthorizationCode) error {
+	userID, err := strconv.ParseInt(code.UserID, 10, 64)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	query := `
+		INSERT INTO oauth_authorization_codes
+			(code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, auth_time, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`
+
+	err = s.db.QueryRowContext(ctx, query, code.CodeHash, code.ClientID, userID, code.RedirectURI,
+		code.Scope, code.Nonce, code.CodeChallenge, code.AuthTime, code.ExpiresAt).
+		Scan(&code.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar código de autorização: %w", err)
+	}
+	return nil
+}
+
+// Consume marca o código como usado e o retorna. A condição no UPDATE garante
+// que trocas concorrentes do mesmo código sejam aceitas apenas uma vez.
+func (s *PostgresAuthorizationCodeStore) Consume(ctx context.Context, codeHash string) (*AuthorizationCode, error) {
+	query := `
+		UPDATE oauth_authorization_codes
+		SET used_at = NOW()
+		WHERE code_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, auth_time, expires_at, created_at
+	`
+
+	var code AuthorizationCode
+	var userID int64
+	err := s.db.QueryRowContext(ctx, query, codeHash).
+		Scan(&code.CodeHash, &code.ClientID, &userID, &code.RedirectURI, &code.Scope, &code.Nonce,
+			&code.CodeChallenge, &code.AuthTime, &code.ExpiresAt, &code.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAuthorizationCodeNotFound
+		}
+		return nil, fmt.Errorf("erro ao consumir código de autorização: %w", err)
+	}
+
+	code.UserID = strconv.FormatInt(userID, 10)
+	return &code, nil
+}
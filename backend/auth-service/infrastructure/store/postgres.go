@@ -0,0 +1,201 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Códigos de erro do PostgreSQL
+const (
+	// uniqueViolation indica violação de constraint UNIQUE
+	uniqueViolation = "23505"
+
+	// invalidTextRepresentation indica um valor que não pode ser convertido
+	// para o tipo da coluna, como um UUID malformado
+	invalidTextRepresentation = "22P02"
+)
+
+// PostgresUserStore implementa UserStore usando a tabela users do PostgreSQL
+type PostgresUserStore struct {
+	db *sql.DB
+}
+
+// NewPostgresUserStore cria uma nova instância do PostgresUserStore
+func NewPostgresUserStore(db *sql.DB) *PostgresUserStore {
+	return &PostgresUserStore{db: db}
+}
+
+// Create cria um novo usuário dentro de uma transação. A unicidade do email
+// é garantida pela constraint UNIQUE da tabela, e não por uma busca prévia.
+func (s *PostgresUserStore) Create(ctx context.Context, user *User) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO users (name, email, password, verified_at, password_changed_at)
+		VALUES ($1, $2, $3, $4, COALESCE($5, NOW()))
+		RETURNING id, email, created_at, password_changed_at
+	`
+
+	var id int64
+	var passwordChangedAt time.Time
+	err = tx.QueryRowContext(ctx, query, user.Name, NormalizeEmail(user.Email), user.Password, user.VerifiedAt, user.PasswordChangedAt).
+		Scan(&id, &user.Email, &user.CreatedAt, &passwordChangedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrEmailAlreadyExists
+		}
+		return fmt.Errorf("erro ao criar usuário: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		if isUniqueViolation(err) {
+			return ErrEmailAlreadyExists
+		}
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	user.ID = strconv.FormatInt(id, 10)
+	user.PasswordChangedAt = &passwordChangedAt
+	return nil
+}
+
+// FindByEmail busca um usuário pelo email
+func (s *PostgresUserStore) FindByEmail(ctx context.Context, email string) (*User, error) {
+	query := `
+		SELECT u.id, u.name, u.email, u.password, u.created_at, u.verified_at, u.password_changed_at, COALESCE(r.name, ''),
+			u.mfa_enabled_at, COALESCE(r.mfa_required, FALSE)
+		FROM users u
+		LEFT JOIN roles r ON r.id = u.role_id
+		WHERE u.email = $1
+	`
+
+	return s.findOne(ctx, query, NormalizeEmail(email))
+}
+
+// FindByID busca um usuário pelo ID
+func (s *PostgresUserStore) FindByID(ctx context.Context, id string) (*User, error) {
+	numericID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	query := `
+		SELECT u.id, u.name, u.email, u.password, u.created_at, u.verified_at, u.password_changed_at, COALESCE(r.name, ''),
+			u.mfa_enabled_at, COALESCE(r.mfa_required, FALSE)
+		FROM users u
+		LEFT JOIN roles r ON r.id = u.role_id
+		WHERE u.id = $1
+	`
+
+	return s.findOne(ctx, query, numericID)
+}
+
+// UpdatePasswordHash substitui o hash da senha do usuário
+func (s *PostgresUserStore) UpdatePasswordHash(ctx context.Context, id, hash string) error {
+	numericID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE users SET password = $1, updated_at = NOW()
+		WHERE id = $2
+	`, hash, numericID)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar hash da senha: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar hash da senha: %w", err)
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// Permissions lista as permissões do papel do usuário
+func (s *PostgresUserStore) Permissions(ctx context.Context, id string) ([]string, error) {
+	numericID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT p.resource || ':' || p.action
+		FROM users u
+		JOIN role_permissions rp ON rp.role_id = u.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE u.id = $1
+		ORDER BY p.resource, p.action
+	`, numericID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar permissões: %w", err)
+	}
+	defer rows.Close()
+
+	permissions := make([]string, 0)
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, fmt.Errorf("erro ao ler permissão: %w", err)
+		}
+		permissions = append(permissions, permission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao listar permissões: %w", err)
+	}
+	return permissions, nil
+}
+
+// findOne executa uma consulta que retorna no máximo um usuário
+func (s *PostgresUserStore) findOne(ctx context.Context, query string, arg interface{}) (*User, error) {
+	var user User
+	var id int64
+	var verifiedAt, passwordChangedAt, mfaEnabledAt sql.NullTime
+
+	err := s.db.QueryRowContext(ctx, query, arg).
+		Scan(&id, &user.Name, &user.Email, &user.Password, &user.CreatedAt, &verifiedAt, &passwordChangedAt, &user.Role,
+			&mfaEnabledAt, &user.RoleRequiresMFA)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar usuário: %w", err)
+	}
+
+	user.ID = strconv.FormatInt(id, 10)
+	if verifiedAt.Valid {
+		user.VerifiedAt = &verifiedAt.Time
+	}
+	if passwordChangedAt.Valid {
+		user.PasswordChangedAt = &passwordChangedAt.Time
+	}
+	if mfaEnabledAt.Valid {
+		user.MFAEnabledAt = &mfaEnabledAt.Time
+	}
+	return &user, nil
+}
+
+// isUniqueViolation verifica se o erro é uma violação de constraint UNIQUE
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// isInvalidText verifica se o erro é de um valor inválido para o tipo da coluna
+func isInvalidText(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == invalidTextRepresentation
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token não encontrado")
+	ErrRefreshTokenReused   = errors.New("refresh token já utilizado")
+	ErrRefreshTokenRevoked  = errors.New("refresh token revogado")
+	ErrRefreshTokenExpired  = errors.New("refresh token expirado")
+)
+
+// RefreshToken representa um refresh token emitido e rastreado pelo servidor.
+// Tokens de uma mesma sessão compartilham o FamilyID: cada uso gera um sucessor
+// na família, e a reutilização de um token já trocado revoga a família inteira.
+type RefreshToken struct {
+	ID         string     `json:"id"` // jti do token
+	FamilyID   string     `json:"family_id"`
+	UserID     string     `json:"user_id"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UsedAt     *time.Time `json:"used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy string     `json:"replaced_by,omitempty"`
+}
+
+// RefreshTokenStore define as operações de persistência de refresh tokens
+type RefreshTokenStore interface {
+	// Create registra um novo refresh token
+	Create(ctx context.Context, token *RefreshToken) error
+
+	// Rotate marca o token atual como usado e registra o sucessor na mesma
+	// família de forma atômica. O token atual é retornado sempre que
+	// encontrado, inclusive junto de ErrRefreshTokenReused, para que o
+	// chamador possa revogar a família.
+	Rotate(ctx context.Context, currentID string, next *RefreshToken) (*RefreshToken, error)
+
+	// RevokeFamily revoga todos os tokens de uma família
+	RevokeFamily(ctx context.Context, familyID string) error
+
+	// RevokeUser revoga todos os tokens de um usuário
+	RevokeUser(ctx context.Context, userID string) error
+}
+
+// checkRotatable verifica se o token atual ainda pode ser trocado
+func checkRotatable(token *RefreshToken, now time.Time) error {
+	switch {
+	case token.RevokedAt != nil:
+		return ErrRefreshTokenRevoked
+	case token.UsedAt != nil:
+		return ErrRefreshTokenReused
+	case !now.Before(token.ExpiresAt):
+		return ErrRefreshTokenExpired
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryRefreshTokenStore implementa RefreshTokenStore em memória, usado em testes
+type MemoryRefreshTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]*RefreshToken
+}
+
+// NewMemoryRefreshTokenStore cria uma nova instância do MemoryRefreshTokenStore
+func NewMemoryRefreshTokenStore() *MemoryRefreshTokenStore {
+	return &MemoryRefreshTokenStore{
+		tokens: make(map[string]*RefreshToken),
+	}
+}
+
+// Create registra um novo refresh token
+func (s *MemoryRefreshTokenStore) Create(ctx context.Context, token *RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token.CreatedAt = time.Now()
+	stored := *token
+	s.tokens[token.ID] = &stored
+	return nil
+}
+
+// Rotate marca o token atual como usado e registra o sucessor
+func (s *MemoryRefreshTokenStore) Rotate(ctx context.Context, currentID string, next *RefreshToken) (*RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, exists := s.tokens[currentID]
+	if !exists {
+		return nil, ErrRefreshTokenNotFound
+	}
+
+	now := time.Now()
+	if err := checkRotatable(current, now); err != nil {
+		found := *current
+		return &found, err
+	}
+
+	current.UsedAt = &now
+	current.ReplacedBy = next.ID
+
+	next.FamilyID = current.FamilyID
+	next.UserID = current.UserID
+	next.CreatedAt = now
+	stored := *next
+	s.tokens[next.ID] = &stored
+
+	found := *current
+	return &found, nil
+}
+
+// RevokeFamily revoga todos os tokens de uma família
+func (s *MemoryRefreshTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	s.revokeWhere(func(t *RefreshToken) bool { return t.FamilyID == familyID })
+	return nil
+}
+
+// RevokeUser revoga todos os tokens de um usuário
+func (s *MemoryRefreshTokenStore) RevokeUser(ctx context.Context, userID string) error {
+	s.revokeWhere(func(t *RefreshToken) bool { return t.UserID == userID })
+	return nil
+}
+
+// revokeWhere revoga os tokens ainda ativos que atendem ao filtro
+func (s *MemoryRefreshTokenStore) revokeWhere(match func(*RefreshToken) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range s.tokens {
+		if token.RevokedAt == nil && match(token) {
+			token.RevokedAt = &now
+		}
+	}
+}
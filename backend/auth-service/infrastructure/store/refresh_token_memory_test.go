@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryRefreshTokenStoreRotate(t *testing.T) {
+	s := NewMemoryRefreshTokenStore()
+	ctx := context.Background()
+
+	first := &RefreshToken{ID: "t1", FamilyID: "f1", UserID: "1", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := s.Create(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+
+	second := &RefreshToken{ID: "t2", ExpiresAt: time.Now().Add(time.Hour)}
+	current, err := s.Rotate(ctx, "t1", second)
+	if err != nil {
+		t.Fatalf("Não esperava erro, obteve: %v", err)
+	}
+	if current.ReplacedBy != "t2" {
+		t.Errorf("Sucessor esperado t2, obtido %s", current.ReplacedBy)
+	}
+	if second.FamilyID != "f1" || second.UserID != "1" {
+		t.Error("Sucessor deveria herdar família e usuário")
+	}
+
+	// Reapresentar o token já trocado deve indicar reuso
+	current, err = s.Rotate(ctx, "t1", &RefreshToken{ID: "t3", ExpiresAt: time.Now().Add(time.Hour)})
+	if !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Esperava ErrRefreshTokenReused, obteve: %v", err)
+	}
+	if current == nil || current.FamilyID != "f1" {
+		t.Fatal("Token atual deveria ser retornado junto do erro de reuso")
+	}
+
+	// Após revogar a família, o sucessor legítimo também deixa de valer
+	if err := s.RevokeFamily(ctx, current.FamilyID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Rotate(ctx, "t2", &RefreshToken{ID: "t4", ExpiresAt: time.Now().Add(time.Hour)})
+	if !errors.Is(err, ErrRefreshTokenRevoked) {
+		t.Errorf("Esperava ErrRefreshTokenRevoked, obteve: %v", err)
+	}
+}
+
+func TestMemoryRefreshTokenStoreExpired(t *testing.T) {
+	s := NewMemoryRefreshTokenStore()
+	ctx := context.Background()
+
+	expired := &RefreshToken{ID: "t1", FamilyID: "f1", UserID: "1", ExpiresAt: time.Now().Add(-time.Minute)}
+	if err := s.Create(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := s.Rotate(ctx, "t1", &RefreshToken{ID: "t2", ExpiresAt: time.Now().Add(time.Hour)})
+	if !errors.Is(err, ErrRefreshTokenExpired) {
+		t.Errorf("Esperava ErrRefreshTokenExpired, obteve: %v", err)
+	}
+
+	if _, err := s.Rotate(ctx, "inexistente", &RefreshToken{ID: "t3"}); !errors.Is(err, ErrRefreshTokenNotFound) {
+		t.Errorf("Esperava ErrRefreshTokenNotFound, obteve: %v", err)
+	}
+}
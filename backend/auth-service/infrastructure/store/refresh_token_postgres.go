@@ -0,0 +1,154 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// PostgresRefreshTokenStore implementa RefreshTokenStore usando a tabela refresh_tokens
+type PostgresRefreshTokenStore struct {
+	db *sql.DB
+}
+
+// NewPostgresRefreshTokenStore cria uma nova instância do PostgresRefreshTokenStore
+func NewPostgresRefreshTokenStore(db *sql.DB) *PostgresRefreshTokenStore {
+	return &PostgresRefreshTokenStore{db: db}
+}
+
+// Create registra um novo refresh token
+func (s *PostgresRefreshTokenStore) Create(ctx context.Context, token *RefreshToken) error {
+	userID, err := strconv.ParseInt(token.UserID, 10, 64)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	query := `
+		INSERT INTO refresh_tokens (id, family_id, user_id, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+
+	err = s.db.QueryRowContext(ctx, query, token.ID, token.FamilyID, userID, token.ExpiresAt).
+		Scan(&token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// Rotate marca o token atual como usado e registra o sucessor na mesma
+// transação. O SELECT ... FOR UPDATE serializa trocas concorrentes do mesmo
+// token, de modo que apenas uma delas é aceita.
+func (s *PostgresRefreshTokenStore) Rotate(ctx context.Context, currentID string, next *RefreshToken) (*RefreshToken, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT id, family_id, user_id, expires_at, created_at, used_at, revoked_at, COALESCE(replaced_by::text, '')
+		FROM refresh_tokens
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	current, err := scanRefreshToken(tx.QueryRowContext(ctx, query, currentID))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := checkRotatable(current, now); err != nil {
+		return current, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET used_at = $2, replaced_by = $3
+		WHERE id = $1
+	`, current.ID, now, next.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao marcar refresh token como usado: %w", err)
+	}
+
+	next.FamilyID = current.FamilyID
+	next.UserID = current.UserID
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO refresh_tokens (id, family_id, user_id, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`, next.ID, next.FamilyID, current.UserID, next.ExpiresAt).Scan(&next.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao registrar refresh token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	current.UsedAt = &now
+	current.ReplacedBy = next.ID
+	return current, nil
+}
+
+// RevokeFamily revoga todos os tokens de uma família
+func (s *PostgresRefreshTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, familyID)
+	if err != nil {
+		return fmt.Errorf("erro ao revogar família de refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// RevokeUser revoga todos os tokens de um usuário
+func (s *PostgresRefreshTokenStore) RevokeUser(ctx context.Context, userID string) error {
+	numericID, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, numericID)
+	if err != nil {
+		return fmt.Errorf("erro ao revogar refresh tokens do usuário: %w", err)
+	}
+	return nil
+}
+
+// scanRefreshToken lê um refresh token de uma linha de resultado
+func scanRefreshToken(row *sql.Row) (*RefreshToken, error) {
+	var token RefreshToken
+	var userID int64
+	var usedAt, revokedAt sql.NullTime
+
+	err := row.Scan(&token.ID, &token.FamilyID, &userID, &token.ExpiresAt, &token.CreatedAt,
+		&usedAt, &revokedAt, &token.ReplacedBy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar refresh token: %w", err)
+	}
+
+	token.UserID = strconv.FormatInt(userID, 10)
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return &token, nil
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrSessionNotFound = errors.New("sessão não encontrada")
+)
+
+// Session representa um dispositivo conectado. O ID é o FamilyID dos refresh
+// tokens da sessão, de modo que revogar a sessão equivale a revogar a família.
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// SessionStore define as operações de persistência das sessões
+type SessionStore interface {
+	// Create registra uma nova sessão no login
+	Create(ctx context.Context, session *Session) error
+
+	// Touch atualiza o último acesso, o IP e a validade da sessão a cada refresh
+	Touch(ctx context.Context, id, ip string, expiresAt time.Time) error
+
+	// Find busca uma sessão pelo ID
+	Find(ctx context.Context, id string) (*Session, error)
+
+	// ListActive lista as sessões não revogadas e não expiradas do usuário,
+	// da mais recente para a mais antiga
+	ListActive(ctx context.Context, userID string) ([]*Session, error)
+
+	// Revoke marca a sessão como revogada
+	Revoke(ctx context.Context, id string) error
+
+	// RevokeUser marca todas as sessões do usuário como revogadas
+	RevokeUser(ctx context.Context, userID string) error
+}
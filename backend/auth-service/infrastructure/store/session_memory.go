@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemorySessionStore implementa SessionStore em memória, usado em testes
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewMemorySessionStore cria uma nova instância do MemorySessionStore
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		sessions: make(map[string]*Session),
+	}
+}
+
+// Create registra uma nova sessão
+func (s *MemorySessionStore) Create(ctx context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	session.CreatedAt = now
+	session.LastSeenAt = now
+	stored := *session
+	s.sessions[session.ID] = &stored
+	return nil
+}
+
+// Touch atualiza o último acesso da sessão
+func (s *MemorySessionStore) Touch(ctx context.Context, id, ip string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.sessions[id]
+	if !exists {
+		return ErrSessionNotFound
+	}
+	session.LastSeenAt = time.Now()
+	session.IP = ip
+	session.ExpiresAt = expiresAt
+	return nil
+}
+
+// Find busca uma sessão pelo ID
+func (s *MemorySessionStore) Find(ctx context.Context, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.sessions[id]
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+	found := *session
+	return &found, nil
+}
+
+// ListActive lista as sessões ativas do usuário
+func (s *MemorySessionStore) ListActive(ctx context.Context, userID string) ([]*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	sessions := make([]*Session, 0)
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && now.Before(session.ExpiresAt) {
+			found := *session
+			sessions = append(sessions, &found)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// Revoke marca a sessão como revogada
+func (s *MemorySessionStore) Revoke(ctx context.Context, id string) error {
+	s.revokeWhere(func(session *Session) bool { return session.ID == id })
+	return nil
+}
+
+// RevokeUser marca todas as sessões do usuário como revogadas
+func (s *MemorySessionStore) RevokeUser(ctx context.Context, userID string) error {
+	s.revokeWhere(func(session *Session) bool { return session.UserID == userID })
+	return nil
+}
+
+// revokeWhere revoga as sessões ainda ativas que atendem ao filtro
+func (s *MemorySessionStore) revokeWhere(match func(*Session) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, session := range s.sessions {
+		if session.RevokedAt == nil && match(session) {
+			session.RevokedAt = &now
+		}
+	}
+}
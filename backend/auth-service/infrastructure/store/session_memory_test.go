@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemorySessionStore(t *testing.T) {
+	s := NewMemorySessionStore()
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	for _, session := range []*Session{
+		{ID: "s1", UserID: "1", Device: "Chrome no Windows", ExpiresAt: expiresAt},
+		{ID: "s2", UserID: "1", Device: "Safari no iOS", ExpiresAt: expiresAt},
+		{ID: "s3", UserID: "2", Device: "Firefox no Linux", ExpiresAt: expiresAt},
+		{ID: "s4", UserID: "1", Device: "Expirada", ExpiresAt: time.Now().Add(-time.Minute)},
+	} {
+		if err := s.Create(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A sessão usada mais recentemente aparece primeiro
+	time.Sleep(time.Millisecond)
+	if err := s.Touch(ctx, "s1", "10.0.0.2", expiresAt); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, _ := s.ListActive(ctx, "1")
+	if len(sessions) != 2 || sessions[0].ID != "s1" || sessions[0].IP != "10.0.0.2" {
+		t.Fatalf("Sessões ativas inesperadas: %+v", sessions)
+	}
+
+	s.Revoke(ctx, "s1")
+	if sessions, _ := s.ListActive(ctx, "1"); len(sessions) != 1 || sessions[0].ID != "s2" {
+		t.Errorf("Sessão revogada não deveria ser listada: %+v", sessions)
+	}
+
+	s.RevokeUser(ctx, "1")
+	if sessions, _ := s.ListActive(ctx, "1"); len(sessions) != 0 {
+		t.Errorf("Todas as sessões do usuário deveriam estar revogadas: %+v", sessions)
+	}
+	if sessions, _ := s.ListActive(ctx, "2"); len(sessions) != 1 {
+		t.Error("Sessões de outros usuários não deveriam ser afetadas")
+	}
+
+	if _, err := s.Find(ctx, "inexistente"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Esperava ErrSessionNotFound, obteve %v", err)
+	}
+}
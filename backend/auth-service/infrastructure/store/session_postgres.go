@@ -0,0 +1,190 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostgresSessionStore implementa SessionStore usando a tabela sessions
+type PostgresSessionStore struct {
+	db *sql.DB
+}
+
+// NewPostgresSessionStore cria uma nova instância do PostgresSessionStore
+func NewPostgresSessionStore(db *sql.DB) *PostgresSessionStore {
+	return &PostgresSessionStore{db: db}
+}
+
+// Create registra uma nova sessão
+func (s *PostgresSessionStore) Create(ctx context.Context, session *Session) error {
+	userID, err := strconv.ParseInt(session.UserID, 10, 64)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	query := `
+		INSERT INTO sessions (id, user_id, device, user_agent, ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, last_seen_at
+	`
+
+	err = s.db.QueryRowContext(ctx, query, session.ID, userID, session.Device, session.UserAgent, session.IP, session.ExpiresAt).
+		Scan(&session.CreatedAt, &session.LastSeenAt)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar sessão: %w", err)
+	}
+	return nil
+}
+
+// Touch atualiza o último acesso da sessão
+func (s *PostgresSessionStore) Touch(ctx context.Context, id, ip string, expiresAt time.Time) error {
+	if !validSessionID(id) {
+		return ErrSessionNotFound
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE sessions
+		SET last_seen_at = NOW(), ip = $2, expires_at = $3
+		WHERE id = $1
+	`, id, ip, expiresAt)
+	if isInvalidText(err) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar sessão: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// Find busca uma sessão pelo ID
+func (s *PostgresSessionStore) Find(ctx context.Context, id string) (*Session, error) {
+	// IDs fora do formato UUID não existem; a consulta falharia na conversão
+	if !validSessionID(id) {
+		return nil, ErrSessionNotFound
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, device, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE id = $1
+	`, id)
+	if isInvalidText(err) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar sessão: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("erro ao buscar sessão: %w", err)
+		}
+		return nil, ErrSessionNotFound
+	}
+	return scanSession(rows)
+}
+
+// ListActive lista as sessões ativas do usuário
+func (s *PostgresSessionStore) ListActive(ctx context.Context, userID string) ([]*Session, error) {
+	numericID, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, user_id, device, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC
+	`, numericID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar sessões: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]*Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao listar sessões: %w", err)
+	}
+	return sessions, nil
+}
+
+// Revoke marca a sessão como revogada
+func (s *PostgresSessionStore) Revoke(ctx context.Context, id string) error {
+	if !validSessionID(id) {
+		return nil
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("erro ao revogar sessão: %w", err)
+	}
+	return nil
+}
+
+// RevokeUser marca todas as sessões do usuário como revogadas
+func (s *PostgresSessionStore) RevokeUser(ctx context.Context, userID string) error {
+	numericID, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, numericID)
+	if err != nil {
+		return fmt.Errorf("erro ao revogar sessões do usuário: %w", err)
+	}
+	return nil
+}
+
+// validSessionID verifica se o ID tem o formato UUID da coluna sessions.id
+func validSessionID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+// scanSession lê uma sessão da linha atual do resultado
+func scanSession(rows *sql.Rows) (*Session, error) {
+	var session Session
+	var userID int64
+	var revokedAt sql.NullTime
+
+	err := rows.Scan(&session.ID, &userID, &session.Device, &session.UserAgent, &session.IP,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &revokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("erro ao ler sessão: %w", err)
+	}
+
+	session.UserID = strconv.FormatInt(userID, 10)
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	return &session, nil
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUserNotFound       = errors.New("usuário não encontrado")
+	ErrEmailAlreadyExists = errors.New("email já está em uso")
+)
+
+// User representa as credenciais de um usuário persistidas pelo serviço
+type User struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Password  string    `json:"-"` // hash da senha, nunca serializado
+	CreatedAt time.Time `json:"created_at"`
+	// VerifiedAt é preenchido quando o usuário confirma o email
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	// PasswordChangedAt é a data da última troca de senha, usada na validade
+	// máxima da política de senhas
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+	// Role é o nome do papel do usuário na tabela roles, vazio quando não há papel
+	Role string `json:"role,omitempty"`
+	// MFAEnabledAt é preenchido quando o usuário conclui o cadastro do
+	// segundo fator na API principal
+	MFAEnabledAt *time.Time `json:"-"`
+	// RoleRequiresMFA indica que o papel do usuário exige o segundo fator
+	RoleRequiresMFA bool `json:"-"`
+}
+
+// RequiresMFA indica se o login do usuário depende do segundo fator, por
+// cadastro próprio ou pela política do papel
+func (u *User) RequiresMFA() bool {
+	return u.MFAEnabledAt != nil || u.RoleRequiresMFA
+}
+
+// UserStore define as operações de persistência de credenciais
+type UserStore interface {
+	// Create cria um novo usuário, garantindo a unicidade do email
+	Create(ctx context.Context, user *User) error
+
+	// FindByEmail busca um usuário pelo email
+	FindByEmail(ctx context.Context, email string) (*User, error)
+
+	// FindByID busca um usuário pelo ID
+	FindByID(ctx context.Context, id string) (*User, error)
+
+	// Permissions lista as permissões do papel do usuário no formato "recurso:ação"
+	Permissions(ctx context.Context, id string) ([]string, error)
+
+	// UpdatePasswordHash substitui o hash da senha sem alterar a data da última
+	// troca; usado para refazer, no login, hashes abaixo da configuração atual
+	UpdatePasswordHash(ctx context.Context, id, hash string) error
+}
+
+// NormalizeEmail padroniza o email usado como chave de unicidade
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// Finalidades dos tokens de uso único; devem coincidir com as da API principal,
+// que confirma os tokens gravados na mesma tabela user_tokens
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeMagicLink         = "magic_link"
+)
+
+var (
+	ErrUserTokenNotFound = errors.New("token não encontrado ou expirado")
+)
+
+// UserToken representa um token de uso único enviado ao usuário por email.
+// Apenas o hash SHA-256 do token é persistido. DeviceHash, quando presente,
+// vincula o token ao dispositivo que o solicitou.
+type UserToken struct {
+	UserID     string
+	Purpose    string
+	TokenHash  string
+	DeviceHash string
+	ExpiresAt  time.Time
+	UsedAt     *time.Time
+	CreatedAt  time.Time
+}
+
+// UserTokenStore define as operações de persistência de tokens de uso único
+type UserTokenStore interface {
+	// Create registra um novo token
+	Create(ctx context.Context, token *UserToken) error
+
+	// Consume marca como usado o token válido com o hash e a finalidade
+	// informados e o retorna; tokens usados ou expirados retornam
+	// ErrUserTokenNotFound
+	Consume(ctx context.Context, tokenHash, purpose string) (*UserToken, error)
+}
+
+// NewUserToken gera um token aleatório. O valor em texto é enviado ao usuário
+// e o UserToken retornado guarda apenas o hash.
+func NewUserToken(userID, purpose string, ttl time.Duration) (string, *UserToken, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, &UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// BindDevice vincula o token a um dispositivo. O valor retornado fica com o
+// dispositivo que solicitou o token e precisa ser apresentado junto dele.
+func (t *UserToken) BindDevice() (string, error) {
+	device, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	t.DeviceHash = HashToken(device)
+	return device, nil
+}
+
+// DeviceMatches verifica se o dispositivo é o vinculado ao token; tokens sem
+// vínculo aceitam qualquer dispositivo
+func (t *UserToken) DeviceMatches(device string) bool {
+	if t.DeviceHash == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(t.DeviceHash), []byte(HashToken(device))) == 1
+}
+
+// randomToken gera 32 bytes aleatórios codificados em base64 para URLs
+func randomToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// HashToken calcula o hash SHA-256, em hexadecimal, persistido no lugar de
+// tokens e segredos aleatórios
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
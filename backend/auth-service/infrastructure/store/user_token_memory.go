@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryUserTokenStore implementa UserTokenStore em memória, usado em testes
+type MemoryUserTokenStore struct {
+	mu     sync.Mutex
+	tokens []UserToken
+}
+
+// NewMemoryUserTokenStore cria uma nova instância do MemoryUserTokenStore
+func NewMemoryUserTokenStore() *MemoryUserTokenStore {
+	return &MemoryUserTokenStore{}
+}
+
+// Create registra um novo token
+func (s *MemoryUserTokenStore) Create(ctx context.Context, token *UserToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token.CreatedAt = time.Now()
+	s.tokens = append(s.tokens, *token)
+	return nil
+}
+
+// Consume marca como usado o token válido com o hash e a finalidade informados
+func (s *MemoryUserTokenStore) Consume(ctx context.Context, tokenHash, purpose string) (*UserToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for i := range s.tokens {
+		token := &s.tokens[i]
+		if token.TokenHash != tokenHash || token.Purpose != purpose {
+			continue
+		}
+		if token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+			return nil, ErrUserTokenNotFound
+		}
+		token.UsedAt = &now
+		consumed := *token
+		return &consumed, nil
+	}
+	return nil, ErrUserTokenNotFound
+}
+
+// Tokens retorna uma cópia dos tokens registrados
+func (s *MemoryUserTokenStore) Tokens() []UserToken {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]UserToken(nil), s.tokens...)
+}
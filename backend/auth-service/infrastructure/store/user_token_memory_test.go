@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryUserTokenStoreConsume(t *testing.T) {
+	s := NewMemoryUserTokenStore()
+	ctx := context.Background()
+
+	token, userToken, err := NewUserToken("1", TokenPurposeMagicLink, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	device, err := userToken.BindDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Create(ctx, userToken); err != nil {
+		t.Fatal(err)
+	}
+
+	// A finalidade faz parte da busca
+	if _, err := s.Consume(ctx, HashToken(token), TokenPurposeEmailVerification); !errors.Is(err, ErrUserTokenNotFound) {
+		t.Errorf("Esperava ErrUserTokenNotFound para outra finalidade, obteve: %v", err)
+	}
+
+	consumed, err := s.Consume(ctx, HashToken(token), TokenPurposeMagicLink)
+	if err != nil {
+		t.Fatalf("Não esperava erro, obteve: %v", err)
+	}
+	if consumed.UserID != "1" || consumed.UsedAt == nil {
+		t.Errorf("Token consumido inesperado: %+v", consumed)
+	}
+	if !consumed.DeviceMatches(device) || consumed.DeviceMatches("outro-dispositivo") {
+		t.Error("Token deveria aceitar apenas o dispositivo vinculado")
+	}
+
+	// O token é de uso único
+	if _, err := s.Consume(ctx, HashToken(token), TokenPurposeMagicLink); !errors.Is(err, ErrUserTokenNotFound) {
+		t.Errorf("Esperava ErrUserTokenNotFound no reuso, obteve: %v", err)
+	}
+
+	expired, expiredToken, _ := NewUserToken("1", TokenPurposeMagicLink, -time.Minute)
+	s.Create(ctx, expiredToken)
+	if _, err := s.Consume(ctx, HashToken(expired), TokenPurposeMagicLink); !errors.Is(err, ErrUserTokenNotFound) {
+		t.Errorf("Esperava ErrUserTokenNotFound para token expirado, obteve: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// PostgresUserTokenStore implementa UserTokenStore usando a tabela user_tokens
+type PostgresUserTokenStore struct {
+	db *sql.DB
+}
+
+// NewPostgresUserTokenStore cria uma nova instância do PostgresUserTokenStore
+func NewPostgresUserTokenStore(db *sql.DB) *PostgresUserTokenStore {
+	return &PostgresUserTokenStore{db: db}
+}
+
+// Create registra um novo token
+func (s *PostgresUserTokenStore) Create(ctx context.Context, token *UserToken) error {
+	userID, err := strconv.ParseInt(token.UserID, 10, 64)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	query := `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, device_hash, expires_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING created_at
+	`
+
+	err = s.db.QueryRowContext(ctx, query, userID, token.Purpose, token.TokenHash, token.DeviceHash, token.ExpiresAt).
+		Scan(&token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar token: %w", err)
+	}
+	return nil
+}
+
+// Consume marca como usado o token válido com o hash e a finalidade informados.
+// A atualização condicional garante o uso único mesmo com requisições
+// simultâneas.
+func (s *PostgresUserTokenStore) Consume(ctx context.Context, tokenHash, purpose string) (*UserToken, error) {
+	query := `
+		UPDATE user_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id, COALESCE(device_hash, ''), expires_at, used_at, created_at
+	`
+
+	token := UserToken{Purpose: purpose, TokenHash: tokenHash}
+	var userID int64
+	var usedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, query, tokenHash, purpose).
+		Scan(&userID, &token.DeviceHash, &token.ExpiresAt, &usedAt, &token.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserTokenNotFound
+		}
+		return nil, fmt.Errorf("erro ao consumir token: %w", err)
+	}
+
+	token.UserID = strconv.FormatInt(userID, 10)
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	return &token, nil
+}
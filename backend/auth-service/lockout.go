@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/auth-service/infrastructure/notifier"
+	"github.com/insidechurch/auth-service/infrastructure/store"
+	"github.com/insidechurch/cache"
+	"github.com/insidechurch/lockout"
+	"github.com/insidechurch/observability/logger"
+	"github.com/insidechurch/observability/tracing"
+)
+
+// Controle de falhas de login por conta e por IP, inicializado em main. As
+// políticas e as chaves são as mesmas da API (ver lockout.NewLogin).
+var (
+	loginLockout *lockout.Login
+
+	// adminRole é o papel (tabela roles) com acesso às rotas administrativas
+	adminRole = getEnv("ADMIN_ROLE", "admin")
+
+	// trustedProxies são os proxies (TRUSTED_PROXIES, carregado em init)
+	// cujo header trustedProxyHeader informa o IP de origem; sem proxies
+	// confiáveis, vale o endereço da conexão
+	trustedProxies     []*net.IPNet
+	trustedProxyHeader = getEnv("TRUSTED_PROXY_HEADER", "X-Forwarded-For")
+)
+
+// newLockoutTrackers inicializa o controle de falhas sobre o cache compartilhado
+func newLockoutTrackers(c cache.Cache) {
+	loginLockout = lockout.NewLogin(c)
+}
+
+// parseTrustedProxies lê IPs ou CIDRs separados por vírgula
+func parseTrustedProxies(spec string) ([]*net.IPNet, error) {
+	var proxies []*net.IPNet
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("proxy inválido %q", entry)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// isTrustedProxy informa se o IP pertence a um proxy confiável
+func isTrustedProxy(ip net.IP) bool {
+	for _, network := range trustedProxies {
+		if ip != nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP retorna o IP de origem da requisição, sem a porta. Se a conexão
+// vem de um proxy confiável, o IP é o último endereço de trustedProxyHeader
+// que não pertence a um proxy confiável; os anteriores podem ter sido
+// informados pelo próprio cliente.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(net.ParseIP(host)) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values(trustedProxyHeader), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			break
+		}
+		host = ip.String()
+		if !isTrustedProxy(ip) {
+			break
+		}
+	}
+	return host
+}
+
+// loginRetryAfter retorna quanto tempo o login deve aguardar para a conta ou
+// o IP. Falhas do cache não impedem o login.
+func loginRetryAfter(ctx context.Context, email, ip string) time.Duration {
+	retryAfter, err := loginLockout.RetryAfter(ctx, email, ip)
+	if err != nil {
+		log.Error("Erro ao consultar falhas de login", err, logger.String("ip", ip))
+	}
+	return retryAfter
+}
+
+// recordLoginFailure registra a falha para a conta e para o IP. Quando a
+// falha bloqueia a conta, o usuário é avisado por email. user é nil quando o
+// email não está cadastrado; o contador é mantido mesmo assim, para não
+// revelar quais emails existem.
+func recordLoginFailure(ctx context.Context, email, ip string, user *store.User) {
+	status, err := loginLockout.Fail(ctx, email, ip)
+	if err != nil {
+		log.Error("Erro ao registrar falha de login", err, logger.String("ip", ip))
+	}
+	if !status.NewlyLocked || user == nil {
+		return
+	}
+
+	log.Info("Conta bloqueada por falhas de login",
+		logger.String("user_id", user.ID),
+		logger.String("ip", ip),
+	)
+
+	err = notifications.Send(ctx, notifier.Notification{
+		UserID:  user.ID,
+		Channel: "email",
+		To:      user.Email,
+		Subject: "Conta bloqueada temporariamente",
+		Message: fmt.Sprintf(
+			"Detectamos %d tentativas de login com senha incorreta na sua conta, que ficará bloqueada até %s. "+
+				"Se não foi você, redefina sua senha ao recuperar o acesso.",
+			status.Failures, time.Now().Add(status.RetryAfter).Format("02/01/2006 15:04"),
+		),
+	})
+	if err != nil {
+		log.Error("Erro ao enviar aviso de bloqueio", err, logger.String("user_id", user.ID))
+	}
+}
+
+// clearLoginFailures zera as falhas da conta após um login bem-sucedido. As
+// falhas do IP são mantidas, para que uma conta válida não libere o IP.
+func clearLoginFailures(ctx context.Context, email string) {
+	if err := loginLockout.Reset(ctx, email); err != nil {
+		log.Error("Erro ao limpar falhas de login", err)
+	}
+}
+
+// writeTooManyAttempts responde 429 com o cabeçalho Retry-After em segundos
+func writeTooManyAttempts(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	http.Error(w, "Muitas tentativas de login, tente novamente mais tarde", http.StatusTooManyRequests)
+}
+
+// adminMiddleware restringe a rota a usuários com o papel adminRole. Deve ser
+// usado após authMiddleware; o papel é consultado a cada requisição, de modo
+// que a remoção do papel vale imediatamente. Chaves de API e tokens de
+// personificação não dão acesso administrativo.
+func adminMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(claimsKey).(*Claims)
+		if !ok || claims.ActorID != "" {
+			http.Error(w, "Acesso negado", http.StatusForbidden)
+			return
+		}
+
+		user, err := userStore.FindByID(r.Context(), claims.UserID)
+		if err != nil || user.Role != adminRole {
+			http.Error(w, "Acesso negado", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+type UnlockRequest struct {
+	Email string `json:"email"`
+	IP    string `json:"ip,omitempty"`
+}
+
+// unlockHandler permite a um administrador desbloquear uma conta e,
+// opcionalmente, o IP de origem das falhas
+func unlockHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "unlock", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			return nil
+		}
+
+		var req UnlockRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+			http.Error(w, "Email é obrigatório", http.StatusBadRequest)
+			return nil
+		}
+
+		email := store.NormalizeEmail(req.Email)
+		if err := loginLockout.Reset(ctx, email); err != nil {
+			http.Error(w, "Erro ao desbloquear conta", http.StatusInternalServerError)
+			return err
+		}
+		if req.IP != "" {
+			if err := loginLockout.IP.Reset(ctx, req.IP); err != nil {
+				http.Error(w, "Erro ao desbloquear IP", http.StatusInternalServerError)
+				return err
+			}
+		}
+
+		adminID, _ := ctx.Value(userIDKey).(string)
+		// O alvo é o email: o desbloqueio vale também para emails sem conta
+		auditLogger.Record(ctx, auditevent.Event{
+			Action: auditevent.AccountUnlocked,
+			Target: auditevent.Target(auditevent.TargetUser, email),
+		})
+		log.Info("Bloqueio de login removido",
+			logger.String("admin_id", adminID),
+			logger.String("email", email),
+			logger.String("ip", req.IP),
+		)
+
+		// O aviso de desbloqueio só é enviado a contas cadastradas
+		if user, err := userStore.FindByEmail(ctx, email); err == nil {
+			err = notifications.Send(ctx, notifier.Notification{
+				UserID:  user.ID,
+				Channel: "email",
+				To:      user.Email,
+				Subject: "Conta desbloqueada",
+				Message: "Sua conta foi desbloqueada por um administrador e você já pode fazer login novamente.",
+			})
+			if err != nil {
+				log.Error("Erro ao enviar aviso de desbloqueio", err, logger.String("user_id", user.ID))
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler de desbloqueio", err)
+	}
+}
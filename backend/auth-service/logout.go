@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/observability/logger"
+	"github.com/insidechurch/observability/tracing"
+)
+
+// logoutHandler encerra a sessão atual: o access token usado na requisição
+// entra na denylist até expirar e a família de refresh tokens é revogada
+func logoutHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "logout", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			return nil
+		}
+
+		claims, ok := ctx.Value(claimsKey).(*Claims)
+		if !ok {
+			http.Error(w, "Token inválido", http.StatusUnauthorized)
+			return nil
+		}
+
+		expiresAt := time.Now().Add(accessTokenTTL)
+		if claims.ExpiresAt != nil {
+			expiresAt = claims.ExpiresAt.Time
+		}
+
+		if err := tokenDenylist.RevokeToken(ctx, claims.ID, expiresAt); err != nil {
+			http.Error(w, "Erro ao encerrar sessão", http.StatusInternalServerError)
+			return err
+		}
+
+		// O logout com um token de personificação encerra apenas a
+		// personificação; a sessão é a do administrador
+		if claims.SessionID != "" && claims.ActorID == "" {
+			if err := revokeSession(ctx, claims.SessionID); err != nil {
+				http.Error(w, "Erro ao encerrar sessão", http.StatusInternalServerError)
+				return err
+			}
+		}
+
+		auditLogger.Record(ctx, auditevent.Event{
+			Action: auditevent.SessionRevoked,
+			Target: auditevent.Target(auditevent.TargetSession, claims.SessionID),
+		})
+		log.Info("Sessão encerrada",
+			logger.String("user_id", claims.UserID),
+			logger.String("session_id", claims.SessionID),
+		)
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler de logout", err)
+	}
+}
+
+// logoutAllHandler encerra todas as sessões do usuário: os refresh tokens são
+// revogados e todo access token emitido até agora passa a ser recusado
+func logoutAllHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "logout_all", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			return nil
+		}
+
+		// Apenas sessões encerram sessões; chaves de API não trazem claims
+		claims, ok := ctx.Value(claimsKey).(*Claims)
+		if !ok {
+			http.Error(w, "Token inválido", http.StatusUnauthorized)
+			return nil
+		}
+		userID := claims.UserID
+
+		if err := refreshTokenStore.RevokeUser(ctx, userID); err != nil {
+			http.Error(w, "Erro ao encerrar sessões", http.StatusInternalServerError)
+			return err
+		}
+		if err := sessionStore.RevokeUser(ctx, userID); err != nil {
+			http.Error(w, "Erro ao encerrar sessões", http.StatusInternalServerError)
+			return err
+		}
+
+		// O corte dura o tempo de vida do access token mais longo, o da API:
+		// depois disso todos os tokens anteriores já expiraram naturalmente
+		if err := tokenDenylist.RevokeUser(ctx, userID, userRevocationTTL); err != nil {
+			http.Error(w, "Erro ao encerrar sessões", http.StatusInternalServerError)
+			return err
+		}
+
+		auditLogger.Record(ctx, auditevent.Event{
+			Action: auditevent.SessionsRevoked,
+			Target: auditevent.Target(auditevent.TargetUser, userID),
+		})
+		log.Info("Todas as sessões encerradas",
+			logger.String("user_id", userID),
+		)
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler de logout-all", err)
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/auth-service/infrastructure/notifier"
+	"github.com/insidechurch/auth-service/infrastructure/store"
+	"github.com/insidechurch/observability/logger"
+	"github.com/insidechurch/observability/tracing"
+)
+
+// Validade do link de acesso enviado por email
+const magicLinkTTL = 15 * time.Minute
+
+var (
+	// magicLinkURL é a página do frontend que recebe o token do link de acesso
+	magicLinkURL = getEnv("MAGIC_LINK_URL", "http://localhost:3000/magic-link")
+
+	// magicLinkEnabled permite desativar o login sem senha
+	magicLinkEnabled = getEnv("MAGIC_LINK_ENABLED", "true") == "true"
+)
+
+type MagicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+// MagicLinkResponse traz o identificador do dispositivo que solicitou o link.
+// O cliente o guarda e o apresenta junto do token: o link só funciona no
+// mesmo dispositivo.
+type MagicLinkResponse struct {
+	DeviceToken string `json:"device_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type MagicLinkLoginRequest struct {
+	Token       string `json:"token"`
+	DeviceToken string `json:"device_token"`
+	Device      string `json:"device,omitempty"` // nome do dispositivo exibido na lista de sessões
+}
+
+// magicLinkHandler envia ao email informado um link de acesso de uso único.
+// A resposta é a mesma para emails não cadastrados, para não revelar quais
+// existem.
+func magicLinkHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "magic_link", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			return nil
+		}
+
+		var req MagicLinkRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return nil
+		}
+		email := store.NormalizeEmail(req.Email)
+		if email == "" {
+			http.Error(w, "Email é obrigatório", http.StatusBadRequest)
+			return nil
+		}
+
+		user, err := userStore.FindByEmail(ctx, email)
+		if err != nil && !errors.Is(err, store.ErrUserNotFound) {
+			http.Error(w, "Erro ao buscar usuário", http.StatusInternalServerError)
+			return err
+		}
+
+		var userID string
+		if user != nil {
+			userID = user.ID
+		}
+		token, userToken, err := store.NewUserToken(userID, store.TokenPurposeMagicLink, magicLinkTTL)
+		if err != nil {
+			http.Error(w, "Erro ao gerar link de acesso", http.StatusInternalServerError)
+			return err
+		}
+		deviceToken, err := userToken.BindDevice()
+		if err != nil {
+			http.Error(w, "Erro ao gerar link de acesso", http.StatusInternalServerError)
+			return err
+		}
+
+		if user == nil {
+			log.Info("Link de acesso solicitado para email não encontrado",
+				logger.String("email", email),
+				logger.String("ip", r.RemoteAddr),
+			)
+		} else if err := sendMagicLink(ctx, user, userToken, token); err != nil {
+			// Falhas de entrega não alteram a resposta; o link pode ser
+			// solicitado novamente
+			log.Error("Erro ao enviar link de acesso", err,
+				logger.String("user_id", user.ID),
+			)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(MagicLinkResponse{
+			DeviceToken: deviceToken,
+			ExpiresIn:   int(magicLinkTTL.Seconds()),
+		})
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler de link de acesso", err)
+	}
+}
+
+// sendMagicLink registra o token e envia o link ao usuário pelo
+// notification-service
+func sendMagicLink(ctx context.Context, user *store.User, userToken *store.UserToken, token string) error {
+	if err := userTokenStore.Create(ctx, userToken); err != nil {
+		return err
+	}
+
+	err := notifications.Send(ctx, notifier.Notification{
+		UserID:  user.ID,
+		Channel: "email",
+		To:      user.Email,
+		Subject: "Seu link de acesso",
+		Message: fmt.Sprintf(
+			"Entre na sua conta acessando %s?token=%s. O link é válido por %d minutos e deve ser aberto "+
+				"no mesmo dispositivo em que foi solicitado. Se não foi você, ignore este email.",
+			magicLinkURL, token, int(magicLinkTTL.Minutes()),
+		),
+		Sensitive: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("Link de acesso enviado",
+		logger.String("user_id", user.ID),
+	)
+	return nil
+}
+
+// magicLinkLoginHandler troca o token do link de acesso por um par de tokens,
+// como no login com senha. O token é consumido antes da verificação do
+// dispositivo: um link aberto em outro dispositivo deixa de valer.
+func magicLinkLoginHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "magic_link_login", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			return nil
+		}
+
+		var req MagicLinkLoginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return nil
+		}
+		if req.Token == "" || req.DeviceToken == "" {
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			http.Error(w, "Token e dispositivo são obrigatórios", http.StatusBadRequest)
+			return nil
+		}
+
+		userToken, err := userTokenStore.Consume(ctx, store.HashToken(req.Token), store.TokenPurposeMagicLink)
+		if err != nil {
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			if errors.Is(err, store.ErrUserTokenNotFound) {
+				auditLogger.Record(ctx, auditevent.Event{
+					Action:  auditevent.LoginFailed,
+					Outcome: auditevent.OutcomeFailure,
+					Reason:  auditevent.ReasonInvalidCredentials,
+				})
+				http.Error(w, "Link de acesso inválido ou expirado", http.StatusUnauthorized)
+				return nil
+			}
+			http.Error(w, "Erro ao validar link de acesso", http.StatusInternalServerError)
+			return err
+		}
+
+		if !userToken.DeviceMatches(req.DeviceToken) {
+			log.Info("Link de acesso usado em outro dispositivo",
+				logger.String("user_id", userToken.UserID),
+				logger.String("ip", r.RemoteAddr),
+			)
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			auditLogger.Record(ctx, auditevent.Event{
+				Action:  auditevent.LoginFailed,
+				Target:  auditevent.Target(auditevent.TargetUser, userToken.UserID),
+				Outcome: auditevent.OutcomeDenied,
+				Reason:  auditevent.ReasonDeviceMismatch,
+			})
+			http.Error(w, "Abra o link no mesmo dispositivo em que ele foi solicitado", http.StatusUnauthorized)
+			return nil
+		}
+
+		user, err := userStore.FindByID(ctx, userToken.UserID)
+		if err != nil {
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			if errors.Is(err, store.ErrUserNotFound) {
+				http.Error(w, "Link de acesso inválido ou expirado", http.StatusUnauthorized)
+				return nil
+			}
+			http.Error(w, "Erro ao buscar usuário", http.StatusInternalServerError)
+			return err
+		}
+
+		// Aplicar a política para contas com email não verificado
+		if !loginAllowed(user, time.Now()) {
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			auditLogger.Record(ctx, auditevent.Event{
+				Action:  auditevent.LoginFailed,
+				Target:  auditevent.Target(auditevent.TargetUser, user.ID),
+				Outcome: auditevent.OutcomeDenied,
+				Reason:  auditevent.ReasonEmailNotVerified,
+			})
+			http.Error(w, "Email não verificado", http.StatusForbidden)
+			return nil
+		}
+
+		// O link substitui apenas a senha; o segundo fator é verificado pelo
+		// login da API principal
+		if user.RequiresMFA() {
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			refuseMFALogin(ctx, w, user)
+			return nil
+		}
+
+		tokenPair, err := issueTokenPair(ctx, user.ID, newSession(r, req.Device), clientGrant{})
+		if err != nil {
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			http.Error(w, "Erro ao gerar tokens", http.StatusInternalServerError)
+			return err
+		}
+
+		authMetrics.LoginAttempts.WithLabelValues("success").Inc()
+		authMetrics.TokenGenerations.WithLabelValues("access").Inc()
+		authMetrics.TokenGenerations.WithLabelValues("refresh").Inc()
+		auditLogger.Record(ctx, auditevent.Event{
+			Action: auditevent.LoginSucceeded,
+			UserID: user.ID,
+			Target: auditevent.Target(auditevent.TargetUser, user.ID),
+			Reason: auditevent.ReasonMagicLink,
+		})
+
+		log.Info("Login por link de acesso realizado com sucesso",
+			logger.String("user_id", user.ID),
+			logger.String("email", user.Email),
+		)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tokenPair)
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler de login por link de acesso", err)
+	}
+}
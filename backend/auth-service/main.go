@@ -3,34 +3,110 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
-	"sync"
+	"syscall"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"golang.org/x/crypto/bcrypt"
-	"golang.org/x/time/rate"
-
-	"github.com/insidechurch/auth-service/infrastructure/logger"
-	"github.com/insidechurch/auth-service/infrastructure/metrics"
-	"github.com/insidechurch/auth-service/infrastructure/tracing"
+	"github.com/google/uuid"
+	"github.com/insidechurch/passwordhash"
+	"github.com/insidechurch/passwordpolicy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/auth-service/infrastructure/notifier"
+	"github.com/insidechurch/auth-service/infrastructure/store"
+	"github.com/insidechurch/cache"
+	"github.com/insidechurch/denylist"
+	"github.com/insidechurch/observability"
+	"github.com/insidechurch/observability/logger"
+	"github.com/insidechurch/observability/redact"
+	"github.com/insidechurch/observability/tracing"
+	"github.com/insidechurch/ratelimit"
 )
 
 var (
 	log *logger.JSONLogger
+
+	// piiRedactor aplica a política de dados pessoais aos logs, à auditoria
+	// e aos traces
+	piiRedactor *redact.Redactor
+
+	// obs é a instrumentação comum aos serviços: logs, métricas HTTP,
+	// tracing e a cadeia de middlewares aplicada a todas as rotas
+	obs *observability.Observability
+
+	// authMetrics são as métricas próprias do serviço, expostas em /metrics
+	// junto com as de obs
+	authMetrics = newServiceMetrics(prometheus.DefaultRegisterer)
 )
 
+// metricsRoutes são os templates das rotas registradas em main, usados no
+// rótulo path das métricas HTTP e no nome dos spans; caminhos fora da lista
+// são contados como "other". Rotas novas devem ser incluídas aqui.
+var metricsRoutes = []string{
+	"/auth/register",
+	"/auth/login",
+	"/auth/refresh",
+	"/auth/magic-link",
+	"/auth/magic-link/login",
+	"/.well-known/jwks.json",
+	"/.well-known/openid-configuration",
+	"/health",
+	"/oauth/authorize",
+	"/oauth/token",
+	"/oauth/userinfo",
+	"/auth/validate",
+	"/auth/logout",
+	"/auth/logout-all",
+	"/auth/sessions",
+	"/auth/sessions/{id}",
+	"/auth/api-keys",
+	"/auth/api-keys/{id}",
+	"/auth/admin/unlock",
+	"/auth/admin/impersonate",
+	"/auth/admin/users/{userID}/sessions",
+	"/auth/admin/users/{userID}/sessions/{id}",
+	"/auth/admin/users/{userID}/api-keys",
+	"/auth/admin/users/{userID}/api-keys/{id}",
+	"/audit",
+	"/metrics",
+}
+
 func init() {
+	// iat em milissegundos, na precisão do corte de Denylist.RevokeUser
+	jwt.TimePrecision = time.Millisecond
+
 	var err error
-	log, err = logger.NewJSONLogger("logs/app.log")
+	obs, err = observability.Setup(observability.Config{
+		ServiceName: "auth-service",
+		Routes:      metricsRoutes,
+	})
 	if err != nil {
-		fmt.Printf("Erro ao inicializar logger: %v\n", err)
+		fmt.Printf("Erro ao inicializar a observabilidade: %v\n", err)
 		os.Exit(1)
 	}
+	log = obs.Logger
+	piiRedactor = obs.Redactor
+
+	trustedProxies, err = parseTrustedProxies(getEnv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Error("Valor inválido para TRUSTED_PROXIES", err)
+		os.Exit(1)
+	}
+
+	rateLimitPolicies, err = ratelimit.FromEnv(defaultRateLimitPolicies)
+	if err != nil {
+		log.Error("Erro ao carregar as políticas de limite de requisições", err)
+		os.Exit(1)
+	}
+	rateLimiter = ratelimit.New(ratelimit.NewMemoryStore(), rateLimitPolicies...)
 }
 
 // Tipo personalizado para chaves do contexto
@@ -38,89 +114,82 @@ type contextKey string
 
 const (
 	userIDKey contextKey = "user_id"
+	claimsKey contextKey = "claims"
+	apiKeyKey contextKey = "api_key"
 )
 
-// Estrutura para armazenar os limitadores por usuário
-type userRateLimiter struct {
-	limiters map[string]*rate.Limiter
-	mu       sync.RWMutex
-}
-
-// Configurações do rate limiter
-const (
-	requestsPerMinute = 60 // 60 requisições por minuto
-	burstSize         = 10 // Permite até 10 requisições em burst
-)
+// defaultRateLimitPolicies são as políticas usadas sem RATE_LIMIT_POLICIES:
+// 60 requisições por minuto por IP, com rajadas de até 10
+const defaultRateLimitPolicies = "*=ip:60/1m:token_bucket:10"
 
 var (
-	limiter = &userRateLimiter{
-		limiters: make(map[string]*rate.Limiter),
-	}
-)
+	// rateLimitPolicies são as políticas de limite de requisições, lidas em init
+	rateLimitPolicies []ratelimit.Policy
 
-// Obtém ou cria um limitador para um usuário específico
-func (rl *userRateLimiter) getLimiter(userID string) *rate.Limiter {
-	rl.mu.RLock()
-	limiter, exists := rl.limiters[userID]
-	rl.mu.RUnlock()
-
-	if exists {
-		return limiter
-	}
-
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	// Verificar novamente após adquirir o lock (double-check pattern)
-	limiter, exists = rl.limiters[userID]
-	if exists {
-		return limiter
-	}
+	// rateLimiter aplica as políticas; em memória até main conectar ao Redis
+	rateLimiter *ratelimit.Limiter
+)
 
-	// Criar novo limitador
-	limiter = rate.NewLimiter(rate.Every(time.Minute/requestsPerMinute), burstSize)
-	rl.limiters[userID] = limiter
-	return limiter
+// Middleware de rate limiting por IP, aplicado antes da autenticação; os
+// limites por usuário são aplicados por authMiddleware
+func rateLimitMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return limitRequests(ratelimit.ScopeIP, next)
 }
 
-// Middleware de rate limiting
-func rateLimitMiddleware(next http.HandlerFunc) http.HandlerFunc {
+// limitRequests aplica as políticas do escopo à rota da requisição e
+// informa a cota nos headers RateLimit-*. Falhas do Redis não bloqueiam as
+// requisições.
+func limitRequests(scope ratelimit.Scope, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Obter o ID do usuário do contexto (se disponível)
-		userID := "anonymous"
-		if claims, ok := r.Context().Value(userIDKey).(string); ok {
-			userID = claims
+		// A rota é o template de metricsRoutes, como /auth/sessions/{id}, ou
+		// "other" para caminhos fora da lista
+		req := ratelimit.Request{Route: obs.Metrics.Route(r), IP: clientIP(r)}
+		if userID, ok := r.Context().Value(userIDKey).(string); ok {
+			req.UserID = userID
 		}
 
-		// Obter o limitador para o usuário
-		limiter := limiter.getLimiter(userID)
+		res, err := rateLimiter.Allow(r.Context(), req, scope)
+		if err != nil {
+			log.Error("Erro ao consultar o limite de requisições", err, logger.String("scope", string(scope)))
+		}
+		if res.Limit == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
 
-		// Verificar se a requisição pode prosseguir
-		if !limiter.Allow() {
-			metrics.RateLimitHits.WithLabelValues("rejected").Inc()
+		ratelimit.WriteHeaders(w.Header(), res)
+		if !res.Allowed {
+			authMetrics.RateLimitHits.WithLabelValues("rejected").Inc()
 			http.Error(w, "Limite de requisições excedido", http.StatusTooManyRequests)
 			return
 		}
 
-		metrics.RateLimitHits.WithLabelValues("allowed").Inc()
+		authMetrics.RateLimitHits.WithLabelValues("allowed").Inc()
 		next.ServeHTTP(w, r)
 	}
 }
 
-type User struct {
-	ID       string `json:"id"`
+type RegisterRequest struct {
+	Name     string `json:"name"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
-type RegisterRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+type RegisterResponse struct {
+	User    *store.User `json:"user"`
+	Message string      `json:"message"`
+}
+
+// PasswordPolicyError lista as regras da política de senhas não atendidas
+type PasswordPolicyError struct {
+	Error      string                     `json:"error"`
+	Violations []passwordpolicy.Violation `json:"violations"`
 }
 
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Device   string `json:"device,omitempty"` // nome do dispositivo exibido na lista de sessões
 }
 
 type LoginResponse struct {
@@ -134,11 +203,40 @@ type TokenPair struct {
 }
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Type   string `json:"type"` // "access" ou "refresh"
+	UserID    string `json:"user_id"`
+	Type      string `json:"type"`          // "access", "refresh" ou "impersonation"
+	SessionID string `json:"sid,omitempty"` // família de refresh tokens da sessão
+	// ActorID é o administrador que age em nome de UserID nos tokens de personificação
+	ActorID string `json:"actor_id,omitempty"`
+	// Scope traz os escopos autorizados nos tokens emitidos a clientes OpenID
+	// Connect, que também trazem o client_id em aud
+	Scope string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// clientGrant vincula os tokens ao cliente OpenID Connect e aos escopos
+// autorizados. O valor zero emite tokens do próprio auth-service.
+type clientGrant struct {
+	ClientID string
+	Scope    string
+}
+
+// audience retorna a claim aud dos tokens vinculados ao cliente
+func (g clientGrant) audience() jwt.ClaimStrings {
+	if g.ClientID == "" {
+		return nil
+	}
+	return jwt.ClaimStrings{g.ClientID}
+}
+
+// grant retorna o cliente e os escopos a que os tokens estão vinculados
+func (c *Claims) grant() clientGrant {
+	if len(c.Audience) == 0 {
+		return clientGrant{}
+	}
+	return clientGrant{ClientID: c.Audience[0], Scope: c.Scope}
+}
+
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
 }
@@ -149,44 +247,73 @@ type ValidateRequest struct {
 
 type ValidateResponse struct {
 	Valid bool `json:"valid"`
+	*Claims
 }
 
-var jwtKey = []byte(os.Getenv("JWT_SECRET"))
-var users = []User{
-	{
-		ID:       "1",
-		Email:    "admin@insidechurch.com",
-		Password: "$2a$10$XOPbrlUPQdwdJUpSrIF6X.LbE14qsMmKGhM1A8W9iqDp0JxX5QKHy", // "admin123"
-	},
-}
+// Armazenamentos de credenciais, refresh tokens e tokens revogados, inicializados em main
+var (
+	userStore         store.UserStore
+	refreshTokenStore store.RefreshTokenStore
+	tokenDenylist     *denylist.Denylist
+)
+
+// passwordPolicy é a política de senhas compartilhada com a API principal,
+// carregada do ambiente em main
+var passwordPolicy = passwordpolicy.Default()
+
+// passwordHasher gera e verifica os hashes de senha no formato compartilhado
+// com a API principal, carregado do ambiente em main
+var passwordHasher = passwordhash.Default()
+
+// shutdownTimeout limita a espera pelas requisições em andamento no
+// encerramento do serviço
+const shutdownTimeout = 15 * time.Second
+
+// Validade dos tokens emitidos
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 7 * 24 * time.Hour
+
+	// userRevocationTTL mantém o corte de Denylist.RevokeUser, compartilhado
+	// com a API, pela validade dos access tokens emitidos por ela (24h)
+	userRevocationTTL = 24 * time.Hour
+)
 
-func generateTokenPair(userID string) (*TokenPair, error) {
+// generateTokenPair assina um par de tokens para o refresh token já
+// registrado no refreshTokenStore, vinculado ao cliente de grant, se houver
+func generateTokenPair(refresh *store.RefreshToken, grant clientGrant) (*TokenPair, error) {
 	// Gerar access token (15 minutos)
 	accessClaims := &Claims{
-		UserID: userID,
-		Type:   "access",
+		UserID:    refresh.UserID,
+		Type:      "access",
+		SessionID: refresh.FamilyID,
+		Scope:     grant.Scope,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
+			ID:        uuid.NewString(),
+			Audience:  grant.audience(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenString, err := accessToken.SignedString(jwtKey)
+	accessTokenString, err := signingKeys.Sign(accessClaims)
 	if err != nil {
 		return nil, err
 	}
 
 	// Gerar refresh token (7 dias)
 	refreshClaims := &Claims{
-		UserID: userID,
-		Type:   "refresh",
+		UserID:    refresh.UserID,
+		Type:      "refresh",
+		SessionID: refresh.FamilyID,
+		Scope:     grant.Scope,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
+			ID:        refresh.ID,
+			Audience:  grant.audience(),
+			ExpiresAt: jwt.NewNumericDate(refresh.ExpiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := refreshToken.SignedString(jwtKey)
+	refreshTokenString, err := signingKeys.Sign(refreshClaims)
 	if err != nil {
 		return nil, err
 	}
@@ -197,6 +324,29 @@ func generateTokenPair(userID string) (*TokenPair, error) {
 	}, nil
 }
 
+// issueTokenPair emite um par de tokens iniciando uma nova família de
+// refresh tokens, registrada como uma sessão do dispositivo informado
+func issueTokenPair(ctx context.Context, userID string, session *store.Session, grant clientGrant) (*TokenPair, error) {
+	refresh := &store.RefreshToken{
+		ID:        uuid.NewString(),
+		FamilyID:  uuid.NewString(),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}
+	if err := refreshTokenStore.Create(ctx, refresh); err != nil {
+		return nil, err
+	}
+
+	session.ID = refresh.FamilyID
+	session.UserID = userID
+	session.ExpiresAt = refresh.ExpiresAt
+	if err := sessionStore.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return generateTokenPair(refresh, grant)
+}
+
 func registerHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	err := tracing.TraceSpanWithAttributes(ctx, "register", map[string]string{
@@ -216,58 +366,80 @@ func registerHandler(w http.ResponseWriter, r *http.Request) {
 				logger.String("path", r.URL.Path),
 				logger.String("ip", r.RemoteAddr),
 			)
-			metrics.RegisterAttempts.WithLabelValues("failure").Inc()
+			authMetrics.RegisterAttempts.WithLabelValues("failure").Inc()
 			http.Error(w, "JSON inválido", http.StatusBadRequest)
 			return nil
 		}
 
-		// Verificar se o email já está em uso
-		for _, u := range users {
-			if u.Email == req.Email {
-				log.Info("Tentativa de registro com email já existente",
-					logger.String("email", req.Email),
-					logger.String("ip", r.RemoteAddr),
-				)
-				metrics.RegisterAttempts.WithLabelValues("failure").Inc()
-				http.Error(w, "Email já está em uso", http.StatusBadRequest)
-				return nil
-			}
+		if req.Email == "" || req.Password == "" {
+			authMetrics.RegisterAttempts.WithLabelValues("failure").Inc()
+			http.Error(w, "Email e senha são obrigatórios", http.StatusBadRequest)
+			return nil
+		}
+
+		if violations := passwordPolicy.Validate(req.Password); len(violations) > 0 {
+			authMetrics.RegisterAttempts.WithLabelValues("failure").Inc()
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(PasswordPolicyError{
+				Error:      passwordpolicy.Summary(violations),
+				Violations: violations,
+			})
+			return nil
 		}
 
 		// Gerar hash da senha
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		hashedPassword, err := passwordHasher.Hash(req.Password)
 		if err != nil {
 			log.Error("Erro ao gerar hash da senha", err,
 				logger.String("email", req.Email),
 			)
-			metrics.RegisterAttempts.WithLabelValues("failure").Inc()
+			authMetrics.RegisterAttempts.WithLabelValues("failure").Inc()
 			http.Error(w, "Erro ao processar senha", http.StatusInternalServerError)
 			return err
 		}
 
-		// Criar novo usuário
-		newUser := User{
-			ID:       fmt.Sprintf("%d", len(users)+1),
+		// Criar novo usuário; a unicidade do email é garantida pelo store
+		newUser := &store.User{
+			Name:     req.Name,
 			Email:    req.Email,
-			Password: string(hashedPassword),
+			Password: hashedPassword,
 		}
-		users = append(users, newUser)
+		if err := userStore.Create(ctx, newUser); err != nil {
+			if errors.Is(err, store.ErrEmailAlreadyExists) {
+				log.Info("Tentativa de registro com email já existente",
+					logger.String("email", req.Email),
+					logger.String("ip", r.RemoteAddr),
+				)
+				authMetrics.RegisterAttempts.WithLabelValues("failure").Inc()
+				http.Error(w, "Email já está em uso", http.StatusBadRequest)
+				return nil
+			}
 
-		// Gerar tokens
-		tokenPair, err := generateTokenPair(newUser.ID)
-		if err != nil {
-			log.Error("Erro ao gerar tokens", err,
-				logger.String("user_id", newUser.ID),
+			log.Error("Erro ao criar usuário", err,
+				logger.String("email", req.Email),
 			)
-			metrics.RegisterAttempts.WithLabelValues("failure").Inc()
-			http.Error(w, "Erro ao gerar tokens", http.StatusInternalServerError)
+			authMetrics.RegisterAttempts.WithLabelValues("failure").Inc()
+			http.Error(w, "Erro ao criar usuário", http.StatusInternalServerError)
 			return err
 		}
 
-		metrics.RegisterAttempts.WithLabelValues("success").Inc()
-		metrics.ActiveUsers.Inc()
-		metrics.TokenGenerations.WithLabelValues("access").Inc()
-		metrics.TokenGenerations.WithLabelValues("refresh").Inc()
+		// A conta só é ativada após a confirmação do email; nenhum token é
+		// emitido no registro. Falhas de entrega não impedem o cadastro, já
+		// que o link pode ser reenviado.
+		if err := sendVerificationEmail(ctx, newUser); err != nil {
+			log.Error("Erro ao enviar link de verificação", err,
+				logger.String("user_id", newUser.ID),
+			)
+		}
+
+		authMetrics.RegisterAttempts.WithLabelValues("success").Inc()
+		authMetrics.ActiveUsers.Inc()
+		auditLogger.Record(ctx, auditevent.Event{
+			Action: auditevent.UserRegistered,
+			UserID: newUser.ID,
+			Target: auditevent.Target(auditevent.TargetUser, newUser.ID),
+		})
 
 		log.Info("Usuário registrado com sucesso",
 			logger.String("user_id", newUser.ID),
@@ -275,12 +447,16 @@ func registerHandler(w http.ResponseWriter, r *http.Request) {
 		)
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(tokenPair)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(RegisterResponse{
+			User:    newUser,
+			Message: "Usuário criado, verifique seu email para ativar a conta",
+		})
 		return nil
 	})
 
 	if err != nil {
-		log.Error("Erro no handler de registro", err)
+		log.WithContext(ctx).Error("Erro no handler de registro", err)
 	}
 }
 
@@ -303,55 +479,139 @@ func loginHandler(w http.ResponseWriter, r *http.Request) {
 				logger.String("path", r.URL.Path),
 				logger.String("ip", r.RemoteAddr),
 			)
-			metrics.LoginAttempts.WithLabelValues("failure").Inc()
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
 			http.Error(w, "JSON inválido", http.StatusBadRequest)
 			return nil
 		}
 
+		// Recusar tentativas durante o atraso ou bloqueio da conta ou do IP,
+		// antes de verificar a senha
+		email := store.NormalizeEmail(req.Email)
+		ip := clientIP(r)
+		if retryAfter := loginRetryAfter(ctx, email, ip); retryAfter > 0 {
+			log.Info("Tentativa de login durante bloqueio",
+				logger.String("email", email),
+				logger.String("ip", r.RemoteAddr),
+			)
+			authMetrics.LoginAttempts.WithLabelValues("locked").Inc()
+			auditLogger.Record(ctx, auditevent.Event{
+				Action:  auditevent.LoginFailed,
+				Outcome: auditevent.OutcomeDenied,
+				Reason:  auditevent.ReasonAccountLocked,
+			})
+			writeTooManyAttempts(w, retryAfter)
+			return nil
+		}
+
 		// Encontrar usuário
-		var user *User
-		for _, u := range users {
-			if u.Email == req.Email {
-				user = &u
-				break
+		user, err := userStore.FindByEmail(ctx, email)
+		if err != nil {
+			if !errors.Is(err, store.ErrUserNotFound) {
+				log.Error("Erro ao buscar usuário", err,
+					logger.String("email", req.Email),
+				)
+				authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+				http.Error(w, "Erro ao buscar usuário", http.StatusInternalServerError)
+				return err
 			}
-		}
 
-		if user == nil {
 			log.Info("Tentativa de login com email não encontrado",
 				logger.String("email", req.Email),
 				logger.String("ip", r.RemoteAddr),
 			)
-			metrics.LoginAttempts.WithLabelValues("failure").Inc()
+			recordLoginFailure(ctx, email, ip, nil)
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			auditLogger.Record(ctx, auditevent.Event{
+				Action:  auditevent.LoginFailed,
+				Outcome: auditevent.OutcomeFailure,
+				Reason:  auditevent.ReasonInvalidCredentials,
+			})
 			http.Error(w, "Credenciais inválidas", http.StatusUnauthorized)
 			return nil
 		}
 
 		// Verificar senha
-		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		if err := passwordHasher.Verify(req.Password, user.Password); err != nil {
 			log.Info("Tentativa de login com senha inválida",
 				logger.String("email", req.Email),
 				logger.String("ip", r.RemoteAddr),
 			)
-			metrics.LoginAttempts.WithLabelValues("failure").Inc()
+			recordLoginFailure(ctx, email, ip, user)
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			auditLogger.Record(ctx, auditevent.Event{
+				Action:  auditevent.LoginFailed,
+				Target:  auditevent.Target(auditevent.TargetUser, user.ID),
+				Outcome: auditevent.OutcomeFailure,
+				Reason:  auditevent.ReasonInvalidCredentials,
+			})
 			http.Error(w, "Credenciais inválidas", http.StatusUnauthorized)
 			return nil
 		}
+		clearLoginFailures(ctx, email)
+		rehashPassword(ctx, user, req.Password)
+
+		// Aplicar a política para contas com email não verificado
+		if !loginAllowed(user, time.Now()) {
+			log.Info("Tentativa de login com email não verificado",
+				logger.String("user_id", user.ID),
+				logger.String("ip", r.RemoteAddr),
+			)
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			auditLogger.Record(ctx, auditevent.Event{
+				Action:  auditevent.LoginFailed,
+				Target:  auditevent.Target(auditevent.TargetUser, user.ID),
+				Outcome: auditevent.OutcomeDenied,
+				Reason:  auditevent.ReasonEmailNotVerified,
+			})
+			http.Error(w, "Email não verificado", http.StatusForbidden)
+			return nil
+		}
+
+		// Senhas vencidas precisam ser redefinidas antes de um novo acesso
+		if user.PasswordChangedAt != nil && passwordPolicy.Expired(*user.PasswordChangedAt, time.Now()) {
+			log.Info("Tentativa de login com senha expirada",
+				logger.String("user_id", user.ID),
+			)
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			auditLogger.Record(ctx, auditevent.Event{
+				Action:  auditevent.LoginFailed,
+				Target:  auditevent.Target(auditevent.TargetUser, user.ID),
+				Outcome: auditevent.OutcomeDenied,
+				Reason:  auditevent.ReasonPasswordExpired,
+			})
+			http.Error(w, "Senha expirada, redefina sua senha para continuar", http.StatusForbidden)
+			return nil
+		}
+
+		// O segundo fator é verificado apenas pelo login da API principal
+		if user.RequiresMFA() {
+			log.Info("Tentativa de login sem o segundo fator",
+				logger.String("user_id", user.ID),
+			)
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
+			refuseMFALogin(ctx, w, user)
+			return nil
+		}
 
 		// Gerar tokens
-		tokenPair, err := generateTokenPair(user.ID)
+		tokenPair, err := issueTokenPair(ctx, user.ID, newSession(r, req.Device), clientGrant{})
 		if err != nil {
 			log.Error("Erro ao gerar tokens", err,
 				logger.String("user_id", user.ID),
 			)
-			metrics.LoginAttempts.WithLabelValues("failure").Inc()
+			authMetrics.LoginAttempts.WithLabelValues("failure").Inc()
 			http.Error(w, "Erro ao gerar tokens", http.StatusInternalServerError)
 			return err
 		}
 
-		metrics.LoginAttempts.WithLabelValues("success").Inc()
-		metrics.TokenGenerations.WithLabelValues("access").Inc()
-		metrics.TokenGenerations.WithLabelValues("refresh").Inc()
+		authMetrics.LoginAttempts.WithLabelValues("success").Inc()
+		authMetrics.TokenGenerations.WithLabelValues("access").Inc()
+		authMetrics.TokenGenerations.WithLabelValues("refresh").Inc()
+		auditLogger.Record(ctx, auditevent.Event{
+			Action: auditevent.LoginSucceeded,
+			UserID: user.ID,
+			Target: auditevent.Target(auditevent.TargetUser, user.ID),
+		})
 
 		log.Info("Login realizado com sucesso",
 			logger.String("user_id", user.ID),
@@ -364,10 +624,50 @@ func loginHandler(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		log.Error("Erro no handler de login", err)
+		log.WithContext(ctx).Error("Erro no handler de login", err)
 	}
 }
 
+// refuseMFALogin recusa o login de contas com o segundo fator ativo ou exigido
+// pelo papel. O auth-service não verifica o segundo fator: essas contas entram
+// pelo login em duas etapas da API principal.
+func refuseMFALogin(ctx context.Context, w http.ResponseWriter, user *store.User) {
+	auditLogger.Record(ctx, auditevent.Event{
+		Action:  auditevent.LoginFailed,
+		Target:  auditevent.Target(auditevent.TargetUser, user.ID),
+		Outcome: auditevent.OutcomeDenied,
+		Reason:  auditevent.ReasonMFARequired,
+	})
+	http.Error(w, "Autenticação em dois fatores obrigatória, entre pelo login da API principal", http.StatusForbidden)
+}
+
+// rehashPassword refaz, com a senha recebida no login, o hash gerado com um
+// algoritmo ou parâmetros abaixo da configuração atual. Falhas não impedem o
+// login: o hash antigo continua válido e a atualização é tentada de novo no
+// próximo acesso.
+func rehashPassword(ctx context.Context, user *store.User, password string) {
+	if !passwordHasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := passwordHasher.Hash(password)
+	if err == nil {
+		err = userStore.UpdatePasswordHash(ctx, user.ID, hashedPassword)
+	}
+	if err != nil {
+		log.Error("Erro ao atualizar hash da senha", err,
+			logger.String("user_id", user.ID),
+		)
+		return
+	}
+
+	user.Password = hashedPassword
+	log.Info("Hash da senha atualizado para a configuração atual",
+		logger.String("user_id", user.ID),
+		logger.String("algorithm", passwordHasher.Algorithm),
+	)
+}
+
 func refreshHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	err := tracing.TraceSpanWithAttributes(ctx, "refresh", map[string]string{
@@ -382,42 +682,92 @@ func refreshHandler(w http.ResponseWriter, r *http.Request) {
 
 		var req RefreshRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			metrics.TokenValidations.WithLabelValues("invalid").Inc()
+			authMetrics.TokenValidations.WithLabelValues("invalid").Inc()
 			http.Error(w, "JSON inválido", http.StatusBadRequest)
 			return nil
 		}
 
 		// Validar refresh token
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(req.RefreshToken, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
+		claims, err := verifyToken(req.RefreshToken)
+		if err != nil {
+			authMetrics.TokenValidations.WithLabelValues("invalid").Inc()
+			http.Error(w, "Token inválido", http.StatusUnauthorized)
+			return nil
+		}
+
+		// Verificar se é um refresh token rastreado pelo servidor
+		if claims.Type != "refresh" || claims.ID == "" {
+			authMetrics.TokenValidations.WithLabelValues("invalid").Inc()
+			http.Error(w, "Token inválido", http.StatusUnauthorized)
+			return nil
+		}
 
-		if err != nil || !token.Valid {
-			metrics.TokenValidations.WithLabelValues("invalid").Inc()
+		// Trocar o refresh token pelo sucessor na mesma família
+		next := &store.RefreshToken{
+			ID:        uuid.NewString(),
+			ExpiresAt: time.Now().Add(refreshTokenTTL),
+		}
+		current, err := refreshTokenStore.Rotate(ctx, claims.ID, next)
+		if err != nil {
+			if errors.Is(err, store.ErrRefreshTokenReused) {
+				// Um token já trocado foi reapresentado: a família pode ter sido
+				// comprometida, então todas as sessões derivadas são revogadas
+				log.Info("Reutilização de refresh token detectada, revogando família",
+					logger.String("user_id", current.UserID),
+					logger.String("family_id", current.FamilyID),
+					logger.String("ip", r.RemoteAddr),
+				)
+				if revokeErr := revokeSession(ctx, current.FamilyID); revokeErr != nil {
+					log.Error("Erro ao revogar família de refresh tokens", revokeErr,
+						logger.String("family_id", current.FamilyID),
+					)
+				}
+				auditLogger.Record(ctx, auditevent.Event{
+					Action:  auditevent.SessionReuseDetected,
+					UserID:  current.UserID,
+					Target:  auditevent.Target(auditevent.TargetSession, current.FamilyID),
+					Outcome: auditevent.OutcomeDenied,
+				})
+				authMetrics.TokenRefreshes.WithLabelValues("reused").Inc()
+			} else if !errors.Is(err, store.ErrRefreshTokenNotFound) &&
+				!errors.Is(err, store.ErrRefreshTokenRevoked) &&
+				!errors.Is(err, store.ErrRefreshTokenExpired) {
+				authMetrics.TokenRefreshes.WithLabelValues("failure").Inc()
+				http.Error(w, "Erro ao renovar tokens", http.StatusInternalServerError)
+				return err
+			}
+
+			authMetrics.TokenValidations.WithLabelValues("invalid").Inc()
 			http.Error(w, "Token inválido", http.StatusUnauthorized)
 			return nil
 		}
 
-		// Verificar se é um refresh token
-		if claims.Type != "refresh" {
-			metrics.TokenValidations.WithLabelValues("invalid").Inc()
+		if current.UserID != claims.UserID {
+			authMetrics.TokenValidations.WithLabelValues("invalid").Inc()
 			http.Error(w, "Token inválido", http.StatusUnauthorized)
 			return nil
 		}
 
-		// Gerar novo par de tokens
-		tokenPair, err := generateTokenPair(claims.UserID)
+		// Atualizar o último acesso da sessão; famílias anteriores ao registro
+		// de sessões não têm sessão associada
+		if err := sessionStore.Touch(ctx, next.FamilyID, clientIP(r), next.ExpiresAt); err != nil && !errors.Is(err, store.ErrSessionNotFound) {
+			log.Error("Erro ao atualizar sessão", err,
+				logger.String("session_id", next.FamilyID),
+			)
+		}
+
+		// Gerar novo par de tokens, mantendo o vínculo com o cliente
+		tokenPair, err := generateTokenPair(next, claims.grant())
 		if err != nil {
-			metrics.TokenValidations.WithLabelValues("invalid").Inc()
+			authMetrics.TokenValidations.WithLabelValues("invalid").Inc()
 			http.Error(w, "Erro ao gerar tokens", http.StatusInternalServerError)
 			return err
 		}
 
-		metrics.TokenValidations.WithLabelValues("valid").Inc()
-		metrics.TokenRefreshes.WithLabelValues("success").Inc()
-		metrics.TokenGenerations.WithLabelValues("access").Inc()
-		metrics.TokenGenerations.WithLabelValues("refresh").Inc()
+		authMetrics.TokenValidations.WithLabelValues("valid").Inc()
+		authMetrics.TokenRefreshes.WithLabelValues("success").Inc()
+		authMetrics.TokenGenerations.WithLabelValues("access").Inc()
+		authMetrics.TokenGenerations.WithLabelValues("refresh").Inc()
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(tokenPair)
@@ -425,7 +775,7 @@ func refreshHandler(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		log.Error("Erro no handler de refresh", err)
+		log.WithContext(ctx).Error("Erro no handler de refresh", err)
 	}
 }
 
@@ -446,35 +796,99 @@ func validateHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			metrics.TokenValidations.WithLabelValues("invalid").Inc()
+			authMetrics.TokenValidations.WithLabelValues("invalid").Inc()
 			http.Error(w, "JSON inválido", http.StatusBadRequest)
 			return nil
 		}
 
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(req.Token, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
-
-		if err != nil || !token.Valid {
-			metrics.TokenValidations.WithLabelValues("invalid").Inc()
+		// Tokens emitidos a clientes OpenID Connect não valem nas APIs
+		claims, err := parseToken(ctx, req.Token)
+		if err != nil || len(claims.Audience) > 0 {
+			authMetrics.TokenValidations.WithLabelValues("invalid").Inc()
 			http.Error(w, "Token inválido", http.StatusUnauthorized)
 			return nil
 		}
 
-		metrics.TokenValidations.WithLabelValues("valid").Inc()
+		authMetrics.TokenValidations.WithLabelValues("valid").Inc()
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(claims)
+		json.NewEncoder(w).Encode(ValidateResponse{Valid: true, Claims: claims})
 		return nil
 	})
 
 	if err != nil {
-		log.Error("Erro no handler de validação", err)
+		log.WithContext(ctx).Error("Erro no handler de validação", err)
 	}
 }
 
+// parseToken valida a assinatura e a expiração do token e consulta a denylist
+func parseToken(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := verifyToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	revoked, err := tokenDenylist.IsRevoked(ctx, claims.ID, claims.SessionID, claims.UserID, issuedAt)
+	if err != nil {
+		// Na falha da denylist o token é recusado, evitando aceitar um token revogado
+		log.Error("Erro ao consultar denylist", err,
+			logger.String("user_id", claims.UserID),
+		)
+		return nil, err
+	}
+	if revoked {
+		return nil, errors.New("token revogado")
+	}
+
+	return claims, nil
+}
+
+// authPolicy define as credenciais aceitas por uma rota autenticada, além dos
+// access tokens e tokens de personificação do próprio auth-service
+type authPolicy struct {
+	// clients aceita os access tokens emitidos a clientes pelo /oauth/token
+	clients bool
+
+	// apiKeyScope aceita as chaves de API com o escopo informado; vazio
+	// recusa as chaves de API
+	apiKeyScope string
+}
+
+// userinfoScope é o escopo exigido das chaves de API no userinfo, o mesmo
+// de GET /api/users/me na API
+const userinfoScope = "users:read"
+
+// authMiddleware autentica o access token ou o token de personificação do
+// header Authorization. Chaves de API e tokens emitidos a clientes OpenID
+// Connect são recusados: eles valem apenas nas rotas que os declaram, com
+// apiKeyMiddleware e clientAuthMiddleware.
 func authMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return authenticate(authPolicy{}, next)
+}
+
+// apiKeyMiddleware autentica como authMiddleware, aceitando também as chaves
+// de API com o escopo informado
+func apiKeyMiddleware(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return authenticate(authPolicy{apiKeyScope: scope}, next)
+}
+
+// clientAuthMiddleware autentica as rotas do provedor OpenID Connect,
+// aceitando também os access tokens emitidos a clientes pelo /oauth/token e
+// as chaves de API com o userinfoScope
+func clientAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return authenticate(authPolicy{clients: true, apiKeyScope: userinfoScope}, next)
+}
+
+// authenticate implementa os middlewares de autenticação conforme a política
+func authenticate(policy authPolicy, next http.HandlerFunc) http.HandlerFunc {
+	// Os limites por usuário dependem da identidade autenticada
+	next = limitRequests(ratelimit.ScopeUser, next)
+
 	return func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
@@ -489,33 +903,132 @@ func authMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		token := parts[1]
-		claims := &Claims{}
-		parsedToken, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
+		// Chaves de API identificam o dono da chave, mas não trazem claims:
+		// valem apenas nas rotas que declaram o escopo exigido
+		if store.IsAPIKey(parts[1]) {
+			apiKey, err := authenticateAPIKey(r.Context(), parts[1])
+			if err != nil {
+				if !errors.Is(err, store.ErrAPIKeyNotFound) {
+					log.Error("Erro ao buscar chave de API", err)
+				}
+				http.Error(w, "Token inválido", http.StatusUnauthorized)
+				return
+			}
+			if policy.apiKeyScope == "" {
+				http.Error(w, "Rota indisponível para chaves de API", http.StatusForbidden)
+				return
+			}
+			if !apiKey.HasScope(policy.apiKeyScope) {
+				http.Error(w, "Escopo insuficiente: "+policy.apiKeyScope, http.StatusForbidden)
+				return
+			}
 
-		if err != nil || !parsedToken.Valid {
-			http.Error(w, "Token inválido", http.StatusUnauthorized)
+			ctx := auditevent.WithIdentity(r.Context(), apiKey.UserID, "")
+			ctx = context.WithValue(ctx, userIDKey, apiKey.UserID)
+			ctx = context.WithValue(ctx, apiKeyKey, apiKey)
+			next.ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
 
-		// Verificar se é um access token
-		if claims.Type != "access" {
+		claims, err := parseToken(r.Context(), parts[1])
+		if err != nil {
 			http.Error(w, "Token inválido", http.StatusUnauthorized)
 			return
 		}
 
-		// Adicionar o ID do usuário ao contexto da requisição usando o tipo personalizado
-		ctx := context.WithValue(r.Context(), userIDKey, claims.UserID)
+		// Verificar se é um access token ou um token de personificação
+		if claims.Type != "access" && claims.Type != impersonationTokenType {
+			http.Error(w, "Token inválido", http.StatusUnauthorized)
+			return
+		}
+		// Tokens de clientes trazem o client_id em aud
+		if len(claims.Audience) > 0 && !policy.clients {
+			http.Error(w, "Token inválido", http.StatusUnauthorized)
+			return
+		}
+		// Adicionar o ID do usuário ao contexto da requisição usando o tipo
+		// personalizado e as identidades à origem dos eventos de auditoria
+		ctx := auditevent.WithIdentity(r.Context(), claims.UserID, claims.ActorID)
+		ctx = context.WithValue(ctx, userIDKey, claims.UserID)
+		ctx = context.WithValue(ctx, claimsKey, claims)
+		if claims.ActorID != "" {
+			auditImpersonatedRequest(next)(w, r.WithContext(ctx))
+			return
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
 
+// newRedisClient conecta ao Redis compartilhado quando REDIS_HOST estiver
+// definido; retorna nil caso contrário
+func newRedisClient() *redis.Client {
+	if os.Getenv("REDIS_HOST") == "" {
+		log.Info("REDIS_HOST não definido, usando cache e limites de requisições em memória")
+		return nil
+	}
+	return cache.NewRedisClient(cache.NewRedisConfig())
+}
+
+// newCache cria o cache compartilhado: Redis, se houver cliente, ou memória
+// local caso contrário
+func newCache(client *redis.Client) cache.Cache {
+	if client == nil {
+		return cache.NewMemoryCache()
+	}
+	return cache.NewRedisCache(client)
+}
+
 func main() {
-	// Definir JWT_SECRET se não estiver definido
-	if os.Getenv("JWT_SECRET") == "" {
-		os.Setenv("JWT_SECRET", "sua_chave_secreta_aqui")
+	// O logger é fechado por último, depois dos demais defers
+	defer log.Close()
+
+	// Carregar as chaves de assinatura dos tokens
+	keySet, err := loadSigningKeys()
+	if err != nil {
+		log.Error("Erro ao carregar chaves de assinatura", err)
+		os.Exit(1)
+	}
+	signingKeys = keySet
+
+	// Carregar a política de senhas
+	policy, err := passwordpolicy.FromEnv()
+	if err != nil {
+		log.Error("Erro ao carregar a política de senhas", err)
+		os.Exit(1)
+	}
+	passwordPolicy = policy
+
+	hasher, err := passwordhash.FromEnv()
+	if err != nil {
+		log.Error("Erro ao carregar a configuração de hash de senhas", err)
+		os.Exit(1)
+	}
+	passwordHasher = hasher
+
+	// Inicializar o armazenamento de credenciais
+	db, err := openDatabase()
+	if err != nil {
+		log.Error("Erro ao conectar ao banco de dados", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+	userStore = store.NewPostgresUserStore(db)
+	refreshTokenStore = store.NewPostgresRefreshTokenStore(db)
+	sessionStore = store.NewPostgresSessionStore(db)
+	userTokenStore = store.NewPostgresUserTokenStore(db)
+	apiKeyStore = store.NewPostgresAPIKeyStore(db)
+	clientStore = store.NewPostgresClientStore(db)
+	authorizationCodeStore = store.NewPostgresAuthorizationCodeStore(db)
+	auditStore = store.NewPostgresAuditStore(db)
+	notifications = notifier.NewHTTPNotifier(getEnv("NOTIFICATION_SERVICE_URL", "http://notification-service:8080"), os.Getenv("NOTIFICATION_SERVICE_TOKEN"))
+	redisClient := newRedisClient()
+	sharedCache := newCache(redisClient)
+	tokenDenylist = denylist.New(sharedCache)
+	newLockoutTrackers(sharedCache)
+
+	// Com o Redis, os limites de requisições valem para todas as réplicas
+	if redisClient != nil {
+		rateLimiter = ratelimit.New(ratelimit.NewRedisStore(redisClient), rateLimitPolicies...)
 	}
 
 	// Inicializar o logger de auditoria
@@ -525,30 +1038,76 @@ func main() {
 	}
 	defer auditLogger.Close()
 
-	// Inicializar o tracer
-	if err := tracing.InitTracer("auth-service"); err != nil {
-		log.Error("Erro ao inicializar tracer", err)
-		os.Exit(1)
+	// Rotas públicas com rate limiting e auditoria
+	http.Handle("/auth/register", auditMiddleware(rateLimitMiddleware(registerHandler)))
+	http.Handle("/auth/login", auditMiddleware(rateLimitMiddleware(loginHandler)))
+	http.Handle("/auth/refresh", auditMiddleware(rateLimitMiddleware(refreshHandler)))
+	if magicLinkEnabled {
+		http.Handle("/auth/magic-link", auditMiddleware(rateLimitMiddleware(magicLinkHandler)))
+		http.Handle("/auth/magic-link/login", auditMiddleware(rateLimitMiddleware(magicLinkLoginHandler)))
 	}
-
-	// Rotas públicas com rate limiting, auditoria, métricas e tracing
-	http.Handle("/auth/register", tracing.TracedHandler(metrics.MetricsMiddleware(auditMiddleware(rateLimitMiddleware(registerHandler)))))
-	http.Handle("/auth/login", tracing.TracedHandler(metrics.MetricsMiddleware(auditMiddleware(rateLimitMiddleware(loginHandler)))))
-	http.Handle("/auth/refresh", tracing.TracedHandler(metrics.MetricsMiddleware(auditMiddleware(rateLimitMiddleware(refreshHandler)))))
-	http.Handle("/health", tracing.TracedHandler(metrics.MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	http.HandleFunc("/.well-known/jwks.json", jwksHandler)
+	http.HandleFunc("/.well-known/openid-configuration", openidConfigurationHandler)
+	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "ok")
-	}))))
+	})
 
-	// Rotas protegidas com rate limiting, autenticação, auditoria, métricas e tracing
-	http.Handle("/auth/validate", tracing.TracedHandler(metrics.MetricsMiddleware(auditMiddleware(rateLimitMiddleware(authMiddleware(validateHandler))))))
+	// Provedor OpenID Connect; /oauth/authorize identifica o usuário pelo
+	// access token, quando presente, e /oauth/token autentica o cliente
+	http.Handle("/oauth/authorize", auditMiddleware(rateLimitMiddleware(authorizeHandler)))
+	http.Handle("/oauth/token", auditMiddleware(rateLimitMiddleware(oauthTokenHandler)))
+	http.Handle("/oauth/userinfo", auditMiddleware(rateLimitMiddleware(clientAuthMiddleware(userinfoHandler))))
+
+	// Rotas protegidas com rate limiting, autenticação e auditoria
+	http.Handle("/auth/validate", auditMiddleware(rateLimitMiddleware(authMiddleware(validateHandler))))
+	http.Handle("/auth/logout", auditMiddleware(rateLimitMiddleware(authMiddleware(logoutHandler))))
+	http.Handle("/auth/logout-all", auditMiddleware(rateLimitMiddleware(authMiddleware(denyImpersonation(logoutAllHandler)))))
+	http.Handle("/auth/sessions", auditMiddleware(rateLimitMiddleware(authMiddleware(sessionsHandler))))
+	http.Handle("/auth/sessions/", auditMiddleware(rateLimitMiddleware(authMiddleware(sessionsHandler))))
+	http.Handle("/auth/api-keys", auditMiddleware(rateLimitMiddleware(authMiddleware(denyImpersonation(apiKeysHandler)))))
+	http.Handle("/auth/api-keys/", auditMiddleware(rateLimitMiddleware(authMiddleware(denyImpersonation(apiKeysHandler)))))
+
+	// Rotas administrativas, restritas a sessões do próprio usuário com o papel ADMIN_ROLE
+	http.Handle("/auth/admin/unlock", auditMiddleware(rateLimitMiddleware(authMiddleware(adminMiddleware(unlockHandler)))))
+	http.Handle("/auth/admin/impersonate", auditMiddleware(rateLimitMiddleware(authMiddleware(adminMiddleware(impersonateHandler)))))
+	http.Handle("/auth/admin/users/", auditMiddleware(rateLimitMiddleware(authMiddleware(adminMiddleware(adminUsersHandler)))))
+	http.Handle("/audit", auditMiddleware(rateLimitMiddleware(authMiddleware(adminMiddleware(auditHandler)))))
 
 	// Endpoint do Prometheus
-	http.Handle("/metrics", promhttp.Handler())
+	http.Handle("/metrics", obs.Handler())
 
 	log.Info("Serviço de autenticação iniciado",
 		logger.String("port", "8081"),
 	)
 
 	fmt.Println("Auth Service rodando na porta 8081")
-	http.ListenAndServe(":8081", nil)
+
+	// Ao receber SIGINT ou SIGTERM, aguardar as requisições em andamento; os
+	// registros de auditoria pendentes são gravados no fechamento do logger
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Métricas, tracing, log das requisições, recuperação de panics e ID da
+	// requisição valem para todas as rotas
+	server := &http.Server{Addr: ":8081", Handler: obs.Middleware(http.DefaultServeMux)}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Erro no servidor HTTP", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info("Encerrando o serviço de autenticação")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error("Erro ao encerrar o servidor HTTP", err)
+	}
+
+	// Exportar os spans pendentes antes de sair
+	if err := tracing.Shutdown(shutdownCtx); err != nil {
+		log.Error("Erro ao encerrar o tracing", err)
+	}
 }
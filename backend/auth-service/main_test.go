@@ -2,13 +2,242 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/denylist"
+	"github.com/insidechurch/keys"
+	"github.com/insidechurch/lockout"
+	"github.com/insidechurch/passwordpolicy"
+	"github.com/insidechurch/ratelimit"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/insidechurch/auth-service/infrastructure/auditchain"
+	"github.com/insidechurch/auth-service/infrastructure/notifier"
+	"github.com/insidechurch/auth-service/infrastructure/store"
+	"github.com/insidechurch/cache"
 )
 
+// recordingNotifier guarda as notificações enviadas durante os testes
+type recordingNotifier struct {
+	sent []notifier.Notification
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, notification notifier.Notification) error {
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// setupStores inicializa stores em memória com um usuário de teste
+func setupStores(t *testing.T) {
+	t.Helper()
+
+	userStore = store.NewMemoryUserStore()
+	refreshTokenStore = store.NewMemoryRefreshTokenStore()
+	sessionStore = store.NewMemorySessionStore()
+	apiKeyStore = store.NewMemoryAPIKeyStore()
+	tokenDenylist = denylist.New(cache.NewMemoryCache())
+	newLockoutTrackers(cache.NewMemoryCache())
+	ks, err := keys.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingKeys = ks
+	userTokenStore = store.NewMemoryUserTokenStore()
+	notifications = &recordingNotifier{}
+	clients := store.NewMemoryClientStore()
+	clients.Register(&store.Client{ID: "wiki", Name: "Wiki", RedirectURIs: []string{"https://wiki.exemplo.com/callback"}})
+	clients.Register(&store.Client{
+		ID: "louvor", Name: "Louvor", SecretHash: store.HashToken("segredo"),
+		RedirectURIs: []string{"https://louvor.exemplo.com/oidc/callback?tenant=1"},
+	})
+	clientStore = clients
+	authorizationCodeStore = store.NewMemoryAuthorizationCodeStore()
+	auditStore = store.NewMemoryAuditStore()
+	auditLogger = &AuditLogger{queue: make(chan AuditLog, auditQueueSize)}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifiedAt := time.Now()
+	if err := userStore.Create(context.Background(), &store.User{
+		Name:       "João",
+		Email:      "joao@email.com",
+		Password:   string(hash),
+		VerifiedAt: &verifiedAt,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegisterHandler(t *testing.T) {
+	setupStores(t)
+
+	reqBody := RegisterRequest{
+		Name:     "Maria",
+		Email:    "maria@email.com",
+		Password: "Culto#Domingo9",
+	}
+	jsonBody, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBuffer(jsonBody))
+	rec := httptest.NewRecorder()
+	registerHandler(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Esperava status 201, recebeu %d", rec.Code)
+	}
+
+	// O registro não emite tokens; o link de verificação é enviado por email
+	var pair TokenPair
+	json.Unmarshal(rec.Body.Bytes(), &pair)
+	if pair.AccessToken != "" || pair.RefreshToken != "" {
+		t.Error("Registro não deveria emitir tokens")
+	}
+
+	sent := notifications.(*recordingNotifier).sent
+	if len(sent) != 1 || sent[0].To != "maria@email.com" || !strings.Contains(sent[0].Message, "token=") {
+		t.Fatalf("Link de verificação não enviado: %+v", sent)
+	}
+	tokens := userTokenStore.(*store.MemoryUserTokenStore).Tokens()
+	if len(tokens) != 1 || strings.Contains(sent[0].Message, tokens[0].TokenHash) {
+		t.Error("Apenas o hash do token deveria ser armazenado")
+	}
+
+	// Registro repetido com o mesmo email deve falhar
+	req = httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBuffer(jsonBody))
+	rec = httptest.NewRecorder()
+	registerHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Esperava status 400, recebeu %d", rec.Code)
+	}
+}
+
+func TestRegisterHandlerPasswordPolicy(t *testing.T) {
+	setupStores(t)
+
+	jsonBody, _ := json.Marshal(RegisterRequest{Name: "Maria", Email: "maria@email.com", Password: "senha123"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewBuffer(jsonBody))
+	rec := httptest.NewRecorder()
+	registerHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Esperava status 400, recebeu %d", rec.Code)
+	}
+
+	var resp PasswordPolicyError
+	json.NewDecoder(rec.Body).Decode(&resp)
+	rules := make([]string, len(resp.Violations))
+	for i, violation := range resp.Violations {
+		rules[i] = violation.Rule
+	}
+	want := []string{passwordpolicy.RuleUpper, passwordpolicy.RuleSpecial, passwordpolicy.RuleBreached}
+	if strings.Join(rules, ",") != strings.Join(want, ",") {
+		t.Errorf("Esperava as violações %v, obteve %v", want, rules)
+	}
+	if _, err := userStore.FindByEmail(context.Background(), "maria@email.com"); err == nil {
+		t.Error("Usuário não deveria ser criado")
+	}
+}
+
+func TestLoginHandlerPasswordExpired(t *testing.T) {
+	setupStores(t)
+	defer func(policy *passwordpolicy.Policy) { passwordPolicy = policy }(passwordPolicy)
+	passwordPolicy = &passwordpolicy.Policy{MaxAge: 90 * 24 * time.Hour}
+
+	if rec := attemptLogin("joao@email.com", "senha123"); rec.Code != http.StatusOK {
+		t.Fatalf("Senha recente deveria ser aceita, recebeu %d", rec.Code)
+	}
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	changedAt := time.Now().Add(-100 * 24 * time.Hour)
+	verifiedAt := time.Now()
+	userStore.Create(context.Background(), &store.User{
+		Email: "antigo@email.com", Password: string(hash), VerifiedAt: &verifiedAt, PasswordChangedAt: &changedAt,
+	})
+	if rec := attemptLogin("antigo@email.com", "senha123"); rec.Code != http.StatusForbidden {
+		t.Errorf("Senha expirada deveria ser recusada, recebeu %d", rec.Code)
+	}
+}
+
+func TestLoginHandlerRehashesLegacyPassword(t *testing.T) {
+	setupStores(t)
+	ctx := context.Background()
+
+	legacy, _ := userStore.FindByEmail(ctx, "joao@email.com")
+	if !strings.HasPrefix(legacy.Password, "$2a$") {
+		t.Fatalf("Usuário de teste deveria ter hash bcrypt, obteve %s", legacy.Password)
+	}
+
+	if rec := attemptLogin("joao@email.com", "errada"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Senha inválida deveria ser recusada, recebeu %d", rec.Code)
+	}
+	if user, _ := userStore.FindByEmail(ctx, "joao@email.com"); user.Password != legacy.Password {
+		t.Fatal("Hash não deveria mudar após uma senha inválida")
+	}
+
+	if rec := attemptLogin("joao@email.com", "senha123"); rec.Code != http.StatusOK {
+		t.Fatalf("Hash $2a$ existente deveria continuar válido, recebeu %d", rec.Code)
+	}
+	user, _ := userStore.FindByEmail(ctx, "joao@email.com")
+	if !strings.HasPrefix(user.Password, "$argon2id$") {
+		t.Fatalf("Hash deveria migrar para Argon2id, obteve %s", user.Password)
+	}
+	if !user.PasswordChangedAt.Equal(*legacy.PasswordChangedAt) {
+		t.Error("Atualização do hash não deveria alterar a data da troca de senha")
+	}
+
+	if rec := attemptLogin("joao@email.com", "senha123"); rec.Code != http.StatusOK {
+		t.Errorf("Senha deveria ser aceita com o novo hash, recebeu %d", rec.Code)
+	}
+}
+
+func TestLoginHandlerUnverifiedPolicy(t *testing.T) {
+	setupStores(t)
+	defer func(policy string) { unverifiedLoginPolicy = policy }(unverifiedLoginPolicy)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	userStore.Create(context.Background(), &store.User{
+		Name:     "Maria",
+		Email:    "maria@email.com",
+		Password: string(hash),
+	})
+
+	login := func() int {
+		jsonBody, _ := json.Marshal(LoginRequest{Email: "maria@email.com", Password: "senha123"})
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(jsonBody))
+		rec := httptest.NewRecorder()
+		loginHandler(rec, req)
+		return rec.Code
+	}
+
+	for policy, want := range map[string]int{
+		"deny":  http.StatusForbidden,
+		"limit": http.StatusOK,
+		"allow": http.StatusOK,
+	} {
+		unverifiedLoginPolicy = policy
+		if got := login(); got != want {
+			t.Errorf("Política %s: esperava status %d, recebeu %d", policy, want, got)
+		}
+	}
+}
+
 func TestLoginHandler(t *testing.T) {
+	setupStores(t)
+
 	reqBody := LoginRequest{
 		Email:    "joao@email.com",
 		Password: "senha123",
@@ -21,41 +250,310 @@ func TestLoginHandler(t *testing.T) {
 	if rec.Code != http.StatusOK {
 		t.Errorf("Esperava status 200, recebeu %d", rec.Code)
 	}
-	var resp LoginResponse
+	var resp TokenPair
 	json.NewDecoder(rec.Body).Decode(&resp)
-	if resp.Token == "" {
+	if resp.AccessToken == "" {
 		t.Error("Token não foi gerado")
 	}
 }
 
-func TestRefreshHandler(t *testing.T) {
-	reqBody := RefreshRequest{
-		RefreshToken: "token-antigo",
+// attemptLogin envia uma tentativa de login e retorna a resposta
+func attemptLogin(email, password string) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(LoginRequest{Email: email, Password: password})
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(jsonBody))
+	rec := httptest.NewRecorder()
+	loginHandler(rec, req)
+	return rec
+}
+
+func TestLoginHandlerLockout(t *testing.T) {
+	setupStores(t)
+	sent := notifications.(*recordingNotifier)
+
+	// Política sem atrasos, bloqueando na terceira falha
+	loginLockout.Account = lockout.New(cache.NewMemoryCache(), lockout.AccountPrefix, lockout.Policy{
+		LockAfter:    3,
+		LockDuration: time.Minute,
+		Window:       time.Hour,
+	})
+
+	for i := 0; i < 3; i++ {
+		if rec := attemptLogin("joao@email.com", "errada"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Tentativa %d: esperava status 401, recebeu %d", i+1, rec.Code)
+		}
 	}
-	jsonBody, _ := json.Marshal(reqBody)
+	if len(sent.sent) != 1 || sent.sent[0].To != "joao@email.com" {
+		t.Fatalf("Esperava um aviso de bloqueio, obteve %+v", sent.sent)
+	}
+
+	// Conta bloqueada recusa até a senha correta, informando a espera
+	rec := attemptLogin("JOAO@email.com", "senha123")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("Esperava status 429 com Retry-After 60, recebeu %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// Emails inexistentes também são contados, sem aviso
+	for i := 0; i < 3; i++ {
+		attemptLogin("ninguem@email.com", "errada")
+	}
+	if rec := attemptLogin("ninguem@email.com", "errada"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Esperava status 429 para email inexistente, recebeu %d", rec.Code)
+	}
+	if len(sent.sent) != 1 {
+		t.Errorf("Email inexistente não deveria gerar aviso")
+	}
+
+	// Usuário sem papel de administrador não pode desbloquear
+	pair := loginAs(t, "maria@email.com", "")
+	unlockBody, _ := json.Marshal(UnlockRequest{Email: "joao@email.com"})
+	if rec := unlock(pair.AccessToken, unlockBody); rec.Code != http.StatusForbidden {
+		t.Fatalf("Esperava status 403, recebeu %d", rec.Code)
+	}
+
+	admin := loginAs(t, "admin@email.com", adminRole)
+	if rec := unlock(admin.AccessToken, unlockBody); rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava status 204, recebeu %d", rec.Code)
+	}
+	if last := sent.sent[len(sent.sent)-1]; last.Subject != "Conta desbloqueada" {
+		t.Errorf("Esperava aviso de desbloqueio, obteve %+v", last)
+	}
+	login(t)
+}
+
+// loginAs cria um usuário verificado com o papel informado e autentica
+func loginAs(t *testing.T, email, role string) TokenPair {
+	t.Helper()
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	verifiedAt := time.Now()
+	if err := userStore.Create(context.Background(), &store.User{
+		Email: email, Password: string(hash), VerifiedAt: &verifiedAt, Role: role,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := attemptLogin(email, "senha123")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Login falhou com status %d", rec.Code)
+	}
+	var pair TokenPair
+	json.NewDecoder(rec.Body).Decode(&pair)
+	return pair
+}
+
+// unlock chama o desbloqueio administrativo com o access token informado
+func unlock(accessToken string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/auth/admin/unlock", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	rec := httptest.NewRecorder()
+	authMiddleware(adminMiddleware(unlockHandler))(rec, req)
+	return rec
+}
+
+// useRateLimitPolicies substitui as políticas de limite de requisições
+// durante o teste
+func useRateLimitPolicies(t *testing.T, spec string) {
+	t.Helper()
+	policies, err := ratelimit.ParsePolicies(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := rateLimiter
+	rateLimiter = ratelimit.New(ratelimit.NewMemoryStore(), policies...)
+	t.Cleanup(func() { rateLimiter = previous })
+}
+
+func TestClientIPTrustedProxy(t *testing.T) {
+	proxies, err := parseTrustedProxies("10.0.0.0/8, 192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(previous []*net.IPNet) { trustedProxies = previous }(trustedProxies)
+	trustedProxies = proxies
+
+	for _, tc := range []struct {
+		remoteAddr, forwarded, want string
+	}{
+		// Conexões diretas ignoram o header, que pode ser forjado
+		{"198.51.100.7:1234", "203.0.113.9", "198.51.100.7"},
+		{"192.0.2.1:1234", "203.0.113.9", "203.0.113.9"},
+		// O endereço informado pelo cliente antes dos proxies é ignorado
+		{"10.0.0.2:1234", "203.0.113.50, 203.0.113.9, 10.0.0.3", "203.0.113.9"},
+		{"10.0.0.2:1234", "", "10.0.0.2"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = tc.remoteAddr
+		if tc.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tc.forwarded)
+		}
+		if got := clientIP(r); got != tc.want {
+			t.Errorf("%s com %q: IP %q, esperado %q", tc.remoteAddr, tc.forwarded, got, tc.want)
+		}
+	}
+
+	if _, err := parseTrustedProxies("10.0.0.0/33"); err == nil {
+		t.Error("CIDR inválido deveria ser recusado")
+	}
+}
+
+func TestRateLimitKeysAnonymousByIP(t *testing.T) {
+	useRateLimitPolicies(t, defaultRateLimitPolicies)
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+
+	send := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+		req.RemoteAddr = ip + ":4321"
+		rec := httptest.NewRecorder()
+		rateLimitMiddleware(ok)(rec, req)
+		return rec
+	}
+
+	// A política padrão aceita rajadas de até 10 requisições
+	for i := 0; i < 10; i++ {
+		send("198.51.100.7")
+	}
+	rec := send("198.51.100.7")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Esperava status 429 após o burst, recebeu %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "1" || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("Headers de limite inesperados: %v", rec.Header())
+	}
+	if rec := send("198.51.100.8"); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "9" {
+		t.Errorf("Outro IP não deveria compartilhar o limite, recebeu %d", rec.Code)
+	}
+}
+
+func TestRateLimitUsesRouteTemplate(t *testing.T) {
+	useRateLimitPolicies(t, "/auth/sessions/{id}=ip:1/1m")
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+
+	send := func(path string) int {
+		rec := httptest.NewRecorder()
+		rateLimitMiddleware(ok)(rec, httptest.NewRequest(http.MethodDelete, path, nil))
+		return rec.Code
+	}
+
+	send("/auth/sessions/1")
+	if code := send("/auth/sessions/2"); code != http.StatusTooManyRequests {
+		t.Errorf("Caminhos da mesma rota deveriam compartilhar o limite, recebeu %d", code)
+	}
+	if code := send("/auth/sessions"); code != http.StatusOK {
+		t.Errorf("Outras rotas não deveriam ser limitadas, recebeu %d", code)
+	}
+}
+
+func TestRateLimitKeysAuthenticatedByUser(t *testing.T) {
+	setupStores(t)
+	useRateLimitPolicies(t, "/auth/sessions*=user:2/1m:sliding_window")
+	pair := login(t)
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+
+	for i := 0; i < 2; i++ {
+		if rec := authenticated(ok, "/auth/sessions", pair.AccessToken); rec.Code != http.StatusOK {
+			t.Fatalf("Esperava status 200, recebeu %d", rec.Code)
+		}
+	}
+	rec := authenticated(ok, "/auth/sessions/1", pair.AccessToken)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("RateLimit-Policy") != "2;w=60" {
+		t.Errorf("Esperava status 429 pelo limite do usuário, recebeu %d %v", rec.Code, rec.Header())
+	}
+	if rec := authenticated(ok, "/auth/validate", pair.AccessToken); rec.Code != http.StatusOK {
+		t.Errorf("Rotas fora da política não deveriam ser limitadas, recebeu %d", rec.Code)
+	}
+}
+
+// login autentica o usuário de teste e retorna o par de tokens
+func login(t *testing.T) TokenPair {
+	t.Helper()
+
+	jsonBody, _ := json.Marshal(LoginRequest{Email: "joao@email.com", Password: "senha123"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(jsonBody))
+	rec := httptest.NewRecorder()
+	loginHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Login falhou com status %d", rec.Code)
+	}
+
+	var pair TokenPair
+	json.NewDecoder(rec.Body).Decode(&pair)
+	return pair
+}
+
+// refresh troca o refresh token e retorna a resposta
+func refresh(refreshToken string) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(RefreshRequest{RefreshToken: refreshToken})
 	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
 	refreshHandler(rec, req)
+	return rec
+}
+
+func TestRefreshHandler(t *testing.T) {
+	setupStores(t)
+	pair := login(t)
+
+	rec := refresh(pair.RefreshToken)
 	if rec.Code != http.StatusOK {
 		t.Errorf("Esperava status 200, recebeu %d", rec.Code)
 	}
-	var resp LoginResponse
+	var resp TokenPair
 	json.NewDecoder(rec.Body).Decode(&resp)
-	if resp.Token == "" {
+	if resp.AccessToken == "" || resp.RefreshToken == "" {
 		t.Error("Novo token não foi gerado")
 	}
+
+	// Token inexistente deve ser recusado
+	if rec := refresh("token-antigo"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Esperava status 401, recebeu %d", rec.Code)
+	}
 }
 
-func TestValidateHandler(t *testing.T) {
-	reqBody := ValidateRequest{
-		Token: "token-jwt",
+func TestRefreshHandlerReuseRevokesFamily(t *testing.T) {
+	setupStores(t)
+	pair := login(t)
+
+	rec := refresh(pair.RefreshToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Esperava status 200, recebeu %d", rec.Code)
 	}
-	jsonBody, _ := json.Marshal(reqBody)
+	var rotated TokenPair
+	json.NewDecoder(rec.Body).Decode(&rotated)
+
+	// Reapresentar o token antigo revoga a família inteira
+	if rec := refresh(pair.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Esperava status 401 no reuso, recebeu %d", rec.Code)
+	}
+	if rec := refresh(rotated.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Esperava status 401 para token da família revogada, recebeu %d", rec.Code)
+	}
+}
+
+// validate envia o token ao validateHandler
+func validate(token string) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(ValidateRequest{Token: token})
 	req := httptest.NewRequest(http.MethodPost, "/auth/validate", bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
 	rec := httptest.NewRecorder()
 	validateHandler(rec, req)
+	return rec
+}
+
+// authenticated executa o handler protegido pelo authMiddleware com o access token
+func authenticated(handler http.HandlerFunc, path, accessToken string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	rec := httptest.NewRecorder()
+	authMiddleware(handler)(rec, req)
+	return rec
+}
+
+func TestValidateHandler(t *testing.T) {
+	setupStores(t)
+	pair := login(t)
+
+	rec := validate(pair.AccessToken)
 	if rec.Code != http.StatusOK {
 		t.Errorf("Esperava status 200, recebeu %d", rec.Code)
 	}
@@ -64,4 +562,1320 @@ func TestValidateHandler(t *testing.T) {
 	if !resp.Valid {
 		t.Error("Token deveria ser válido")
 	}
+
+	if rec := validate("token-jwt"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Esperava status 401 para token inválido, recebeu %d", rec.Code)
+	}
+}
+
+func TestLogoutHandler(t *testing.T) {
+	setupStores(t)
+	pair := login(t)
+	other := login(t)
+
+	if rec := authenticated(logoutHandler, "/auth/logout", pair.AccessToken); rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava status 204, recebeu %d", rec.Code)
+	}
+
+	if rec := validate(pair.AccessToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Access token deveria ser recusado após logout, recebeu %d", rec.Code)
+	}
+	if rec := refresh(pair.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Refresh token deveria ser recusado após logout, recebeu %d", rec.Code)
+	}
+
+	// Outras sessões do usuário continuam válidas
+	if rec := validate(other.AccessToken); rec.Code != http.StatusOK {
+		t.Errorf("Outra sessão deveria continuar válida, recebeu %d", rec.Code)
+	}
+}
+
+func TestLogoutAllHandler(t *testing.T) {
+	setupStores(t)
+	pair := login(t)
+	other := login(t)
+
+	if rec := authenticated(logoutAllHandler, "/auth/logout-all", pair.AccessToken); rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava status 204, recebeu %d", rec.Code)
+	}
+
+	for _, p := range []TokenPair{pair, other} {
+		if rec := validate(p.AccessToken); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Access token deveria ser recusado após logout-all, recebeu %d", rec.Code)
+		}
+		if rec := refresh(p.RefreshToken); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Refresh token deveria ser recusado após logout-all, recebeu %d", rec.Code)
+		}
+	}
+}
+
+func TestJWKSHandler(t *testing.T) {
+	setupStores(t)
+	pair := login(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	jwksHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Esperava status 200, recebeu %d", rec.Code)
+	}
+
+	var jwks keys.JWKS
+	json.NewDecoder(rec.Body).Decode(&jwks)
+
+	// O kid do token emitido deve estar publicado no JWKS
+	token, _, err := jwt.NewParser().ParseUnverified(pair.AccessToken, &Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, key := range jwks.Keys {
+		found = found || key.Kid == token.Header["kid"]
+	}
+	if !found {
+		t.Errorf("kid %v não publicado no JWKS", token.Header["kid"])
+	}
+}
+
+// sessionsRequest chama o handler de sessões do usuário autenticado
+func sessionsRequest(method, path, accessToken string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	rec := httptest.NewRecorder()
+	authMiddleware(sessionsHandler)(rec, req)
+	return rec
+}
+
+func TestSessionsHandler(t *testing.T) {
+	setupStores(t)
+
+	jsonBody, _ := json.Marshal(LoginRequest{Email: "joao@email.com", Password: "senha123"})
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(jsonBody))
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36")
+	rec := httptest.NewRecorder()
+	loginHandler(rec, req)
+	var desktop TokenPair
+	json.NewDecoder(rec.Body).Decode(&desktop)
+
+	phone := login(t)
+
+	rec = sessionsRequest(http.MethodGet, "/auth/sessions", desktop.AccessToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Esperava status 200, recebeu %d", rec.Code)
+	}
+	var sessions []SessionResponse
+	json.NewDecoder(rec.Body).Decode(&sessions)
+	if len(sessions) != 2 {
+		t.Fatalf("Esperava 2 sessões, obteve %d", len(sessions))
+	}
+
+	var desktopSession, phoneSession *SessionResponse
+	for i := range sessions {
+		if sessions[i].Current {
+			desktopSession = &sessions[i]
+		} else {
+			phoneSession = &sessions[i]
+		}
+	}
+	if desktopSession == nil || desktopSession.Device != "Chrome no Windows" {
+		t.Fatalf("Sessão atual não identificada: %+v", sessions)
+	}
+
+	// Sessões de outro usuário não podem ser revogadas
+	other := loginAs(t, "maria@email.com", "")
+	if rec := sessionsRequest(http.MethodDelete, "/auth/sessions/"+phoneSession.ID, other.AccessToken); rec.Code != http.StatusNotFound {
+		t.Errorf("Esperava status 404, recebeu %d", rec.Code)
+	}
+
+	if rec := sessionsRequest(http.MethodDelete, "/auth/sessions/"+phoneSession.ID, desktop.AccessToken); rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava status 204, recebeu %d", rec.Code)
+	}
+
+	// A sessão revogada perde o refresh token e os access tokens já emitidos
+	if rec := refresh(phone.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Refresh da sessão revogada deveria falhar, recebeu %d", rec.Code)
+	}
+	if rec := validate(phone.AccessToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Access token da sessão revogada deveria ser recusado, recebeu %d", rec.Code)
+	}
+	if rec := validate(desktop.AccessToken); rec.Code != http.StatusOK {
+		t.Errorf("Outras sessões não deveriam ser afetadas, recebeu %d", rec.Code)
+	}
+}
+
+func TestAdminSessionsHandler(t *testing.T) {
+	setupStores(t)
+	member := login(t)
+	admin := loginAs(t, "admin@email.com", adminRole)
+
+	send := func(method, path, accessToken string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		rec := httptest.NewRecorder()
+		authMiddleware(adminMiddleware(adminUsersHandler))(rec, req)
+		return rec
+	}
+
+	if rec := send(http.MethodGet, "/auth/admin/users/1/sessions", member.AccessToken); rec.Code != http.StatusForbidden {
+		t.Errorf("Esperava status 403 para membro, recebeu %d", rec.Code)
+	}
+
+	rec := send(http.MethodGet, "/auth/admin/users/1/sessions", admin.AccessToken)
+	var sessions []SessionResponse
+	json.NewDecoder(rec.Body).Decode(&sessions)
+	if rec.Code != http.StatusOK || len(sessions) != 1 || sessions[0].UserID != "1" {
+		t.Fatalf("Esperava a sessão do membro, recebeu %d %+v", rec.Code, sessions)
+	}
+
+	if rec := send(http.MethodDelete, "/auth/admin/users/1/sessions/"+sessions[0].ID, admin.AccessToken); rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava status 204, recebeu %d", rec.Code)
+	}
+	if rec := validate(member.AccessToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Access token da sessão revogada deveria ser recusado, recebeu %d", rec.Code)
+	}
+}
+
+func TestDescribeDevice(t *testing.T) {
+	cases := map[string]string{
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1": "Safari no iOS",
+		"Mozilla/5.0 (Linux; Android 14) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36":                                "Chrome no Android",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36 Edg/120.0":                   "Edge no Windows",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0":                                                                  "Firefox no Linux",
+		"curl/8.0": "Dispositivo desconhecido",
+	}
+	for userAgent, want := range cases {
+		if got := describeDevice(userAgent); got != want {
+			t.Errorf("%s: esperava %q, obteve %q", userAgent, want, got)
+		}
+	}
+}
+
+func TestOpenIDConfigurationHandler(t *testing.T) {
+	setupStores(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/openid-configuration", nil)
+	rec := httptest.NewRecorder()
+	openidConfigurationHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Esperava status 200, recebeu %d", rec.Code)
+	}
+
+	var doc DiscoveryDocument
+	json.NewDecoder(rec.Body).Decode(&doc)
+	if doc.Issuer != oidcIssuer || doc.TokenEndpoint != oidcIssuer+"/oauth/token" || doc.JWKSURI != oidcIssuer+"/.well-known/jwks.json" {
+		t.Errorf("Metadados inesperados: %+v", doc)
+	}
+	if len(doc.CodeChallengeMethodsSupported) != 1 || doc.CodeChallengeMethodsSupported[0] != "S256" {
+		t.Errorf("Esperava apenas PKCE S256, obteve %v", doc.CodeChallengeMethodsSupported)
+	}
+}
+
+// pkceVerifier é um code_verifier válido e pkceChallenge o seu S256
+const (
+	pkceVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	pkceChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+// authorize chama /oauth/authorize via POST, como o frontend após o login
+func authorize(accessToken string, params url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/oauth/authorize", strings.NewReader(params.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	rec := httptest.NewRecorder()
+	authorizeHandler(rec, req)
+	return rec
+}
+
+// authorizationParams retorna uma requisição de autorização válida para o cliente wiki
+func authorizationParams() url.Values {
+	return url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"wiki"},
+		"redirect_uri":          {"https://wiki.exemplo.com/callback"},
+		"scope":                 {"openid profile email"},
+		"state":                 {"xyz"},
+		"nonce":                 {"n-0S6"},
+		"code_challenge":        {pkceChallenge},
+		"code_challenge_method": {"S256"},
+	}
+}
+
+// redirectParams decodifica a URL de retorno devolvida em JSON pelo POST
+func redirectParams(t *testing.T, rec *httptest.ResponseRecorder) (*url.URL, url.Values) {
+	t.Helper()
+
+	var body map[string]string
+	json.NewDecoder(rec.Body).Decode(&body)
+	target, err := url.Parse(body["redirect_to"])
+	if err != nil || body["redirect_to"] == "" {
+		t.Fatalf("redirect_to inválido: %v", body)
+	}
+	return target, target.Query()
+}
+
+// userinfo chama o endpoint userinfo com o access token informado
+func userinfo(accessToken string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/oauth/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	rec := httptest.NewRecorder()
+	clientAuthMiddleware(userinfoHandler)(rec, req)
+	return rec
+}
+
+// exchangeCode troca o código de autorização no endpoint de token
+func exchangeCode(form url.Values, basicUser, basicPassword string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if basicUser != "" {
+		req.SetBasicAuth(basicUser, basicPassword)
+	}
+	rec := httptest.NewRecorder()
+	oauthTokenHandler(rec, req)
+	return rec
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	setupStores(t)
+	pair := login(t)
+
+	rec := authorize(pair.AccessToken, authorizationParams())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Esperava status 200, recebeu %d: %s", rec.Code, rec.Body.String())
+	}
+	target, params := redirectParams(t, rec)
+	if target.Host != "wiki.exemplo.com" || params.Get("state") != "xyz" || params.Get("code") == "" {
+		t.Fatalf("Redirecionamento inesperado: %s", target)
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {params.Get("code")},
+		"redirect_uri":  {"https://wiki.exemplo.com/callback"},
+		"client_id":     {"wiki"},
+		"code_verifier": {pkceVerifier},
+	}
+	rec = exchangeCode(form, "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Esperava status 200, recebeu %d: %s", rec.Code, rec.Body.String())
+	}
+	var tokens OAuthTokenResponse
+	json.NewDecoder(rec.Body).Decode(&tokens)
+
+	// O ID token é destinado ao cliente e não vale como access token
+	idClaims := &IDTokenClaims{}
+	_, err := jwt.ParseWithClaims(tokens.IDToken, idClaims, signingKeys.Keyfunc,
+		jwt.WithAudience("wiki"), jwt.WithIssuer(oidcIssuer))
+	if err != nil {
+		t.Fatalf("ID token inválido: %v", err)
+	}
+	if idClaims.Subject != "1" || idClaims.Nonce != "n-0S6" || idClaims.Email != "joao@email.com" ||
+		idClaims.EmailVerified == nil || !*idClaims.EmailVerified || idClaims.Name != "João" {
+		t.Errorf("Claims inesperadas no ID token: %+v", idClaims)
+	}
+	if rec := userinfo(tokens.IDToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("ID token não deveria ser aceito como access token, recebeu %d", rec.Code)
+	}
+
+	rec = userinfo(tokens.AccessToken)
+	var info UserInfoResponse
+	json.NewDecoder(rec.Body).Decode(&info)
+	if rec.Code != http.StatusOK || info.Subject != "1" || info.Email != "joao@email.com" || info.Name != "João" {
+		t.Errorf("Userinfo inesperado: %d %+v", rec.Code, info)
+	}
+
+	// O access token é vinculado ao cliente e não vale nas rotas do usuário
+	accessClaims, err := verifyToken(tokens.AccessToken)
+	if err != nil || len(accessClaims.Audience) != 1 || accessClaims.Audience[0] != "wiki" || accessClaims.Scope != "openid profile email" {
+		t.Errorf("Access token deveria trazer aud e scope do cliente, obteve %+v (%v)", accessClaims, err)
+	}
+	if rec := sessionsRequest(http.MethodGet, "/auth/sessions", tokens.AccessToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Token do cliente não deveria acessar as sessões do usuário, recebeu %d", rec.Code)
+	}
+	if rec := validate(tokens.AccessToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Token do cliente não deveria ser validado para as APIs, recebeu %d", rec.Code)
+	}
+	if rec := authorize(tokens.AccessToken, authorizationParams()); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Token do cliente não deveria autorizar outros clientes, recebeu %d", rec.Code)
+	}
+
+	// O refresh mantém o vínculo com o cliente
+	rec = refresh(tokens.RefreshToken)
+	var renewed TokenPair
+	json.NewDecoder(rec.Body).Decode(&renewed)
+	renewedClaims, err := verifyToken(renewed.AccessToken)
+	if err != nil || len(renewedClaims.Audience) != 1 || renewedClaims.Audience[0] != "wiki" || renewedClaims.Scope != "openid profile email" {
+		t.Errorf("Tokens renovados deveriam manter o vínculo com o cliente, obteve %+v (%v)", renewedClaims, err)
+	}
+
+	// A sessão aberta para o cliente aparece com o nome dele
+	session, err := sessionStore.Find(context.Background(), idClaims.SessionID)
+	if err != nil || session.Device != "Wiki" {
+		t.Errorf("Esperava sessão do cliente Wiki, obteve %+v (%v)", session, err)
+	}
+
+	// O código é de uso único
+	if rec := exchangeCode(form, "", ""); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_grant") {
+		t.Errorf("Reuso do código deveria falhar com invalid_grant, recebeu %d", rec.Code)
+	}
+}
+
+func TestUserinfoFollowsScopes(t *testing.T) {
+	setupStores(t)
+	pair := login(t)
+
+	params := authorizationParams()
+	params.Set("scope", "openid")
+	_, query := redirectParams(t, authorize(pair.AccessToken, params))
+	rec := exchangeCode(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {query.Get("code")},
+		"redirect_uri":  {"https://wiki.exemplo.com/callback"},
+		"client_id":     {"wiki"},
+		"code_verifier": {pkceVerifier},
+	}, "", "")
+	var tokens OAuthTokenResponse
+	json.NewDecoder(rec.Body).Decode(&tokens)
+
+	rec = userinfo(tokens.AccessToken)
+	var info UserInfoResponse
+	json.NewDecoder(rec.Body).Decode(&info)
+	if rec.Code != http.StatusOK || info.Subject != "1" || info.Name != "" || info.Email != "" || info.EmailVerified != nil {
+		t.Errorf("Sem profile e email, o userinfo deveria trazer apenas sub, obteve %d %+v", rec.Code, info)
+	}
+
+	// Tokens do próprio usuário recebem todas as claims
+	rec = userinfo(pair.AccessToken)
+	info = UserInfoResponse{}
+	json.NewDecoder(rec.Body).Decode(&info)
+	if rec.Code != http.StatusOK || info.Name != "João" || info.Email != "joao@email.com" {
+		t.Errorf("Userinfo inesperado para o token do usuário: %d %+v", rec.Code, info)
+	}
+}
+
+func TestAuthorizationCodeExchangeChecks(t *testing.T) {
+	setupStores(t)
+	pair := login(t)
+
+	newCode := func(params url.Values) string {
+		_, query := redirectParams(t, authorize(pair.AccessToken, params))
+		return query.Get("code")
+	}
+
+	// code_verifier diferente do registrado no code_challenge
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {newCode(authorizationParams())},
+		"redirect_uri":  {"https://wiki.exemplo.com/callback"},
+		"client_id":     {"wiki"},
+		"code_verifier": {strings.Repeat("a", 43)},
+	}
+	if rec := exchangeCode(form, "", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("code_verifier inválido deveria falhar, recebeu %d", rec.Code)
+	}
+
+	// Cliente confidencial precisa apresentar o segredo
+	params := authorizationParams()
+	params.Set("client_id", "louvor")
+	params.Set("redirect_uri", "https://louvor.exemplo.com/oidc/callback?tenant=1")
+	target, query := redirectParams(t, authorize(pair.AccessToken, params))
+	if query.Get("tenant") != "1" {
+		t.Errorf("Query registrada no redirect_uri deveria ser preservada: %s", target)
+	}
+	form = url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {query.Get("code")},
+		"redirect_uri":  {"https://louvor.exemplo.com/oidc/callback?tenant=1"},
+		"code_verifier": {pkceVerifier},
+	}
+	if rec := exchangeCode(form, "louvor", "errado"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Segredo inválido deveria falhar com 401, recebeu %d", rec.Code)
+	}
+	if rec := exchangeCode(form, "louvor", "segredo"); rec.Code != http.StatusOK {
+		t.Errorf("Esperava status 200, recebeu %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAuthorizeHandlerValidation(t *testing.T) {
+	setupStores(t)
+	pair := login(t)
+
+	// redirect_uri fora da lista não recebe redirecionamento
+	params := authorizationParams()
+	params.Set("redirect_uri", "https://atacante.exemplo.com/callback")
+	if rec := authorize(pair.AccessToken, params); rec.Code != http.StatusBadRequest {
+		t.Errorf("redirect_uri não registrado deveria falhar com 400, recebeu %d", rec.Code)
+	}
+	params = authorizationParams()
+	params.Set("client_id", "desconhecido")
+	if rec := authorize(pair.AccessToken, params); rec.Code != http.StatusBadRequest {
+		t.Errorf("Cliente desconhecido deveria falhar com 400, recebeu %d", rec.Code)
+	}
+
+	// Sem PKCE o erro é devolvido ao cliente
+	params = authorizationParams()
+	params.Del("code_challenge")
+	if _, query := redirectParams(t, authorize(pair.AccessToken, params)); query.Get("error") != "invalid_request" || query.Get("state") != "xyz" {
+		t.Errorf("Esperava invalid_request, obteve %v", query)
+	}
+
+	// Navegação sem sessão é enviada ao login do frontend
+	req := httptest.NewRequest(http.MethodGet, "/oauth/authorize?"+authorizationParams().Encode(), nil)
+	rec := httptest.NewRecorder()
+	authorizeHandler(rec, req)
+	location := rec.Header().Get("Location")
+	if rec.Code != http.StatusFound || !strings.HasPrefix(location, oidcLoginURL+"?return_to=") {
+		t.Errorf("Esperava redirecionamento ao login, recebeu %d %s", rec.Code, location)
+	}
+}
+
+// apiKeysRequest chama o handler de chaves de API do usuário autenticado
+func apiKeysRequest(method, path, credential string, body interface{}) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(jsonBody))
+	req.Header.Set("Authorization", "Bearer "+credential)
+	rec := httptest.NewRecorder()
+	authMiddleware(apiKeysHandler)(rec, req)
+	return rec
+}
+
+func TestAPIKeysHandler(t *testing.T) {
+	setupStores(t)
+	userStore.(*store.MemoryUserStore).GrantRolePermissions("secretaria", "members:read", "events:read", "users:read")
+	pair := loginAs(t, "secretaria@email.com", "secretaria")
+
+	// Escopos fora das permissões do papel são recusados
+	rec := apiKeysRequest(http.MethodPost, "/auth/api-keys", pair.AccessToken,
+		CreateAPIKeyRequest{Name: "Quiosque", Scopes: []string{"members:delete"}})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Escopo não concedido ao papel deveria falhar, recebeu %d", rec.Code)
+	}
+	past := time.Now().Add(-time.Hour)
+	rec = apiKeysRequest(http.MethodPost, "/auth/api-keys", pair.AccessToken,
+		CreateAPIKeyRequest{Name: "Quiosque", Scopes: []string{"members:read"}, ExpiresAt: &past})
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Validade no passado deveria falhar, recebeu %d", rec.Code)
+	}
+
+	rec = apiKeysRequest(http.MethodPost, "/auth/api-keys", pair.AccessToken,
+		CreateAPIKeyRequest{Name: "Quiosque", Scopes: []string{"members:read", "users:read"}})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Esperava status 201, recebeu %d: %s", rec.Code, rec.Body.String())
+	}
+	var created CreateAPIKeyResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if !strings.HasPrefix(created.Key, store.APIKeyPrefix) || !strings.HasPrefix(created.Key, created.Prefix) {
+		t.Fatalf("Chave inesperada: %+v", created)
+	}
+
+	// A chave autentica como o dono, mas não gerencia chaves
+	if rec := userinfo(created.Key); rec.Code != http.StatusOK {
+		t.Errorf("Chave de API deveria ser aceita, recebeu %d", rec.Code)
+	}
+	if rec := apiKeysRequest(http.MethodGet, "/auth/api-keys", created.Key, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("Chave de API não deveria listar chaves, recebeu %d", rec.Code)
+	}
+
+	rec = apiKeysRequest(http.MethodGet, "/auth/api-keys", pair.AccessToken, nil)
+	var keys []store.APIKey
+	json.NewDecoder(rec.Body).Decode(&keys)
+	if len(keys) != 1 || keys[0].LastUsedAt == nil || keys[0].Scopes[0] != "members:read" {
+		t.Fatalf("Esperava a chave com último uso registrado, obteve %+v", keys)
+	}
+
+	if rec := apiKeysRequest(http.MethodDelete, "/auth/api-keys/"+created.ID, pair.AccessToken, nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava status 204, recebeu %d", rec.Code)
+	}
+	if rec := userinfo(created.Key); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Chave revogada deveria ser recusada, recebeu %d", rec.Code)
+	}
+}
+
+func TestAPIKeyScopesPerRoute(t *testing.T) {
+	setupStores(t)
+	userStore.(*store.MemoryUserStore).GrantRolePermissions(adminRole, "members:read")
+	admin := loginAs(t, "admin@email.com", adminRole)
+	member, _ := userStore.FindByEmail(context.Background(), "joao@email.com")
+
+	rec := apiKeysRequest(http.MethodPost, "/auth/api-keys", admin.AccessToken,
+		CreateAPIKeyRequest{Name: "Projeção", Scopes: []string{"members:read"}})
+	var created CreateAPIKeyResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Esperava status 201, recebeu %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Sem o escopo da rota, a chave é recusada
+	if rec := userinfo(created.Key); rec.Code != http.StatusForbidden {
+		t.Errorf("Chave sem users:read deveria ser recusada no userinfo, recebeu %d", rec.Code)
+	}
+
+	// Rotas de sessão, de credenciais e administrativas não aceitam chaves,
+	// mesmo de um administrador
+	for name, rec := range map[string]*httptest.ResponseRecorder{
+		"logout-all":    authenticated(denyImpersonation(logoutAllHandler), "/auth/logout-all", created.Key),
+		"sessões":       sessionsRequest(http.MethodDelete, "/auth/sessions/qualquer", created.Key),
+		"criar chave":   apiKeysRequest(http.MethodPost, "/auth/api-keys", created.Key, CreateAPIKeyRequest{Name: "Nova", Scopes: []string{"members:read"}}),
+		"personificar":  impersonate(created.Key, ImpersonateRequest{UserID: member.ID, Reason: "suporte"}),
+		"validar token": authenticated(validateHandler, "/auth/validate", created.Key),
+	} {
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: chave de API deveria ser recusada com 403, recebeu %d", name, rec.Code)
+		}
+	}
+	if keys, _ := apiKeyStore.List(context.Background(), created.UserID); len(keys) != 1 {
+		t.Errorf("Chave de API não deveria criar chaves, existem %d", len(keys))
+	}
+}
+
+func TestAdminAPIKeysHandler(t *testing.T) {
+	setupStores(t)
+	userStore.(*store.MemoryUserStore).GrantRolePermissions("servico", "donations:read")
+	admin := loginAs(t, "admin@email.com", adminRole)
+	loginAs(t, "conciliacao@email.com", "servico")
+	service, _ := userStore.FindByEmail(context.Background(), "conciliacao@email.com")
+
+	send := func(method, path, credential string, body interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Authorization", "Bearer "+credential)
+		rec := httptest.NewRecorder()
+		authMiddleware(adminMiddleware(adminUsersHandler))(rec, req)
+		return rec
+	}
+
+	path := "/auth/admin/users/" + service.ID + "/api-keys"
+	rec := send(http.MethodPost, path, admin.AccessToken,
+		CreateAPIKeyRequest{Name: "Conciliação noturna", Scopes: []string{"donations:read"}})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Esperava status 201, recebeu %d: %s", rec.Code, rec.Body.String())
+	}
+	var created CreateAPIKeyResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if created.UserID != service.ID || created.CreatedBy == service.ID {
+		t.Errorf("Chave deveria pertencer à conta de serviço e registrar o admin: %+v", created.APIKey)
+	}
+
+	// Chaves de API não dão acesso administrativo
+	if rec := send(http.MethodGet, path, created.Key, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("Esperava status 403 para chave de API, recebeu %d", rec.Code)
+	}
+
+	if rec := send(http.MethodDelete, path+"/"+created.ID, admin.AccessToken, nil); rec.Code != http.StatusNoContent {
+		t.Errorf("Esperava status 204, recebeu %d", rec.Code)
+	}
+}
+
+// impersonate pede ao impersonateHandler um token para agir como o usuário
+func impersonate(accessToken string, body ImpersonateRequest) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "/auth/admin/impersonate", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	rec := httptest.NewRecorder()
+	authMiddleware(adminMiddleware(impersonateHandler))(rec, req)
+	return rec
+}
+
+func TestImpersonateHandler(t *testing.T) {
+	setupStores(t)
+	member := login(t)
+	admin := loginAs(t, "admin@email.com", adminRole)
+	other, _ := userStore.FindByEmail(context.Background(), "admin@email.com")
+
+	if rec := impersonate(member.AccessToken, ImpersonateRequest{UserID: other.ID, Reason: "teste"}); rec.Code != http.StatusForbidden {
+		t.Errorf("Membro não deveria personificar, recebeu %d", rec.Code)
+	}
+	if rec := impersonate(admin.AccessToken, ImpersonateRequest{UserID: "1"}); rec.Code != http.StatusBadRequest {
+		t.Errorf("Motivo deveria ser obrigatório, recebeu %d", rec.Code)
+	}
+	loginAs(t, "pastor@email.com", adminRole)
+	pastor, _ := userStore.FindByEmail(context.Background(), "pastor@email.com")
+	if rec := impersonate(admin.AccessToken, ImpersonateRequest{UserID: pastor.ID, Reason: "teste"}); rec.Code != http.StatusForbidden {
+		t.Errorf("Administradores não deveriam ser personificados, recebeu %d", rec.Code)
+	}
+
+	rec := impersonate(admin.AccessToken, ImpersonateRequest{UserID: "1", Reason: "Chamado 42: erro no cadastro"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Esperava status 200, recebeu %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp ImpersonateResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.UserID != "1" || resp.ExpiresIn != int(impersonationTokenTTL.Seconds()) {
+		t.Fatalf("Resposta inesperada: %+v", resp)
+	}
+
+	rec = validate(resp.AccessToken)
+	var validated ValidateResponse
+	json.NewDecoder(rec.Body).Decode(&validated)
+	if !validated.Valid || validated.UserID != "1" || validated.ActorID != other.ID || validated.Type != impersonationTokenType {
+		t.Fatalf("Claims inesperadas: %+v", validated.Claims)
+	}
+
+	// Ações sensíveis são bloqueadas durante a personificação
+	if rec := authenticated(denyImpersonation(logoutAllHandler), "/auth/logout-all", resp.AccessToken); rec.Code != http.StatusForbidden {
+		t.Errorf("logout-all deveria ser bloqueado, recebeu %d", rec.Code)
+	}
+	if rec := sessionsRequest(http.MethodDelete, "/auth/sessions/1", resp.AccessToken); rec.Code != http.StatusForbidden {
+		t.Errorf("Revogar sessões deveria ser bloqueado, recebeu %d", rec.Code)
+	}
+	if rec := impersonate(resp.AccessToken, ImpersonateRequest{UserID: "1", Reason: "teste"}); rec.Code != http.StatusForbidden {
+		t.Errorf("Rotas administrativas deveriam ser bloqueadas, recebeu %d", rec.Code)
+	}
+	if rec := authorize(resp.AccessToken, authorizationParams()); rec.Code == http.StatusOK {
+		t.Errorf("Autorização OIDC deveria ser recusada durante a personificação")
+	}
+
+	// Encerrar a personificação não encerra a sessão do administrador
+	if rec := authenticated(logoutHandler, "/auth/logout", resp.AccessToken); rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava status 204, recebeu %d", rec.Code)
+	}
+	if rec := validate(resp.AccessToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Token de personificação deveria ser recusado após logout, recebeu %d", rec.Code)
+	}
+	if rec := validate(admin.AccessToken); rec.Code != http.StatusOK {
+		t.Errorf("Sessão do administrador deveria continuar válida, recebeu %d", rec.Code)
+	}
+}
+
+// drainAudit retorna os registros de auditoria enfileirados até o momento
+func drainAudit() []AuditLog {
+	var logs []AuditLog
+	for {
+		select {
+		case log := <-auditLogger.queue:
+			logs = append(logs, log)
+		default:
+			return logs
+		}
+	}
+}
+
+func TestAuditEventsRecordActorAndCorrelation(t *testing.T) {
+	setupStores(t)
+	admin := loginAs(t, "admin@email.com", adminRole)
+	adminUser, _ := userStore.FindByEmail(context.Background(), "admin@email.com")
+
+	rec := impersonate(admin.AccessToken, ImpersonateRequest{UserID: "1", Reason: "suporte"})
+	var resp ImpersonateResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	drainAudit()
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+resp.AccessToken)
+	req.Header.Set(auditevent.CorrelationHeader, "req-123")
+	rec = httptest.NewRecorder()
+	auditMiddleware(authMiddleware(logoutHandler))(rec, req)
+
+	if got := rec.Header().Get(auditevent.CorrelationHeader); got != "req-123" {
+		t.Errorf("ID de correlação deveria ser devolvido, obteve %q", got)
+	}
+	logs := drainAudit()
+	if len(logs) != 2 {
+		t.Fatalf("Esperava 2 registros, obteve %+v", logs)
+	}
+	entry := logs[0]
+	if entry.Action != auditevent.SessionRevoked || entry.UserID != "1" || entry.ActorID != adminUser.ID ||
+		entry.Outcome != string(auditevent.OutcomeSuccess) || entry.CorrelationID != "req-123" {
+		t.Errorf("Entrada de auditoria inesperada: %+v", entry)
+	}
+
+	// Toda requisição personificada é registrada, inclusive as consultas
+	request := logs[1]
+	if request.Action != auditevent.ImpersonatedRequest || request.ActorID != adminUser.ID ||
+		request.Details != "POST /auth/logout" || request.CorrelationID != "req-123" {
+		t.Errorf("Requisição personificada deveria ser registrada: %+v", request)
+	}
+	rec = impersonate(admin.AccessToken, ImpersonateRequest{UserID: "1", Reason: "suporte"})
+	json.NewDecoder(rec.Body).Decode(&resp)
+	drainAudit()
+	sessionsRequest(http.MethodGet, "/auth/sessions", resp.AccessToken)
+	if logs := drainAudit(); len(logs) != 1 || logs[0].Action != auditevent.ImpersonatedRequest ||
+		logs[0].Details != "GET /auth/sessions" || logs[0].Resource != "user:1" {
+		t.Errorf("Consulta personificada deveria ser registrada: %+v", logs)
+	}
+
+	// Sem o header, um ID é gerado
+	req = httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+admin.AccessToken)
+	rec = httptest.NewRecorder()
+	auditMiddleware(authMiddleware(logoutHandler))(rec, req)
+	if logs := drainAudit(); len(logs) != 1 || logs[0].CorrelationID == "" ||
+		logs[0].CorrelationID != rec.Header().Get(auditevent.CorrelationHeader) {
+		t.Errorf("ID de correlação deveria ser gerado: %+v", logs)
+	}
+}
+
+func TestAuditRedactsPersonalData(t *testing.T) {
+	setupStores(t)
+
+	auditLogger.LogEntry(AuditLog{
+		UserID:    "1",
+		Action:    auditevent.AccountUnlocked,
+		Resource:  "user:maria@email.com",
+		IP:        "203.0.113.7",
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0",
+		Changes: []auditevent.Change{
+			{Field: "email", Before: "maria@email.com", After: "maria.souza@email.com"},
+			{Field: "role", Before: "member", After: "admin"},
+		},
+		Timestamp: time.Now(),
+	})
+
+	logs := drainAudit()
+	if len(logs) != 1 {
+		t.Fatalf("Esperava 1 registro, obteve %+v", logs)
+	}
+	entry := logs[0]
+	if entry.Resource != "user:m***@email.com" || entry.IP != "203.0.113.0" || entry.UserAgent != "Mozilla/5.0" {
+		t.Errorf("Dados pessoais deveriam ser mascarados: %+v", entry)
+	}
+	if entry.Changes[0].Before != "m***@email.com" || entry.Changes[0].After != "m***@email.com" ||
+		entry.Changes[1].After != "admin" {
+		t.Errorf("Alterações inesperadas: %+v", entry.Changes)
+	}
+}
+
+func TestMFAUsersAreNotIssuedTokens(t *testing.T) {
+	setupStores(t)
+
+	// Código autorizado com um token emitido antes do cadastro do segundo fator
+	pair := login(t)
+	_, params := redirectParams(t, authorize(pair.AccessToken, authorizationParams()))
+	userStore.(*store.MemoryUserStore).EnableMFA("1", time.Now())
+	drainAudit()
+
+	if rec := attemptLogin("joao@email.com", "senha123"); rec.Code != http.StatusForbidden || strings.Contains(rec.Body.String(), "access_token") {
+		t.Errorf("Login com senha deveria ser recusado, recebeu %d: %s", rec.Code, rec.Body.String())
+	}
+
+	token, deviceToken := requestMagicLink(t, "joao@email.com")
+	if rec := magicLinkLogin(token, deviceToken); rec.Code != http.StatusForbidden || strings.Contains(rec.Body.String(), "access_token") {
+		t.Errorf("Login por link de acesso deveria ser recusado, recebeu %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := exchangeCode(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {params.Get("code")},
+		"redirect_uri":  {"https://wiki.exemplo.com/callback"},
+		"client_id":     {"wiki"},
+		"code_verifier": {pkceVerifier},
+	}, "", "")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_grant") {
+		t.Errorf("Troca do código deveria ser recusada, recebeu %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var denied []string
+	for _, log := range drainAudit() {
+		if log.Outcome == string(auditevent.OutcomeDenied) && log.Details == auditevent.ReasonMFARequired {
+			denied = append(denied, log.Action)
+		}
+	}
+	want := []string{auditevent.LoginFailed, auditevent.LoginFailed, auditevent.OAuthTokenIssued}
+	if strings.Join(denied, ",") != strings.Join(want, ",") {
+		t.Errorf("Esperava as recusas %v registradas, obteve %v", want, denied)
+	}
+
+	// O papel pode exigir o segundo fator antes do cadastro
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	verifiedAt := time.Now()
+	if err := userStore.Create(context.Background(), &store.User{
+		Email: "lider@email.com", Password: string(hash), VerifiedAt: &verifiedAt, Role: "lider", RoleRequiresMFA: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if rec := attemptLogin("lider@email.com", "senha123"); rec.Code != http.StatusForbidden {
+		t.Errorf("Login de papel que exige MFA deveria ser recusado, recebeu %d", rec.Code)
+	}
+}
+
+func TestLoginRecordsAuditEvents(t *testing.T) {
+	setupStores(t)
+
+	for _, password := range []string{"errada", "senha123"} {
+		body, _ := json.Marshal(LoginRequest{Email: "joao@email.com", Password: password})
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body))
+		auditMiddleware(loginHandler)(httptest.NewRecorder(), req)
+	}
+
+	logs := drainAudit()
+	if len(logs) != 2 {
+		t.Fatalf("Esperava 2 registros, obteve %+v", logs)
+	}
+	failed, succeeded := logs[0], logs[1]
+	if failed.Action != auditevent.LoginFailed || failed.UserID != "anonymous" || failed.Resource != "user:1" ||
+		failed.Outcome != string(auditevent.OutcomeFailure) || failed.Details != auditevent.ReasonInvalidCredentials {
+		t.Errorf("Falha de login inesperada: %+v", failed)
+	}
+	if succeeded.Action != auditevent.LoginSucceeded || succeeded.UserID != "1" || succeeded.Resource != "user:1" ||
+		succeeded.Outcome != string(auditevent.OutcomeSuccess) {
+		t.Errorf("Login inesperado: %+v", succeeded)
+	}
+}
+
+func TestAuditLoggerChainsRecords(t *testing.T) {
+	setupStores(t)
+	dir := t.TempDir()
+
+	open := func(name string) *os.File {
+		file, err := os.OpenFile(filepath.Join(dir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { file.Close() })
+		return file
+	}
+	l := &AuditLogger{file: open("audit.log"), checkpoints: open("audit.checkpoints"), sign: signingKeys.Sign}
+
+	for _, action := range []string{"POST", "GET", "DELETE"} {
+		if err := l.write(AuditLog{UserID: "1", Action: action, Resource: "/auth/sessions", Timestamp: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.checkpoint(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// O encadeamento continua após o reinício do serviço
+	head, err := readAuditHead(filepath.Join(dir, "audit.log"))
+	if err != nil || head != l.head || head.Sequence != 3 {
+		t.Fatalf("Último registro inesperado: %+v (%v)", head, err)
+	}
+
+	checkpointFile, _ := os.Open(filepath.Join(dir, "audit.checkpoints"))
+	defer checkpointFile.Close()
+	checkpoints, err := auditchain.ReadCheckpoints(checkpointFile, signingKeys.Keyfunc, signingKeys.Methods())
+	if err != nil || len(checkpoints) != 1 {
+		t.Fatalf("Checkpoint assinado deveria ser aceito: %v", err)
+	}
+
+	logFile, _ := os.Open(filepath.Join(dir, "audit.log"))
+	defer logFile.Close()
+	report, err := auditchain.Verify(logFile, checkpoints)
+	if err != nil || report.Records != 3 || report.Checkpoints != 1 {
+		t.Errorf("Log deveria ser íntegro: %+v, %v", report, err)
+	}
+}
+
+func TestAuditLoggerSpillsAndFlushesOnClose(t *testing.T) {
+	setupStores(t)
+	dir := t.TempDir()
+
+	file, err := os.OpenFile(filepath.Join(dir, "audit.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spillPath := filepath.Join(dir, "audit.spill")
+	l := &AuditLogger{
+		file:           file,
+		queue:          make(chan AuditLog, 1),
+		enqueueTimeout: 10 * time.Millisecond,
+		spillPath:      spillPath,
+		done:           make(chan struct{}),
+	}
+
+	// Sem o worker, a fila enche e os registros seguintes são desviados
+	for _, action := range []string{auditevent.LoginSucceeded, auditevent.SessionRevoked, auditevent.SessionsRevoked} {
+		l.Record(context.Background(), auditevent.Event{Action: action, UserID: "1"})
+	}
+	spilled, err := os.ReadFile(spillPath)
+	if err != nil || strings.Count(string(spilled), "\n") != 2 {
+		t.Fatalf("Esperava 2 registros desviados, obteve %q (%v)", spilled, err)
+	}
+
+	go l.processLogs()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Registros recebidos após o fechamento também são preservados
+	l.Record(context.Background(), auditevent.Event{Action: auditevent.AccountUnlocked, UserID: "1"})
+	spilled, _ = os.ReadFile(spillPath)
+	if !strings.Contains(string(spilled), `"action":"account.unlocked"`) {
+		t.Errorf("Registro após o fechamento deveria ser desviado: %q", spilled)
+	}
+
+	logFile, _ := os.Open(filepath.Join(dir, "audit.log"))
+	defer logFile.Close()
+	report, err := auditchain.Verify(logFile, nil)
+	if err != nil || report.Records != 3 {
+		t.Errorf("Esperava 3 registros encadeados após o fechamento: %+v, %v", report, err)
+	}
+}
+
+// failingAuditStore recusa as gravações enquanto failures for positivo
+type failingAuditStore struct {
+	*store.MemoryAuditStore
+	failures int
+}
+
+func (s *failingAuditStore) Append(ctx context.Context, entry *store.AuditEntry) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("banco indisponível")
+	}
+	return s.MemoryAuditStore.Append(ctx, entry)
+}
+
+func TestAuditLoggerResumesInterruptedDrain(t *testing.T) {
+	setupStores(t)
+	dir := t.TempDir()
+
+	file, err := os.OpenFile(filepath.Join(dir, "audit.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := &AuditLogger{
+		file:      file,
+		queue:     make(chan AuditLog, 1),
+		spillPath: filepath.Join(dir, "audit.spill"),
+		done:      make(chan struct{}),
+	}
+
+	// Um lote separado antes de uma interrupção e um desvio posterior
+	if err := appendSpill(l.drainPath(), AuditLog{UserID: "1", Action: "POST"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendSpill(l.spillPath, AuditLog{UserID: "1", Action: "DELETE"}); err != nil {
+		t.Fatal(err)
+	}
+	l.spilled.Store(true)
+
+	go l.processLogs()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{l.spillPath, l.drainPath()} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s deveria ser removido após a gravação", filepath.Base(path))
+		}
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "audit.log"))
+	if first, second := strings.Index(string(data), `"action":"POST"`), strings.Index(string(data), `"action":"DELETE"`); first < 0 || second < first {
+		t.Errorf("O lote interrompido deveria ser gravado antes dos novos desvios: %q", data)
+	}
+}
+
+func TestAuditLoggerDrainsSpilledWithoutNewRecords(t *testing.T) {
+	setupStores(t)
+	dir := t.TempDir()
+
+	file, err := os.OpenFile(filepath.Join(dir, "audit.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := &AuditLogger{
+		file:          file,
+		queue:         make(chan AuditLog, 1),
+		retryInterval: 10 * time.Millisecond,
+		spillPath:     filepath.Join(dir, "audit.spill"),
+		done:          make(chan struct{}),
+	}
+	go l.processLogs()
+	defer l.Close()
+
+	// Registro desviado durante uma falha, sem eventos posteriores
+	l.spill(AuditLog{UserID: "1", Action: auditevent.SessionRevoked, Timestamp: time.Now()})
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		data, _ := os.ReadFile(filepath.Join(dir, "audit.log"))
+		if strings.Contains(string(data), `"action":"session.revoked"`) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Registro desviado deveria ser gravado sem aguardar um novo evento")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAuditLoggerCopiesRecordsToStore(t *testing.T) {
+	setupStores(t)
+	file, err := os.OpenFile(filepath.Join(t.TempDir(), "audit.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records := &failingAuditStore{MemoryAuditStore: store.NewMemoryAuditStore(), failures: 1}
+	l := &AuditLogger{file: file, store: records}
+
+	// Com o banco indisponível, o registro aguarda a próxima tentativa
+	if err := l.write(AuditLog{UserID: "1", Action: "POST", Resource: "/auth/login", Timestamp: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.pending) != 1 {
+		t.Fatalf("Esperava 1 registro pendente, obteve %d", len(l.pending))
+	}
+
+	l.retryAt = time.Time{}
+	if err := l.write(AuditLog{UserID: "2", Action: "GET", Resource: "/auth/sessions", Timestamp: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	var copied []*store.AuditEntry
+	records.Scan(context.Background(), store.AuditFilter{}, func(entry *store.AuditEntry) error {
+		copied = append(copied, entry)
+		return nil
+	})
+	if len(l.pending) != 0 || len(copied) != 2 {
+		t.Fatalf("Esperava 2 registros copiados em ordem, obteve %d (pendentes: %d)", len(copied), len(l.pending))
+	}
+	if copied[0].Sequence != 1 || copied[1].PrevHash != copied[0].Hash || copied[1].Hash != l.head.Hash {
+		t.Errorf("Cópia deveria manter o encadeamento do arquivo: %+v, %+v", copied[0], copied[1])
+	}
+}
+
+func TestAuditLoggerSpillsStoreCopiesAndBackfills(t *testing.T) {
+	setupStores(t)
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "audit.log")
+	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records := &failingAuditStore{MemoryAuditStore: store.NewMemoryAuditStore(), failures: 1}
+	l := &AuditLogger{
+		file:           file,
+		store:          records,
+		storeSpillPath: filepath.Join(dir, "audit.store.spill"),
+		logPath:        logPath,
+	}
+
+	// Além da capacidade da fila, as cópias aguardam no arquivo de espera
+	for i := 0; i < auditQueueSize+2; i++ {
+		if err := l.write(AuditLog{UserID: "1", Action: "GET", Resource: "/auth/sessions", Timestamp: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(l.pending) != auditQueueSize || !l.storeSpilled {
+		t.Fatalf("Esperava %d registros pendentes e o excedente no arquivo, obteve %d", auditQueueSize, len(l.pending))
+	}
+
+	copied := func(s store.AuditStore) []*store.AuditEntry {
+		var entries []*store.AuditEntry
+		s.Scan(context.Background(), store.AuditFilter{}, func(entry *store.AuditEntry) error {
+			entries = append(entries, entry)
+			return nil
+		})
+		return entries
+	}
+
+	l.retryAt = time.Time{}
+	l.flushPending()
+	entries := copied(records)
+	if len(entries) != auditQueueSize+2 || l.storeSpilled {
+		t.Fatalf("Esperava %d registros copiados, obteve %d", auditQueueSize+2, len(entries))
+	}
+	if entries[len(entries)-1].Hash != l.head.Hash {
+		t.Error("Cópias desviadas deveriam ser copiadas depois das pendentes")
+	}
+	if _, err := os.Stat(l.storeSpillPath); !os.IsNotExist(err) {
+		t.Errorf("Arquivo de espera do banco deveria ser removido: %v", err)
+	}
+
+	// Na inicialização, os registros que faltam no banco são copiados do log
+	partial := store.NewMemoryAuditStore()
+	if err := partial.Append(context.Background(), entries[0]); err != nil {
+		t.Fatal(err)
+	}
+	l.store = partial
+	l.backfill = true
+	l.flushPending()
+	if got := copied(partial); len(got) != auditQueueSize+2 || l.backfill {
+		t.Errorf("Esperava o banco completado com %d registros, obteve %d", auditQueueSize+2, len(got))
+	}
+}
+
+// seedAudit grava registros de auditoria no auditStore
+func seedAudit(t *testing.T, entries ...AuditLog) {
+	t.Helper()
+	for i, entry := range entries {
+		entry.Sequence = uint64(i + 1)
+		entry.Hash = fmt.Sprintf("%064d", i+1)
+		if err := auditStore.Append(context.Background(), auditEntry(entry)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// auditRequest consulta GET /audit com o access token e o header Accept
+func auditRequest(accessToken, query, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/audit?"+query, nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	authMiddleware(adminMiddleware(auditHandler))(rec, req)
+	return rec
+}
+
+func TestAuditHandler(t *testing.T) {
+	setupStores(t)
+	member := login(t)
+	admin := loginAs(t, "admin@email.com", adminRole)
+	adminUser, _ := userStore.FindByEmail(context.Background(), "admin@email.com")
+
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	seedAudit(t,
+		AuditLog{UserID: "1", Action: "POST", Resource: "/auth/login", IP: "10.0.0.0", Timestamp: start},
+		AuditLog{UserID: "1", Action: "GET", Resource: "/auth/sessions", IP: "10.0.0.0", Timestamp: start.Add(time.Hour)},
+		AuditLog{UserID: "2", Action: "POST", Resource: "/auth/login", IP: "10.0.1.0", Timestamp: start.Add(2 * time.Hour)},
+		AuditLog{UserID: "1", Action: "DELETE", Resource: "/auth/sessions/s1", IP: "10.0.0.0", Timestamp: start.Add(3 * time.Hour), Details: "=HYPERLINK(\"x\")"},
+	)
+
+	if rec := auditRequest(member.AccessToken, "", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("Esperava status 403 para membro, recebeu %d", rec.Code)
+	}
+
+	// Paginação por cursor com filtros
+	var ids []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		rec := auditRequest(admin.AccessToken, "user_id=1&from=2024-03-01T12:00:00Z&limit=2&cursor="+cursor, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Esperava status 200, recebeu %d: %s", rec.Code, rec.Body.String())
+		}
+		var page AuditPage
+		json.NewDecoder(rec.Body).Decode(&page)
+		for _, entry := range page.Entries {
+			ids = append(ids, entry.ID)
+		}
+		if cursor = page.NextCursor; cursor == "" || pages > 3 {
+			break
+		}
+	}
+	if strings.Join(ids, ",") != "1,2,4" {
+		t.Errorf("Esperava os registros 1, 2 e 4 do usuário, obteve %v", ids)
+	}
+
+	// Exportação em CSV, com células que seriam fórmulas neutralizadas
+	rec := auditRequest(admin.AccessToken, "resource=/auth/sessions*&format=csv", "")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("Exportação CSV inesperada: %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil || len(rows) != 3 || rows[0][0] != "id" || rows[2][7] != "/auth/sessions/s1" {
+		t.Fatalf("CSV inesperado: %v (%v)", rows, err)
+	}
+	if details := rows[2][12]; !strings.HasPrefix(details, "'=") {
+		t.Errorf("Fórmula em details deveria ser neutralizada, obteve %q", details)
+	}
+
+	// Exportação em NDJSON pelo header Accept
+	rec = auditRequest(admin.AccessToken, "action=POST&to=2024-03-01T13:00:00Z", "application/x-ndjson")
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	var exported store.AuditEntry
+	json.Unmarshal([]byte(lines[0]), &exported)
+	if rec.Code != http.StatusOK || len(lines) != 1 || exported.IP != "10.0.0.0" {
+		t.Errorf("NDJSON inesperado: %d %q", rec.Code, rec.Body.String())
+	}
+
+	// O IP do filtro recebe a mesma máscara dos registros gravados
+	rec = auditRequest(admin.AccessToken, "ip=10.0.1.7", "")
+	var byIP AuditPage
+	json.NewDecoder(rec.Body).Decode(&byIP)
+	if rec.Code != http.StatusOK || len(byIP.Entries) != 1 || byIP.Entries[0].ID != "3" {
+		t.Errorf("Filtro por IP deveria encontrar o registro 3: %d %+v", rec.Code, byIP.Entries)
+	}
+
+	// As consultas também entram na trilha
+	queried := 0
+	for _, log := range drainAudit() {
+		if log.Action == auditevent.AuditQueried && log.UserID == adminUser.ID {
+			queried++
+		}
+	}
+	if queried == 0 {
+		t.Error("Consulta à auditoria deveria ser registrada")
+	}
+
+	for _, query := range []string{"format=xml", "from=ontem", "limit=0", "cursor=abc", "from=2024-03-02T00:00:00Z&to=2024-03-01T00:00:00Z"} {
+		if rec := auditRequest(admin.AccessToken, query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("Esperava status 400 para %q, recebeu %d", query, rec.Code)
+		}
+	}
+}
+
+// requestMagicLink solicita um link de acesso e retorna o token enviado por
+// email e o identificador do dispositivo
+func requestMagicLink(t *testing.T, email string) (string, string) {
+	t.Helper()
+
+	jsonBody, _ := json.Marshal(MagicLinkRequest{Email: email})
+	req := httptest.NewRequest(http.MethodPost, "/auth/magic-link", bytes.NewBuffer(jsonBody))
+	rec := httptest.NewRecorder()
+	magicLinkHandler(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Esperava status 202, recebeu %d", rec.Code)
+	}
+	var resp MagicLinkResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.DeviceToken == "" {
+		t.Fatal("Identificador do dispositivo não retornado")
+	}
+
+	sent := notifications.(*recordingNotifier).sent
+	if len(sent) == 0 {
+		return "", resp.DeviceToken
+	}
+	_, link, _ := strings.Cut(sent[len(sent)-1].Message, "token=")
+	token, _, _ := strings.Cut(link, ".")
+	return token, resp.DeviceToken
+}
+
+// magicLinkLogin troca o token do link de acesso por um par de tokens
+func magicLinkLogin(token, deviceToken string) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(MagicLinkLoginRequest{Token: token, DeviceToken: deviceToken})
+	req := httptest.NewRequest(http.MethodPost, "/auth/magic-link/login", bytes.NewBuffer(jsonBody))
+	rec := httptest.NewRecorder()
+	magicLinkLoginHandler(rec, req)
+	return rec
+}
+
+func TestMagicLinkLogin(t *testing.T) {
+	setupStores(t)
+
+	// Emails não cadastrados recebem a mesma resposta, sem envio
+	requestMagicLink(t, "ninguem@email.com")
+	if sent := notifications.(*recordingNotifier).sent; len(sent) != 0 {
+		t.Fatalf("Nenhum link deveria ser enviado: %+v", sent)
+	}
+
+	token, deviceToken := requestMagicLink(t, "Joao@Email.com")
+	if token == "" {
+		t.Fatal("Link de acesso não enviado")
+	}
+
+	// O link só funciona no dispositivo que o solicitou e é consumido na tentativa
+	if rec := magicLinkLogin(token, "outro-dispositivo"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Esperava status 401 em outro dispositivo, recebeu %d", rec.Code)
+	}
+	if rec := magicLinkLogin(token, deviceToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Link já tentado deveria ser recusado, recebeu %d", rec.Code)
+	}
+
+	token, deviceToken = requestMagicLink(t, "joao@email.com")
+	rec := magicLinkLogin(token, deviceToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Esperava status 200, recebeu %d: %s", rec.Code, rec.Body.String())
+	}
+	var pair TokenPair
+	json.NewDecoder(rec.Body).Decode(&pair)
+	if rec := validate(pair.AccessToken); rec.Code != http.StatusOK {
+		t.Errorf("Access token emitido deveria ser válido, recebeu %d", rec.Code)
+	}
+	if rec := refresh(pair.RefreshToken); rec.Code != http.StatusOK {
+		t.Errorf("Refresh token emitido deveria ser válido, recebeu %d", rec.Code)
+	}
+
+	// O link é de uso único
+	if rec := magicLinkLogin(token, deviceToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Esperava status 401 no reuso, recebeu %d", rec.Code)
+	}
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// serviceMetrics são as métricas próprias do serviço de autenticação; as
+// métricas HTTP e as comuns aos serviços vêm do módulo observability
+type serviceMetrics struct {
+	// Métricas de Rate Limiting
+	RateLimitHits *prometheus.CounterVec // status
+
+	// Métricas de Autenticação
+	LoginAttempts    *prometheus.CounterVec // status
+	RegisterAttempts *prometheus.CounterVec // status
+	ActiveUsers      prometheus.Gauge
+	TokenGenerations *prometheus.CounterVec // type
+	TokenValidations *prometheus.CounterVec // status
+	TokenRefreshes   *prometheus.CounterVec // status
+
+	// Métricas de Auditoria
+	AuditQueueDepth     prometheus.Gauge
+	AuditEntriesSpilled prometheus.Counter
+	AuditEntriesDropped prometheus.Counter
+	AuditStoreDeferred  prometheus.Counter
+}
+
+// newServiceMetrics cria uma nova instância de serviceMetrics e registra os
+// coletores em reg
+func newServiceMetrics(reg prometheus.Registerer) *serviceMetrics {
+	factory := promauto.With(reg)
+
+	return &serviceMetrics{
+		RateLimitHits: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "rate_limit_hits_total",
+			Help: "Total de hits no rate limiting",
+		}, []string{"status"}),
+
+		LoginAttempts: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "login_attempts_total",
+			Help: "Total de tentativas de login",
+		}, []string{"status"}),
+		RegisterAttempts: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "register_attempts_total",
+			Help: "Total de tentativas de registro",
+		}, []string{"status"}),
+		ActiveUsers: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "active_users",
+			Help: "Número de usuários ativos",
+		}),
+		TokenGenerations: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "token_generations_total",
+			Help: "Total de gerações de tokens",
+		}, []string{"type"}),
+		TokenValidations: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "token_validations_total",
+			Help: "Total de validações de tokens",
+		}, []string{"status"}),
+		TokenRefreshes: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "token_refreshes_total",
+			Help: "Total de refreshs de tokens",
+		}, []string{"status"}),
+
+		AuditQueueDepth: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "audit_queue_depth",
+			Help: "Registros de auditoria aguardando gravação na fila",
+		}),
+		AuditEntriesSpilled: factory.NewCounter(prometheus.CounterOpts{
+			Name: "audit_entries_spilled_total",
+			Help: "Total de registros de auditoria desviados para o arquivo de espera",
+		}),
+		AuditEntriesDropped: factory.NewCounter(prometheus.CounterOpts{
+			Name: "audit_entries_dropped_total",
+			Help: "Total de registros de auditoria perdidos por falha de gravação",
+		}),
+		AuditStoreDeferred: factory.NewCounter(prometheus.CounterOpts{
+			Name: "audit_store_entries_deferred_total",
+			Help: "Total de cópias de auditoria para o banco desviadas para o arquivo de espera",
+		}),
+	}
+}
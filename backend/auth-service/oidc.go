@@ -0,0 +1,607 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/auth-service/infrastructure/store"
+	"github.com/insidechurch/observability/logger"
+	"github.com/insidechurch/observability/tracing"
+)
+
+// Provedor OpenID Connect, inicializado em main
+var (
+	clientStore            store.ClientStore
+	authorizationCodeStore store.AuthorizationCodeStore
+
+	// oidcIssuer é a URL pública do serviço, usada como iss dos ID tokens e
+	// como base dos endpoints publicados na descoberta
+	oidcIssuer = getEnv("OIDC_ISSUER", "http://localhost:8081")
+
+	// oidcLoginURL é a página do frontend para onde o usuário sem sessão é
+	// enviado; ela recebe a requisição original em return_to
+	oidcLoginURL = getEnv("OIDC_LOGIN_URL", "http://localhost:3000/login")
+)
+
+// Validade dos códigos de autorização e dos ID tokens
+const (
+	authorizationCodeTTL = time.Minute
+	idTokenTTL           = time.Hour
+)
+
+// Escopos reconhecidos; openid é obrigatório
+var oidcScopes = []string{"openid", "profile", "email"}
+
+var errRedirectURINotAllowed = errors.New("redirect_uri não registrado para o cliente")
+
+// DiscoveryDocument representa o /.well-known/openid-configuration
+type DiscoveryDocument struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// IDTokenClaims são as claims do ID token. O Type "id" impede que ele seja
+// aceito como access token pelo authMiddleware.
+type IDTokenClaims struct {
+	Claims
+	AuthTime      *jwt.NumericDate `json:"auth_time,omitempty"`
+	Nonce         string           `json:"nonce,omitempty"`
+	Name          string           `json:"name,omitempty"`
+	Email         string           `json:"email,omitempty"`
+	EmailVerified *bool            `json:"email_verified,omitempty"`
+}
+
+// OAuthTokenResponse é a resposta do endpoint de token
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+	Scope        string `json:"scope"`
+}
+
+// UserInfoResponse é a resposta do endpoint userinfo
+type UserInfoResponse struct {
+	Subject       string `json:"sub"`
+	Name          string `json:"name,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+}
+
+// oauthError representa um erro do protocolo OAuth 2.0, devolvido ao cliente
+// no redirecionamento ou no corpo da resposta do endpoint de token
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *oauthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// authorizationRequest é uma requisição de autorização já validada
+type authorizationRequest struct {
+	client        *store.Client
+	redirectURI   string
+	state         string
+	scope         string
+	nonce         string
+	codeChallenge string
+}
+
+// openidConfigurationHandler publica os metadados de descoberta do provedor
+func openidConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(DiscoveryDocument{
+		Issuer:                            oidcIssuer,
+		AuthorizationEndpoint:             oidcIssuer + "/oauth/authorize",
+		TokenEndpoint:                     oidcIssuer + "/oauth/token",
+		UserinfoEndpoint:                  oidcIssuer + "/oauth/userinfo",
+		JWKSURI:                           oidcIssuer + "/.well-known/jwks.json",
+		ScopesSupported:                   oidcScopes,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  signingKeys.Methods(),
+		TokenEndpointAuthMethodsSupported: []string{"none", "client_secret_basic", "client_secret_post"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "name", "email", "email_verified"},
+	})
+}
+
+// parseAuthorizationRequest valida os parâmetros de /oauth/authorize. Erros de
+// cliente ou de redirect_uri não podem ser enviados ao redirect_uri e são
+// retornados sem authorizationRequest; os demais acompanham a requisição para
+// que o erro seja devolvido ao cliente.
+func parseAuthorizationRequest(ctx context.Context, params url.Values) (*authorizationRequest, error) {
+	client, err := clientStore.FindClient(ctx, params.Get("client_id"))
+	if err != nil {
+		return nil, err
+	}
+
+	redirectURI := params.Get("redirect_uri")
+	if !client.AllowsRedirect(redirectURI) {
+		return nil, errRedirectURINotAllowed
+	}
+
+	req := &authorizationRequest{
+		client:        client,
+		redirectURI:   redirectURI,
+		state:         params.Get("state"),
+		scope:         params.Get("scope"),
+		nonce:         params.Get("nonce"),
+		codeChallenge: params.Get("code_challenge"),
+	}
+
+	if params.Get("response_type") != "code" {
+		return req, &oauthError{Code: "unsupported_response_type", Description: "apenas response_type=code é suportado"}
+	}
+	if !hasScope(req.scope, "openid") {
+		return req, &oauthError{Code: "invalid_scope", Description: "o escopo openid é obrigatório"}
+	}
+	for _, scope := range strings.Fields(req.scope) {
+		if !supportedScope(scope) {
+			return req, &oauthError{Code: "invalid_scope", Description: "escopo não suportado: " + scope}
+		}
+	}
+	if req.codeChallenge == "" || params.Get("code_challenge_method") != "S256" {
+		return req, &oauthError{Code: "invalid_request", Description: "PKCE com code_challenge_method=S256 é obrigatório"}
+	}
+
+	return req, nil
+}
+
+// hasScope verifica se a lista de escopos separados por espaço contém o escopo
+func hasScope(scopes, scope string) bool {
+	for _, candidate := range strings.Fields(scopes) {
+		if candidate == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// supportedScope verifica se o escopo está entre os oidcScopes
+func supportedScope(scope string) bool {
+	for _, supported := range oidcScopes {
+		if supported == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizedClaims retorna as claims do access token enviado no header
+// Authorization, ou nil se não houver um token válido
+func authorizedClaims(r *http.Request) *Claims {
+	tokenString, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return nil
+	}
+	claims, err := parseToken(r.Context(), tokenString)
+	if err != nil || claims.Type != "access" || len(claims.Audience) > 0 {
+		return nil
+	}
+	return claims
+}
+
+// authorizationRedirect monta o redirect_uri com os parâmetros da resposta,
+// preservando a query já registrada na URI
+func authorizationRedirect(redirectURI string, params url.Values) string {
+	target, _ := url.Parse(redirectURI)
+	query := target.Query()
+	for key, values := range params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+// finishAuthorization envia o usuário de volta ao cliente. Navegações (GET)
+// são redirecionadas; o POST feito pelo frontend após o login recebe a URL em
+// JSON, já que o fetch não segue redirecionamentos para outra origem.
+func finishAuthorization(w http.ResponseWriter, r *http.Request, target string) {
+	if r.Method == http.MethodGet {
+		http.Redirect(w, r, target, http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"redirect_to": target})
+}
+
+// authorizeHandler atende GET e POST /oauth/authorize (fluxo authorization
+// code com PKCE). O usuário é identificado pelo access token; sem ele, a
+// navegação é enviada à página de login do frontend, que repete a requisição
+// via POST com o token após autenticar.
+func authorizeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "oauth_authorize", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			return nil
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Requisição inválida", http.StatusBadRequest)
+			return nil
+		}
+
+		req, err := parseAuthorizationRequest(ctx, r.Form)
+		if req == nil {
+			if !errors.Is(err, store.ErrClientNotFound) && !errors.Is(err, errRedirectURINotAllowed) {
+				http.Error(w, "Erro ao buscar cliente", http.StatusInternalServerError)
+				return err
+			}
+			log.Info("Requisição de autorização com cliente ou redirect_uri inválido",
+				logger.String("client_id", r.Form.Get("client_id")),
+				logger.String("ip", r.RemoteAddr),
+			)
+			http.Error(w, "Cliente ou redirect_uri inválido", http.StatusBadRequest)
+			return nil
+		}
+
+		var protocolErr *oauthError
+		if errors.As(err, &protocolErr) {
+			finishAuthorization(w, r, authorizationRedirect(req.redirectURI, url.Values{
+				"error":             {protocolErr.Code},
+				"error_description": {protocolErr.Description},
+				"state":             {req.state},
+			}))
+			return nil
+		}
+
+		claims := authorizedClaims(r)
+		if claims == nil {
+			switch {
+			case r.Form.Get("prompt") == "none":
+				finishAuthorization(w, r, authorizationRedirect(req.redirectURI, url.Values{
+					"error": {"login_required"},
+					"state": {req.state},
+				}))
+			case r.Method == http.MethodGet:
+				returnTo := oidcIssuer + "/oauth/authorize?" + r.URL.RawQuery
+				http.Redirect(w, r, oidcLoginURL+"?return_to="+url.QueryEscape(returnTo), http.StatusFound)
+			default:
+				http.Error(w, "Token inválido", http.StatusUnauthorized)
+			}
+			return nil
+		}
+
+		code, authorization, err := store.NewAuthorizationCode(authorizationCodeTTL)
+		if err != nil {
+			http.Error(w, "Erro ao gerar código de autorização", http.StatusInternalServerError)
+			return err
+		}
+		authorization.ClientID = req.client.ID
+		authorization.UserID = claims.UserID
+		authorization.RedirectURI = req.redirectURI
+		authorization.Scope = req.scope
+		authorization.Nonce = req.nonce
+		authorization.CodeChallenge = req.codeChallenge
+		authorization.AuthTime = claims.IssuedAt.Time
+		if err := authorizationCodeStore.Create(ctx, authorization); err != nil {
+			http.Error(w, "Erro ao gerar código de autorização", http.StatusInternalServerError)
+			return err
+		}
+
+		auditLogger.Record(ctx, auditevent.Event{
+			Action: auditevent.OAuthCodeIssued,
+			Target: auditevent.Target(auditevent.TargetClient, req.client.ID),
+		})
+		log.Info("Código de autorização emitido",
+			logger.String("user_id", claims.UserID),
+			logger.String("client_id", req.client.ID),
+		)
+
+		finishAuthorization(w, r, authorizationRedirect(req.redirectURI, url.Values{
+			"code":  {code},
+			"state": {req.state},
+		}))
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler de autorização", err)
+	}
+}
+
+// writeOAuthError responde com um erro do endpoint de token
+func writeOAuthError(w http.ResponseWriter, status int, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(oauthError{Code: code, Description: description})
+}
+
+// authenticateClient identifica o cliente pelo HTTP Basic ou pelos campos
+// client_id e client_secret do formulário. Clientes confidenciais precisam
+// apresentar o segredo.
+func authenticateClient(ctx context.Context, r *http.Request) (*store.Client, error) {
+	clientID, secret, basic := r.BasicAuth()
+	if !basic {
+		clientID = r.PostForm.Get("client_id")
+		secret = r.PostForm.Get("client_secret")
+	}
+
+	client, err := clientStore.FindClient(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if !client.Public() && !client.CheckSecret(secret) {
+		return nil, store.ErrClientNotFound
+	}
+	return client, nil
+}
+
+// verifyCodeChallenge confere o code_verifier com o code_challenge S256
+// registrado na autorização (RFC 7636)
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// generateIDToken assina o ID token do usuário para o cliente, incluindo as
+// claims liberadas pelos escopos autorizados
+func generateIDToken(user *store.User, authorization *store.AuthorizationCode, sessionID string) (string, error) {
+	now := time.Now()
+	claims := &IDTokenClaims{
+		Claims: Claims{
+			UserID:    user.ID,
+			Type:      "id",
+			SessionID: sessionID,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        uuid.NewString(),
+				Issuer:    oidcIssuer,
+				Subject:   user.ID,
+				Audience:  jwt.ClaimStrings{authorization.ClientID},
+				ExpiresAt: jwt.NewNumericDate(now.Add(idTokenTTL)),
+				IssuedAt:  jwt.NewNumericDate(now),
+			},
+		},
+		AuthTime: jwt.NewNumericDate(authorization.AuthTime),
+		Nonce:    authorization.Nonce,
+	}
+	if hasScope(authorization.Scope, "profile") {
+		claims.Name = user.Name
+	}
+	if hasScope(authorization.Scope, "email") {
+		verified := user.VerifiedAt != nil
+		claims.Email = user.Email
+		claims.EmailVerified = &verified
+	}
+
+	return signingKeys.Sign(claims)
+}
+
+// oauthTokenHandler atende POST /oauth/token, trocando o código de
+// autorização por access, refresh e ID tokens. Os tokens emitidos abrem uma
+// sessão com o nome do cliente, renovada por /auth/refresh, e trazem o
+// client_id em aud e os escopos autorizados em scope: o access token vale
+// apenas no userinfo, e não nas rotas do próprio usuário.
+func oauthTokenHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "oauth_token", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			return nil
+		}
+		if err := r.ParseForm(); err != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request", "formulário inválido")
+			return nil
+		}
+
+		if r.PostForm.Get("grant_type") != "authorization_code" {
+			writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "apenas authorization_code é suportado")
+			return nil
+		}
+
+		client, err := authenticateClient(ctx, r)
+		if err != nil {
+			if !errors.Is(err, store.ErrClientNotFound) {
+				writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+				return err
+			}
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "autenticação do cliente falhou")
+			return nil
+		}
+
+		// O código é consumido antes das demais verificações: uma troca
+		// inválida também o invalida
+		authorization, err := authorizationCodeStore.Consume(ctx, store.HashToken(r.PostForm.Get("code")))
+		if err != nil {
+			if !errors.Is(err, store.ErrAuthorizationCodeNotFound) {
+				writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+				return err
+			}
+			authMetrics.TokenValidations.WithLabelValues("invalid").Inc()
+			writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "código inválido ou expirado")
+			return nil
+		}
+
+		if authorization.ClientID != client.ID ||
+			authorization.RedirectURI != r.PostForm.Get("redirect_uri") ||
+			!verifyCodeChallenge(r.PostForm.Get("code_verifier"), authorization.CodeChallenge) {
+			auditLogger.Record(ctx, auditevent.Event{
+				Action:  auditevent.OAuthTokenIssued,
+				UserID:  authorization.UserID,
+				Target:  auditevent.Target(auditevent.TargetClient, client.ID),
+				Outcome: auditevent.OutcomeDenied,
+				Reason:  auditevent.ReasonInvalidCode,
+			})
+			log.Info("Troca de código de autorização recusada",
+				logger.String("client_id", client.ID),
+				logger.String("ip", r.RemoteAddr),
+			)
+			authMetrics.TokenValidations.WithLabelValues("invalid").Inc()
+			writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "código, redirect_uri ou code_verifier inválido")
+			return nil
+		}
+
+		user, err := userStore.FindByID(ctx, authorization.UserID)
+		if err != nil {
+			if !errors.Is(err, store.ErrUserNotFound) {
+				writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+				return err
+			}
+			writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "usuário não encontrado")
+			return nil
+		}
+
+		// Um access token emitido antes do cadastro do segundo fator ainda
+		// autoriza o código; a troca é recusada como no login
+		if user.RequiresMFA() {
+			auditLogger.Record(ctx, auditevent.Event{
+				Action:  auditevent.OAuthTokenIssued,
+				UserID:  user.ID,
+				Target:  auditevent.Target(auditevent.TargetClient, client.ID),
+				Outcome: auditevent.OutcomeDenied,
+				Reason:  auditevent.ReasonMFARequired,
+			})
+			writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "autenticação em dois fatores obrigatória")
+			return nil
+		}
+
+		session := &store.Session{Device: client.Name, UserAgent: r.UserAgent(), IP: clientIP(r)}
+		tokenPair, err := issueTokenPair(ctx, user.ID, session, clientGrant{ClientID: client.ID, Scope: authorization.Scope})
+		if err != nil {
+			writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+			return err
+		}
+		idToken, err := generateIDToken(user, authorization, session.ID)
+		if err != nil {
+			writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+			return err
+		}
+
+		authMetrics.TokenGenerations.WithLabelValues("access").Inc()
+		authMetrics.TokenGenerations.WithLabelValues("refresh").Inc()
+		authMetrics.TokenGenerations.WithLabelValues("id").Inc()
+
+		auditLogger.Record(ctx, auditevent.Event{
+			Action: auditevent.OAuthTokenIssued,
+			UserID: user.ID,
+			Target: auditevent.Target(auditevent.TargetClient, client.ID),
+		})
+		log.Info("Tokens emitidos para cliente OpenID Connect",
+			logger.String("user_id", user.ID),
+			logger.String("client_id", client.ID),
+		)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(OAuthTokenResponse{
+			AccessToken:  tokenPair.AccessToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int(accessTokenTTL.Seconds()),
+			RefreshToken: tokenPair.RefreshToken,
+			IDToken:      idToken,
+			Scope:        authorization.Scope,
+		})
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler de token OAuth", err)
+	}
+}
+
+// userinfoHandler atende GET e POST /oauth/userinfo para o usuário do access
+// token. Para tokens de clientes, as claims seguem os escopos autorizados,
+// como no ID token; os demais tokens representam o próprio usuário e recebem
+// todas as claims.
+func userinfoHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "oauth_userinfo", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			return nil
+		}
+
+		scope := strings.Join(oidcScopes, " ")
+		if claims, ok := ctx.Value(claimsKey).(*Claims); ok && len(claims.Audience) > 0 {
+			scope = claims.Scope
+		}
+		if !hasScope(scope, "openid") {
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+			http.Error(w, "Escopo openid não autorizado", http.StatusForbidden)
+			return nil
+		}
+
+		userID, _ := ctx.Value(userIDKey).(string)
+		user, err := userStore.FindByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, store.ErrUserNotFound) {
+				http.Error(w, "Token inválido", http.StatusUnauthorized)
+				return nil
+			}
+			http.Error(w, "Erro ao buscar usuário", http.StatusInternalServerError)
+			return err
+		}
+
+		info := UserInfoResponse{Subject: user.ID}
+		if hasScope(scope, "profile") {
+			info.Name = user.Name
+		}
+		if hasScope(scope, "email") {
+			verified := user.VerifiedAt != nil
+			info.Email = user.Email
+			info.EmailVerified = &verified
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(info)
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler de userinfo", err)
+	}
+}
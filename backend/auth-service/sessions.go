@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/auth-service/infrastructure/store"
+	"github.com/insidechurch/observability/logger"
+	"github.com/insidechurch/observability/tracing"
+)
+
+// sessionStore registra os dispositivos conectados, inicializado em main
+var sessionStore store.SessionStore
+
+// SessionResponse representa uma sessão listada, indicando a da requisição atual
+type SessionResponse struct {
+	*store.Session
+	Current bool `json:"current"`
+}
+
+// newSession descreve o dispositivo que iniciou a sessão. device é o nome
+// informado pelo cliente no login; sem ele, o nome é derivado do User-Agent.
+func newSession(r *http.Request, device string) *store.Session {
+	userAgent := r.UserAgent()
+	if device == "" {
+		device = describeDevice(userAgent)
+	}
+	return &store.Session{
+		Device:    device,
+		UserAgent: userAgent,
+		IP:        clientIP(r),
+	}
+}
+
+// describeDevice resume o User-Agent como "navegador no sistema"
+func describeDevice(userAgent string) string {
+	browser := ""
+	for _, candidate := range []struct{ token, name string }{
+		// A ordem importa: Edge e Opera também se identificam como Chrome,
+		// e o Chrome também se identifica como Safari
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+
+	system := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			system = candidate.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " no " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+	return "Dispositivo desconhecido"
+}
+
+// revokeSession encerra uma sessão: a família de refresh tokens é revogada e
+// os access tokens já emitidos para a sessão passam a ser recusados
+func revokeSession(ctx context.Context, sessionID string) error {
+	if err := refreshTokenStore.RevokeFamily(ctx, sessionID); err != nil {
+		return err
+	}
+	if err := sessionStore.Revoke(ctx, sessionID); err != nil {
+		return err
+	}
+	return tokenDenylist.RevokeSession(ctx, sessionID, accessTokenTTL)
+}
+
+// listSessions responde com as sessões ativas do usuário
+func listSessions(ctx context.Context, w http.ResponseWriter, userID, currentSessionID string) error {
+	sessions, err := sessionStore.ListActive(ctx, userID)
+	if errors.Is(err, store.ErrUserNotFound) {
+		http.Error(w, "Usuário não encontrado", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		http.Error(w, "Erro ao listar sessões", http.StatusInternalServerError)
+		return err
+	}
+
+	response := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		response[i] = SessionResponse{Session: session, Current: session.ID == currentSessionID}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+	return nil
+}
+
+// deleteSession revoga a sessão se ela pertencer ao usuário; sessões de
+// outros usuários são tratadas como inexistentes
+func deleteSession(ctx context.Context, w http.ResponseWriter, userID, sessionID, actorID string) error {
+	session, err := sessionStore.Find(ctx, sessionID)
+	if err != nil && !errors.Is(err, store.ErrSessionNotFound) {
+		http.Error(w, "Erro ao buscar sessão", http.StatusInternalServerError)
+		return err
+	}
+	if session == nil || session.UserID != userID {
+		http.Error(w, "Sessão não encontrada", http.StatusNotFound)
+		return nil
+	}
+
+	if err := revokeSession(ctx, sessionID); err != nil {
+		http.Error(w, "Erro ao encerrar sessão", http.StatusInternalServerError)
+		return err
+	}
+
+	auditLogger.Record(ctx, auditevent.Event{
+		Action: auditevent.SessionRevoked,
+		Target: auditevent.Target(auditevent.TargetSession, sessionID),
+	})
+	log.Info("Sessão revogada",
+		logger.String("user_id", userID),
+		logger.String("session_id", sessionID),
+		logger.String("actor_id", actorID),
+	)
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// sessionsHandler atende GET /auth/sessions e DELETE /auth/sessions/{id}
+// para o usuário autenticado
+func sessionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "sessions", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		claims, ok := ctx.Value(claimsKey).(*Claims)
+		if !ok {
+			http.Error(w, "Token inválido", http.StatusUnauthorized)
+			return nil
+		}
+
+		sessionID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/auth/sessions"), "/")
+		switch {
+		case r.Method == http.MethodGet && sessionID == "":
+			return listSessions(ctx, w, claims.UserID, claims.SessionID)
+		case r.Method == http.MethodDelete && sessionID != "" && !strings.Contains(sessionID, "/"):
+			if claims.ActorID != "" {
+				http.Error(w, "Ação não permitida durante a personificação", http.StatusForbidden)
+				return nil
+			}
+			return deleteSession(ctx, w, claims.UserID, sessionID, claims.UserID)
+		}
+
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler de sessões", err)
+	}
+}
+
+// adminSessionsHandler atende GET /auth/admin/users/{userID}/sessions e
+// DELETE /auth/admin/users/{userID}/sessions/{id} para qualquer usuário
+func adminSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := tracing.TraceSpanWithAttributes(ctx, "admin_sessions", map[string]string{
+		"method": r.Method,
+		"path":   r.URL.Path,
+		"ip":     r.RemoteAddr,
+	}, func(ctx context.Context) error {
+		adminID, _ := ctx.Value(userIDKey).(string)
+
+		// Segmentos esperados: {userID}/sessions[/{id}]
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/auth/admin/users/"), "/"), "/")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] != "sessions" {
+			http.NotFound(w, r)
+			return nil
+		}
+		userID := parts[0]
+
+		switch {
+		case r.Method == http.MethodGet && len(parts) == 2:
+			return listSessions(ctx, w, userID, "")
+		case r.Method == http.MethodDelete && len(parts) == 3 && parts[2] != "":
+			return deleteSession(ctx, w, userID, parts[2], adminID)
+		}
+
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return nil
+	})
+
+	if err != nil {
+		log.WithContext(ctx).Error("Erro no handler administrativo de sessões", err)
+	}
+}
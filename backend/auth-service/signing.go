@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/insidechurch/keys"
+	"github.com/insidechurch/observability/logger"
+)
+
+// signingKeys assina os tokens emitidos e verifica os recebidos, inicializado em main
+var signingKeys *keys.KeySet
+
+// loadSigningKeys carrega as chaves configuradas em JWT_PRIVATE_KEY_FILE. Sem
+// configuração é gerada uma chave efêmera, adequada apenas a desenvolvimento:
+// os tokens emitidos deixam de valer a cada reinício do serviço.
+func loadSigningKeys() (*keys.KeySet, error) {
+	ks, err := keys.Load(keys.NewConfig())
+	if errors.Is(err, keys.ErrNoSigningKey) {
+		ks, err = keys.Generate()
+		if err != nil {
+			return nil, err
+		}
+		log.Info("JWT_PRIVATE_KEY_FILE não definido, usando chave de assinatura efêmera",
+			logger.String("kid", ks.SigningKey().ID),
+		)
+		return ks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Chaves de assinatura carregadas",
+		logger.String("kid", ks.SigningKey().ID),
+		logger.String("alg", ks.SigningKey().Method.Alg()),
+	)
+	return ks, nil
+}
+
+// verifyToken valida a assinatura e a expiração do token usando o kid do cabeçalho
+func verifyToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, signingKeys.Keyfunc,
+		jwt.WithValidMethods(signingKeys.Methods()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("token inválido")
+	}
+	return claims, nil
+}
+
+// jwksHandler publica as chaves públicas usadas na verificação dos tokens
+func jwksHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(signingKeys.JWKS())
+}
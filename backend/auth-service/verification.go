@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/insidechurch/auth-service/infrastructure/notifier"
+	"github.com/insidechurch/auth-service/infrastructure/store"
+	"github.com/insidechurch/observability/logger"
+)
+
+// Validade do link de verificação e prazo para verificar o email na política
+// "limit"; acompanham os valores da API principal, que confirma o token
+const (
+	emailVerificationTTL  = 48 * time.Hour
+	unverifiedGracePeriod = 24 * time.Hour
+)
+
+// Verificação de email, inicializada em main
+var (
+	userTokenStore store.UserTokenStore
+	notifications  notifier.Notifier
+
+	// verifyEmailURL é a página do frontend que recebe o token de verificação
+	verifyEmailURL = getEnv("VERIFY_EMAIL_URL", "http://localhost:3000/verify-email")
+
+	// unverifiedLoginPolicy define o login de contas não verificadas: "allow",
+	// "limit" (apenas durante unverifiedGracePeriod) ou "deny"
+	unverifiedLoginPolicy = getEnv("UNVERIFIED_LOGIN_POLICY", "deny")
+)
+
+// getEnv retorna o valor da variável de ambiente ou um valor padrão
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// loginAllowed aplica a política de login para contas não verificadas. Valores
+// desconhecidos são tratados como "deny".
+func loginAllowed(user *store.User, now time.Time) bool {
+	if user.VerifiedAt != nil {
+		return true
+	}
+
+	switch unverifiedLoginPolicy {
+	case "allow":
+		return true
+	case "limit":
+		return now.Sub(user.CreatedAt) < unverifiedGracePeriod
+	default:
+		return false
+	}
+}
+
+// sendVerificationEmail registra um token de verificação e envia o link ao
+// usuário pelo notification-service
+func sendVerificationEmail(ctx context.Context, user *store.User) error {
+	token, userToken, err := store.NewUserToken(user.ID, store.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	if err := userTokenStore.Create(ctx, userToken); err != nil {
+		return err
+	}
+
+	err = notifications.Send(ctx, notifier.Notification{
+		UserID:  user.ID,
+		Channel: "email",
+		To:      user.Email,
+		Subject: "Confirme seu email",
+		Message: fmt.Sprintf(
+			"Confirme seu email acessando %s?token=%s. O link é válido por %d horas.",
+			verifyEmailURL, token, int(emailVerificationTTL.Hours()),
+		),
+		Sensitive: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("Link de verificação enviado",
+		logger.String("user_id", user.ID),
+	)
+	return nil
+}
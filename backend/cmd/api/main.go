@@ -1,18 +1,36 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	_ "insidechurch/backend/cmd/api/docs" // Importar a documentação do Swagger
+	"insidechurch/backend/internal/adapters/audit"
 	"insidechurch/backend/internal/adapters/handlers"
+	"insidechurch/backend/internal/adapters/notifier"
 	"insidechurch/backend/internal/adapters/repositories"
+	"insidechurch/backend/internal/adapters/sessions"
 	"insidechurch/backend/internal/core/usecases/auth"
 	"insidechurch/backend/internal/core/usecases/user"
+	"insidechurch/backend/internal/domain/services"
 	"insidechurch/backend/internal/middleware"
 	"insidechurch/backend/internal/routes"
 
 	"github.com/gin-gonic/gin"
+	"github.com/insidechurch/cache"
+	"github.com/insidechurch/keys"
+	"github.com/insidechurch/lockout"
+	"github.com/insidechurch/observability"
+	"github.com/insidechurch/passwordhash"
+	"github.com/insidechurch/passwordpolicy"
+	"github.com/insidechurch/ratelimit"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
@@ -60,27 +78,111 @@ func main() {
 		logrus.Fatalf("Erro ao conectar ao banco de dados: %v", err)
 	}
 
-	// Inicializa o router
-	router := gin.Default()
+	// Logs estruturados, métricas e tracing comuns aos serviços
+	obs, err := observability.Setup(observability.Config{ServiceName: "api"})
+	if err != nil {
+		logrus.Fatalf("Erro ao inicializar a observabilidade: %v", err)
+	}
+
+	// Inicializa o router; a cadeia de observabilidade registra cada
+	// requisição e recupera panics
+	router := gin.New()
+	router.Use(middleware.Observability(obs))
+
+	// O IP de origem informado pelos proxies só é aceito dos configurados em
+	// TRUSTED_PROXIES; sem eles, vale o endereço da conexão
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		logrus.Fatalf("Valor inválido para TRUSTED_PROXIES: %v", err)
+	}
+	router.RemoteIPHeaders = []string{getEnv("TRUSTED_PROXY_HEADER", "X-Forwarded-For")}
+	router.GET("/metrics", gin.WrapH(obs.Handler()))
 
 	// Inicializa os repositórios
 	userRepo := repositories.NewUserRepository(db)
+	userTokenRepo := repositories.NewUserTokenRepository(db)
+	recoveryCodeRepo := repositories.NewRecoveryCodeRepository(db)
+	roleRepo := repositories.NewRoleRepository(db)
+	apiKeyRepo := repositories.NewAPIKeyRepository(db)
+
+	// Inicializa os serviços externos; o Redis é compartilhado com o auth-service
+	// e guarda os limites de requisições e as falhas de login de todas as
+	// réplicas. Sem ele, o corte de tokens das sessões encerradas e o bloqueio
+	// de contas valem apenas para este processo.
+	var sharedCache cache.Cache = cache.NewMemoryCache()
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if os.Getenv("REDIS_HOST") != "" {
+		redisClient := cache.NewRedisClient(cache.NewRedisConfig())
+		sharedCache = cache.NewRedisCache(redisClient)
+		rateLimitStore = ratelimit.NewRedisStore(redisClient)
+	}
+	sessionRevoker := sessions.NewRevoker(db, sharedCache)
+	notificationService := notifier.NewHTTPNotifier(getEnv("NOTIFICATION_SERVICE_URL", "http://notification-service:8080"), os.Getenv("NOTIFICATION_SERVICE_TOKEN"))
+
+	// Eventos de auditoria gravados em audit_logs, junto com os do auth-service
+	auditRecorder := audit.NewRecorder(db, obs.Redactor)
+
+	// Inicializa os serviços de domínio; a política de papéis define quem é
+	// obrigado a usar autenticação em dois fatores
+	roleService := services.NewRoleService(roleRepo, auditRecorder)
+
+	// Política e hash de senhas compartilhados com o auth-service
+	passwordPolicy, err := passwordpolicy.FromEnv()
+	if err != nil {
+		logrus.Fatalf("Erro ao carregar a política de senhas: %v", err)
+	}
+	passwordHasher, err := passwordhash.FromEnv()
+	if err != nil {
+		logrus.Fatalf("Erro ao carregar a configuração de hash de senhas: %v", err)
+	}
 
 	// Inicializa os casos de uso
-	loginUseCase := auth.NewLoginUseCase(userRepo)
-	registerUseCase := auth.NewRegisterUseCase(userRepo)
+	emailVerificationUseCase := auth.NewEmailVerificationUseCase(
+		userRepo,
+		userTokenRepo,
+		notificationService,
+		getEnv("VERIFY_EMAIL_URL", "http://localhost:3000/verify-email"),
+		auditRecorder,
+	)
+	loginUseCase := auth.NewLoginUseCase(userRepo, auth.UnverifiedLoginPolicy(getEnv("UNVERIFIED_LOGIN_POLICY", "deny")), roleService, passwordPolicy, passwordHasher, auditRecorder, tokenKeys(), lockout.NewLogin(sharedCache))
+	mfaUseCase := auth.NewMFAUseCase(userRepo, recoveryCodeRepo, loginUseCase, getEnv("MFA_ISSUER", "InsideChurch"), auditRecorder)
+	registerUseCase := auth.NewRegisterUseCase(userRepo, emailVerificationUseCase, passwordPolicy, passwordHasher, auditRecorder)
+	apiKeyUseCase := auth.NewAPIKeyUseCase(apiKeyRepo)
 	getUserUseCase := user.NewGetUserUseCase(userRepo)
+	memberUseCase := user.NewMemberUseCase(userRepo, sessionRevoker, auditRecorder)
+	passwordResetUseCase := auth.NewPasswordResetUseCase(
+		userRepo,
+		userTokenRepo,
+		notificationService,
+		sessionRevoker,
+		passwordPolicy,
+		passwordHasher,
+		getEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		auditRecorder,
+	)
 
-	// Inicializa os middlewares
-	authMiddleware := middleware.NewAuthMiddleware(loginUseCase)
-	securityMiddleware := middleware.NewSecurityMiddleware()
+	// Inicializa os middlewares; tokens do auth-service são verificados pelo
+	// JWKS e as chaves de API criadas por ele, pela tabela api_keys
+	var jwksClient *middleware.JWKSClient
+	if jwksURL := os.Getenv("JWKS_URL"); jwksURL != "" {
+		jwksClient = middleware.NewJWKSClient(jwksURL)
+	}
+	authMiddleware := middleware.NewAuthMiddleware(loginUseCase, apiKeyUseCase, jwksClient, sessionRevoker)
+	permissionMiddleware := middleware.NewPermissionMiddleware(userRepo, roleService)
+	rateLimitPolicies, err := ratelimit.FromEnv(defaultRateLimitPolicies)
+	if err != nil {
+		logrus.Fatalf("Erro ao carregar as políticas de limite de requisições: %v", err)
+	}
+	securityMiddleware := middleware.NewSecurityMiddleware(ratelimit.New(rateLimitStore, rateLimitPolicies...))
 
 	// Inicializa os handlers
-	authHandler := handlers.NewAuthHandler(loginUseCase, registerUseCase)
+	authHandler := handlers.NewAuthHandler(loginUseCase, registerUseCase, passwordResetUseCase, emailVerificationUseCase)
 	userHandler := handlers.NewUserHandler(getUserUseCase)
+	mfaHandler := handlers.NewMFAHandler(mfaUseCase)
+	memberHandler := handlers.NewMemberHandler(memberUseCase)
+	roleHandler := handlers.NewRoleHandler(roleService)
 
 	// Configura as rotas
-	routes.SetupRoutes(router, authHandler, userHandler, authMiddleware, securityMiddleware)
+	routes.SetupRoutes(router, authHandler, userHandler, mfaHandler, memberHandler, roleHandler, authMiddleware, permissionMiddleware, securityMiddleware)
 
 	// Inicia o servidor
 	port := os.Getenv("PORT")
@@ -88,8 +190,87 @@ func main() {
 		port = "8080"
 	}
 
-	logrus.Infof("Servidor iniciado na porta %s", port)
-	if err := router.Run(":" + port); err != nil {
-		logrus.Fatalf("Erro ao iniciar o servidor: %v", err)
+	// Ao receber SIGINT ou SIGTERM, aguardar as requisições em andamento e
+	// exportar os spans pendentes
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		logrus.Infof("Servidor iniciado na porta %s", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Errorf("Erro ao iniciar o servidor: %v", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	logrus.Info("Encerrando o servidor")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logrus.Errorf("Erro ao encerrar o servidor: %v", err)
+	}
+	if err := obs.Shutdown(shutdownCtx); err != nil {
+		logrus.Errorf("Erro ao encerrar a observabilidade: %v", err)
+	}
+}
+
+// defaultRateLimitPolicies são as políticas usadas sem RATE_LIMIT_POLICIES:
+// 100 requisições por IP, repostas à taxa de uma por minuto, e limites menores
+// para o reenvio de emails e para os códigos de autenticação em dois fatores
+const defaultRateLimitPolicies = "*=ip:1/1m:token_bucket:100," +
+	"/api/auth/verify-email/resend=ip:1/10m:token_bucket:3," +
+	"/api/auth/mfa/verify|/api/auth/mfa/enroll/challenge/confirm=ip:1/10s:token_bucket:5"
+
+// shutdownTimeout limita a espera pelas requisições em andamento e pela
+// exportação dos spans no encerramento do servidor
+const shutdownTimeout = 15 * time.Second
+
+// trustedProxies retorna os IPs ou CIDRs de TRUSTED_PROXIES, separados por
+// vírgula, ou nil se a variável estiver vazia
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// tokenKeys carrega as chaves de JWT_PRIVATE_KEY_FILE que assinam os tokens
+// da API. Sem configuração é gerada uma chave efêmera, adequada apenas a
+// desenvolvimento. Com JWT_HMAC_FALLBACK=true, os tokens HS256 assinados com
+// JWT_SECRET antes da migração continuam válidos até expirarem.
+func tokenKeys() *auth.TokenKeys {
+	keySet, err := keys.Load(keys.NewConfig())
+	if errors.Is(err, keys.ErrNoSigningKey) {
+		keySet, err = keys.Generate()
+		if err == nil {
+			logrus.Warnf("JWT_PRIVATE_KEY_FILE não definido, usando chave de assinatura efêmera %s", keySet.SigningKey().ID)
+		}
+	}
+	if err != nil {
+		logrus.Fatalf("Erro ao carregar as chaves de assinatura: %v", err)
+	}
+
+	var legacySecret string
+	if os.Getenv("JWT_HMAC_FALLBACK") == "true" {
+		legacySecret = os.Getenv("JWT_SECRET")
+		if legacySecret == "" {
+			logrus.Fatal("JWT_HMAC_FALLBACK exige JWT_SECRET")
+		}
+		logrus.Warn("JWT_HMAC_FALLBACK ativo: tokens HS256 sem kid ainda são aceitos")
+	}
+	return auth.NewTokenKeys(keySet, legacySecret)
+}
+
+// getEnv retorna o valor da variável de ambiente ou um valor padrão
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
 	}
+	return defaultValue
 }
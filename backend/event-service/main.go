@@ -1,10 +1,18 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
+
+	"github.com/insidechurch/observability"
 )
 
 type Event struct {
@@ -68,12 +76,59 @@ func eventByIDHandler(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "Evento não encontrado", http.StatusNotFound)
 }
 
+// shutdownTimeout limita a espera pelas requisições em andamento e pela
+// exportação dos spans no encerramento do serviço
+const shutdownTimeout = 15 * time.Second
+
+// metricsRoutes são os templates das rotas registradas em main, usados no
+// rótulo path das métricas HTTP e no nome dos spans
+var metricsRoutes = []string{
+	"/events",
+	"/events/{id}",
+	"/health",
+	"/metrics",
+}
+
 func main() {
+	obs, err := observability.Setup(observability.Config{
+		ServiceName: "event-service",
+		Routes:      metricsRoutes,
+	})
+	if err != nil {
+		fmt.Printf("Erro ao inicializar a observabilidade: %v\n", err)
+		os.Exit(1)
+	}
+
 	http.HandleFunc("/events", eventsHandler)
 	http.HandleFunc("/events/", eventByIDHandler)
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "ok")
 	})
+	http.Handle("/metrics", obs.Handler())
+
 	fmt.Println("Event Service rodando na porta 8080")
-	http.ListenAndServe(":8080", nil)
+
+	// Ao receber SIGINT ou SIGTERM, aguardar as requisições em andamento e
+	// exportar os spans pendentes
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Métricas, tracing, log das requisições, recuperação de panics e ID da
+	// requisição valem para todas as rotas
+	server := &http.Server{Addr: ":8080", Handler: obs.Middleware(http.DefaultServeMux)}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			obs.Logger.Error("Erro no servidor HTTP", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		obs.Logger.Error("Erro ao encerrar o servidor HTTP", err)
+	}
+	obs.Shutdown(shutdownCtx)
 }
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/observability/redact"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Source identifica os registros gravados pela API na tabela audit_logs,
+// compartilhada com o auth-service
+const Source = "api"
+
+// recordTimeout limita a gravação de um evento, que não deve atrasar a
+// resposta além disso
+const recordTimeout = 2 * time.Second
+
+// Recorder implementa auditevent.Recorder gravando os eventos na tabela
+// audit_logs. Os registros da API não fazem parte do log encadeado do
+// auth-service: seq é 0, prev_hash é vazio e hash identifica o conteúdo.
+// Os dados pessoais recebem a mesma redação aplicada pelo auth-service.
+type Recorder struct {
+	db       *gorm.DB
+	redactor *redact.Redactor
+}
+
+// NewRecorder cria uma nova instância do Recorder. redactor nil usa
+// redact.Default.
+func NewRecorder(db *gorm.DB, redactor *redact.Redactor) *Recorder {
+	return &Recorder{db: db, redactor: redactor}
+}
+
+// Record grava o evento completado com a origem da requisição. Falhas são
+// registradas no log e não interrompem o caso de uso.
+func (r *Recorder) Record(ctx context.Context, event auditevent.Event) {
+	entry := r.redact(auditevent.NewEntry(ctx, event, time.Now()))
+	if err := r.insert(ctx, entry); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"action":         entry.Action,
+			"user_id":        entry.UserID,
+			"target":         entry.Target,
+			"correlation_id": entry.CorrelationID,
+		}).Error("Falha ao gravar evento de auditoria")
+	}
+}
+
+// redact aplica a política de dados pessoais ao registro: IP e user agent
+// pela regra de cada campo, textos livres por Scrub e os valores das
+// alterações pela regra do campo alterado
+func (r *Recorder) redact(entry auditevent.Entry) auditevent.Entry {
+	entry.IP, _ = r.redactor.String("ip", entry.IP)
+	entry.UserAgent, _ = r.redactor.String("user_agent", entry.UserAgent)
+	entry.Target = r.redactor.Scrub(entry.Target)
+	entry.Reason = r.redactor.Scrub(entry.Reason)
+
+	if len(entry.Changes) > 0 {
+		changes := make([]auditevent.Change, len(entry.Changes))
+		for i, change := range entry.Changes {
+			if !change.Redacted {
+				before, keepBefore := r.redactor.Value(change.Field, change.Before)
+				after, keepAfter := r.redactor.Value(change.Field, change.After)
+				if keepBefore && keepAfter {
+					change.Before, change.After = before, after
+				} else {
+					change = auditevent.Change{Field: change.Field, Redacted: true}
+				}
+			}
+			changes[i] = change
+		}
+		entry.Changes = changes
+	}
+	return entry
+}
+
+// insert grava o registro. A gravação não é cancelada com a requisição: o
+// evento já aconteceu mesmo que o cliente tenha desconectado.
+func (r *Recorder) insert(ctx context.Context, entry auditevent.Entry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar evento de auditoria: %w", err)
+	}
+	sum := sha256.Sum256(content)
+
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar alterações: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	return r.db.WithContext(ctx).Exec(
+		`INSERT INTO audit_logs (source, seq, prev_hash, hash, user_id, actor_id, action, resource, outcome,
+			changes, ip, user_agent, correlation_id, details, occurred_at)
+		VALUES (?, 0, '', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		Source, hex.EncodeToString(sum[:]), entry.UserID, entry.ActorID, entry.Action, entry.Target,
+		string(entry.Outcome), string(changes), entry.IP, entry.UserAgent, entry.CorrelationID, entry.Reason,
+		entry.Timestamp,
+	).Error
+}
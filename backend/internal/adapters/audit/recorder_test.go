@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/insidechurch/auditevent"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// captureInsert abre um gorm em DryRun, sem conexão com o banco, e retorna os
+// argumentos de cada INSERT montado pelo Recorder
+func captureInsert(t *testing.T) (*gorm.DB, *[][]interface{}) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var inserts [][]interface{}
+	err = db.Callback().Raw().After("gorm:raw").Register("test:capture", func(tx *gorm.DB) {
+		if strings.HasPrefix(strings.TrimSpace(tx.Statement.SQL.String()), "INSERT INTO audit_logs") {
+			inserts = append(inserts, tx.Statement.Vars)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, &inserts
+}
+
+func TestRecorderRedactsPersonalData(t *testing.T) {
+	db, inserts := captureInsert(t)
+	recorder := NewRecorder(db, nil)
+
+	ctx := auditevent.WithOrigin(context.Background(), auditevent.Origin{
+		IP:        "192.168.10.25",
+		UserAgent: "Mozilla/5.0",
+	})
+	recorder.Record(ctx, auditevent.Event{
+		Action: auditevent.MemberUpdated,
+		UserID: "1",
+		Target: "user:2",
+		Changes: []auditevent.Change{
+			{Field: "email", Before: "ana@email.com", After: "ana.souza@email.com"},
+			{Field: "name", Before: "Ana", After: "Ana Souza"},
+		},
+	})
+
+	if len(*inserts) != 1 {
+		t.Fatalf("Esperava 1 INSERT, obteve %d", len(*inserts))
+	}
+	// source, hash, user_id, actor_id, action, resource, outcome, changes, ip, ...
+	vars := (*inserts)[0]
+	if ip := vars[8]; ip != "192.168.10.0" {
+		t.Errorf("IP deveria ser gravado mascarado, obteve %v", ip)
+	}
+
+	changes := vars[7].(string)
+	if strings.Contains(changes, "ana@email.com") || strings.Contains(changes, "ana.souza@email.com") {
+		t.Errorf("Email não deveria ser gravado nas alterações: %s", changes)
+	}
+	var stored []auditevent.Change
+	if err := json.Unmarshal([]byte(changes), &stored); err != nil || len(stored) != 2 || stored[0].Field != "email" {
+		t.Fatalf("Alterações inesperadas: %s (%v)", changes, err)
+	}
+	if after, _ := stored[0].After.(string); !strings.HasSuffix(after, "@email.com") {
+		t.Errorf("Email deveria ser mascarado mantendo o domínio, obteve %q", after)
+	}
+}
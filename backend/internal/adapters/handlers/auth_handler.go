@@ -1,26 +1,36 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
+	domainerrors "insidechurch/backend/internal/core/errors"
 	"insidechurch/backend/internal/core/usecases/auth"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AuthHandler struct {
-	loginUseCase    *auth.LoginUseCase
-	registerUseCase *auth.RegisterUseCase
+	loginUseCase             *auth.LoginUseCase
+	registerUseCase          *auth.RegisterUseCase
+	passwordResetUseCase     *auth.PasswordResetUseCase
+	emailVerificationUseCase *auth.EmailVerificationUseCase
 }
 
 // NewAuthHandler cria uma nova instância do handler de autenticação
 func NewAuthHandler(
 	loginUseCase *auth.LoginUseCase,
 	registerUseCase *auth.RegisterUseCase,
+	passwordResetUseCase *auth.PasswordResetUseCase,
+	emailVerificationUseCase *auth.EmailVerificationUseCase,
 ) *AuthHandler {
 	return &AuthHandler{
-		loginUseCase:    loginUseCase,
-		registerUseCase: registerUseCase,
+		loginUseCase:             loginUseCase,
+		registerUseCase:          registerUseCase,
+		passwordResetUseCase:     passwordResetUseCase,
+		emailVerificationUseCase: emailVerificationUseCase,
 	}
 }
 
@@ -32,12 +42,37 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	output, err := h.loginUseCase.Login(input)
+	output, err := h.loginUseCase.Login(c.Request.Context(), input)
 	if err != nil {
+		var tooMany *auth.TooManyAttemptsError
+		if errors.As(err, &tooMany) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(tooMany.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, auth.ErrEmailNotVerified) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, auth.ErrPasswordExpired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "password_expired": true})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Login em duas etapas: o token de desafio é trocado pelo token de acesso
+	// em /auth/mfa/verify ou, no cadastro obrigatório, em /auth/mfa/enroll/challenge
+	if output.ChallengeToken != "" {
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required":            output.MFARequired,
+			"mfa_enrollment_required": output.MFAEnrollmentRequired,
+			"challenge_token":         output.ChallengeToken,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"user":  output.User,
 		"token": output.Token,
@@ -52,10 +87,86 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	if err := h.registerUseCase.Register(input); err != nil {
+	if err := h.registerUseCase.Register(c.Request.Context(), input); err != nil {
+		// Violações da política de senha seguem com os detalhes de cada regra
+		var domainErr *domainerrors.DomainError
+		if errors.As(err, &domainErr) {
+			c.Error(domainErr)
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "usuário criado com sucesso"})
+	c.JSON(http.StatusCreated, gin.H{"message": "usuário criado com sucesso, verifique seu email para ativar a conta"})
+}
+
+// RequestPasswordReset lida com a solicitação de redefinição de senha. A
+// resposta é sempre a mesma, esteja o email cadastrado ou não.
+func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
+	var input auth.RequestPasswordResetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	if err := h.passwordResetUseCase.RequestReset(c.Request.Context(), input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "se o email estiver cadastrado, você receberá as instruções para redefinir a senha",
+	})
+}
+
+// ConfirmPasswordReset lida com a definição da nova senha a partir do token
+func (h *AuthHandler) ConfirmPasswordReset(c *gin.Context) {
+	var input auth.ConfirmPasswordResetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	if err := h.passwordResetUseCase.ConfirmReset(c.Request.Context(), input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "senha redefinida com sucesso"})
+}
+
+// VerifyEmail lida com a confirmação do email a partir do token
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var input auth.VerifyEmailInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	if err := h.emailVerificationUseCase.Verify(c.Request.Context(), input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email verificado com sucesso"})
+}
+
+// ResendVerification lida com o reenvio do link de verificação. A resposta é
+// sempre a mesma, esteja o email cadastrado ou não.
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var input auth.ResendVerificationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	if err := h.emailVerificationUseCase.Resend(input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "se a conta existir e ainda não estiver verificada, um novo link será enviado",
+	})
 }
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	userusecase "insidechurch/backend/internal/core/usecases/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MemberHandler struct {
+	memberUseCase *userusecase.MemberUseCase
+}
+
+// NewMemberHandler cria uma nova instância do handler de membros
+func NewMemberHandler(memberUseCase *userusecase.MemberUseCase) *MemberHandler {
+	return &MemberHandler{memberUseCase: memberUseCase}
+}
+
+// Create lida com o cadastro de um membro pela administração
+func (h *MemberHandler) Create(c *gin.Context) {
+	var input userusecase.CreateMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	member, err := h.memberUseCase.Create(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// Update lida com a alteração de um membro
+func (h *MemberHandler) Update(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var input userusecase.UpdateMemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	member, err := h.memberUseCase.Update(c.Request.Context(), id, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// Delete lida com a remoção de um membro
+func (h *MemberHandler) Delete(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.memberUseCase.Delete(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// pathID lê o parâmetro :id da rota, respondendo 400 quando inválido
+func pathID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id inválido"})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+
+	"insidechurch/backend/internal/core/usecases/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAHandler struct {
+	mfaUseCase *auth.MFAUseCase
+}
+
+// NewMFAHandler cria uma nova instância do handler de autenticação em dois fatores
+func NewMFAHandler(mfaUseCase *auth.MFAUseCase) *MFAHandler {
+	return &MFAHandler{mfaUseCase: mfaUseCase}
+}
+
+// Verify lida com a conclusão do login com o código do segundo fator
+func (h *MFAHandler) Verify(c *gin.Context) {
+	var input auth.VerifyMFAInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	output, err := h.mfaUseCase.VerifyChallenge(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":  output.User,
+		"token": output.Token,
+	})
+}
+
+// BeginChallengeEnrollment lida com o início do cadastro obrigatório do
+// segundo fator durante o login
+func (h *MFAHandler) BeginChallengeEnrollment(c *gin.Context) {
+	var input auth.MFAChallengeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	output, err := h.mfaUseCase.BeginChallengeEnrollment(input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// ConfirmChallengeEnrollment lida com a conclusão do cadastro obrigatório e do login
+func (h *MFAHandler) ConfirmChallengeEnrollment(c *gin.Context) {
+	var input auth.VerifyMFAInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	output, err := h.mfaUseCase.ConfirmChallengeEnrollment(c.Request.Context(), input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":           output.User,
+		"token":          output.Token,
+		"recovery_codes": output.RecoveryCodes,
+	})
+}
+
+// BeginEnrollment lida com o início do cadastro do segundo fator pelo usuário autenticado
+func (h *MFAHandler) BeginEnrollment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	output, err := h.mfaUseCase.BeginEnrollment(userID.(uint))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// ConfirmEnrollment lida com a ativação do segundo fator pelo usuário autenticado
+func (h *MFAHandler) ConfirmEnrollment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var input auth.MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	codes, err := h.mfaUseCase.ConfirmEnrollment(c.Request.Context(), userID.(uint), input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Disable lida com a desativação do segundo fator pelo usuário autenticado
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não autenticado"})
+		return
+	}
+
+	var input auth.MFACodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	if err := h.mfaUseCase.Disable(c.Request.Context(), userID.(uint), input); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "autenticação em dois fatores desativada"})
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"insidechurch/backend/internal/domain/repositories"
+	"insidechurch/backend/internal/domain/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	roleService *services.RoleService
+}
+
+// NewRoleHandler cria uma nova instância do handler de papéis
+func NewRoleHandler(roleService *services.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+// CreateRoleRequest representa os dados para criar um papel
+type CreateRoleRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// UpdateRoleRequest representa a alteração da política de um papel
+type UpdateRoleRequest struct {
+	MFARequired *bool `json:"mfa_required" binding:"required"`
+}
+
+// PermissionRequest representa uma permissão concedida a um papel
+type PermissionRequest struct {
+	Resource string `json:"resource" binding:"required"`
+	Action   string `json:"action" binding:"required"`
+}
+
+// List lida com a listagem dos papéis e das suas permissões
+func (h *RoleHandler) List(c *gin.Context) {
+	roles, err := h.roleService.ListRoles()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// Create lida com a criação de um papel
+func (h *RoleHandler) Create(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	role, err := h.roleService.CreateRole(c.Request.Context(), req.Name)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+// Update lida com a alteração da exigência de MFA do papel
+func (h *RoleHandler) Update(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	if err := h.roleService.SetMFARequired(c.Request.Context(), id, *req.MFARequired); err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Delete lida com a remoção de um papel
+func (h *RoleHandler) Delete(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.DeleteRole(c.Request.Context(), id); err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GrantPermission lida com a concessão de uma permissão ao papel
+func (h *RoleHandler) GrantPermission(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	var req PermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dados inválidos"})
+		return
+	}
+
+	if err := h.roleService.AssignPermission(c.Request.Context(), id, req.Resource, req.Action); err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RevokePermission lida com a remoção de uma permissão do papel, informada
+// na rota no formato recurso:ação
+func (h *RoleHandler) RevokePermission(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	resource, action, found := strings.Cut(c.Param("permission"), ":")
+	if !found || resource == "" || action == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "permissão inválida"})
+		return
+	}
+
+	if err := h.roleService.RemovePermission(c.Request.Context(), id, resource, action); err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// fail responde aos erros do RoleService; os erros de domínio seguem para o
+// ErrorHandler
+func (h *RoleHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.Error(err)
+	}
+}
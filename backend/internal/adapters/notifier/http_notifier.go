@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"insidechurch/backend/internal/core/ports"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPNotifier implementa a interface Notifier enviando as notificações ao
+// endpoint POST /notifications do notification-service, autenticado pelo
+// token do serviço
+type HTTPNotifier struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewHTTPNotifier cria uma nova instância do HTTPNotifier
+func NewHTTPNotifier(baseURL, token string) *HTTPNotifier {
+	return &HTTPNotifier{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Send envia a notificação ao notification-service. Falhas são registradas no
+// log, já que alguns casos de uso não as repassam ao cliente.
+func (n *HTTPNotifier) Send(notification ports.Notification) error {
+	if err := n.send(notification); err != nil {
+		logrus.WithError(err).WithField("user_id", notification.UserID).Warn("Falha ao entregar notificação")
+		return err
+	}
+	return nil
+}
+
+// send executa a requisição ao notification-service
+func (n *HTTPNotifier) send(notification ports.Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar notificação: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.baseURL+"/notifications", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("erro ao criar requisição de notificação: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+n.token)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("erro ao enviar notificação: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification-service respondeu com status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository implementa a interface APIKeyRepository usando GORM
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository cria uma nova instância do APIKeyRepository
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// FindActive implementa a busca de uma chave ativa pelo hash
+func (r *APIKeyRepository) FindActive(keyHash string) (*entities.APIKey, error) {
+	var key entities.APIKey
+	err := r.db.Where("key_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", keyHash, time.Now()).
+		First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domainerrors.NewInvalidToken()
+		}
+		return nil, domainerrors.NewInternalError(err)
+	}
+	return &key, nil
+}
+
+// Touch implementa o registro do último uso. A gravação é limitada a uma por
+// minuto, evitando um UPDATE a cada requisição das integrações.
+func (r *APIKeyRepository) Touch(id uint) error {
+	now := time.Now()
+	err := r.db.Model(&entities.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-time.Minute)).
+		Update("last_used_at", now).Error
+	if err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeRepository implementa a interface RecoveryCodeRepository usando GORM
+type RecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewRecoveryCodeRepository cria uma nova instância do RecoveryCodeRepository
+func NewRecoveryCodeRepository(db *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: db}
+}
+
+// Replace implementa a troca dos códigos do usuário em uma única transação
+func (r *RecoveryCodeRepository) Replace(userID uint, codeHashes []string) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]entities.RecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = entities.RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+	return nil
+}
+
+// Consume marca o código como usado com um único UPDATE condicional, de modo
+// que requisições concorrentes com o mesmo código não são aceitas duas vezes
+func (r *RecoveryCodeRepository) Consume(userID uint, codeHash string) (bool, error) {
+	result := r.db.Model(&entities.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, domainerrors.NewInternalError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteUser implementa a remoção dos códigos do usuário
+func (r *RecoveryCodeRepository) DeleteUser(userID uint) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+	return nil
+}
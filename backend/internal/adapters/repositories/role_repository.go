@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"errors"
+
+	domainerrors "insidechurch/backend/internal/core/errors"
+	"insidechurch/backend/internal/domain/entities"
+	"insidechurch/backend/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// RoleRepository implementa a interface RoleRepository usando GORM
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository cria uma nova instância do RoleRepository
+func NewRoleRepository(db *gorm.DB) repositories.RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// Create implementa a criação de um novo papel
+func (r *RoleRepository) Create(role *entities.Role) error {
+	if err := r.db.Create(role).Error; err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+	return nil
+}
+
+// FindByID implementa a busca de papel por ID, com as permissões
+func (r *RoleRepository) FindByID(id uint) (*entities.Role, error) {
+	var role entities.Role
+	err := r.db.Preload("Permissions").First(&role, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repositories.ErrRoleNotFound
+		}
+		return nil, domainerrors.NewInternalError(err)
+	}
+	return &role, nil
+}
+
+// FindByName implementa a busca de papel por nome
+func (r *RoleRepository) FindByName(name string) (*entities.Role, error) {
+	var role entities.Role
+	err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, domainerrors.NewInternalError(err)
+	}
+	return &role, nil
+}
+
+// Update implementa a atualização de um papel
+func (r *RoleRepository) Update(role *entities.Role) error {
+	if err := r.db.Omit("Permissions").Save(role).Error; err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+	return nil
+}
+
+// Delete implementa a remoção de um papel
+func (r *RoleRepository) Delete(id uint) error {
+	result := r.db.Select("Permissions").Delete(&entities.Role{ID: id})
+	if result.Error != nil {
+		return domainerrors.NewInternalError(result.Error)
+	}
+	return nil
+}
+
+// List implementa a listagem de papéis
+func (r *RoleRepository) List() ([]entities.Role, error) {
+	var roles []entities.Role
+	if err := r.db.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, domainerrors.NewInternalError(err)
+	}
+	return roles, nil
+}
+
+// AddPermission implementa a associação de uma permissão ao papel, criando a
+// permissão quando ainda não existir
+func (r *RoleRepository) AddPermission(roleID uint, permission *entities.Permission) error {
+	err := r.db.Where(entities.Permission{Resource: permission.Resource, Action: permission.Action}).
+		FirstOrCreate(permission).Error
+	if err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+
+	if err := r.db.Model(&entities.Role{ID: roleID}).Association("Permissions").Append(permission); err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+	return nil
+}
+
+// RemovePermission implementa a remoção de uma permissão do papel
+func (r *RoleRepository) RemovePermission(roleID uint, permission *entities.Permission) error {
+	var existing entities.Permission
+	err := r.db.Where("resource = ? AND action = ?", permission.Resource, permission.Action).First(&existing).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return domainerrors.NewInternalError(err)
+	}
+
+	if err := r.db.Model(&entities.Role{ID: roleID}).Association("Permissions").Delete(&existing); err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+	return nil
+}
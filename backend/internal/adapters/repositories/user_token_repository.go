@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserTokenRepository implementa a interface UserTokenRepository usando GORM
+type UserTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewUserTokenRepository cria uma nova instância do UserTokenRepository
+func NewUserTokenRepository(db *gorm.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+// Create implementa o registro de um novo token
+func (r *UserTokenRepository) Create(token *entities.UserToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+	return nil
+}
+
+// Consume marca o token como usado com um único UPDATE condicional, de modo
+// que requisições concorrentes com o mesmo token não são aceitas duas vezes
+func (r *UserTokenRepository) Consume(tokenHash, purpose string) (*entities.UserToken, error) {
+	var token entities.UserToken
+	now := time.Now()
+
+	result := r.db.Model(&token).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, domainerrors.NewInternalError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, domainerrors.NewInvalidToken()
+	}
+
+	return &token, nil
+}
+
+// FindLatest implementa a busca do token mais recente do usuário
+func (r *UserTokenRepository) FindLatest(userID uint, purpose string) (*entities.UserToken, error) {
+	var token entities.UserToken
+	err := r.db.Where("user_id = ? AND purpose = ?", userID, purpose).
+		Order("created_at DESC").
+		First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, domainerrors.NewInternalError(err)
+	}
+	return &token, nil
+}
+
+// InvalidateUser implementa o descarte dos tokens pendentes do usuário
+func (r *UserTokenRepository) InvalidateUser(userID uint, purpose string) error {
+	err := r.db.Model(&entities.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+	return nil
+}
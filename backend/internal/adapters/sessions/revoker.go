@@ -0,0 +1,86 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	domainerrors "insidechurch/backend/internal/core/errors"
+
+	"github.com/insidechurch/cache"
+	"github.com/insidechurch/denylist"
+	"gorm.io/gorm"
+)
+
+// accessTokenTTL é a validade do corte de access tokens, a do access token
+// mais longo, o da API (24h), para que o corte valha até a expiração de todos
+// os tokens emitidos antes dele
+const accessTokenTTL = 24 * time.Hour
+
+// Revoker encerra as sessões emitidas pelo auth-service e pela API: revoga os
+// refresh tokens e as sessões do usuário e recusa os access tokens emitidos
+// até o momento na denylist compartilhada com o auth-service
+type Revoker struct {
+	db       *gorm.DB
+	denylist *denylist.Denylist
+}
+
+// NewRevoker cria uma nova instância do Revoker. O cache deve ser o mesmo
+// Redis usado pelo auth-service; sem ele apenas os refresh tokens e as
+// sessões são revogados e os access tokens expiram naturalmente.
+func NewRevoker(db *gorm.DB, c cache.Cache) *Revoker {
+	r := &Revoker{db: db}
+	if c != nil {
+		r.denylist = denylist.New(c)
+	}
+	return r
+}
+
+// RevokeUserSessions implementa o encerramento de todas as sessões do usuário
+func (r *Revoker) RevokeUserSessions(userID uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(
+			"UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL",
+			userID,
+		).Error
+		if err != nil {
+			return fmt.Errorf("erro ao revogar refresh tokens: %w", err)
+		}
+
+		err = tx.Exec(
+			"UPDATE sessions SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL",
+			userID,
+		).Error
+		if err != nil {
+			return fmt.Errorf("erro ao revogar sessões: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+
+	if r.denylist == nil {
+		return nil
+	}
+
+	if err := r.denylist.RevokeUser(context.Background(), userKey(userID), accessTokenTTL); err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+	return nil
+}
+
+// IsRevoked implementa a consulta aos tokens revogados, incluindo os
+// revogados pelo auth-service no logout e no encerramento de uma sessão
+func (r *Revoker) IsRevoked(tokenID, sessionID string, userID uint, issuedAt time.Time) (bool, error) {
+	if r.denylist == nil {
+		return false, nil
+	}
+	return r.denylist.IsRevoked(context.Background(), tokenID, sessionID, userKey(userID), issuedAt)
+}
+
+// userKey é o ID do usuário no formato usado pelo auth-service
+func userKey(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 10)
+}
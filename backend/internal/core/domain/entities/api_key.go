@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+)
+
+// APIKey representa uma chave de API de longa duração usada por integrações.
+// As chaves são criadas pelo auth-service; apenas o hash SHA-256 é persistido.
+// Os escopos são permissões no formato de Permission.String() ("recurso:ação").
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primarykey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"not null"`
+	KeyHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json;type:jsonb;not null"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedBy  uint       `json:"created_by" gorm:"not null"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// TableName especifica o nome da tabela no banco de dados
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// HasScope verifica se a chave concede a permissão informada
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
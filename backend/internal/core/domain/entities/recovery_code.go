@@ -0,0 +1,20 @@
+package entities
+
+import (
+	"time"
+)
+
+// RecoveryCode representa um código de recuperação de uso único do segundo
+// fator. Apenas o hash SHA-256 do código é persistido.
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primarykey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName especifica o nome da tabela no banco de dados
+func (RecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
@@ -12,6 +12,20 @@ type User struct {
 	Name     string `json:"name" gorm:"not null"`
 	Email    string `json:"email" gorm:"unique;not null"`
 	Password string `json:"-" gorm:"not null"` // "-" para não serializar a senha
+	// PasswordChangedAt é a data da última troca de senha, usada na validade
+	// máxima da política de senhas
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+	// VerifiedAt é preenchido quando o usuário confirma o email; nulo indica conta não verificada
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	// RoleID referencia o papel do usuário; a política do papel pode exigir MFA
+	RoleID *uint `json:"role_id,omitempty"`
+	// MFASecret é o segredo TOTP em base32; preenchido já no início do cadastro
+	MFASecret string `json:"-"`
+	// MFAEnabledAt é preenchido quando o cadastro do segundo fator é confirmado
+	MFAEnabledAt *time.Time `json:"mfa_enabled_at,omitempty"`
+	// MFALastStep é o último intervalo TOTP aceito, usado para impedir a
+	// reutilização de um código
+	MFALastStep int64 `json:"-"`
 }
 
 // TableName especifica o nome da tabela no banco de dados
@@ -19,6 +33,16 @@ func (User) TableName() string {
 	return "users"
 }
 
+// IsVerified indica se o usuário já confirmou o email
+func (u *User) IsVerified() bool {
+	return u.VerifiedAt != nil
+}
+
+// MFAEnabled indica se o usuário concluiu o cadastro do segundo fator
+func (u *User) MFAEnabled() bool {
+	return u.MFAEnabledAt != nil
+}
+
 // BeforeCreate é um hook do GORM que é executado antes de criar um usuário
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	u.CreatedAt = time.Now()
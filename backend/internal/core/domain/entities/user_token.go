@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+)
+
+// Finalidades dos tokens de uso único enviados ao usuário
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// UserToken representa um token de uso único enviado ao usuário por email.
+// Apenas o hash SHA-256 do token é persistido.
+type UserToken struct {
+	ID        uint       `json:"id" gorm:"primarykey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Purpose   string     `json:"purpose" gorm:"not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName especifica o nome da tabela no banco de dados
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
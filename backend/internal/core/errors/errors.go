@@ -35,7 +35,7 @@ func (e *DomainError) HTTPStatus() int {
 		return http.StatusUnauthorized
 	case ErrEmailAlreadyExists:
 		return http.StatusConflict
-	case ErrInvalidInput:
+	case ErrInvalidInput, ErrInvalidToken:
 		return http.StatusBadRequest
 	case ErrUnauthorized:
 		return http.StatusUnauthorized
@@ -52,6 +52,7 @@ const (
 	ErrInvalidCredentials = "INVALID_CREDENTIALS"
 	ErrEmailAlreadyExists = "EMAIL_EXISTS"
 	ErrInvalidInput       = "INVALID_INPUT"
+	ErrInvalidToken       = "INVALID_TOKEN"
 	ErrUnauthorized       = "UNAUTHORIZED"
 	ErrForbidden          = "FORBIDDEN"
 	ErrInternal           = "INTERNAL_ERROR"
@@ -93,6 +94,13 @@ func NewInvalidInput(message string, details map[string]interface{}) *DomainErro
 	}
 }
 
+func NewInvalidToken() *DomainError {
+	return &DomainError{
+		Code:    ErrInvalidToken,
+		Message: "Token inválido ou expirado",
+	}
+}
+
 func NewUnauthorized(message string) *DomainError {
 	return &DomainError{
 		Code:    ErrUnauthorized,
@@ -12,3 +12,36 @@ type UserRepository interface {
 	Update(user *entities.User) error
 	Delete(id uint) error
 }
+
+// UserTokenRepository define a interface para persistência de tokens de uso único
+type UserTokenRepository interface {
+	Create(token *entities.UserToken) error
+	// Consume marca como usado o token ainda válido com o hash e a finalidade
+	// informados; a operação é atômica, de modo que o token é aceito uma única vez
+	Consume(tokenHash, purpose string) (*entities.UserToken, error)
+	// FindLatest retorna o token mais recente do usuário para a finalidade, ou nil
+	FindLatest(userID uint, purpose string) (*entities.UserToken, error)
+	// InvalidateUser descarta os tokens pendentes do usuário para a finalidade
+	InvalidateUser(userID uint, purpose string) error
+}
+
+// RecoveryCodeRepository define a interface para persistência dos códigos de
+// recuperação do segundo fator
+type RecoveryCodeRepository interface {
+	// Replace descarta os códigos do usuário e registra os novos hashes
+	Replace(userID uint, codeHashes []string) error
+	// Consume marca como usado o código ainda não utilizado; retorna false
+	// quando o código não existe ou já foi usado
+	Consume(userID uint, codeHash string) (bool, error)
+	// DeleteUser remove todos os códigos do usuário
+	DeleteUser(userID uint) error
+}
+
+// APIKeyRepository define a interface para consulta das chaves de API criadas
+// pelo auth-service
+type APIKeyRepository interface {
+	// FindActive busca pelo hash uma chave não revogada e não expirada
+	FindActive(keyHash string) (*entities.APIKey, error)
+	// Touch registra o último uso da chave
+	Touch(id uint) error
+}
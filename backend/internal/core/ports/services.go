@@ -0,0 +1,44 @@
+package ports
+
+import "time"
+
+// Notification representa uma mensagem entregue pelo notification-service
+type Notification struct {
+	UserID  string `json:"user_id"`
+	Channel string `json:"channel,omitempty"`
+	To      string `json:"to,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	Message string `json:"message"`
+	// Sensitive indica uma mensagem com link de uso único, que o
+	// notification-service entrega sem guardar o conteúdo
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+// Notifier define a interface para envio de notificações ao usuário
+type Notifier interface {
+	Send(notification Notification) error
+}
+
+// SessionRevoker define a interface para encerrar as sessões ativas de um usuário
+type SessionRevoker interface {
+	RevokeUserSessions(userID uint) error
+}
+
+// TokenRevocations define a interface para consultar se um access token foi
+// revogado: individualmente pelo tokenID (jti), com a sua sessão ou pelo
+// encerramento das sessões do usuário até issuedAt
+type TokenRevocations interface {
+	IsRevoked(tokenID, sessionID string, userID uint, issuedAt time.Time) (bool, error)
+}
+
+// MFAPolicy define a interface para consultar se um papel exige autenticação
+// em dois fatores
+type MFAPolicy interface {
+	RequiresMFA(roleID uint) (bool, error)
+}
+
+// PermissionPolicy define a interface para consultar se um papel tem a
+// permissão, no formato recurso e ação de entities.Permission
+type PermissionPolicy interface {
+	CheckPermission(roleID uint, resource, action string) (bool, error)
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"strings"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	"insidechurch/backend/internal/core/ports"
+)
+
+// APIKeyPrefix identifica as chaves de API no header Authorization, separando-as
+// dos JWTs; deve coincidir com o do auth-service, que cria as chaves
+const APIKeyPrefix = "ick_"
+
+// APIKeyUseCase autentica as requisições feitas com chaves de API
+type APIKeyUseCase struct {
+	apiKeyRepo ports.APIKeyRepository
+}
+
+// NewAPIKeyUseCase cria uma nova instância do caso de uso de chaves de API
+func NewAPIKeyUseCase(apiKeyRepo ports.APIKeyRepository) *APIKeyUseCase {
+	return &APIKeyUseCase{apiKeyRepo: apiKeyRepo}
+}
+
+// IsAPIKey verifica se a credencial do header Authorization é uma chave de API
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// Authenticate busca a chave ativa e registra o seu uso. Chaves inexistentes,
+// revogadas ou expiradas retornam token inválido.
+func (uc *APIKeyUseCase) Authenticate(key string) (*entities.APIKey, error) {
+	apiKey, err := uc.apiKeyRepo.FindActive(hashUserToken(key))
+	if err != nil {
+		return nil, err
+	}
+
+	// O último uso é informativo: uma falha ao gravá-lo não recusa a requisição
+	_ = uc.apiKeyRepo.Touch(apiKey.ID)
+	return apiKey, nil
+}
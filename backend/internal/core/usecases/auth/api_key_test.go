@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+)
+
+type fakeAPIKeyRepo struct {
+	keys []*entities.APIKey
+}
+
+func (r *fakeAPIKeyRepo) FindActive(keyHash string) (*entities.APIKey, error) {
+	for _, key := range r.keys {
+		if key.KeyHash == keyHash && key.RevokedAt == nil && (key.ExpiresAt == nil || key.ExpiresAt.After(time.Now())) {
+			return key, nil
+		}
+	}
+	return nil, domainerrors.NewInvalidToken()
+}
+
+func (r *fakeAPIKeyRepo) Touch(id uint) error {
+	for _, key := range r.keys {
+		if key.ID == id {
+			now := time.Now()
+			key.LastUsedAt = &now
+		}
+	}
+	return nil
+}
+
+func TestAPIKeyUseCaseAuthenticate(t *testing.T) {
+	expired := time.Now().Add(-time.Hour)
+	repo := &fakeAPIKeyRepo{keys: []*entities.APIKey{
+		{ID: 1, UserID: 7, KeyHash: hashUserToken("ick_valida"), Scopes: []string{"members:read"}},
+		{ID: 2, UserID: 7, KeyHash: hashUserToken("ick_expirada"), ExpiresAt: &expired},
+	}}
+	uc := NewAPIKeyUseCase(repo)
+
+	key, err := uc.Authenticate("ick_valida")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.UserID != 7 || !key.HasScope("members:read") || key.HasScope("members:write") {
+		t.Errorf("Chave inesperada: %+v", key)
+	}
+	if repo.keys[0].LastUsedAt == nil {
+		t.Error("O último uso deveria ser registrado")
+	}
+
+	_, err = uc.Authenticate("ick_expirada")
+	assertDomainError(t, err, domainerrors.ErrInvalidToken)
+	if !IsAPIKey("ick_valida") || IsAPIKey("eyJhbGciOiJIUzI1NiJ9.e30.x") {
+		t.Error("Prefixo das chaves de API não reconhecido")
+	}
+}
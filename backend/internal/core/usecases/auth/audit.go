@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"strconv"
+
+	"github.com/insidechurch/auditevent"
+)
+
+// auditUserID formata o ID do usuário como gravado nos eventos de auditoria,
+// o mesmo formato usado pelo auth-service
+func auditUserID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// auditUser identifica o usuário como alvo de um evento de auditoria
+func auditUser(id uint) string {
+	return auditevent.Target(auditevent.TargetUser, auditUserID(id))
+}
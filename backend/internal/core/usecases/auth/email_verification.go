@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+	"insidechurch/backend/internal/core/ports"
+
+	"github.com/insidechurch/auditevent"
+)
+
+const (
+	// emailVerificationTTL é a validade do link de verificação de email
+	emailVerificationTTL = 48 * time.Hour
+
+	// resendVerificationCooldown é o intervalo mínimo entre dois envios para
+	// a mesma conta, independente do IP de origem
+	resendVerificationCooldown = time.Minute
+)
+
+// EmailVerificationUseCase implementa o envio e a confirmação da verificação de email
+type EmailVerificationUseCase struct {
+	userRepo  ports.UserRepository
+	tokenRepo ports.UserTokenRepository
+	notifier  ports.Notifier
+	verifyURL string
+	recorder  auditevent.Recorder
+}
+
+// NewEmailVerificationUseCase cria uma nova instância do caso de uso de
+// verificação de email. verifyURL é a página do frontend que recebe o token;
+// as confirmações são registradas em recorder.
+func NewEmailVerificationUseCase(
+	userRepo ports.UserRepository,
+	tokenRepo ports.UserTokenRepository,
+	notifier ports.Notifier,
+	verifyURL string,
+	recorder auditevent.Recorder,
+) *EmailVerificationUseCase {
+	return &EmailVerificationUseCase{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		notifier:  notifier,
+		verifyURL: verifyURL,
+		recorder:  recorder,
+	}
+}
+
+// SendVerification emite um novo token de verificação e o envia ao email do
+// usuário, invalidando os links enviados anteriormente
+func (uc *EmailVerificationUseCase) SendVerification(user *entities.User) error {
+	if err := uc.tokenRepo.InvalidateUser(user.ID, entities.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	token, userToken, err := newUserToken(user.ID, entities.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+	if err := uc.tokenRepo.Create(userToken); err != nil {
+		return err
+	}
+
+	return uc.notifier.Send(ports.Notification{
+		UserID:  strconv.FormatUint(uint64(user.ID), 10),
+		Channel: "email",
+		To:      user.Email,
+		Subject: "Confirme seu email",
+		Message: fmt.Sprintf(
+			"Confirme seu email acessando %s?token=%s. O link é válido por %d horas.",
+			uc.verifyURL, token, int(emailVerificationTTL.Hours()),
+		),
+		Sensitive: true,
+	})
+}
+
+// Verify confirma o email a partir do token recebido
+func (uc *EmailVerificationUseCase) Verify(ctx context.Context, input VerifyEmailInput) error {
+	if input.Token == "" {
+		return domainerrors.NewInvalidToken()
+	}
+
+	userToken, err := uc.tokenRepo.Consume(hashUserToken(input.Token), entities.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	user, err := uc.userRepo.FindByID(userToken.UserID)
+	if err != nil {
+		return err
+	}
+	if user.IsVerified() {
+		return nil
+	}
+
+	now := time.Now()
+	user.VerifiedAt = &now
+	if err := uc.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	uc.recorder.Record(ctx, auditevent.Event{
+		Action: auditevent.EmailVerified,
+		UserID: auditUserID(user.ID),
+		Target: auditUser(user.ID),
+	})
+	return nil
+}
+
+// Resend reenvia o link de verificação. Assim como na redefinição de senha, a
+// resposta não revela se o email está cadastrado ou já foi verificado.
+func (uc *EmailVerificationUseCase) Resend(input ResendVerificationInput) error {
+	email := strings.TrimSpace(input.Email)
+	if email == "" {
+		return domainerrors.NewInvalidInput("email é obrigatório", nil)
+	}
+
+	user, err := uc.userRepo.FindByEmail(email)
+	if err != nil {
+		if isUserNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if user == nil || user.IsVerified() {
+		return nil
+	}
+
+	latest, err := uc.tokenRepo.FindLatest(user.ID, entities.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < resendVerificationCooldown {
+		return nil
+	}
+
+	// Falhas de entrega não são repassadas ao cliente; o adapter registra o erro
+	_ = uc.SendVerification(user)
+	return nil
+}
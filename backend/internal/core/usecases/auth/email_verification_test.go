@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/passwordhash"
+	"github.com/insidechurch/passwordpolicy"
+)
+
+type emailVerificationFixture struct {
+	verification *EmailVerificationUseCase
+	register     *RegisterUseCase
+	users        *fakeUserRepo
+	tokens       *fakeTokenRepo
+	notifier     *fakeNotifier
+}
+
+func newEmailVerificationFixture() *emailVerificationFixture {
+	f := &emailVerificationFixture{
+		users:    &fakeUserRepo{users: map[uint]*entities.User{}},
+		tokens:   &fakeTokenRepo{},
+		notifier: &fakeNotifier{},
+	}
+	f.verification = NewEmailVerificationUseCase(f.users, f.tokens, f.notifier, "https://app/verificar", auditevent.Discard)
+	f.register = NewRegisterUseCase(f.users, f.verification, passwordpolicy.Default(), passwordhash.Default(), auditevent.Discard)
+	return f
+}
+
+func TestRegisterRequiresEmailVerification(t *testing.T) {
+	f := newEmailVerificationFixture()
+
+	err := f.register.Register(context.Background(), RegisterInput{Name: "Ana", Email: "ana@email.com", Password: "Culto#Domingo9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := f.users.users[1]
+	if user.IsVerified() {
+		t.Fatal("conta não deveria nascer verificada")
+	}
+
+	token := f.notifier.lastToken(t)
+	if err := f.verification.Verify(context.Background(), VerifyEmailInput{Token: token}); err != nil {
+		t.Fatal(err)
+	}
+	if !user.IsVerified() {
+		t.Error("conta deveria estar verificada")
+	}
+
+	err = f.verification.Verify(context.Background(), VerifyEmailInput{Token: token})
+	assertDomainError(t, err, domainerrors.ErrInvalidToken)
+}
+
+func TestResendVerification(t *testing.T) {
+	f := newEmailVerificationFixture()
+	f.register.Register(context.Background(), RegisterInput{Name: "Ana", Email: "ana@email.com", Password: "Culto#Domingo9"})
+	first := f.notifier.lastToken(t)
+
+	// Dentro do intervalo mínimo nada é reenviado
+	if err := f.verification.Resend(ResendVerificationInput{Email: "ana@email.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.notifier.sent) != 1 {
+		t.Fatalf("esperava 1 envio, obteve %d", len(f.notifier.sent))
+	}
+
+	f.tokens.tokens[0].CreatedAt = time.Now().Add(-2 * resendVerificationCooldown)
+	if err := f.verification.Resend(ResendVerificationInput{Email: "ana@email.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.notifier.sent) != 2 {
+		t.Fatalf("esperava 2 envios, obteve %d", len(f.notifier.sent))
+	}
+
+	// O link anterior deixa de valer
+	err := f.verification.Verify(context.Background(), VerifyEmailInput{Token: first})
+	assertDomainError(t, err, domainerrors.ErrInvalidToken)
+
+	// Email inexistente recebe a mesma resposta
+	if err := f.verification.Resend(ResendVerificationInput{Email: "ninguem@email.com"}); err != nil {
+		t.Errorf("não deveria revelar email inexistente: %v", err)
+	}
+}
+
+func TestLoginUnverifiedPolicy(t *testing.T) {
+	f := newEmailVerificationFixture()
+	f.register.Register(context.Background(), RegisterInput{Name: "Ana", Email: "ana@email.com", Password: "Culto#Domingo9"})
+	input := LoginInput{Email: "ana@email.com", Password: "Culto#Domingo9"}
+
+	if _, err := NewLoginUseCase(f.users, UnverifiedLoginDeny, nil, nil, passwordhash.Default(), auditevent.Discard, newTokenKeys(t), nil).Login(context.Background(), input); !errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("política deny deveria recusar, obteve %v", err)
+	}
+	if _, err := NewLoginUseCase(f.users, UnverifiedLoginLimit, nil, nil, passwordhash.Default(), auditevent.Discard, newTokenKeys(t), nil).Login(context.Background(), input); err != nil {
+		t.Errorf("política limit deveria permitir dentro do prazo: %v", err)
+	}
+
+	f.users.users[1].CreatedAt = time.Now().Add(-2 * unverifiedGracePeriod)
+	if _, err := NewLoginUseCase(f.users, UnverifiedLoginLimit, nil, nil, passwordhash.Default(), auditevent.Discard, newTokenKeys(t), nil).Login(context.Background(), input); !errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("política limit deveria recusar após o prazo, obteve %v", err)
+	}
+	if _, err := NewLoginUseCase(f.users, UnverifiedLoginAllow, nil, nil, passwordhash.Default(), auditevent.Discard, newTokenKeys(t), nil).Login(context.Background(), input); err != nil {
+		t.Errorf("política allow deveria permitir: %v", err)
+	}
+
+	// Senha incorreta continua retornando credenciais inválidas
+	wrong := LoginInput{Email: "ana@email.com", Password: "Errada#Domingo9"}
+	if _, err := NewLoginUseCase(f.users, UnverifiedLoginDeny, nil, nil, passwordhash.Default(), auditevent.Discard, newTokenKeys(t), nil).Login(context.Background(), wrong); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("esperava credenciais inválidas, obteve %v", err)
+	}
+}
+
+func TestRegisterPasswordPolicyViolations(t *testing.T) {
+	f := newEmailVerificationFixture()
+
+	err := f.register.Register(context.Background(), RegisterInput{Name: "Ana", Email: "ana@email.com", Password: "jesus123"})
+	assertDomainError(t, err, domainerrors.ErrInvalidInput)
+
+	var domainErr *domainerrors.DomainError
+	errors.As(err, &domainErr)
+	violations, _ := domainErr.Details["violations"].([]passwordpolicy.Violation)
+	rules := make([]string, len(violations))
+	for i, violation := range violations {
+		rules[i] = violation.Rule
+	}
+	want := []string{passwordpolicy.RuleUpper, passwordpolicy.RuleSpecial, passwordpolicy.RuleBreached}
+	if len(rules) != len(want) || rules[0] != want[0] || rules[1] != want[1] || rules[2] != want[2] {
+		t.Errorf("esperava as violações %v, obteve %v", want, rules)
+	}
+	if len(f.users.users) != 0 {
+		t.Error("usuário não deveria ser criado")
+	}
+}
+
+func TestLoginPasswordExpired(t *testing.T) {
+	f := newEmailVerificationFixture()
+	f.register.Register(context.Background(), RegisterInput{Name: "Ana", Email: "ana@email.com", Password: "Culto#Domingo9"})
+	input := LoginInput{Email: "ana@email.com", Password: "Culto#Domingo9"}
+
+	policy := passwordpolicy.Default()
+	policy.MaxAge = 90 * 24 * time.Hour
+	login := NewLoginUseCase(f.users, UnverifiedLoginAllow, nil, policy, passwordhash.Default(), auditevent.Discard, newTokenKeys(t), nil)
+
+	if f.users.users[1].PasswordChangedAt == nil {
+		t.Fatal("registro deveria guardar a data da senha")
+	}
+	if _, err := login.Login(context.Background(), input); err != nil {
+		t.Errorf("senha recente deveria ser aceita: %v", err)
+	}
+
+	changedAt := time.Now().Add(-100 * 24 * time.Hour)
+	f.users.users[1].PasswordChangedAt = &changedAt
+	if _, err := login.Login(context.Background(), input); !errors.Is(err, ErrPasswordExpired) {
+		t.Errorf("esperava senha expirada, obteve %v", err)
+	}
+}
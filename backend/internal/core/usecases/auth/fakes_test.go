@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+	"insidechurch/backend/internal/core/ports"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/keys"
+)
+
+// newTokenKeys cria chaves de assinatura efêmeras, sem o segredo HMAC legado
+func newTokenKeys(t *testing.T) *TokenKeys {
+	t.Helper()
+	keySet, err := keys.Generate()
+	if err != nil {
+		t.Fatalf("keys.Generate falhou: %v", err)
+	}
+	return NewTokenKeys(keySet, "")
+}
+
+type fakeUserRepo struct {
+	users map[uint]*entities.User
+}
+
+func (r *fakeUserRepo) Create(user *entities.User) error {
+	user.ID = uint(len(r.users) + 1)
+	user.CreatedAt = time.Now()
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepo) FindByID(id uint) (*entities.User, error) {
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, domainerrors.NewUserNotFound(id)
+}
+
+func (r *fakeUserRepo) FindByEmail(email string) (*entities.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, domainerrors.NewUserNotFound(0)
+}
+
+func (r *fakeUserRepo) Update(user *entities.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepo) Delete(id uint) error {
+	delete(r.users, id)
+	return nil
+}
+
+type fakeTokenRepo struct {
+	tokens []*entities.UserToken
+}
+
+func (r *fakeTokenRepo) Create(token *entities.UserToken) error {
+	token.ID = uint(len(r.tokens) + 1)
+	token.CreatedAt = time.Now()
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *fakeTokenRepo) Consume(tokenHash, purpose string) (*entities.UserToken, error) {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash && token.Purpose == purpose && token.UsedAt == nil && token.ExpiresAt.After(now) {
+			token.UsedAt = &now
+			return token, nil
+		}
+	}
+	return nil, domainerrors.NewInvalidToken()
+}
+
+func (r *fakeTokenRepo) FindLatest(userID uint, purpose string) (*entities.UserToken, error) {
+	for i := len(r.tokens) - 1; i >= 0; i-- {
+		if r.tokens[i].UserID == userID && r.tokens[i].Purpose == purpose {
+			return r.tokens[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeTokenRepo) InvalidateUser(userID uint, purpose string) error {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+type fakeNotifier struct {
+	sent []ports.Notification
+}
+
+func (n *fakeNotifier) Send(notification ports.Notification) error {
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+type fakeRevoker struct {
+	revoked []uint
+}
+
+func (r *fakeRevoker) RevokeUserSessions(userID uint) error {
+	r.revoked = append(r.revoked, userID)
+	return nil
+}
+
+// lastToken extrai o token do link enviado na última notificação
+func (n *fakeNotifier) lastToken(t *testing.T) string {
+	t.Helper()
+	if len(n.sent) == 0 {
+		t.Fatal("nenhuma notificação enviada")
+	}
+	message := n.sent[len(n.sent)-1].Message
+	start := strings.Index(message, "token=") + len("token=")
+	return strings.TrimSuffix(strings.Fields(message[start:])[0], ".")
+}
+
+type fakeRecoveryRepo struct {
+	codes map[uint]map[string]bool
+}
+
+func (r *fakeRecoveryRepo) Replace(userID uint, codeHashes []string) error {
+	if r.codes == nil {
+		r.codes = make(map[uint]map[string]bool)
+	}
+	r.codes[userID] = make(map[string]bool)
+	for _, hash := range codeHashes {
+		r.codes[userID][hash] = false
+	}
+	return nil
+}
+
+func (r *fakeRecoveryRepo) Consume(userID uint, codeHash string) (bool, error) {
+	used, exists := r.codes[userID][codeHash]
+	if !exists || used {
+		return false, nil
+	}
+	r.codes[userID][codeHash] = true
+	return true, nil
+}
+
+func (r *fakeRecoveryRepo) DeleteUser(userID uint) error {
+	delete(r.codes, userID)
+	return nil
+}
+
+// fakeMFAPolicy exige MFA dos papéis marcados
+// fakeRecorder guarda os eventos de auditoria registrados
+type fakeRecorder struct {
+	events []auditevent.Event
+}
+
+func (r *fakeRecorder) Record(_ context.Context, event auditevent.Event) {
+	r.events = append(r.events, event)
+}
+
+// actions lista as ações registradas, na ordem
+func (r *fakeRecorder) actions() []string {
+	actions := make([]string, len(r.events))
+	for i, event := range r.events {
+		actions[i] = event.Action
+	}
+	return actions
+}
+
+type fakeMFAPolicy map[uint]bool
+
+func (p fakeMFAPolicy) RequiresMFA(roleID uint) (bool, error) {
+	return p[roleID], nil
+}
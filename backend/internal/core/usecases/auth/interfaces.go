@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"context"
+
 	"insidechurch/backend/internal/core/domain/entities"
 )
 
@@ -14,6 +16,16 @@ type LoginInput struct {
 type LoginOutput struct {
 	User  *entities.User
 	Token string
+	// MFARequired indica que o login aguarda o código do segundo fator,
+	// informado junto com ChallengeToken
+	MFARequired bool
+	// MFAEnrollmentRequired indica que o papel do usuário exige o segundo
+	// fator e que o cadastro deve ser feito com ChallengeToken
+	MFAEnrollmentRequired bool
+	ChallengeToken        string
+	// RecoveryCodes é preenchido apenas quando o login conclui o cadastro do
+	// segundo fator
+	RecoveryCodes []string
 }
 
 // RegisterInput representa os dados necessários para o registro
@@ -23,9 +35,52 @@ type RegisterInput struct {
 	Password string
 }
 
+// RequestPasswordResetInput representa os dados para solicitar a redefinição de senha
+type RequestPasswordResetInput struct {
+	Email string
+}
+
+// ConfirmPasswordResetInput representa os dados para confirmar a redefinição de senha
+type ConfirmPasswordResetInput struct {
+	Token    string
+	Password string
+}
+
+// VerifyEmailInput representa os dados para confirmar o email
+type VerifyEmailInput struct {
+	Token string
+}
+
+// ResendVerificationInput representa os dados para reenviar o link de verificação
+type ResendVerificationInput struct {
+	Email string
+}
+
+// MFACodeInput representa o código TOTP ou de recuperação informado pelo usuário
+type MFACodeInput struct {
+	Code string
+}
+
+// MFAChallengeInput representa o token de desafio emitido no login
+type MFAChallengeInput struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+// VerifyMFAInput representa os dados para concluir o login com o segundo fator
+type VerifyMFAInput struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// MFAEnrollmentOutput representa os dados para cadastrar o aplicativo autenticador
+type MFAEnrollmentOutput struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
 // AuthUseCase define a interface para os casos de uso de autenticação
 type AuthUseCase interface {
-	Login(input LoginInput) (*LoginOutput, error)
-	Register(input RegisterInput) error
+	Login(ctx context.Context, input LoginInput) (*LoginOutput, error)
+	Register(ctx context.Context, input RegisterInput) error
 	ValidateToken(token string) (*entities.User, error)
 }
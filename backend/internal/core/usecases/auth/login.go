@@ -1,107 +1,307 @@
 package auth
 
 import (
+	"context"
 	"errors"
-	"os"
 	"time"
 
 	"insidechurch/backend/internal/core/domain/entities"
 	"insidechurch/backend/internal/core/ports"
 
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/lockout"
+	"github.com/insidechurch/passwordhash"
+	"github.com/insidechurch/passwordpolicy"
 )
 
 var (
 	ErrInvalidCredentials = errors.New("credenciais inválidas")
 	ErrInvalidInput       = errors.New("entrada inválida")
+	ErrEmailNotVerified   = errors.New("email não verificado")
+	ErrPasswordExpired    = errors.New("senha expirada, redefina sua senha para continuar")
+	ErrTooManyAttempts    = errors.New("muitas tentativas de login, tente novamente mais tarde")
+)
+
+// TooManyAttemptsError indica um login recusado pelo controle de falhas da
+// conta ou do IP; RetryAfter é a espera até a próxima tentativa
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *TooManyAttemptsError) Error() string { return ErrTooManyAttempts.Error() }
+func (e *TooManyAttemptsError) Unwrap() error { return ErrTooManyAttempts }
+
+const (
+	// mfaChallengeTTL é a validade do token de desafio emitido entre a senha e
+	// o segundo fator
+	mfaChallengeTTL = 5 * time.Minute
+
+	// mfaChallengeType identifica os tokens de desafio, que não autorizam
+	// requisições
+	mfaChallengeType = "mfa_challenge"
+
+	// Finalidades do token de desafio: confirmar o segundo fator já cadastrado
+	// ou cadastrá-lo, quando o papel do usuário o exige
+	mfaChallengeVerify = "verify"
+	mfaChallengeEnroll = "enroll"
 )
 
 // LoginUseCase implementa o caso de uso de login
 type LoginUseCase struct {
-	userRepo ports.UserRepository
+	userRepo         ports.UserRepository
+	unverifiedPolicy UnverifiedLoginPolicy
+	mfaPolicy        ports.MFAPolicy
+	passwordPolicy   *passwordpolicy.Policy
+	passwordHasher   *passwordhash.Hasher
+	recorder         auditevent.Recorder
+	tokenKeys        *TokenKeys
+	lockout          *lockout.Login
 }
 
-// NewLoginUseCase cria uma nova instância do caso de uso de login
-func NewLoginUseCase(userRepo ports.UserRepository) *LoginUseCase {
+// NewLoginUseCase cria uma nova instância do caso de uso de login. mfaPolicy
+// pode ser nil quando nenhum papel exige autenticação em dois fatores e
+// passwordPolicy, quando as senhas não expiram. Hashes abaixo da configuração de
+// passwordHasher são refeitos no login. Tentativas e logins concluídos são
+// registrados em recorder. Os tokens de acesso e de desafio são assinados e
+// verificados com tokenKeys. As senhas incorretas são contadas em
+// loginLockout, compartilhado com o auth-service; nil desativa o controle de
+// falhas.
+func NewLoginUseCase(userRepo ports.UserRepository, unverifiedPolicy UnverifiedLoginPolicy, mfaPolicy ports.MFAPolicy, passwordPolicy *passwordpolicy.Policy, passwordHasher *passwordhash.Hasher, recorder auditevent.Recorder, tokenKeys *TokenKeys, loginLockout *lockout.Login) *LoginUseCase {
 	return &LoginUseCase{
-		userRepo: userRepo,
+		userRepo:         userRepo,
+		unverifiedPolicy: unverifiedPolicy,
+		mfaPolicy:        mfaPolicy,
+		passwordPolicy:   passwordPolicy,
+		passwordHasher:   passwordHasher,
+		recorder:         recorder,
+		tokenKeys:        tokenKeys,
+		lockout:          loginLockout,
 	}
 }
 
 // Login executa o caso de uso de login
-func (uc *LoginUseCase) Login(input LoginInput) (*LoginOutput, error) {
+func (uc *LoginUseCase) Login(ctx context.Context, input LoginInput) (*LoginOutput, error) {
 	// Validação de entrada
 	if input.Email == "" || input.Password == "" {
 		return nil, ErrInvalidInput
 	}
 
+	// Conta ou IP com falhas recentes aguardam antes de uma nova tentativa,
+	// inclusive com a senha correta
+	ip := auditevent.OriginFrom(ctx).IP
+	if retryAfter := uc.retryAfter(ctx, input.Email, ip); retryAfter > 0 {
+		uc.failed(ctx, nil, auditevent.OutcomeDenied, auditevent.ReasonAccountLocked)
+		return nil, &TooManyAttemptsError{RetryAfter: retryAfter}
+	}
+
 	// Buscar usuário pelo email
 	user, err := uc.userRepo.FindByEmail(input.Email)
-	if err != nil {
+	if err != nil || user == nil {
+		uc.countFailure(ctx, input.Email, ip)
+		uc.failed(ctx, nil, auditevent.OutcomeFailure, auditevent.ReasonInvalidCredentials)
 		return nil, ErrInvalidCredentials
 	}
 
-	if user == nil {
+	// Verificar senha
+	if err := uc.passwordHasher.Verify(input.Password, user.Password); err != nil {
+		uc.countFailure(ctx, input.Email, ip)
+		uc.failed(ctx, user, auditevent.OutcomeFailure, auditevent.ReasonInvalidCredentials)
 		return nil, ErrInvalidCredentials
 	}
+	uc.clearFailures(ctx, input.Email)
+	uc.rehash(user, input.Password)
 
-	// Verificar senha
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
-		return nil, ErrInvalidCredentials
+	// Verificado após a senha para não revelar o estado da conta a terceiros
+	if !uc.unverifiedPolicy.Allows(user, time.Now()) {
+		uc.failed(ctx, user, auditevent.OutcomeDenied, auditevent.ReasonEmailNotVerified)
+		return nil, ErrEmailNotVerified
+	}
+
+	// Senhas vencidas precisam ser redefinidas antes de um novo acesso
+	if uc.passwordExpired(user) {
+		uc.failed(ctx, user, auditevent.OutcomeDenied, auditevent.ReasonPasswordExpired)
+		return nil, ErrPasswordExpired
 	}
 
-	// Gerar token JWT
+	// Com o segundo fator cadastrado, o token só é emitido após o código TOTP
+	if user.MFAEnabled() {
+		return uc.challenge(user, mfaChallengeVerify)
+	}
+
+	requiresMFA, err := uc.requiresMFA(user)
+	if err != nil {
+		return nil, err
+	}
+	if requiresMFA {
+		return uc.challenge(user, mfaChallengeEnroll)
+	}
+
+	return uc.issue(ctx, user)
+}
+
+// failed registra uma tentativa de login recusada; user é nil quando o email
+// não corresponde a nenhuma conta
+func (uc *LoginUseCase) failed(ctx context.Context, user *entities.User, outcome auditevent.Outcome, reason string) {
+	event := auditevent.Event{
+		Action:  auditevent.LoginFailed,
+		Outcome: outcome,
+		Reason:  reason,
+	}
+	if user != nil {
+		event.Target = auditUser(user.ID)
+	}
+	uc.recorder.Record(ctx, event)
+}
+
+// retryAfter consulta a espera da conta e do IP. Falhas do cache não
+// impedem o login: vale a espera das consultas que funcionaram.
+func (uc *LoginUseCase) retryAfter(ctx context.Context, email, ip string) time.Duration {
+	if uc.lockout == nil {
+		return 0
+	}
+	retryAfter, _ := uc.lockout.RetryAfter(ctx, email, ip)
+	return retryAfter
+}
+
+// countFailure registra a senha incorreta para a conta e para o IP, mesmo
+// quando o email não está cadastrado, para não revelar quais emails existem.
+// Falhas do cache não alteram a resposta do login.
+func (uc *LoginUseCase) countFailure(ctx context.Context, email, ip string) {
+	if uc.lockout != nil {
+		uc.lockout.Fail(ctx, email, ip)
+	}
+}
+
+// clearFailures zera as falhas da conta após a senha correta
+func (uc *LoginUseCase) clearFailures(ctx context.Context, email string) {
+	if uc.lockout != nil {
+		uc.lockout.Reset(ctx, email)
+	}
+}
+
+// passwordExpired verifica a validade máxima da senha; usuários sem a data da
+// última troca não expiram
+func (uc *LoginUseCase) passwordExpired(user *entities.User) bool {
+	return uc.passwordPolicy != nil && user.PasswordChangedAt != nil &&
+		uc.passwordPolicy.Expired(*user.PasswordChangedAt, time.Now())
+}
+
+// rehash atualiza o hash da senha gerado com um algoritmo ou parâmetros
+// abaixo da configuração atual, aproveitando a senha recebida no login. Falhas
+// não impedem o login: o hash antigo continua válido e a atualização é tentada
+// novamente no próximo acesso.
+func (uc *LoginUseCase) rehash(user *entities.User, password string) {
+	if !uc.passwordHasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := uc.passwordHasher.Hash(password)
+	if err != nil {
+		return
+	}
+
+	previous := user.Password
+	user.Password = hashedPassword
+	if err := uc.userRepo.Update(user); err != nil {
+		user.Password = previous
+	}
+}
+
+// issue gera o token JWT de acesso do usuário, concluindo o login
+func (uc *LoginUseCase) issue(ctx context.Context, user *entities.User) (*LoginOutput, error) {
 	token, err := uc.generateToken(user)
 	if err != nil {
 		return nil, err
 	}
 
+	uc.recorder.Record(ctx, auditevent.Event{
+		Action: auditevent.LoginSucceeded,
+		UserID: auditUserID(user.ID),
+		Target: auditUser(user.ID),
+	})
+
 	return &LoginOutput{
 		User:  user,
 		Token: token,
 	}, nil
 }
 
-// ValidateToken valida um token JWT
-func (uc *LoginUseCase) ValidateToken(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("método de assinatura inválido")
-		}
-		return []byte(os.Getenv("JWT_SECRET")), nil
-	})
+// requiresMFA consulta a política do papel do usuário
+func (uc *LoginUseCase) requiresMFA(user *entities.User) (bool, error) {
+	if uc.mfaPolicy == nil || user.RoleID == nil {
+		return false, nil
+	}
+	return uc.mfaPolicy.RequiresMFA(*user.RoleID)
+}
+
+// challenge emite o token de desafio que substitui o token de acesso até a
+// confirmação do segundo fator
+func (uc *LoginUseCase) challenge(user *entities.User, purpose string) (*LoginOutput, error) {
+	claims := jwt.MapClaims{
+		"sub":     user.ID,
+		"type":    mfaChallengeType,
+		"purpose": purpose,
+		"exp":     time.Now().Add(mfaChallengeTTL).Unix(),
+		"iat":     time.Now().Unix(),
+	}
 
+	token, err := uc.tokenKeys.Sign(claims)
 	if err != nil {
 		return nil, err
 	}
 
-	if !token.Valid {
-		return nil, errors.New("token inválido")
+	return &LoginOutput{
+		User:                  user,
+		MFARequired:           purpose == mfaChallengeVerify,
+		MFAEnrollmentRequired: purpose == mfaChallengeEnroll,
+		ChallengeToken:        token,
+	}, nil
+}
+
+// parseChallenge valida o token de desafio e retorna o ID do usuário
+func (uc *LoginUseCase) parseChallenge(challengeToken, purpose string) (uint, bool) {
+	token, err := uc.ValidateToken(challengeToken)
+	if err != nil {
+		return 0, false
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["type"] != mfaChallengeType || claims["purpose"] != purpose {
+		return 0, false
+	}
+
+	sub, ok := claims["sub"].(float64)
+	if !ok {
+		return 0, false
 	}
+	return uint(sub), true
+}
+
+// ValidateToken valida um token JWT emitido pela API
+func (uc *LoginUseCase) ValidateToken(tokenString string) (*jwt.Token, error) {
+	return uc.tokenKeys.Parse(tokenString, jwt.MapClaims{})
+}
 
-	return token, nil
+// IssuedWith informa se o kid identifica uma das chaves de assinatura da API
+func (uc *LoginUseCase) IssuedWith(kid string) bool {
+	return uc.tokenKeys.Has(kid)
 }
 
 // generateToken gera um token JWT para o usuário
 func (uc *LoginUseCase) generateToken(user *entities.User) (string, error) {
-	// Criar claims do token
+	// Criar claims do token; o iat tem a precisão de milissegundos do corte
+	// de tokens revogados, para que o login logo após uma redefinição de
+	// senha não seja recusado
+	now := time.Now()
 	claims := jwt.MapClaims{
 		"sub":   user.ID,
 		"email": user.Email,
 		"name":  user.Name,
-		"exp":   time.Now().Add(time.Hour * 24).Unix(),
-		"iat":   time.Now().Unix(),
-	}
-
-	// Criar token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Assinar token
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
-	if err != nil {
-		return "", err
+		"exp":   now.Add(time.Hour * 24).Unix(),
+		"iat":   float64(now.UnixMilli()) / 1000,
 	}
 
-	return tokenString, nil
+	// Assinar token com a chave atual, identificada pelo kid
+	return uc.tokenKeys.Sign(claims)
 }
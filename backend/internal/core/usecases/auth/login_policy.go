@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+)
+
+// UnverifiedLoginPolicy define se usuários com email não verificado podem fazer login
+type UnverifiedLoginPolicy string
+
+const (
+	// UnverifiedLoginAllow permite o login sem restrições
+	UnverifiedLoginAllow UnverifiedLoginPolicy = "allow"
+	// UnverifiedLoginLimit permite o login apenas durante unverifiedGracePeriod após o cadastro
+	UnverifiedLoginLimit UnverifiedLoginPolicy = "limit"
+	// UnverifiedLoginDeny recusa o login até a verificação do email
+	UnverifiedLoginDeny UnverifiedLoginPolicy = "deny"
+)
+
+// unverifiedGracePeriod é o prazo para verificar o email na política "limit"
+const unverifiedGracePeriod = 24 * time.Hour
+
+// Allows indica se o usuário pode fazer login. Valores desconhecidos são
+// tratados como "deny".
+func (p UnverifiedLoginPolicy) Allows(user *entities.User, now time.Time) bool {
+	if user.IsVerified() {
+		return true
+	}
+
+	switch p {
+	case UnverifiedLoginAllow:
+		return true
+	case UnverifiedLoginLimit:
+		return now.Sub(user.CreatedAt) < unverifiedGracePeriod
+	default:
+		return false
+	}
+}
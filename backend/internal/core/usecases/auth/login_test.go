@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/cache"
+	"github.com/insidechurch/keys"
+	"github.com/insidechurch/lockout"
+	"github.com/insidechurch/passwordhash"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func TestLoginRehashesLegacyPassword(t *testing.T) {
+
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("Culto#Domingo9"), bcrypt.MinCost)
+	users := &fakeUserRepo{users: map[uint]*entities.User{
+		1: {Model: gorm.Model{ID: 1}, Email: "ana@email.com", Password: string(legacy)},
+	}}
+	login := NewLoginUseCase(users, UnverifiedLoginAllow, nil, nil, passwordhash.Default(), auditevent.Discard, newTokenKeys(t), nil)
+
+	if _, err := login.Login(context.Background(), LoginInput{Email: "ana@email.com", Password: "errada"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("esperava credenciais inválidas, obteve %v", err)
+	}
+	if users.users[1].Password != string(legacy) {
+		t.Fatal("hash não deveria mudar após uma senha inválida")
+	}
+
+	if _, err := login.Login(context.Background(), LoginInput{Email: "ana@email.com", Password: "Culto#Domingo9"}); err != nil {
+		t.Fatalf("hash $2a$ existente deveria continuar válido: %v", err)
+	}
+	rehashed := users.users[1].Password
+	if !strings.HasPrefix(rehashed, "$argon2id$") {
+		t.Fatalf("hash deveria migrar para Argon2id, obteve %s", rehashed)
+	}
+
+	if _, err := login.Login(context.Background(), LoginInput{Email: "ana@email.com", Password: "Culto#Domingo9"}); err != nil {
+		t.Fatalf("senha deveria ser aceita com o novo hash: %v", err)
+	}
+	if users.users[1].Password != rehashed {
+		t.Error("hash na configuração atual não deveria ser refeito")
+	}
+}
+
+func TestLoginRecordsAuditEvents(t *testing.T) {
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("Culto#Domingo9"), bcrypt.MinCost)
+	users := &fakeUserRepo{users: map[uint]*entities.User{
+		1: {Model: gorm.Model{ID: 1}, Email: "ana@email.com", Password: string(hash)},
+	}}
+	recorder := &fakeRecorder{}
+	login := NewLoginUseCase(users, UnverifiedLoginDeny, nil, nil, passwordhash.Default(), recorder, newTokenKeys(t), nil)
+
+	ctx := context.Background()
+	login.Login(ctx, LoginInput{Email: "ninguem@email.com", Password: "Culto#Domingo9"})
+	login.Login(ctx, LoginInput{Email: "ana@email.com", Password: "errada"})
+	login.Login(ctx, LoginInput{Email: "ana@email.com", Password: "Culto#Domingo9"})
+
+	now := time.Now()
+	users.users[1].VerifiedAt = &now
+	if _, err := login.Login(ctx, LoginInput{Email: "ana@email.com", Password: "Culto#Domingo9"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []auditevent.Event{
+		{Action: auditevent.LoginFailed, Outcome: auditevent.OutcomeFailure, Reason: auditevent.ReasonInvalidCredentials},
+		{Action: auditevent.LoginFailed, Target: "user:1", Outcome: auditevent.OutcomeFailure, Reason: auditevent.ReasonInvalidCredentials},
+		{Action: auditevent.LoginFailed, Target: "user:1", Outcome: auditevent.OutcomeDenied, Reason: auditevent.ReasonEmailNotVerified},
+		{Action: auditevent.LoginSucceeded, UserID: "1", Target: "user:1"},
+	}
+	if len(recorder.events) != len(want) {
+		t.Fatalf("esperava %d eventos, obteve %+v", len(want), recorder.events)
+	}
+	for i, event := range recorder.events {
+		if event.Action != want[i].Action || event.UserID != want[i].UserID || event.Target != want[i].Target ||
+			event.Outcome != want[i].Outcome || event.Reason != want[i].Reason {
+			t.Errorf("evento %d inesperado: %+v", i, event)
+		}
+	}
+}
+
+func TestValidateTokenLegacyHMAC(t *testing.T) {
+
+	keySet, err := keys.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := jwt.MapClaims{"user_id": float64(1), "exp": time.Now().Add(time.Hour).Unix()}
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("segredo-antigo"))
+
+	login := NewLoginUseCase(&fakeUserRepo{}, UnverifiedLoginAllow, nil, nil, passwordhash.Default(), auditevent.Discard, NewTokenKeys(keySet, ""), nil)
+	if _, err := login.ValidateToken(legacy); err == nil {
+		t.Fatal("token HS256 sem kid deveria ser recusado sem JWT_HMAC_FALLBACK")
+	}
+
+	login = NewLoginUseCase(&fakeUserRepo{}, UnverifiedLoginAllow, nil, nil, passwordhash.Default(), auditevent.Discard, NewTokenKeys(keySet, "segredo-antigo"), nil)
+	if _, err := login.ValidateToken(legacy); err != nil {
+		t.Fatalf("token HS256 sem kid deveria ser aceito durante a migração: %v", err)
+	}
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = keySet.SigningKey().ID
+	forgedString, _ := forged.SignedString([]byte("segredo-antigo"))
+	if _, err := login.ValidateToken(forgedString); err == nil {
+		t.Fatal("token HS256 com o kid de uma chave assimétrica deveria ser recusado")
+	}
+
+	signed, err := login.generateToken(&entities.User{Model: gorm.Model{ID: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := login.ValidateToken(signed)
+	if err != nil {
+		t.Fatalf("token emitido pelo login deveria ser válido: %v", err)
+	}
+	if kid, _ := token.Header["kid"].(string); !login.IssuedWith(kid) {
+		t.Errorf("token emitido deveria trazer o kid da chave atual, obteve %q", kid)
+	}
+}
+
+func TestLoginLockoutSharedWithAuthService(t *testing.T) {
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("Culto#Domingo9"), bcrypt.MinCost)
+	users := &fakeUserRepo{users: map[uint]*entities.User{
+		1: {Model: gorm.Model{ID: 1}, Email: "ana@email.com", Password: string(hash)},
+	}}
+	sharedCache := cache.NewMemoryCache()
+	recorder := &fakeRecorder{}
+	login := NewLoginUseCase(users, UnverifiedLoginAllow, nil, nil, passwordhash.Default(), recorder, newTokenKeys(t), lockout.NewLogin(sharedCache))
+	ctx := auditevent.WithOrigin(context.Background(), auditevent.Origin{IP: "198.51.100.7"})
+
+	// Falhas registradas pelo auth-service no mesmo cache valem para a API
+	authService := lockout.NewLogin(sharedCache)
+	for i := 0; i <= lockout.AccountPolicy.FreeAttempts; i++ {
+		authService.Fail(ctx, "ANA@email.com", "203.0.113.9")
+	}
+
+	_, err := login.Login(ctx, LoginInput{Email: "ana@email.com", Password: "Culto#Domingo9"})
+	var tooMany *TooManyAttemptsError
+	if !errors.As(err, &tooMany) || tooMany.RetryAfter <= 0 || !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("Login deveria aguardar após as falhas no auth-service, obteve %v", err)
+	}
+	if last := recorder.events[len(recorder.events)-1]; last.Reason != auditevent.ReasonAccountLocked {
+		t.Errorf("Recusa deveria ser auditada como conta bloqueada: %+v", last)
+	}
+
+	// Falhas na API também são contadas para a conta
+	authService.Reset(ctx, "ana@email.com")
+	for i := 0; i <= lockout.AccountPolicy.FreeAttempts; i++ {
+		if _, err := login.Login(ctx, LoginInput{Email: "ana@email.com", Password: "errada"}); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Tentativa %d: esperava credenciais inválidas, obteve %v", i+1, err)
+		}
+	}
+	if retryAfter, _ := authService.RetryAfter(ctx, "ana@email.com", ""); retryAfter <= 0 {
+		t.Error("Falhas na API deveriam atrasar o login no auth-service")
+	}
+}
@@ -0,0 +1,239 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+	"insidechurch/backend/internal/core/ports"
+
+	"github.com/insidechurch/auditevent"
+)
+
+// MFAUseCase implementa o cadastro, a verificação e a desativação da
+// autenticação em dois fatores com TOTP
+type MFAUseCase struct {
+	userRepo     ports.UserRepository
+	recoveryRepo ports.RecoveryCodeRepository
+	loginUseCase *LoginUseCase
+	issuer       string
+	recorder     auditevent.Recorder
+}
+
+// NewMFAUseCase cria uma nova instância do caso de uso de autenticação em dois
+// fatores. issuer é o nome exibido no aplicativo autenticador; a ativação e a
+// desativação do segundo fator são registradas em recorder.
+func NewMFAUseCase(
+	userRepo ports.UserRepository,
+	recoveryRepo ports.RecoveryCodeRepository,
+	loginUseCase *LoginUseCase,
+	issuer string,
+	recorder auditevent.Recorder,
+) *MFAUseCase {
+	return &MFAUseCase{
+		userRepo:     userRepo,
+		recoveryRepo: recoveryRepo,
+		loginUseCase: loginUseCase,
+		issuer:       issuer,
+		recorder:     recorder,
+	}
+}
+
+// BeginEnrollment gera um novo segredo para o usuário autenticado. O segundo
+// fator só passa a ser exigido após ConfirmEnrollment.
+func (uc *MFAUseCase) BeginEnrollment(userID uint) (*MFAEnrollmentOutput, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.beginEnrollment(user)
+}
+
+// ConfirmEnrollment ativa o segundo fator a partir do primeiro código gerado
+// pelo aplicativo e retorna os códigos de recuperação, exibidos uma única vez
+func (uc *MFAUseCase) ConfirmEnrollment(ctx context.Context, userID uint, input MFACodeInput) ([]string, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.confirmEnrollment(ctx, user, input.Code)
+}
+
+// Disable desativa o segundo fator mediante um código válido. Usuários cujo
+// papel exige MFA não podem desativá-lo.
+func (uc *MFAUseCase) Disable(ctx context.Context, userID uint, input MFACodeInput) error {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.MFAEnabled() {
+		return domainerrors.NewInvalidInput("autenticação em dois fatores não está ativa", nil)
+	}
+
+	requiresMFA, err := uc.loginUseCase.requiresMFA(user)
+	if err != nil {
+		return err
+	}
+	if requiresMFA {
+		return domainerrors.NewForbidden("o papel do usuário exige autenticação em dois fatores")
+	}
+
+	if err := uc.checkCode(user, input.Code); err != nil {
+		return err
+	}
+
+	user.MFASecret = ""
+	user.MFAEnabledAt = nil
+	user.MFALastStep = 0
+	if err := uc.userRepo.Update(user); err != nil {
+		return err
+	}
+	if err := uc.recoveryRepo.DeleteUser(user.ID); err != nil {
+		return err
+	}
+
+	uc.recorder.Record(ctx, auditevent.Event{
+		Action: auditevent.MFADisabled,
+		Target: auditUser(user.ID),
+	})
+	return nil
+}
+
+// VerifyChallenge conclui o login trocando o token de desafio e um código
+// TOTP ou de recuperação pelo token de acesso
+func (uc *MFAUseCase) VerifyChallenge(ctx context.Context, input VerifyMFAInput) (*LoginOutput, error) {
+	user, err := uc.challengeUser(input.ChallengeToken, mfaChallengeVerify)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFAEnabled() {
+		return nil, domainerrors.NewInvalidToken()
+	}
+
+	if err := uc.checkCode(user, input.Code); err != nil {
+		uc.loginUseCase.failed(ctx, user, auditevent.OutcomeFailure, auditevent.ReasonInvalidCode)
+		return nil, err
+	}
+	return uc.loginUseCase.issue(ctx, user)
+}
+
+// BeginChallengeEnrollment inicia o cadastro obrigatório do segundo fator
+// durante o login, para usuários cujo papel exige MFA
+func (uc *MFAUseCase) BeginChallengeEnrollment(input MFAChallengeInput) (*MFAEnrollmentOutput, error) {
+	user, err := uc.challengeUser(input.ChallengeToken, mfaChallengeEnroll)
+	if err != nil {
+		return nil, err
+	}
+	return uc.beginEnrollment(user)
+}
+
+// ConfirmChallengeEnrollment conclui o cadastro obrigatório e o login,
+// retornando o token de acesso junto com os códigos de recuperação
+func (uc *MFAUseCase) ConfirmChallengeEnrollment(ctx context.Context, input VerifyMFAInput) (*LoginOutput, error) {
+	user, err := uc.challengeUser(input.ChallengeToken, mfaChallengeEnroll)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := uc.confirmEnrollment(ctx, user, input.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := uc.loginUseCase.issue(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	output.RecoveryCodes = codes
+	return output, nil
+}
+
+// challengeUser valida o token de desafio e carrega o usuário
+func (uc *MFAUseCase) challengeUser(challengeToken, purpose string) (*entities.User, error) {
+	userID, ok := uc.loginUseCase.parseChallenge(challengeToken, purpose)
+	if !ok {
+		return nil, domainerrors.NewInvalidToken()
+	}
+	return uc.userRepo.FindByID(userID)
+}
+
+func (uc *MFAUseCase) beginEnrollment(user *entities.User) (*MFAEnrollmentOutput, error) {
+	if user.MFAEnabled() {
+		return nil, domainerrors.NewInvalidInput("autenticação em dois fatores já está ativa", nil)
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, domainerrors.NewInternalError(err)
+	}
+
+	user.MFASecret = secret
+	user.MFALastStep = 0
+	if err := uc.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollmentOutput{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(uc.issuer, user.Email, secret),
+	}, nil
+}
+
+func (uc *MFAUseCase) confirmEnrollment(ctx context.Context, user *entities.User, code string) ([]string, error) {
+	if user.MFAEnabled() {
+		return nil, domainerrors.NewInvalidInput("autenticação em dois fatores já está ativa", nil)
+	}
+	if user.MFASecret == "" {
+		return nil, domainerrors.NewInvalidInput("cadastro do segundo fator não foi iniciado", nil)
+	}
+
+	step, ok := validateTOTP(user.MFASecret, code, time.Now(), user.MFALastStep)
+	if !ok {
+		return nil, domainerrors.NewUnauthorized("código de verificação inválido")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, domainerrors.NewInternalError(err)
+	}
+	if err := uc.recoveryRepo.Replace(user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.MFAEnabledAt = &now
+	user.MFALastStep = step
+	if err := uc.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	// O segundo fator cadastrado durante o login ainda não tem sessão: o
+	// usuário do evento é o dono da conta
+	uc.recorder.Record(ctx, auditevent.Event{
+		Action: auditevent.MFAEnabled,
+		UserID: auditUserID(user.ID),
+		Target: auditUser(user.ID),
+	})
+	return codes, nil
+}
+
+// checkCode aceita um código TOTP ainda não utilizado ou, na falta dele, um
+// código de recuperação, que é consumido
+func (uc *MFAUseCase) checkCode(user *entities.User, code string) error {
+	if step, ok := validateTOTP(user.MFASecret, code, time.Now(), user.MFALastStep); ok {
+		user.MFALastStep = step
+		return uc.userRepo.Update(user)
+	}
+
+	if code != "" {
+		used, err := uc.recoveryRepo.Consume(user.ID, hashRecoveryCode(code))
+		if err != nil {
+			return err
+		}
+		if used {
+			return nil
+		}
+	}
+	return domainerrors.NewUnauthorized("código de verificação inválido")
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/passwordhash"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type mfaFixture struct {
+	useCase  *MFAUseCase
+	login    *LoginUseCase
+	users    *fakeUserRepo
+	recovery *fakeRecoveryRepo
+}
+
+// newMFAFixture cria um usuário verificado com o papel 1, que exige MFA
+// quando roleRequiresMFA é verdadeiro
+func newMFAFixture(t *testing.T, roleRequiresMFA bool) *mfaFixture {
+	t.Helper()
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("Senha@123"), bcrypt.MinCost)
+	now := time.Now()
+	roleID := uint(1)
+	f := &mfaFixture{
+		users: &fakeUserRepo{users: map[uint]*entities.User{
+			1: {Model: gorm.Model{ID: 1}, Name: "Pr. João", Email: "joao@email.com", Password: string(hash), VerifiedAt: &now, RoleID: &roleID},
+		}},
+		recovery: &fakeRecoveryRepo{},
+	}
+	f.login = NewLoginUseCase(f.users, UnverifiedLoginDeny, fakeMFAPolicy{1: roleRequiresMFA}, nil, passwordhash.Default(), auditevent.Discard, newTokenKeys(t), nil)
+	f.useCase = NewMFAUseCase(f.users, f.recovery, f.login, "InsideChurch", auditevent.Discard)
+	return f
+}
+
+// currentCode gera o código TOTP do intervalo atual para o segredo
+func currentCode(t *testing.T, secret string) string {
+	t.Helper()
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return totpCode(key, totpStep(time.Now()))
+}
+
+func (f *mfaFixture) loginOutput(t *testing.T) *LoginOutput {
+	t.Helper()
+	output, err := f.login.Login(context.Background(), LoginInput{Email: "joao@email.com", Password: "Senha@123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output
+}
+
+func TestMFAEnrollmentAndLogin(t *testing.T) {
+	f := newMFAFixture(t, false)
+
+	// Sem MFA o login emite o token diretamente
+	if output := f.loginOutput(t); output.Token == "" || output.ChallengeToken != "" {
+		t.Fatal("login sem MFA deveria emitir o token de acesso")
+	}
+
+	enrollment, err := f.useCase.BeginEnrollment(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.users.users[1].MFAEnabled() {
+		t.Error("MFA não deveria estar ativo antes da confirmação")
+	}
+
+	_, err = f.useCase.ConfirmEnrollment(context.Background(), 1, MFACodeInput{Code: "000000"})
+	assertDomainError(t, err, domainerrors.ErrUnauthorized)
+
+	code := currentCode(t, enrollment.Secret)
+	codes, err := f.useCase.ConfirmEnrollment(context.Background(), 1, MFACodeInput{Code: code})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Errorf("esperava %d códigos de recuperação, obteve %d", recoveryCodeCount, len(codes))
+	}
+
+	// Com MFA ativo, o login retorna apenas o desafio
+	output := f.loginOutput(t)
+	if !output.MFARequired || output.Token != "" || output.ChallengeToken == "" {
+		t.Fatalf("login deveria exigir o segundo fator: %+v", output)
+	}
+
+	// O código usado na confirmação não pode ser reutilizado
+	_, err = f.useCase.VerifyChallenge(context.Background(), VerifyMFAInput{ChallengeToken: output.ChallengeToken, Code: code})
+	assertDomainError(t, err, domainerrors.ErrUnauthorized)
+
+	// Código de recuperação é aceito uma única vez
+	verified, err := f.useCase.VerifyChallenge(context.Background(), VerifyMFAInput{ChallengeToken: output.ChallengeToken, Code: codes[0]})
+	if err != nil || verified.Token == "" {
+		t.Fatalf("código de recuperação deveria ser aceito: %v", err)
+	}
+	_, err = f.useCase.VerifyChallenge(context.Background(), VerifyMFAInput{ChallengeToken: output.ChallengeToken, Code: codes[0]})
+	assertDomainError(t, err, domainerrors.ErrUnauthorized)
+
+	// O token de acesso não substitui o token de desafio
+	_, err = f.useCase.VerifyChallenge(context.Background(), VerifyMFAInput{ChallengeToken: verified.Token, Code: codes[1]})
+	assertDomainError(t, err, domainerrors.ErrInvalidToken)
+
+	// Desativação exige um código válido
+	assertDomainError(t, f.useCase.Disable(context.Background(), 1, MFACodeInput{Code: "000000"}), domainerrors.ErrUnauthorized)
+	if err := f.useCase.Disable(context.Background(), 1, MFACodeInput{Code: codes[1]}); err != nil {
+		t.Fatal(err)
+	}
+	if output := f.loginOutput(t); output.Token == "" {
+		t.Error("login deveria voltar a emitir o token após desativar o MFA")
+	}
+}
+
+func TestMFARequiredByRole(t *testing.T) {
+	f := newMFAFixture(t, true)
+
+	output := f.loginOutput(t)
+	if !output.MFAEnrollmentRequired || output.Token != "" {
+		t.Fatalf("papel deveria exigir o cadastro do segundo fator: %+v", output)
+	}
+
+	// O desafio de cadastro não serve para a verificação
+	_, err := f.useCase.VerifyChallenge(context.Background(), VerifyMFAInput{ChallengeToken: output.ChallengeToken, Code: "000000"})
+	assertDomainError(t, err, domainerrors.ErrInvalidToken)
+
+	enrollment, err := f.useCase.BeginChallengeEnrollment(MFAChallengeInput{ChallengeToken: output.ChallengeToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	completed, err := f.useCase.ConfirmChallengeEnrollment(context.Background(), VerifyMFAInput{
+		ChallengeToken: output.ChallengeToken,
+		Code:           currentCode(t, enrollment.Secret),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if completed.Token == "" || len(completed.RecoveryCodes) != recoveryCodeCount {
+		t.Errorf("cadastro deveria concluir o login com os códigos de recuperação: %+v", completed)
+	}
+
+	// O papel impede a desativação
+	assertDomainError(t, f.useCase.Disable(context.Background(), 1, MFACodeInput{Code: completed.RecoveryCodes[0]}), domainerrors.ErrForbidden)
+}
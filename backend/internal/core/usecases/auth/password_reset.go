@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+	"insidechurch/backend/internal/core/ports"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/passwordhash"
+	"github.com/insidechurch/passwordpolicy"
+)
+
+// passwordResetTTL é a validade do link de redefinição de senha
+const passwordResetTTL = 30 * time.Minute
+
+// PasswordResetUseCase implementa a solicitação e a confirmação da
+// redefinição de senha
+type PasswordResetUseCase struct {
+	userRepo       ports.UserRepository
+	tokenRepo      ports.UserTokenRepository
+	notifier       ports.Notifier
+	sessions       ports.SessionRevoker
+	passwordPolicy *passwordpolicy.Policy
+	passwordHasher *passwordhash.Hasher
+	resetURL       string
+	recorder       auditevent.Recorder
+}
+
+// NewPasswordResetUseCase cria uma nova instância do caso de uso de redefinição
+// de senha. resetURL é a página do frontend que recebe o token; solicitações e
+// trocas de senha são registradas em recorder.
+func NewPasswordResetUseCase(
+	userRepo ports.UserRepository,
+	tokenRepo ports.UserTokenRepository,
+	notifier ports.Notifier,
+	sessions ports.SessionRevoker,
+	passwordPolicy *passwordpolicy.Policy,
+	passwordHasher *passwordhash.Hasher,
+	resetURL string,
+	recorder auditevent.Recorder,
+) *PasswordResetUseCase {
+	return &PasswordResetUseCase{
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		notifier:       notifier,
+		sessions:       sessions,
+		passwordPolicy: passwordPolicy,
+		passwordHasher: passwordHasher,
+		resetURL:       resetURL,
+		recorder:       recorder,
+	}
+}
+
+// RequestReset envia o link de redefinição ao email informado. O retorno é o
+// mesmo exista ou não o usuário, para não revelar quais emails estão cadastrados.
+func (uc *PasswordResetUseCase) RequestReset(ctx context.Context, input RequestPasswordResetInput) error {
+	email := strings.TrimSpace(input.Email)
+	if email == "" {
+		return domainerrors.NewInvalidInput("email é obrigatório", nil)
+	}
+
+	user, err := uc.userRepo.FindByEmail(email)
+	if err != nil {
+		if isUserNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	// Apenas o link mais recente permanece válido
+	if err := uc.tokenRepo.InvalidateUser(user.ID, entities.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	token, userToken, err := newUserToken(user.ID, entities.TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+	if err := uc.tokenRepo.Create(userToken); err != nil {
+		return err
+	}
+
+	uc.recorder.Record(ctx, auditevent.Event{
+		Action: auditevent.PasswordResetRequested,
+		Target: auditUser(user.ID),
+	})
+
+	// Uma falha na entrega não é repassada ao cliente: a resposta precisa ser
+	// a mesma de um email não cadastrado. O adapter registra o erro.
+	_ = uc.notifier.Send(ports.Notification{
+		UserID:  strconv.FormatUint(uint64(user.ID), 10),
+		Channel: "email",
+		To:      user.Email,
+		Subject: "Redefinição de senha",
+		Message: fmt.Sprintf(
+			"Recebemos uma solicitação para redefinir sua senha. Acesse %s?token=%s em até %d minutos. Se não foi você, ignore esta mensagem.",
+			uc.resetURL, token, int(passwordResetTTL.Minutes()),
+		),
+		Sensitive: true,
+	})
+
+	return nil
+}
+
+// ConfirmReset define a nova senha a partir do token recebido por email e
+// encerra todas as sessões ativas do usuário
+func (uc *PasswordResetUseCase) ConfirmReset(ctx context.Context, input ConfirmPasswordResetInput) error {
+	if input.Token == "" {
+		return domainerrors.NewInvalidToken()
+	}
+
+	if err := validatePassword(uc.passwordPolicy, input.Password); err != nil {
+		return err
+	}
+
+	userToken, err := uc.tokenRepo.Consume(hashUserToken(input.Token), entities.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	user, err := uc.userRepo.FindByID(userToken.UserID)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := uc.passwordHasher.Hash(input.Password)
+	if err != nil {
+		return domainerrors.NewInternalError(err)
+	}
+
+	now := time.Now()
+	user.Password = hashedPassword
+	user.PasswordChangedAt = &now
+	if err := uc.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	uc.recorder.Record(ctx, auditevent.Event{
+		Action: auditevent.PasswordChanged,
+		UserID: auditUserID(user.ID),
+		Target: auditUser(user.ID),
+		Reason: auditevent.ReasonPasswordReset,
+	})
+
+	return uc.sessions.RevokeUserSessions(user.ID)
+}
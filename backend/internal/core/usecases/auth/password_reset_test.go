@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/passwordhash"
+	"github.com/insidechurch/passwordpolicy"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type passwordResetFixture struct {
+	useCase  *PasswordResetUseCase
+	users    *fakeUserRepo
+	tokens   *fakeTokenRepo
+	notifier *fakeNotifier
+	revoker  *fakeRevoker
+	recorder *fakeRecorder
+}
+
+func newPasswordResetFixture(t *testing.T) *passwordResetFixture {
+	t.Helper()
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("Antiga@123"), bcrypt.MinCost)
+	f := &passwordResetFixture{
+		users: &fakeUserRepo{users: map[uint]*entities.User{
+			1: {Model: gorm.Model{ID: 1}, Name: "Maria", Email: "maria@email.com", Password: string(hash)},
+		}},
+		tokens:   &fakeTokenRepo{},
+		notifier: &fakeNotifier{},
+		revoker:  &fakeRevoker{},
+		recorder: &fakeRecorder{},
+	}
+	f.useCase = NewPasswordResetUseCase(f.users, f.tokens, f.notifier, f.revoker, passwordpolicy.Default(), passwordhash.Default(), "https://app/reset", f.recorder)
+	return f
+}
+
+func assertDomainError(t *testing.T, err error, code string) {
+	t.Helper()
+	var domainErr *domainerrors.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != code {
+		t.Errorf("esperava erro %s, obteve %v", code, err)
+	}
+}
+
+func TestRequestResetUnknownEmail(t *testing.T) {
+	f := newPasswordResetFixture(t)
+
+	if err := f.useCase.RequestReset(context.Background(), RequestPasswordResetInput{Email: "ninguem@email.com"}); err != nil {
+		t.Fatalf("não deveria revelar email inexistente: %v", err)
+	}
+	if len(f.notifier.sent) != 0 || len(f.tokens.tokens) != 0 {
+		t.Error("não deveria emitir token para email inexistente")
+	}
+}
+
+func TestPasswordResetFlow(t *testing.T) {
+	f := newPasswordResetFixture(t)
+
+	if err := f.useCase.RequestReset(context.Background(), RequestPasswordResetInput{Email: "maria@email.com"}); err != nil {
+		t.Fatal(err)
+	}
+	token := f.notifier.lastToken(t)
+	if f.tokens.tokens[0].TokenHash == token {
+		t.Error("token não deveria ser armazenado em texto")
+	}
+	if f.notifier.sent[0].To != "maria@email.com" {
+		t.Errorf("destinatário inesperado: %s", f.notifier.sent[0].To)
+	}
+
+	// Senha fraca não consome o token
+	err := f.useCase.ConfirmReset(context.Background(), ConfirmPasswordResetInput{Token: token, Password: "fraca"})
+	assertDomainError(t, err, domainerrors.ErrInvalidInput)
+
+	if err := f.useCase.ConfirmReset(context.Background(), ConfirmPasswordResetInput{Token: token, Password: "Nova@1234"}); err != nil {
+		t.Fatal(err)
+	}
+	if passwordhash.Default().Verify("Nova@1234", f.users.users[1].Password) != nil {
+		t.Error("senha não foi atualizada")
+	}
+	if f.users.users[1].PasswordChangedAt == nil {
+		t.Error("data da troca de senha não foi registrada")
+	}
+	if len(f.revoker.revoked) != 1 || f.revoker.revoked[0] != 1 {
+		t.Errorf("sessões não foram revogadas: %v", f.revoker.revoked)
+	}
+	if actions := f.recorder.actions(); strings.Join(actions, ",") != "password.reset_requested,password.changed" {
+		t.Errorf("eventos de auditoria inesperados: %v", actions)
+	}
+	if changed := f.recorder.events[1]; changed.UserID != "1" || changed.Target != "user:1" || changed.Reason != auditevent.ReasonPasswordReset {
+		t.Errorf("troca de senha registrada incorretamente: %+v", changed)
+	}
+
+	// O token é de uso único
+	err = f.useCase.ConfirmReset(context.Background(), ConfirmPasswordResetInput{Token: token, Password: "Outra@1234"})
+	assertDomainError(t, err, domainerrors.ErrInvalidToken)
+}
+
+func TestPasswordResetTokenExpiredOrReplaced(t *testing.T) {
+	f := newPasswordResetFixture(t)
+
+	f.useCase.RequestReset(context.Background(), RequestPasswordResetInput{Email: "maria@email.com"})
+	first := f.notifier.lastToken(t)
+	f.useCase.RequestReset(context.Background(), RequestPasswordResetInput{Email: "maria@email.com"})
+	second := f.notifier.lastToken(t)
+
+	// Uma nova solicitação invalida o link anterior
+	err := f.useCase.ConfirmReset(context.Background(), ConfirmPasswordResetInput{Token: first, Password: "Nova@1234"})
+	assertDomainError(t, err, domainerrors.ErrInvalidToken)
+
+	f.tokens.tokens[1].ExpiresAt = time.Now().Add(-time.Minute)
+	err = f.useCase.ConfirmReset(context.Background(), ConfirmPasswordResetInput{Token: second, Password: "Nova@1234"})
+	assertDomainError(t, err, domainerrors.ErrInvalidToken)
+}
@@ -1,36 +1,51 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
 	"insidechurch/backend/internal/core/ports"
 
-	"golang.org/x/crypto/bcrypt"
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/passwordhash"
+	"github.com/insidechurch/passwordpolicy"
 )
 
 // RegisterUseCase implementa o caso de uso de registro
 type RegisterUseCase struct {
-	userRepo ports.UserRepository
+	userRepo       ports.UserRepository
+	verification   *EmailVerificationUseCase
+	passwordPolicy *passwordpolicy.Policy
+	passwordHasher *passwordhash.Hasher
+	recorder       auditevent.Recorder
 }
 
-// NewRegisterUseCase cria uma nova instância do caso de uso de registro
-func NewRegisterUseCase(userRepo ports.UserRepository) *RegisterUseCase {
+// NewRegisterUseCase cria uma nova instância do caso de uso de registro. A conta
+// é criada sem verificação e o link de confirmação é enviado por verification.
+// Os cadastros são registrados em recorder.
+func NewRegisterUseCase(userRepo ports.UserRepository, verification *EmailVerificationUseCase, passwordPolicy *passwordpolicy.Policy, passwordHasher *passwordhash.Hasher, recorder auditevent.Recorder) *RegisterUseCase {
 	return &RegisterUseCase{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		verification:   verification,
+		passwordPolicy: passwordPolicy,
+		passwordHasher: passwordHasher,
+		recorder:       recorder,
 	}
 }
 
 // Register executa o caso de uso de registro
-func (uc *RegisterUseCase) Register(input RegisterInput) error {
+func (uc *RegisterUseCase) Register(ctx context.Context, input RegisterInput) error {
 	// Validar senha
-	if err := uc.validatePassword(input.Password); err != nil {
+	if err := validatePassword(uc.passwordPolicy, input.Password); err != nil {
 		return err
 	}
 
 	// Verificar se o email já está em uso
 	existingUser, err := uc.userRepo.FindByEmail(input.Email)
-	if err != nil {
+	if err != nil && !isUserNotFound(err) {
 		return err
 	}
 
@@ -39,48 +54,50 @@ func (uc *RegisterUseCase) Register(input RegisterInput) error {
 	}
 
 	// Criar hash da senha
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	hashedPassword, err := uc.passwordHasher.Hash(input.Password)
 	if err != nil {
 		return err
 	}
 
 	// Criar usuário
+	now := time.Now()
 	user := &entities.User{
-		Name:     input.Name,
-		Email:    input.Email,
-		Password: string(hashedPassword),
+		Name:              input.Name,
+		Email:             input.Email,
+		Password:          hashedPassword,
+		PasswordChangedAt: &now,
 	}
 
-	return uc.userRepo.Create(user)
-}
-
-// validatePassword valida se a senha atende aos requisitos mínimos
-func (uc *RegisterUseCase) validatePassword(password string) error {
-	if len(password) < 8 {
-		return errors.New("a senha deve ter pelo menos 8 caracteres")
+	if err := uc.userRepo.Create(user); err != nil {
+		return err
 	}
 
-	hasUpper := false
-	hasLower := false
-	hasNumber := false
-	hasSpecial := false
+	uc.recorder.Record(ctx, auditevent.Event{
+		Action: auditevent.UserRegistered,
+		UserID: auditUserID(user.ID),
+		Target: auditUser(user.ID),
+	})
 
-	for _, char := range password {
-		switch {
-		case 'A' <= char && char <= 'Z':
-			hasUpper = true
-		case 'a' <= char && char <= 'z':
-			hasLower = true
-		case '0' <= char && char <= '9':
-			hasNumber = true
-		case char == '!' || char == '@' || char == '#' || char == '$' || char == '%' || char == '^' || char == '&' || char == '*':
-			hasSpecial = true
-		}
-	}
+	// O cadastro não depende da entrega: o link pode ser reenviado depois
+	_ = uc.verification.SendVerification(user)
+	return nil
+}
 
-	if !hasUpper || !hasLower || !hasNumber || !hasSpecial {
-		return errors.New("a senha deve conter pelo menos uma letra maiúscula, uma minúscula, um número e um caractere especial")
-	}
+// isUserNotFound indica se o erro do repositório corresponde a usuário inexistente
+func isUserNotFound(err error) bool {
+	var domainErr *domainerrors.DomainError
+	return errors.As(err, &domainErr) && domainErr.Code == domainerrors.ErrUserNotFound
+}
 
-	return nil
+// validatePassword aplica a política de senhas. As mesmas regras valem no
+// registro e na redefinição de senha; cada regra não atendida é listada em
+// details["violations"] para que o frontend as exiba separadamente.
+func validatePassword(policy *passwordpolicy.Policy, password string) error {
+	violations := policy.Validate(password)
+	if len(violations) == 0 {
+		return nil
+	}
+	return domainerrors.NewInvalidInput(passwordpolicy.Summary(violations), map[string]interface{}{
+		"violations": violations,
+	})
 }
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/insidechurch/keys"
+)
+
+// ErrLegacyToken indica um token sem kid recebido com a verificação HMAC
+// desativada
+var ErrLegacyToken = errors.New("token sem kid não é mais aceito")
+
+// TokenKeys assina os tokens emitidos pela API com as chaves do pacote keys,
+// incluindo o kid no cabeçalho, e verifica os recebidos. Durante a migração,
+// os tokens HS256 sem kid emitidos antes dela continuam válidos apenas se o
+// segredo legado for informado.
+type TokenKeys struct {
+	keySet       *keys.KeySet
+	legacySecret []byte
+}
+
+// NewTokenKeys cria uma nova instância do TokenKeys. legacySecret vazio
+// desativa a verificação dos tokens HS256 anteriores à migração.
+func NewTokenKeys(keySet *keys.KeySet, legacySecret string) *TokenKeys {
+	k := &TokenKeys{keySet: keySet}
+	if legacySecret != "" {
+		k.legacySecret = []byte(legacySecret)
+	}
+	return k
+}
+
+// Sign assina as claims com a chave atual
+func (k *TokenKeys) Sign(claims jwt.Claims) (string, error) {
+	return k.keySet.Sign(claims)
+}
+
+// Has informa se o token com o kid informado foi assinado por uma das chaves
+// da API
+func (k *TokenKeys) Has(kid string) bool {
+	return k.keySet.Has(kid)
+}
+
+// Parse valida a assinatura e a expiração do token, preenchendo claims
+func (k *TokenKeys) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	methods := k.keySet.Methods()
+	if k.legacySecret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, k.keyfunc, jwt.WithValidMethods(methods))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("token inválido")
+	}
+	return token, nil
+}
+
+// keyfunc escolhe a chave pelo cabeçalho: com kid, as chaves do conjunto;
+// sem kid, o segredo legado, se configurado
+func (k *TokenKeys) keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, hasKid := token.Header["kid"]; hasKid {
+		return k.keySet.Keyfunc(token)
+	}
+	if k.legacySecret == nil {
+		return nil, ErrLegacyToken
+	}
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, errors.New("método de assinatura inválido")
+	}
+	return k.legacySecret, nil
+}
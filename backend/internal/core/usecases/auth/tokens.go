@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"insidechurch/backend/internal/core/domain/entities"
+)
+
+// newUserToken gera um token aleatório de uso único. O valor em texto é
+// enviado ao usuário e apenas o hash é persistido.
+func newUserToken(userID uint, purpose string, ttl time.Duration) (string, *entities.UserToken, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, &entities.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashUserToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// hashUserToken calcula o hash usado para localizar o token persistido
+func hashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
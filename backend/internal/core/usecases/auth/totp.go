@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parâmetros TOTP (RFC 6238) compatíveis com os aplicativos autenticadores
+// mais comuns: HMAC-SHA1, 6 dígitos e intervalos de 30 segundos
+const (
+	totpDigits = 6
+	totpPeriod = 30
+	// totpSkew é o número de intervalos aceitos antes e depois do atual, para
+	// tolerar diferenças de relógio
+	totpSkew = 1
+	// totpSecretSize é o tamanho do segredo em bytes (160 bits, RFC 4226)
+	totpSecretSize = 20
+)
+
+// Códigos de recuperação: recoveryCodeCount códigos no formato xxxxx-xxxxx
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateTOTPSecret gera um novo segredo TOTP codificado em base32
+func generateTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// totpStep retorna o intervalo TOTP correspondente ao instante informado
+func totpStep(now time.Time) int64 {
+	return now.Unix() / totpPeriod
+}
+
+// totpCode calcula o código do intervalo informado (RFC 4226, seção 5.3)
+func totpCode(secret []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+// validateTOTP verifica o código contra o segredo dentro da janela de
+// tolerância. Intervalos iguais ou anteriores a lastStep são recusados, de
+// modo que um código aceito não pode ser reutilizado. Retorna o intervalo
+// aceito.
+func validateTOTP(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	current := totpStep(now)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpProvisioningURI monta a URI otpauth:// exibida como QR code para o
+// cadastro no aplicativo autenticador
+func totpProvisioningURI(issuer, account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
+
+// generateRecoveryCodes gera os códigos de recuperação em texto, exibidos uma
+// única vez ao usuário, e os hashes que são persistidos
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+
+	for i := range codes {
+		var b strings.Builder
+		for j := 0; j < recoveryCodeLength; j++ {
+			if j == recoveryCodeLength/2 {
+				b.WriteByte('-')
+			}
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, nil, err
+			}
+			b.WriteByte(recoveryCodeAlphabet[n.Int64()])
+		}
+		codes[i] = b.String()
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode normaliza o código digitado (caixa e espaços) e calcula o
+// hash usado para localizá-lo
+func hashRecoveryCode(code string) string {
+	return hashUserToken(strings.ToLower(strings.ReplaceAll(code, " ", "")))
+}
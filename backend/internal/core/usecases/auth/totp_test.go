@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Segredo e vetores SHA1 do apêndice B da RFC 6238, truncados em 6 dígitos
+func TestTOTPCodeRFC6238(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, want := range vectors {
+		if got := totpCode(secret, totpStep(time.Unix(unix, 0))); got != want {
+			t.Errorf("T=%d: esperava %s, obteve %s", unix, want, got)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111109, 0)
+
+	step, ok := validateTOTP(secret, "081804", now, 0)
+	if !ok || step != totpStep(now) {
+		t.Fatalf("código atual deveria ser aceito")
+	}
+
+	// Um intervalo de diferença no relógio é tolerado
+	if _, ok := validateTOTP(secret, "081804", now.Add(totpPeriod*time.Second), 0); !ok {
+		t.Error("código do intervalo anterior deveria ser aceito")
+	}
+	if _, ok := validateTOTP(secret, "081804", now.Add(2*totpPeriod*time.Second), 0); ok {
+		t.Error("código fora da janela deveria ser recusado")
+	}
+
+	// O mesmo código não é aceito duas vezes
+	if _, ok := validateTOTP(secret, "081804", now, step); ok {
+		t.Error("código já usado deveria ser recusado")
+	}
+
+	if _, ok := validateTOTP(secret, "000000", now, 0); ok {
+		t.Error("código incorreto deveria ser recusado")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret); err != nil || len(key) != totpSecretSize {
+		t.Fatalf("segredo inválido: %s", secret)
+	}
+
+	uri, err := url.Parse(totpProvisioningURI("InsideChurch", "maria@email.com", secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || !strings.HasSuffix(uri.Path, "InsideChurch:maria@email.com") {
+		t.Errorf("URI inesperada: %s", uri)
+	}
+	if uri.Query().Get("secret") != secret || uri.Query().Get("issuer") != "InsideChurch" {
+		t.Errorf("parâmetros inesperados: %s", uri.RawQuery)
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("esperava %d códigos, obteve %d", recoveryCodeCount, len(codes))
+	}
+
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		if len(code) != recoveryCodeLength+1 || code[recoveryCodeLength/2] != '-' {
+			t.Errorf("formato inesperado: %s", code)
+		}
+		if seen[code] {
+			t.Errorf("código repetido: %s", code)
+		}
+		seen[code] = true
+
+		// O código é aceito independente de caixa e espaços
+		if hashRecoveryCode(" "+strings.ToUpper(code)) != hashes[i] {
+			t.Errorf("hash não corresponde ao código %s", code)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+	"insidechurch/backend/internal/core/ports"
+
+	"github.com/insidechurch/auditevent"
+)
+
+// CreateMemberInput representa os dados do cadastro de um membro pela
+// administração
+type CreateMemberInput struct {
+	Name   string `json:"name" binding:"required"`
+	Email  string `json:"email" binding:"required,email"`
+	RoleID *uint  `json:"role_id"`
+}
+
+// UpdateMemberInput representa a alteração de um membro; os campos omitidos
+// são mantidos
+type UpdateMemberInput struct {
+	Name   *string `json:"name"`
+	Email  *string `json:"email" binding:"omitempty,email"`
+	RoleID *uint   `json:"role_id"`
+}
+
+// MemberUseCase implementa o cadastro, a alteração e a remoção de membros
+// pela administração
+type MemberUseCase struct {
+	userRepo ports.UserRepository
+	sessions ports.SessionRevoker
+	recorder auditevent.Recorder
+}
+
+// NewMemberUseCase cria uma nova instância do caso de uso de membros. As
+// sessões do membro removido são encerradas por sessions; cadastros,
+// alterações e remoções são registrados em recorder.
+func NewMemberUseCase(userRepo ports.UserRepository, sessions ports.SessionRevoker, recorder auditevent.Recorder) *MemberUseCase {
+	return &MemberUseCase{
+		userRepo: userRepo,
+		sessions: sessions,
+		recorder: recorder,
+	}
+}
+
+// Create cadastra o membro sem senha; o acesso é liberado quando ele define a
+// senha pela redefinição de senha
+func (uc *MemberUseCase) Create(ctx context.Context, input CreateMemberInput) (*entities.User, error) {
+	if err := uc.ensureEmailAvailable(input.Email, 0); err != nil {
+		return nil, err
+	}
+
+	member := &entities.User{
+		Name:   input.Name,
+		Email:  input.Email,
+		RoleID: input.RoleID,
+	}
+	if err := uc.userRepo.Create(member); err != nil {
+		return nil, err
+	}
+
+	uc.recorder.Record(ctx, auditevent.Event{
+		Action:  auditevent.MemberCreated,
+		Target:  memberTarget(member.ID),
+		Changes: auditevent.Diff(nil, member),
+	})
+	return member, nil
+}
+
+// Update altera os dados informados do membro
+func (uc *MemberUseCase) Update(ctx context.Context, id uint, input UpdateMemberInput) (*entities.User, error) {
+	member, err := uc.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *member
+
+	if input.Name != nil {
+		member.Name = *input.Name
+	}
+	if input.Email != nil && *input.Email != member.Email {
+		if err := uc.ensureEmailAvailable(*input.Email, member.ID); err != nil {
+			return nil, err
+		}
+		member.Email = *input.Email
+	}
+	if input.RoleID != nil {
+		member.RoleID = input.RoleID
+	}
+
+	if err := uc.userRepo.Update(member); err != nil {
+		return nil, err
+	}
+
+	// updated_at muda em toda gravação e não é registrado
+	after := *member
+	after.UpdatedAt = before.UpdatedAt
+	if changes := auditevent.Diff(&before, &after); len(changes) > 0 {
+		uc.recorder.Record(ctx, auditevent.Event{
+			Action:  auditevent.MemberUpdated,
+			Target:  memberTarget(id),
+			Changes: changes,
+		})
+	}
+	return member, nil
+}
+
+// Delete remove o membro e encerra as suas sessões
+func (uc *MemberUseCase) Delete(ctx context.Context, id uint) error {
+	member, err := uc.userRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	before := *member
+
+	if err := uc.userRepo.Delete(id); err != nil {
+		return err
+	}
+
+	uc.recorder.Record(ctx, auditevent.Event{
+		Action:  auditevent.MemberDeleted,
+		Target:  memberTarget(id),
+		Changes: auditevent.Diff(&before, nil),
+	})
+	return uc.sessions.RevokeUserSessions(id)
+}
+
+// ensureEmailAvailable recusa o email já usado por outro usuário que não exceptID
+func (uc *MemberUseCase) ensureEmailAvailable(email string, exceptID uint) error {
+	existing, err := uc.userRepo.FindByEmail(email)
+	if err != nil {
+		var domainErr *domainerrors.DomainError
+		if errors.As(err, &domainErr) && domainErr.Code == domainerrors.ErrUserNotFound {
+			return nil
+		}
+		return err
+	}
+	if existing != nil && existing.ID != exceptID {
+		return domainerrors.NewEmailAlreadyExists(email)
+	}
+	return nil
+}
+
+// memberTarget identifica o membro como alvo de um evento de auditoria
+func memberTarget(id uint) string {
+	return auditevent.Target(auditevent.TargetUser, strconv.FormatUint(uint64(id), 10))
+}
@@ -4,6 +4,7 @@ package entities
 type Role struct {
 	ID          uint         `gorm:"primaryKey"`
 	Name        string       `gorm:"not null;unique"`
+	MFARequired bool         `gorm:"not null;default:false"` // exige autenticação em dois fatores dos membros
 	Permissions []Permission `gorm:"many2many:role_permissions;"`
 }
 
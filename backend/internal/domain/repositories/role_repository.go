@@ -1,6 +1,13 @@
 package repositories
 
-import "insidechurch/backend/internal/domain/entities"
+import (
+	"errors"
+
+	"insidechurch/backend/internal/domain/entities"
+)
+
+// ErrRoleNotFound indica que o papel solicitado não existe
+var ErrRoleNotFound = errors.New("papel não encontrado")
 
 // RoleRepository define as operações de persistência para roles
 type RoleRepository interface {
@@ -1,27 +1,38 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"strconv"
 
 	"insidechurch/backend/internal/domain/entities"
 	"insidechurch/backend/internal/domain/repositories"
+
+	"github.com/insidechurch/auditevent"
 )
 
+// ErrRoleExists indica que já existe um papel com o nome informado
+var ErrRoleExists = errors.New("papel já existe")
+
 type RoleService struct {
 	roleRepo repositories.RoleRepository
+	recorder auditevent.Recorder
 }
 
-func NewRoleService(roleRepo repositories.RoleRepository) *RoleService {
+// NewRoleService cria uma nova instância do RoleService. As alterações nos
+// papéis e nas suas permissões são registradas em recorder.
+func NewRoleService(roleRepo repositories.RoleRepository, recorder auditevent.Recorder) *RoleService {
 	return &RoleService{
 		roleRepo: roleRepo,
+		recorder: recorder,
 	}
 }
 
 // CreateRole cria um novo papel
-func (s *RoleService) CreateRole(name string) (*entities.Role, error) {
+func (s *RoleService) CreateRole(ctx context.Context, name string) (*entities.Role, error) {
 	existingRole, _ := s.roleRepo.FindByName(name)
 	if existingRole != nil {
-		return nil, errors.New("papel já existe")
+		return nil, ErrRoleExists
 	}
 
 	role := entities.NewRole(name)
@@ -30,29 +41,52 @@ func (s *RoleService) CreateRole(name string) (*entities.Role, error) {
 		return nil, err
 	}
 
+	s.recorder.Record(ctx, auditevent.Event{
+		Action:  auditevent.RoleCreated,
+		Target:  roleTarget(role.ID),
+		Changes: auditevent.Diff(nil, role),
+	})
 	return role, nil
 }
 
 // AssignPermission atribui uma permissão a um papel
-func (s *RoleService) AssignPermission(roleID uint, resource, action string) error {
+func (s *RoleService) AssignPermission(ctx context.Context, roleID uint, resource, action string) error {
 	_, err := s.roleRepo.FindByID(roleID)
 	if err != nil {
 		return err
 	}
 
 	permission := entities.NewPermission(resource, action)
-	return s.roleRepo.AddPermission(roleID, permission)
+	if err := s.roleRepo.AddPermission(roleID, permission); err != nil {
+		return err
+	}
+
+	s.recorder.Record(ctx, auditevent.Event{
+		Action:  auditevent.RolePermissionGranted,
+		Target:  roleTarget(roleID),
+		Changes: []auditevent.Change{{Field: "permissions", After: permission.String()}},
+	})
+	return nil
 }
 
 // RemovePermission remove uma permissão de um papel
-func (s *RoleService) RemovePermission(roleID uint, resource, action string) error {
+func (s *RoleService) RemovePermission(ctx context.Context, roleID uint, resource, action string) error {
 	_, err := s.roleRepo.FindByID(roleID)
 	if err != nil {
 		return err
 	}
 
 	permission := entities.NewPermission(resource, action)
-	return s.roleRepo.RemovePermission(roleID, permission)
+	if err := s.roleRepo.RemovePermission(roleID, permission); err != nil {
+		return err
+	}
+
+	s.recorder.Record(ctx, auditevent.Event{
+		Action:  auditevent.RolePermissionRevoked,
+		Target:  roleTarget(roleID),
+		Changes: []auditevent.Change{{Field: "permissions", Before: permission.String()}},
+	})
+	return nil
 }
 
 // CheckPermission verifica se um papel tem uma determinada permissão
@@ -66,6 +100,40 @@ func (s *RoleService) CheckPermission(roleID uint, resource, action string) (boo
 	return role.HasPermission(permission), nil
 }
 
+// SetMFARequired define se os usuários do papel são obrigados a usar
+// autenticação em dois fatores
+func (s *RoleService) SetMFARequired(ctx context.Context, roleID uint, required bool) error {
+	role, err := s.roleRepo.FindByID(roleID)
+	if err != nil {
+		return err
+	}
+
+	before := *role
+	role.MFARequired = required
+	if err := s.roleRepo.Update(role); err != nil {
+		return err
+	}
+
+	if changes := auditevent.Diff(&before, role); len(changes) > 0 {
+		s.recorder.Record(ctx, auditevent.Event{
+			Action:  auditevent.RoleUpdated,
+			Target:  roleTarget(roleID),
+			Changes: changes,
+		})
+	}
+	return nil
+}
+
+// RequiresMFA verifica se o papel exige autenticação em dois fatores
+func (s *RoleService) RequiresMFA(roleID uint) (bool, error) {
+	role, err := s.roleRepo.FindByID(roleID)
+	if err != nil {
+		return false, err
+	}
+
+	return role.MFARequired, nil
+}
+
 // ListRoles retorna todos os papéis
 func (s *RoleService) ListRoles() ([]entities.Role, error) {
 	return s.roleRepo.List()
@@ -77,6 +145,24 @@ func (s *RoleService) GetRole(id uint) (*entities.Role, error) {
 }
 
 // DeleteRole remove um papel
-func (s *RoleService) DeleteRole(id uint) error {
-	return s.roleRepo.Delete(id)
+func (s *RoleService) DeleteRole(ctx context.Context, id uint) error {
+	role, err := s.roleRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.roleRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.recorder.Record(ctx, auditevent.Event{
+		Action:  auditevent.RoleDeleted,
+		Target:  roleTarget(id),
+		Changes: auditevent.Diff(role, nil),
+	})
+	return nil
+}
+
+// roleTarget identifica o papel como alvo de um evento de auditoria
+func roleTarget(id uint) string {
+	return auditevent.Target(auditevent.TargetRole, strconv.FormatUint(uint64(id), 10))
 }
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"insidechurch/backend/internal/core/domain"
+	domainerrors "insidechurch/backend/internal/core/errors"
 	"insidechurch/backend/internal/core/interfaces"
 	"insidechurch/backend/internal/services"
 
@@ -12,7 +14,8 @@ import (
 
 // ErrorResponse representa uma resposta de erro
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error   string                 `json:"error"`
+	Details map[string]interface{} `json:"details,omitempty"`
 }
 
 type UserHandler struct {
@@ -94,7 +97,12 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 	// Validar senha
 	if err := h.authService.ValidatePassword(req.Password); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		response := ErrorResponse{Error: err.Error()}
+		var domainErr *domainerrors.DomainError
+		if errors.As(err, &domainErr) {
+			response = ErrorResponse{Error: domainErr.Message, Details: domainErr.Details}
+		}
+		c.JSON(http.StatusBadRequest, response)
 		return
 	}
 
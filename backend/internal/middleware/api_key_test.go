@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+	"insidechurch/backend/internal/core/usecases/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeAPIKeyRepo guarda as chaves indexadas pelo hash
+type fakeAPIKeyRepo struct {
+	keys map[string]*entities.APIKey
+}
+
+func (r *fakeAPIKeyRepo) FindActive(keyHash string) (*entities.APIKey, error) {
+	if key, ok := r.keys[keyHash]; ok {
+		return key, nil
+	}
+	return nil, domainerrors.NewInvalidToken()
+}
+
+func (r *fakeAPIKeyRepo) Touch(id uint) error {
+	return nil
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAuthenticateWithAPIKey(t *testing.T) {
+	repo := &fakeAPIKeyRepo{keys: map[string]*entities.APIKey{
+		hashKey("ick_quiosque"): {ID: 1, UserID: 9, Scopes: []string{"members:read"}},
+	}}
+	m := NewAuthMiddleware(nil, auth.NewAPIKeyUseCase(repo), nil, nil)
+
+	code, userID := authenticate(m, "ick_quiosque")
+	if code != http.StatusOK || userID != uint(9) {
+		t.Fatalf("Esperava 200 e userID 9, obteve %d e %v", code, userID)
+	}
+	if code, _ := authenticate(m, "ick_desconhecida"); code != http.StatusUnauthorized {
+		t.Errorf("Chave desconhecida deveria ser recusada, obteve %d", code)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/members", m.Authenticate(), m.RequireScope("members:read"), ok)
+	router.DELETE("/members", m.Authenticate(), m.RequireScope("members:delete"), ok)
+	router.POST("/mfa", m.Authenticate(), m.RequireSession(), ok)
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/members", http.StatusOK},
+		{http.MethodDelete, "/members", http.StatusForbidden},
+		{http.MethodPost, "/mfa", http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer ick_quiosque")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s %s: esperava %d, obteve %d", tc.method, tc.path, tc.want, rec.Code)
+		}
+	}
+}
+
+func TestRequireRouteScopes(t *testing.T) {
+	repo := &fakeAPIKeyRepo{keys: map[string]*entities.APIKey{
+		hashKey("ick_quiosque"): {ID: 1, UserID: 9, Scopes: []string{"members:read"}},
+	}}
+	m := NewAuthMiddleware(nil, auth.NewAPIKeyUseCase(repo), nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(m.Authenticate(), m.RequireRouteScopes(map[string]string{
+		"GET /members/:id":    "members:read",
+		"DELETE /members/:id": "members:delete",
+	}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/members/:id", ok)
+	router.DELETE("/members/:id", ok)
+	router.POST("/members/:id/notes", ok)
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/members/1", http.StatusOK},
+		{http.MethodDelete, "/members/1", http.StatusForbidden},
+		// Rotas sem escopo declarado recusam chaves de API
+		{http.MethodPost, "/members/1/notes", http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer ick_quiosque")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s %s: esperava %d, obteve %d", tc.method, tc.path, tc.want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/insidechurch/auditevent"
+)
+
+// AuditOrigin registra no contexto da requisição a origem usada nos eventos
+// de auditoria: IP, User-Agent e o ID de correlação recebido em X-Request-ID
+// ou gerado aqui, devolvido no mesmo header. O usuário é acrescentado por
+// Authenticate.
+func AuditOrigin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		correlationID := auditevent.CorrelationID(c.GetHeader(auditevent.CorrelationHeader))
+		c.Header(auditevent.CorrelationHeader, correlationID)
+
+		ctx := auditevent.WithOrigin(c.Request.Context(), auditevent.Origin{
+			IP:            c.ClientIP(),
+			UserAgent:     c.Request.UserAgent(),
+			CorrelationID: correlationID,
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"insidechurch/backend/internal/core/domain/entities"
+	"insidechurch/backend/internal/core/usecases/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/insidechurch/auditevent"
+)
+
+func TestAuditOriginWithIdentity(t *testing.T) {
+	repo := &fakeAPIKeyRepo{keys: map[string]*entities.APIKey{
+		hashKey("ick_quiosque"): {ID: 1, UserID: 9, Scopes: []string{"members:read"}},
+	}}
+	m := NewAuthMiddleware(nil, auth.NewAPIKeyUseCase(repo), nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AuditOrigin())
+
+	var origin auditevent.Origin
+	router.GET("/members", m.Authenticate(), func(c *gin.Context) {
+		origin = auditevent.OriginFrom(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/members", nil)
+	req.Header.Set("Authorization", "Bearer ick_quiosque")
+	req.Header.Set("User-Agent", "quiosque/1.0")
+	req.Header.Set(auditevent.CorrelationHeader, "req-42")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Header().Get(auditevent.CorrelationHeader) != "req-42" {
+		t.Errorf("ID de correlação deveria ser devolvido, obteve %q", rec.Header().Get(auditevent.CorrelationHeader))
+	}
+	want := auditevent.Origin{UserID: "9", IP: "192.0.2.1", UserAgent: "quiosque/1.0", CorrelationID: "req-42"}
+	if origin != want {
+		t.Errorf("Origem inesperada: %+v", origin)
+	}
+}
@@ -1,22 +1,44 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"insidechurch/backend/internal/core/domain/entities"
+	"insidechurch/backend/internal/core/ports"
 	"insidechurch/backend/internal/core/usecases/auth"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/insidechurch/auditevent"
+	"github.com/sirupsen/logrus"
 )
 
 type AuthMiddleware struct {
-	loginUseCase *auth.LoginUseCase
+	loginUseCase  *auth.LoginUseCase
+	apiKeyUseCase *auth.APIKeyUseCase
+	jwks          *JWKSClient
+	revocations   ports.TokenRevocations
 }
 
-func NewAuthMiddleware(loginUseCase *auth.LoginUseCase) *AuthMiddleware {
+// NewAuthMiddleware cria o middleware de autenticação. Chaves de API são
+// autenticadas pelo APIKeyUseCase; tokens assinados pela API seguem para o
+// LoginUseCase e os demais são verificados pelas chaves públicas do
+// auth-service (jwks).
+// Tokens emitidos antes do encerramento das sessões do usuário são recusados
+// conforme revocations. jwks e revocations podem ser nil quando o
+// auth-service e o cache compartilhado não são usados.
+func NewAuthMiddleware(loginUseCase *auth.LoginUseCase, apiKeyUseCase *auth.APIKeyUseCase, jwks *JWKSClient, revocations ports.TokenRevocations) *AuthMiddleware {
 	return &AuthMiddleware{
-		loginUseCase: loginUseCase,
+		loginUseCase:  loginUseCase,
+		apiKeyUseCase: apiKeyUseCase,
+		jwks:          jwks,
+		revocations:   revocations,
 	}
 }
 
@@ -38,8 +60,25 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
+		// Chaves de API identificam o dono da chave; os escopos ficam no
+		// contexto para RequireScope e RequireRouteScopes
+		if auth.IsAPIKey(parts[1]) {
+			apiKey, err := m.apiKeyUseCase.Authenticate(parts[1])
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "token inválido"})
+				c.Abort()
+				return
+			}
+
+			c.Set("userID", apiKey.UserID)
+			c.Set("apiKey", apiKey)
+			setAuditIdentity(c, apiKey.UserID, 0)
+			c.Next()
+			return
+		}
+
 		// Validar o token
-		token, err := m.loginUseCase.ValidateToken(parts[1])
+		token, err := m.validateToken(parts[1])
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "token inválido"})
 			c.Abort()
@@ -54,16 +93,203 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
+		// Apenas tokens de acesso e de personificação autorizam requisições;
+		// refresh tokens e tokens de desafio MFA são recusados
+		tokenType, _ := claims["type"].(string)
+		if tokenType != "" && tokenType != "access" && tokenType != "impersonation" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token inválido"})
+			c.Abort()
+			return
+		}
+
+		// Tokens emitidos pelo auth-service a clientes OpenID Connect trazem o
+		// client_id em aud e valem apenas no userinfo dele
+		if _, exists := claims["aud"]; exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token inválido"})
+			c.Abort()
+			return
+		}
+
 		// Extrair o ID do usuário das claims
-		userID, ok := claims["sub"].(float64)
+		userID, ok := userIDFromClaims(claims)
 		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "ID do usuário inválido"})
 			c.Abort()
 			return
 		}
 
+		// Tokens revogados no logout do auth-service (jti), de uma sessão
+		// encerrada em DELETE /auth/sessions/{id} (sid) ou emitidos até o
+		// encerramento das sessões do usuário, como na redefinição de senha ou
+		// no logout-all, são recusados; na falha da consulta o token também é
+		// recusado
+		if m.revocations != nil {
+			tokenID, _ := claims["jti"].(string)
+			sessionID, _ := claims["sid"].(string)
+			revoked, err := m.revocations.IsRevoked(tokenID, sessionID, userID, issuedAt(claims))
+			if err != nil {
+				logrus.Errorf("Erro ao consultar tokens revogados: %v", err)
+			}
+			if err != nil || revoked {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "token inválido"})
+				c.Abort()
+				return
+			}
+		}
+
+		// Na personificação, o token age em nome de userID e actorID identifica
+		// o administrador
+		var actorID uint
+		if tokenType == "impersonation" {
+			id, err := strconv.ParseUint(fmt.Sprint(claims["actor_id"]), 10, 64)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "token inválido"})
+				c.Abort()
+				return
+			}
+			actorID = uint(id)
+			c.Set("actorID", actorID)
+		}
+
 		// Adicionar o ID do usuário ao contexto
-		c.Set("userID", uint(userID))
+		c.Set("userID", userID)
+		setAuditIdentity(c, userID, actorID)
+		c.Next()
+	}
+}
+
+// setAuditIdentity acrescenta o usuário autenticado e, na personificação, o
+// administrador à origem dos eventos de auditoria da requisição
+func setAuditIdentity(c *gin.Context, userID, actorID uint) {
+	var actor string
+	if actorID != 0 {
+		actor = strconv.FormatUint(uint64(actorID), 10)
+	}
+	ctx := auditevent.WithIdentity(c.Request.Context(), strconv.FormatUint(uint64(userID), 10), actor)
+	c.Request = c.Request.WithContext(ctx)
+}
+
+// RequireScope restringe a rota às chaves de API com o escopo informado, no
+// formato de entities.Permission.String(). Requisições com JWT não são
+// afetadas. Deve ser usado após Authenticate.
+func (m *AuthMiddleware) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if value, exists := c.Get("apiKey"); exists {
+			if apiKey, ok := value.(*entities.APIKey); !ok || !apiKey.HasScope(scope) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "escopo insuficiente: " + scope})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequireRouteScopes restringe as chaves de API às rotas de scopes, indexado
+// pelo método e pelo template da rota, como "GET /api/users/me", com o escopo
+// exigido em cada uma. Chaves de API são recusadas nas rotas fora de scopes,
+// de modo que uma rota nova não fica aberta a qualquer chave. Requisições com
+// JWT não são afetadas. Deve ser usado após Authenticate.
+func (m *AuthMiddleware) RequireRouteScopes(scopes map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("apiKey")
+		if !exists {
+			c.Next()
+			return
+		}
+
+		scope, declared := scopes[c.Request.Method+" "+c.FullPath()]
+		if !declared {
+			c.JSON(http.StatusForbidden, gin.H{"error": "rota indisponível para chaves de API"})
+			c.Abort()
+			return
+		}
+		if apiKey, ok := value.(*entities.APIKey); !ok || !apiKey.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "escopo insuficiente: " + scope})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession restringe a rota a sessões do próprio usuário, recusando
+// chaves de API e tokens de personificação. Protege ações sensíveis, como a
+// troca de senha, a autenticação em dois fatores e a edição de ofertas. Deve
+// ser usado após Authenticate.
+func (m *AuthMiddleware) RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("apiKey"); exists {
+			c.JSON(http.StatusForbidden, gin.H{"error": "rota indisponível para chaves de API"})
+			c.Abort()
+			return
+		}
+		if _, exists := c.Get("actorID"); exists {
+			c.JSON(http.StatusForbidden, gin.H{"error": "ação não permitida durante a personificação"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
+
+// validateToken escolhe o verificador pelo kid do cabeçalho: as chaves da
+// própria API, pelo LoginUseCase, ou as publicadas no JWKS do auth-service.
+// Tokens sem kid seguem para o LoginUseCase, que só os aceita durante a
+// migração dos tokens HS256.
+func (m *AuthMiddleware) validateToken(tokenString string) (*jwt.Token, error) {
+	unverified, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := unverified.Header["kid"].(string)
+	if m.loginUseCase != nil && (kid == "" || m.loginUseCase.IssuedWith(kid)) {
+		return m.loginUseCase.ValidateToken(tokenString)
+	}
+
+	if kid == "" || m.jwks == nil {
+		return nil, ErrUnknownKeyID
+	}
+
+	token, err := jwt.Parse(tokenString, m.jwks.Keyfunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("token inválido")
+	}
+	return token, nil
+}
+
+// issuedAt lê o claim iat com a precisão de milissegundos do corte de tokens
+// revogados; tokens sem iat retornam o instante zero
+func issuedAt(claims jwt.MapClaims) time.Time {
+	iat, ok := claims["iat"].(float64)
+	if !ok {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(math.Round(iat * 1000)))
+}
+
+// userIDFromClaims extrai o ID do usuário do claim sub (numérico nos tokens
+// da API) ou user_id (texto nos tokens do auth-service)
+func userIDFromClaims(claims jwt.MapClaims) (uint, bool) {
+	switch sub := claims["sub"].(type) {
+	case float64:
+		return uint(sub), true
+	case string:
+		if id, err := strconv.ParseUint(sub, 10, 64); err == nil {
+			return uint(id), true
+		}
+	}
+
+	if userID, ok := claims["user_id"].(string); ok {
+		if id, err := strconv.ParseUint(userID, 10, 64); err == nil {
+			return uint(id), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKeyID = errors.New("kid não encontrado no JWKS")
+)
+
+// jwk representa uma chave pública publicada pelo auth-service
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+// jwksKey é uma chave pública já decodificada
+type jwksKey struct {
+	alg    string
+	public crypto.PublicKey
+}
+
+// JWKSClient busca e mantém em cache as chaves públicas do auth-service. Um
+// kid desconhecido força uma nova busca, de modo que chaves rotacionadas são
+// aceitas sem reiniciar o serviço.
+type JWKSClient struct {
+	url        string
+	httpClient *http.Client
+	ttl        time.Duration
+	minRefresh time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]jwksKey
+	fetchedAt time.Time
+}
+
+// NewJWKSClient cria uma nova instância do JWKSClient para a URL informada
+func NewJWKSClient(url string) *JWKSClient {
+	return &JWKSClient{
+		url:        url,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		ttl:        5 * time.Minute,
+		minRefresh: 10 * time.Second,
+		keys:       make(map[string]jwksKey),
+	}
+}
+
+// Keyfunc localiza a chave pública pelo kid do token, para uso com jwt.Parse
+func (c *JWKSClient) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrUnknownKeyID
+	}
+
+	key, err := c.key(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.alg {
+		return nil, errors.New("algoritmo do token não corresponde à chave")
+	}
+	return key.public, nil
+}
+
+// key retorna a chave do cache, buscando o JWKS novamente quando o cache
+// expirou ou o kid é desconhecido
+func (c *JWKSClient) key(kid string) (jwksKey, error) {
+	c.mu.RLock()
+	key, exists := c.keys[kid]
+	fresh := time.Since(c.fetchedAt) < c.ttl
+	recent := time.Since(c.fetchedAt) < c.minRefresh
+	c.mu.RUnlock()
+
+	if exists && fresh {
+		return key, nil
+	}
+
+	// Evita que tokens com kid inválido provoquem uma busca por requisição
+	if !exists && recent {
+		return jwksKey{}, ErrUnknownKeyID
+	}
+
+	if err := c.refresh(); err != nil {
+		// Mantém a chave conhecida se o auth-service estiver indisponível
+		if exists {
+			return key, nil
+		}
+		return jwksKey{}, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, exists = c.keys[kid]
+	if !exists {
+		return jwksKey{}, ErrUnknownKeyID
+	}
+	return key, nil
+}
+
+// refresh busca o JWKS e substitui as chaves em cache
+func (c *JWKSClient) refresh() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Outra requisição pode ter atualizado o cache enquanto esta aguardava
+	if time.Since(c.fetchedAt) < c.minRefresh {
+		return nil
+	}
+
+	resp, err := c.httpClient.Get(c.url)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("erro ao buscar JWKS: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("erro ao decodificar JWKS: %w", err)
+	}
+
+	keys := make(map[string]jwksKey, len(set.Keys))
+	for _, k := range set.Keys {
+		key, err := k.decode()
+		if err != nil {
+			// Chaves de tipos não suportados são ignoradas
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	return nil
+}
+
+// decode converte a JWK em chave pública
+func (k jwk) decode() (jwksKey, error) {
+	switch {
+	case k.Kty == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return jwksKey{}, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return jwksKey{}, err
+		}
+		return jwksKey{
+			alg: jwt.SigningMethodRS256.Alg(),
+			public: &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			},
+		}, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return jwksKey{}, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return jwksKey{}, errors.New("chave Ed25519 inválida")
+		}
+		return jwksKey{
+			alg:    jwt.SigningMethodEdDSA.Alg(),
+			public: ed25519.PublicKey(x),
+		}, nil
+	}
+	return jwksKey{}, fmt.Errorf("tipo de chave não suportado: %s", k.Kty)
+}
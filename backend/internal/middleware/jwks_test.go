@@ -0,0 +1,351 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"insidechurch/backend/internal/adapters/sessions"
+	"insidechurch/backend/internal/core/usecases/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/cache"
+	"github.com/insidechurch/denylist"
+	"github.com/insidechurch/keys"
+	"github.com/insidechurch/passwordhash"
+)
+
+// jwksServer simula o endpoint /.well-known/jwks.json do auth-service
+type jwksServer struct {
+	mu       sync.Mutex
+	keys     map[string]ed25519.PublicKey
+	requests int
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	for kid, public := range s.keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			Kid: kid,
+			Alg: "EdDSA",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		})
+	}
+	json.NewEncoder(w).Encode(set)
+}
+
+func (s *jwksServer) publish(kid string, public ed25519.PublicKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[kid] = public
+}
+
+func signEdDSA(t *testing.T, kid string, private ed25519.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func authenticate(m *AuthMiddleware, token string) (int, interface{}) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var userID interface{}
+	router.GET("/", m.Authenticate(), func(c *gin.Context) {
+		userID, _ = c.Get("userID")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code, userID
+}
+
+func TestAuthenticateWithJWKS(t *testing.T) {
+	server := &jwksServer{keys: make(map[string]ed25519.PublicKey)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	server.publish("chave-1", public)
+
+	jwks := NewJWKSClient(ts.URL)
+	jwks.minRefresh = 0
+	m := NewAuthMiddleware(nil, nil, jwks, nil)
+
+	exp := time.Now().Add(time.Minute).Unix()
+	code, userID := authenticate(m, signEdDSA(t, "chave-1", private, jwt.MapClaims{
+		"user_id": "42", "type": "access", "exp": exp,
+	}))
+	if code != http.StatusOK || userID != uint(42) {
+		t.Fatalf("Esperava 200 e userID 42, obteve %d e %v", code, userID)
+	}
+
+	// Refresh tokens não autorizam requisições
+	code, _ = authenticate(m, signEdDSA(t, "chave-1", private, jwt.MapClaims{
+		"user_id": "42", "type": "refresh", "exp": exp,
+	}))
+	if code != http.StatusUnauthorized {
+		t.Errorf("Refresh token deveria ser recusado, obteve %d", code)
+	}
+
+	// Tokens emitidos a clientes OpenID Connect não autorizam requisições
+	code, _ = authenticate(m, signEdDSA(t, "chave-1", private, jwt.MapClaims{
+		"user_id": "42", "type": "access", "exp": exp, "aud": "wiki", "scope": "openid",
+	}))
+	if code != http.StatusUnauthorized {
+		t.Errorf("Token de cliente deveria ser recusado, obteve %d", code)
+	}
+
+	// Após a rotação, o kid novo força uma nova busca do JWKS
+	rotatedPublic, rotatedPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	server.publish("chave-2", rotatedPublic)
+
+	code, _ = authenticate(m, signEdDSA(t, "chave-2", rotatedPrivate, jwt.MapClaims{
+		"user_id": "42", "type": "access", "exp": exp,
+	}))
+	if code != http.StatusOK {
+		t.Errorf("Token da chave rotacionada deveria ser aceito, obteve %d", code)
+	}
+
+	// Assinatura com chave não publicada é recusada
+	_, unknown, _ := ed25519.GenerateKey(rand.Reader)
+	code, _ = authenticate(m, signEdDSA(t, "chave-1", unknown, jwt.MapClaims{
+		"user_id": "42", "type": "access", "exp": exp,
+	}))
+	if code != http.StatusUnauthorized {
+		t.Errorf("Token com assinatura inválida deveria ser recusado, obteve %d", code)
+	}
+}
+
+func TestAuthenticateRoutesByKeyID(t *testing.T) {
+	server := &jwksServer{keys: make(map[string]ed25519.PublicKey)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	server.publish("chave-1", public)
+
+	keySet, err := keys.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newMiddleware := func(legacySecret string) *AuthMiddleware {
+		login := auth.NewLoginUseCase(nil, auth.UnverifiedLoginAllow, nil, nil, passwordhash.Default(), auditevent.Discard, auth.NewTokenKeys(keySet, legacySecret), nil)
+		return NewAuthMiddleware(login, nil, NewJWKSClient(ts.URL), nil)
+	}
+	m := newMiddleware("")
+
+	exp := time.Now().Add(time.Minute).Unix()
+	local, _ := keySet.Sign(jwt.MapClaims{"sub": 7, "exp": exp})
+	if code, userID := authenticate(m, local); code != http.StatusOK || userID != uint(7) {
+		t.Fatalf("Token da API deveria ser aceito, obteve %d e %v", code, userID)
+	}
+
+	remote := signEdDSA(t, "chave-1", private, jwt.MapClaims{"user_id": "42", "type": "access", "exp": exp})
+	if code, userID := authenticate(m, remote); code != http.StatusOK || userID != uint(42) {
+		t.Fatalf("Token do auth-service deveria ser aceito, obteve %d e %v", code, userID)
+	}
+
+	// Tokens HS256 sem kid só valem com JWT_HMAC_FALLBACK
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": 7, "exp": exp}).SignedString([]byte("segredo-antigo"))
+	if code, _ := authenticate(m, legacy); code != http.StatusUnauthorized {
+		t.Errorf("Token HS256 deveria ser recusado sem JWT_HMAC_FALLBACK, obteve %d", code)
+	}
+	if code, _ := authenticate(newMiddleware("segredo-antigo"), legacy); code != http.StatusOK {
+		t.Errorf("Token HS256 deveria ser aceito durante a migração, obteve %d", code)
+	}
+}
+
+// cutoffRevocations recusa os tokens emitidos até cutoff
+type cutoffRevocations struct {
+	cutoff time.Time
+}
+
+func (r cutoffRevocations) IsRevoked(tokenID, sessionID string, userID uint, issuedAt time.Time) (bool, error) {
+	return !issuedAt.After(r.cutoff), nil
+}
+
+func TestAuthenticateRejectsRevokedTokens(t *testing.T) {
+	server := &jwksServer{keys: make(map[string]ed25519.PublicKey)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	server.publish("chave-1", public)
+
+	cutoff := time.Now()
+	m := NewAuthMiddleware(nil, nil, NewJWKSClient(ts.URL), cutoffRevocations{cutoff: cutoff})
+	exp := time.Now().Add(time.Minute).Unix()
+
+	code, _ := authenticate(m, signEdDSA(t, "chave-1", private, jwt.MapClaims{
+		"user_id": "42", "type": "access", "exp": exp, "iat": float64(cutoff.Add(-time.Second).Unix()),
+	}))
+	if code != http.StatusUnauthorized {
+		t.Errorf("Token emitido antes do encerramento das sessões deveria ser recusado, obteve %d", code)
+	}
+
+	// O iat em milissegundos distingue os tokens emitidos logo após o corte
+	issued := float64(cutoff.Add(5*time.Millisecond).UnixMilli()) / 1000
+	code, _ = authenticate(m, signEdDSA(t, "chave-1", private, jwt.MapClaims{
+		"user_id": "42", "type": "access", "exp": exp, "iat": issued,
+	}))
+	if code != http.StatusOK {
+		t.Errorf("Token emitido após o corte deveria ser aceito, obteve %d", code)
+	}
+}
+
+func TestAuthenticateRejectsTokensRevokedByAuthService(t *testing.T) {
+	server := &jwksServer{keys: make(map[string]ed25519.PublicKey)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	server.publish("chave-1", public)
+
+	// O Redis compartilhado: o auth-service grava a denylist e a API a consulta
+	sharedCache := cache.NewMemoryCache()
+	m := NewAuthMiddleware(nil, nil, NewJWKSClient(ts.URL), sessions.NewRevoker(nil, sharedCache))
+	expiresAt := time.Now().Add(time.Minute)
+	token := func(jti string) string {
+		return signEdDSA(t, "chave-1", private, jwt.MapClaims{
+			"user_id": "42", "type": "access", "exp": expiresAt.Unix(), "jti": jti, "sid": "sessao-1",
+		})
+	}
+
+	if code, _ := authenticate(m, token("jti-1")); code != http.StatusOK {
+		t.Fatalf("Token ainda não revogado deveria ser aceito, obteve %d", code)
+	}
+
+	// Logout no auth-service revoga o jti do access token
+	if err := denylist.New(sharedCache).RevokeToken(context.Background(), "jti-1", expiresAt); err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := authenticate(m, token("jti-1")); code != http.StatusUnauthorized {
+		t.Errorf("Token revogado no logout deveria ser recusado, obteve %d", code)
+	}
+	if code, _ := authenticate(m, token("jti-2")); code != http.StatusOK {
+		t.Errorf("Outro token do usuário não deveria ser afetado, obteve %d", code)
+	}
+}
+
+func TestAuthenticateRejectsTokensOfRevokedSession(t *testing.T) {
+	server := &jwksServer{keys: make(map[string]ed25519.PublicKey)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	server.publish("chave-1", public)
+
+	sharedCache := cache.NewMemoryCache()
+	m := NewAuthMiddleware(nil, nil, NewJWKSClient(ts.URL), sessions.NewRevoker(nil, sharedCache))
+	exp := time.Now().Add(time.Minute).Unix()
+	token := func(jti, sid string) string {
+		return signEdDSA(t, "chave-1", private, jwt.MapClaims{
+			"user_id": "42", "type": "access", "exp": exp, "jti": jti, "sid": sid,
+		})
+	}
+
+	// DELETE /auth/sessions/{id} no auth-service revoga a sessão pelo sid
+	if err := denylist.New(sharedCache).RevokeSession(context.Background(), "sessao-1", 15*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := authenticate(m, token("jti-1", "sessao-1")); code != http.StatusUnauthorized {
+		t.Errorf("Token da sessão encerrada deveria ser recusado, obteve %d", code)
+	}
+	if code, _ := authenticate(m, token("jti-2", "sessao-2")); code != http.StatusOK {
+		t.Errorf("Token de outra sessão deveria ser aceito, obteve %d", code)
+	}
+}
+
+func TestAuthenticateImpersonation(t *testing.T) {
+	server := &jwksServer{keys: make(map[string]ed25519.PublicKey)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	server.publish("chave-1", public)
+	m := NewAuthMiddleware(nil, nil, NewJWKSClient(ts.URL), nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var userID, actorID interface{}
+	router.GET("/members", m.Authenticate(), func(c *gin.Context) {
+		userID, _ = c.Get("userID")
+		actorID, _ = c.Get("actorID")
+		c.Status(http.StatusOK)
+	})
+	router.POST("/mfa", m.Authenticate(), m.RequireSession(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	token := signEdDSA(t, "chave-1", private, jwt.MapClaims{
+		"user_id": "42", "actor_id": "7", "type": "impersonation",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	serve := func(method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve(http.MethodGet, "/members"); code != http.StatusOK || userID != uint(42) || actorID != uint(7) {
+		t.Fatalf("Esperava 200, userID 42 e actorID 7, obteve %d, %v e %v", code, userID, actorID)
+	}
+	if code := serve(http.MethodPost, "/mfa"); code != http.StatusForbidden {
+		t.Errorf("Personificação não deveria acessar rotas de sessão, obteve %d", code)
+	}
+}
+
+func TestJWKSClientCachesKeys(t *testing.T) {
+	server := &jwksServer{keys: make(map[string]ed25519.PublicKey)}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	server.publish("chave-1", public)
+
+	m := NewAuthMiddleware(nil, nil, NewJWKSClient(ts.URL), nil)
+	token := signEdDSA(t, "chave-1", private, jwt.MapClaims{
+		"sub": "7", "exp": time.Now().Add(time.Minute).Unix(),
+	})
+
+	for i := 0; i < 3; i++ {
+		if code, _ := authenticate(m, token); code != http.StatusOK {
+			t.Fatalf("Esperava 200, obteve %d", code)
+		}
+	}
+
+	// kid desconhecido logo após uma busca não gera nova requisição
+	authenticate(m, signEdDSA(t, "inexistente", private, jwt.MapClaims{"sub": "7"}))
+
+	if server.requests != 1 {
+		t.Errorf("Esperava 1 busca do JWKS, obteve %d", server.requests)
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/insidechurch/observability"
+)
+
+// Observability aplica às rotas do Gin a cadeia de middlewares comum aos
+// serviços: ID da requisição, tracing, métricas, recuperação de panics e log
+// da requisição. O template da rota é o FullPath do Gin, como
+// /api/users/:id; rotas não registradas são contadas como "other".
+func Observability(obs *observability.Observability) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		obs.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				observability.SetRoute(r.Context(), c.FullPath())
+			}()
+			c.Request = r
+			c.Next()
+		})).ServeHTTP(c.Writer, c.Request)
+
+		// Os handlers seguintes já foram executados em c.Next ou foram
+		// interrompidos por um panic, já respondido pela cadeia
+		c.Abort()
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/observability"
+	"github.com/insidechurch/observability/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newObservabilityRouter(t *testing.T) (*gin.Engine, *observability.Observability, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	obs, err := observability.Setup(observability.Config{
+		ServiceName: "api",
+		Registry:    prometheus.NewRegistry(),
+		Logger:      logger.NewWriterLogger(&buf, logger.LevelInfo, nil),
+	})
+	if err != nil {
+		t.Fatalf("Setup falhou: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Observability(obs))
+	return router, obs, &buf
+}
+
+func TestObservabilityUsesGinRoute(t *testing.T) {
+	router, obs, buf := newObservabilityRouter(t)
+
+	var requestID string
+	router.GET("/api/users/:id", func(c *gin.Context) {
+		requestID = c.GetHeader(auditevent.CorrelationHeader)
+		c.Status(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/42", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nao-existe", nil))
+
+	if requestID == "" || rec.Header().Get(auditevent.CorrelationHeader) != requestID {
+		t.Errorf("ID da requisição deveria chegar ao handler e voltar na resposta, obteve %q e %q",
+			requestID, rec.Header().Get(auditevent.CorrelationHeader))
+	}
+	if got := testutil.ToFloat64(obs.Metrics.HTTPRequestsTotal.WithLabelValues("/api/users/:id", "GET", "204")); got != 1 {
+		t.Errorf("Requisição deveria ser contada no template da rota do Gin, obtido %v", got)
+	}
+	if got := testutil.ToFloat64(obs.Metrics.HTTPRequestsTotal.WithLabelValues("other", "GET", "404")); got != 1 {
+		t.Errorf("Rota não registrada deveria ser contada como other, obtido %v", got)
+	}
+	if !strings.Contains(buf.String(), `"route":"/api/users/:id"`) {
+		t.Errorf("Log da requisição sem a rota: %s", buf.String())
+	}
+}
+
+func TestObservabilityRecoversPanic(t *testing.T) {
+	router, obs, _ := newObservabilityRouter(t)
+
+	after := false
+	router.GET("/panic", func(c *gin.Context) {
+		panic("falha inesperada")
+	}, func(c *gin.Context) {
+		after = true
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Status = %d, esperado 500", rec.Code)
+	}
+	if after {
+		t.Error("Handlers seguintes não deveriam ser executados após o panic")
+	}
+	if got := testutil.ToFloat64(obs.Metrics.HTTPErrors.WithLabelValues("/panic", "GET", "500")); got != 1 {
+		t.Errorf("Panic deveria ser contado como erro, obtido %v", got)
+	}
+}
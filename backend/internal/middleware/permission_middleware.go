@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+
+	"insidechurch/backend/internal/core/ports"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PermissionMiddleware restringe as rotas administrativas aos usuários cujo
+// papel tem a permissão exigida
+type PermissionMiddleware struct {
+	userRepo    ports.UserRepository
+	permissions ports.PermissionPolicy
+}
+
+// NewPermissionMiddleware cria uma nova instância do PermissionMiddleware. O
+// papel do usuário é lido de userRepo e as permissões, de permissions.
+func NewPermissionMiddleware(userRepo ports.UserRepository, permissions ports.PermissionPolicy) *PermissionMiddleware {
+	return &PermissionMiddleware{
+		userRepo:    userRepo,
+		permissions: permissions,
+	}
+}
+
+// RequirePermission restringe a rota aos usuários cujo papel tem a permissão
+// resource:action. Usuários sem papel são recusados. Deve ser usado após
+// Authenticate.
+func (m *PermissionMiddleware) RequirePermission(resource, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("userID")
+		id, ok := userID.(uint)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "usuário não autenticado"})
+			c.Abort()
+			return
+		}
+
+		user, err := m.userRepo.FindByID(id)
+		if err != nil || user.RoleID == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "permissão insuficiente: " + resource + ":" + action})
+			c.Abort()
+			return
+		}
+
+		allowed, err := m.permissions.CheckPermission(*user.RoleID, resource, action)
+		if err != nil {
+			logrus.Errorf("Erro ao consultar as permissões do papel %d: %v", *user.RoleID, err)
+		}
+		if err != nil || !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "permissão insuficiente: " + resource + ":" + action})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -2,24 +2,24 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
-	"sync"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
+	"github.com/insidechurch/observability/metrics"
+	"github.com/insidechurch/ratelimit"
+	"github.com/sirupsen/logrus"
 )
 
 type SecurityMiddleware struct {
-	limiter *rate.Limiter
-	ips     map[string]*rate.Limiter
-	mu      sync.Mutex
+	limiter *ratelimit.Limiter
 }
 
-func NewSecurityMiddleware() *SecurityMiddleware {
+// NewSecurityMiddleware cria uma nova instância do SecurityMiddleware;
+// limiter aplica as políticas de limite de requisições
+func NewSecurityMiddleware(limiter *ratelimit.Limiter) *SecurityMiddleware {
 	return &SecurityMiddleware{
-		limiter: rate.NewLimiter(rate.Every(time.Second), 100),
-		ips:     make(map[string]*rate.Limiter),
+		limiter: limiter,
 	}
 }
 
@@ -71,19 +71,39 @@ func (m *SecurityMiddleware) SecurityHeaders() gin.HandlerFunc {
 	}
 }
 
+// RateLimit aplica as políticas de limite por IP à rota da requisição
 func (m *SecurityMiddleware) RateLimit() gin.HandlerFunc {
+	return m.limit(ratelimit.ScopeIP)
+}
+
+// UserRateLimit aplica as políticas de limite por usuário; deve ser usado
+// depois de AuthMiddleware.Authenticate
+func (m *SecurityMiddleware) UserRateLimit() gin.HandlerFunc {
+	return m.limit(ratelimit.ScopeUser)
+}
+
+// limit aplica as políticas do escopo e informa a cota nos headers
+// RateLimit-*. Falhas do Redis não bloqueiam as requisições.
+func (m *SecurityMiddleware) limit(scope ratelimit.Scope) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
+		// A rota é o template, como /api/users/:id; caminhos sem rota
+		// registrada compartilham um único nome, como no auth-service
+		route := c.FullPath()
+		if route == "" {
+			route = metrics.OtherRoute
+		}
+		req := ratelimit.Request{Route: route, IP: c.ClientIP()}
+		if userID := c.GetUint("userID"); userID != 0 {
+			req.UserID = strconv.FormatUint(uint64(userID), 10)
+		}
 
-		m.mu.Lock()
-		limiter, exists := m.ips[ip]
-		if !exists {
-			limiter = rate.NewLimiter(rate.Every(time.Minute), 100)
-			m.ips[ip] = limiter
+		res, err := m.limiter.Allow(c.Request.Context(), req, scope)
+		if err != nil {
+			logrus.Errorf("Erro ao consultar o limite de requisições: %v", err)
 		}
-		m.mu.Unlock()
+		ratelimit.WriteHeaders(c.Writer.Header(), res)
 
-		if !limiter.Allow() {
+		if !res.Allowed {
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "muitas requisições, tente novamente mais tarde",
 			})
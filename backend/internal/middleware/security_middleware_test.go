@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/insidechurch/ratelimit"
+)
+
+func newRateLimitRouter(t *testing.T, spec string) *gin.Engine {
+	t.Helper()
+	policies, err := ratelimit.ParsePolicies(spec)
+	if err != nil {
+		t.Fatalf("ParsePolicies falhou: %v", err)
+	}
+	m := NewSecurityMiddleware(ratelimit.New(ratelimit.NewMemoryStore(), policies...))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(m.RateLimit())
+	router.GET("/api/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/api/me", func(c *gin.Context) {
+		c.Set("userID", uint(7))
+		c.Next()
+	}, m.UserRateLimit(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func TestRateLimitUsesRouteTemplate(t *testing.T) {
+	router := newRateLimitRouter(t, "/api/users/:id=ip:2/1m:sliding_window")
+
+	send := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	send("/api/users/1")
+	if rec := send("/api/users/2"); rec.Code != http.StatusNoContent || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("Esperava a segunda requisição aceita sem cota restante, obteve %d %v", rec.Code, rec.Header())
+	}
+
+	rec := send("/api/users/3")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Rotas com o mesmo template deveriam compartilhar o limite, status %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" || rec.Header().Get("RateLimit-Policy") != "2;w=60" {
+		t.Errorf("Headers de limite ausentes: %v", rec.Header())
+	}
+
+	if rec := send("/api/me"); rec.Code != http.StatusNoContent || rec.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("Rotas fora da política não deveriam ser limitadas, obteve %d %v", rec.Code, rec.Header())
+	}
+
+	// Caminhos sem rota registrada não correspondem a políticas por caminho
+	router = newRateLimitRouter(t, "/api/users/*=ip:1/1m")
+	send("/api/users/desconhecida/1")
+	if rec := send("/api/users/desconhecida/2"); rec.Code != http.StatusNotFound {
+		t.Errorf("Caminho sem rota não deveria ser limitado pela política, obteve %d", rec.Code)
+	}
+}
+
+func TestUserRateLimit(t *testing.T) {
+	router := newRateLimitRouter(t, "/api/me=user:1/1m")
+
+	for i, want := range []int{http.StatusNoContent, http.StatusTooManyRequests} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/me", nil))
+		if rec.Code != want {
+			t.Errorf("Requisição %d: status %d, esperado %d", i+1, rec.Code, want)
+		}
+	}
+}
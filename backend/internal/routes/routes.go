@@ -1,6 +1,9 @@
 package routes
 
 import (
+	"net/http"
+	"strings"
+
 	"insidechurch/backend/internal/adapters/handlers"
 	"insidechurch/backend/internal/middleware"
 
@@ -9,18 +12,31 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// apiKeyScopes são os escopos exigidos das chaves de API nas rotas
+// protegidas, indexados pelo método e pelo template da rota; as demais rotas
+// protegidas recusam chaves de API
+var apiKeyScopes = map[string]string{
+	"GET /api/users/me": "users:read",
+}
+
 func SetupRoutes(
 	router *gin.Engine,
 	authHandler *handlers.AuthHandler,
 	userHandler *handlers.UserHandler,
+	mfaHandler *handlers.MFAHandler,
+	memberHandler *handlers.MemberHandler,
+	roleHandler *handlers.RoleHandler,
 	authMiddleware *middleware.AuthMiddleware,
+	permissionMiddleware *middleware.PermissionMiddleware,
 	securityMiddleware *middleware.SecurityMiddleware,
 ) {
-	// Middlewares globais
+	// Middlewares globais; os limites de requisições por rota são definidos
+	// pelas políticas do SecurityMiddleware
 	router.Use(securityMiddleware.SecurityHeaders())
 	router.Use(securityMiddleware.RateLimit())
 	router.Use(securityMiddleware.CORS())
 	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.AuditOrigin())
 
 	// Rota raiz
 	router.GET("/", func(c *gin.Context) {
@@ -41,12 +57,18 @@ func SetupRoutes(
 		// Grupo de rotas do Swagger
 		swagger := api.Group("/swagger")
 		{
-			// Servir arquivos estáticos do Swagger
-			swagger.Static("/static", "/app/swagger")
-
-			// Configuração do Swagger UI
+			// Arquivos estáticos do Swagger e Swagger UI na mesma rota: o gin
+			// não aceita /static ao lado do curinga /*any
+			static := http.StripPrefix("/api/swagger/static", http.FileServer(http.Dir("/app/swagger")))
 			url := ginSwagger.URL("/api/swagger/static/swagger.json")
-			swagger.GET("/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
+			ui := ginSwagger.WrapHandler(swaggerFiles.Handler, url)
+			swagger.GET("/*any", func(c *gin.Context) {
+				if strings.HasPrefix(c.Param("any"), "/static/") {
+					static.ServeHTTP(c.Writer, c.Request)
+					return
+				}
+				ui(c)
+			})
 		}
 
 		// Grupo de rotas públicas
@@ -57,18 +79,57 @@ func SetupRoutes(
 			{
 				auth.POST("/register", authHandler.Register)
 				auth.POST("/login", authHandler.Login)
+				auth.POST("/password-reset/request", authHandler.RequestPasswordReset)
+				auth.POST("/password-reset/confirm", authHandler.ConfirmPasswordReset)
+				auth.POST("/verify-email", authHandler.VerifyEmail)
+				auth.POST("/verify-email/resend", authHandler.ResendVerification)
+
+				// Segunda etapa do login, autenticada pelo token de desafio
+				auth.POST("/mfa/verify", mfaHandler.Verify)
+				auth.POST("/mfa/enroll/challenge", mfaHandler.BeginChallengeEnrollment)
+				auth.POST("/mfa/enroll/challenge/confirm", mfaHandler.ConfirmChallengeEnrollment)
 			}
 		}
 
 		// Grupo de rotas protegidas
 		protected := api.Group("")
-		protected.Use(authMiddleware.Authenticate())
+		protected.Use(authMiddleware.Authenticate(), authMiddleware.RequireRouteScopes(apiKeyScopes), securityMiddleware.UserRateLimit())
 		{
 			// Rotas de usuário
 			users := protected.Group("/users")
 			{
 				users.GET("/me", userHandler.GetUser)
 			}
+
+			// Rotas de autenticação em dois fatores
+			mfa := protected.Group("/auth/mfa")
+			mfa.Use(authMiddleware.RequireSession())
+			{
+				mfa.POST("/enroll", mfaHandler.BeginEnrollment)
+				mfa.POST("/enroll/confirm", mfaHandler.ConfirmEnrollment)
+				mfa.POST("/disable", mfaHandler.Disable)
+			}
+
+			// Rotas administrativas, restritas às sessões de usuários cujo
+			// papel tem a permissão de cada grupo
+			members := protected.Group("/members")
+			members.Use(authMiddleware.RequireSession(), permissionMiddleware.RequirePermission("members", "write"))
+			{
+				members.POST("", memberHandler.Create)
+				members.PUT("/:id", memberHandler.Update)
+				members.DELETE("/:id", memberHandler.Delete)
+			}
+
+			roles := protected.Group("/roles")
+			roles.Use(authMiddleware.RequireSession(), permissionMiddleware.RequirePermission("roles", "write"))
+			{
+				roles.GET("", roleHandler.List)
+				roles.POST("", roleHandler.Create)
+				roles.PUT("/:id", roleHandler.Update)
+				roles.DELETE("/:id", roleHandler.Delete)
+				roles.POST("/:id/permissions", roleHandler.GrantPermission)
+				roles.DELETE("/:id/permissions/:permission", roleHandler.RevokePermission)
+			}
 		}
 	}
 }
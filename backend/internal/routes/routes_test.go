@@ -0,0 +1,342 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"insidechurch/backend/internal/adapters/handlers"
+	coreentities "insidechurch/backend/internal/core/domain/entities"
+	domainerrors "insidechurch/backend/internal/core/errors"
+	"insidechurch/backend/internal/core/usecases/auth"
+	"insidechurch/backend/internal/core/usecases/user"
+	"insidechurch/backend/internal/domain/entities"
+	"insidechurch/backend/internal/domain/repositories"
+	"insidechurch/backend/internal/domain/services"
+	"insidechurch/backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/keys"
+	"github.com/insidechurch/passwordhash"
+	"github.com/insidechurch/ratelimit"
+)
+
+type fakeUserRepo struct {
+	users map[uint]*coreentities.User
+}
+
+func (r *fakeUserRepo) Create(u *coreentities.User) error {
+	u.ID = uint(len(r.users) + 1)
+	u.CreatedAt = time.Now()
+	r.users[u.ID] = u
+	return nil
+}
+
+func (r *fakeUserRepo) FindByID(id uint) (*coreentities.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, domainerrors.NewUserNotFound(id)
+}
+
+func (r *fakeUserRepo) FindByEmail(email string) (*coreentities.User, error) {
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, domainerrors.NewUserNotFound(0)
+}
+
+func (r *fakeUserRepo) Update(u *coreentities.User) error {
+	r.users[u.ID] = u
+	return nil
+}
+
+func (r *fakeUserRepo) Delete(id uint) error {
+	delete(r.users, id)
+	return nil
+}
+
+type fakeRoleRepo struct {
+	roles map[uint]*entities.Role
+}
+
+func (r *fakeRoleRepo) Create(role *entities.Role) error {
+	role.ID = uint(len(r.roles) + 1)
+	r.roles[role.ID] = role
+	return nil
+}
+
+func (r *fakeRoleRepo) FindByID(id uint) (*entities.Role, error) {
+	if role, ok := r.roles[id]; ok {
+		return role, nil
+	}
+	return nil, repositories.ErrRoleNotFound
+}
+
+func (r *fakeRoleRepo) FindByName(name string) (*entities.Role, error) {
+	for _, role := range r.roles {
+		if role.Name == name {
+			return role, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRoleRepo) Update(role *entities.Role) error {
+	r.roles[role.ID] = role
+	return nil
+}
+
+func (r *fakeRoleRepo) Delete(id uint) error {
+	delete(r.roles, id)
+	return nil
+}
+
+func (r *fakeRoleRepo) List() ([]entities.Role, error) {
+	var roles []entities.Role
+	for _, role := range r.roles {
+		roles = append(roles, *role)
+	}
+	return roles, nil
+}
+
+func (r *fakeRoleRepo) AddPermission(roleID uint, permission *entities.Permission) error {
+	r.roles[roleID].AddPermission(permission)
+	return nil
+}
+
+func (r *fakeRoleRepo) RemovePermission(roleID uint, permission *entities.Permission) error {
+	r.roles[roleID].RemovePermission(permission)
+	return nil
+}
+
+type fakeRevoker struct {
+	revoked []uint
+}
+
+func (r *fakeRevoker) RevokeUserSessions(userID uint) error {
+	r.revoked = append(r.revoked, userID)
+	return nil
+}
+
+// fakeRecorder guarda os registros de auditoria como o Recorder os gravaria,
+// completados com a origem da requisição
+type fakeRecorder struct {
+	entries []auditevent.Entry
+}
+
+func (r *fakeRecorder) Record(ctx context.Context, event auditevent.Event) {
+	r.entries = append(r.entries, auditevent.NewEntry(ctx, event, time.Now()))
+}
+
+// last retorna o último registro da ação, ou nil
+func (r *fakeRecorder) last(action string) *auditevent.Entry {
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if r.entries[i].Action == action {
+			return &r.entries[i]
+		}
+	}
+	return nil
+}
+
+type adminFixture struct {
+	router   *gin.Engine
+	users    *fakeUserRepo
+	roles    *fakeRoleRepo
+	revoker  *fakeRevoker
+	recorder *fakeRecorder
+}
+
+const testPassword = "Senha@Forte123"
+
+// newAdminFixture monta as rotas com os casos de uso reais sobre
+// repositórios em memória: o usuário 1 tem o papel admin, com as permissões
+// administrativas, e o usuário 2 tem o papel membro, sem permissões
+func newAdminFixture(t *testing.T) *adminFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	hasher := passwordhash.Default()
+	hash, err := hasher.Hash(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roles := &fakeRoleRepo{roles: map[uint]*entities.Role{}}
+	admin := entities.NewRole("admin")
+	roles.Create(admin)
+	admin.AddPermission(entities.NewPermission("members", "write"))
+	admin.AddPermission(entities.NewPermission("roles", "write"))
+	roles.Create(entities.NewRole("membro"))
+
+	users := &fakeUserRepo{users: map[uint]*coreentities.User{}}
+	adminRoleID, memberRoleID := uint(1), uint(2)
+	users.Create(&coreentities.User{Name: "Admin", Email: "admin@email.com", Password: hash, RoleID: &adminRoleID})
+	users.Create(&coreentities.User{Name: "Ana", Email: "ana@email.com", Password: hash, RoleID: &memberRoleID})
+
+	keySet, err := keys.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := &fakeRecorder{}
+	revoker := &fakeRevoker{}
+	roleService := services.NewRoleService(roles, recorder)
+	loginUseCase := auth.NewLoginUseCase(users, auth.UnverifiedLoginAllow, roleService, nil, hasher, recorder, auth.NewTokenKeys(keySet, ""), nil)
+
+	router := gin.New()
+	SetupRoutes(
+		router,
+		handlers.NewAuthHandler(loginUseCase, nil, nil, nil),
+		handlers.NewUserHandler(user.NewGetUserUseCase(users)),
+		handlers.NewMFAHandler(nil),
+		handlers.NewMemberHandler(user.NewMemberUseCase(users, revoker, recorder)),
+		handlers.NewRoleHandler(roleService),
+		middleware.NewAuthMiddleware(loginUseCase, nil, nil, nil),
+		middleware.NewPermissionMiddleware(users, roleService),
+		middleware.NewSecurityMiddleware(ratelimit.New(ratelimit.NewMemoryStore())),
+	)
+	return &adminFixture{router: router, users: users, roles: roles, revoker: revoker, recorder: recorder}
+}
+
+func (f *adminFixture) do(t *testing.T, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auditevent.CorrelationHeader, "req-123")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	f.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func (f *adminFixture) login(t *testing.T, email string) string {
+	t.Helper()
+	rec := f.do(t, http.MethodPost, "/api/auth/login", "", map[string]string{"email": email, "password": testPassword})
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &resp) != nil || resp.Token == "" {
+		t.Fatalf("Login de %s falhou: %d %s", email, rec.Code, rec.Body.String())
+	}
+	return resp.Token
+}
+
+// expectEntry verifica que a ação foi registrada pelo administrador sobre o
+// alvo, com o ID de correlação da requisição
+func (f *adminFixture) expectEntry(t *testing.T, action, target string) *auditevent.Entry {
+	t.Helper()
+	entry := f.recorder.last(action)
+	if entry == nil {
+		t.Fatalf("Evento %s não foi registrado", action)
+	}
+	if entry.UserID != "1" || entry.Target != target || entry.CorrelationID != "req-123" {
+		t.Errorf("Registro de %s inesperado: %+v", action, entry)
+	}
+	return entry
+}
+
+func TestMemberRoutesRecordAuditEvents(t *testing.T) {
+	f := newAdminFixture(t)
+	token := f.login(t, "admin@email.com")
+
+	rec := f.do(t, http.MethodPost, "/api/members", token, map[string]string{"name": "Bruno", "email": "bruno@email.com"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Esperava 201 no cadastro, obteve %d: %s", rec.Code, rec.Body.String())
+	}
+	f.expectEntry(t, auditevent.MemberCreated, "user:3")
+
+	rec = f.do(t, http.MethodPut, "/api/members/2", token, map[string]string{"name": "Ana Souza"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Esperava 200 na alteração, obteve %d: %s", rec.Code, rec.Body.String())
+	}
+	entry := f.expectEntry(t, auditevent.MemberUpdated, "user:2")
+	if len(entry.Changes) != 1 || entry.Changes[0].Field != "name" || entry.Changes[0].Before != "Ana" || entry.Changes[0].After != "Ana Souza" {
+		t.Errorf("Esperava apenas a alteração do nome, obteve %+v", entry.Changes)
+	}
+
+	rec = f.do(t, http.MethodDelete, "/api/members/2", token, nil)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava 204 na remoção, obteve %d: %s", rec.Code, rec.Body.String())
+	}
+	f.expectEntry(t, auditevent.MemberDeleted, "user:2")
+	if _, exists := f.users.users[2]; exists {
+		t.Error("Membro deveria ser removido")
+	}
+	if len(f.revoker.revoked) != 1 || f.revoker.revoked[0] != 2 {
+		t.Errorf("Sessões do membro removido deveriam ser encerradas, obteve %v", f.revoker.revoked)
+	}
+}
+
+func TestRoleRoutesRecordAuditEvents(t *testing.T) {
+	f := newAdminFixture(t)
+	token := f.login(t, "admin@email.com")
+
+	rec := f.do(t, http.MethodPost, "/api/roles", token, map[string]string{"name": "lider"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Esperava 201 na criação, obteve %d: %s", rec.Code, rec.Body.String())
+	}
+	f.expectEntry(t, auditevent.RoleCreated, "role:3")
+
+	rec = f.do(t, http.MethodPost, "/api/roles/3/permissions", token, map[string]string{"resource": "events", "action": "read"})
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava 204 na concessão, obteve %d: %s", rec.Code, rec.Body.String())
+	}
+	entry := f.expectEntry(t, auditevent.RolePermissionGranted, "role:3")
+	if len(entry.Changes) != 1 || entry.Changes[0].After != "events:read" {
+		t.Errorf("Alteração inesperada na concessão: %+v", entry.Changes)
+	}
+
+	rec = f.do(t, http.MethodDelete, "/api/roles/3/permissions/events:read", token, nil)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava 204 na revogação, obteve %d: %s", rec.Code, rec.Body.String())
+	}
+	f.expectEntry(t, auditevent.RolePermissionRevoked, "role:3")
+
+	rec = f.do(t, http.MethodPut, "/api/roles/3", token, map[string]bool{"mfa_required": true})
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava 204 na alteração, obteve %d: %s", rec.Code, rec.Body.String())
+	}
+	f.expectEntry(t, auditevent.RoleUpdated, "role:3")
+
+	rec = f.do(t, http.MethodDelete, "/api/roles/3", token, nil)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava 204 na remoção, obteve %d: %s", rec.Code, rec.Body.String())
+	}
+	f.expectEntry(t, auditevent.RoleDeleted, "role:3")
+}
+
+func TestAdminRoutesRequirePermission(t *testing.T) {
+	f := newAdminFixture(t)
+	token := f.login(t, "ana@email.com")
+	before := len(f.recorder.entries)
+
+	if rec := f.do(t, http.MethodDelete, "/api/members/1", token, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("Esperava 403 para membro sem permissão, obteve %d", rec.Code)
+	}
+	if rec := f.do(t, http.MethodPost, "/api/roles/2/permissions", token, map[string]string{"resource": "roles", "action": "write"}); rec.Code != http.StatusForbidden {
+		t.Errorf("Esperava 403 para membro sem permissão, obteve %d", rec.Code)
+	}
+	if rec := f.do(t, http.MethodDelete, "/api/members/1", "", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Esperava 401 sem token, obteve %d", rec.Code)
+	}
+
+	if len(f.recorder.entries) != before {
+		t.Errorf("Nenhum evento deveria ser registrado, obteve %+v", f.recorder.entries[before:])
+	}
+	if _, exists := f.users.users[1]; !exists {
+		t.Error("Administrador não deveria ser removido")
+	}
+}
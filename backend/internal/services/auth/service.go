@@ -3,6 +3,8 @@ package auth
 import (
 	"time"
 
+	authusecase "insidechurch/backend/internal/core/usecases/auth"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -19,12 +21,14 @@ type Claims struct {
 }
 
 type service struct {
-	jwtSecret string
+	tokenKeys *authusecase.TokenKeys
 }
 
-func NewService(jwtSecret string) Service {
+// NewService cria uma nova instância do Service, que assina e verifica os
+// tokens com tokenKeys
+func NewService(tokenKeys *authusecase.TokenKeys) Service {
 	return &service{
-		jwtSecret: jwtSecret,
+		tokenKeys: tokenKeys,
 	}
 }
 
@@ -38,15 +42,11 @@ func (s *service) GenerateToken(userID string, roles []string) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
+	return s.tokenKeys.Sign(claims)
 }
 
 func (s *service) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.jwtSecret), nil
-	})
-
+	token, err := s.tokenKeys.Parse(tokenString, &Claims{})
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +67,5 @@ func (s *service) RefreshToken(tokenString string) (string, error) {
 	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(24 * time.Hour))
 	claims.IssuedAt = jwt.NewNumericDate(time.Now())
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtSecret))
+	return s.tokenKeys.Sign(claims)
 }
@@ -2,40 +2,50 @@ package services
 
 import (
 	"errors"
-	"os"
 	"time"
 
 	"insidechurch/backend/internal/core/domain"
+	domainerrors "insidechurch/backend/internal/core/errors"
 	"insidechurch/backend/internal/core/interfaces"
+	authusecase "insidechurch/backend/internal/core/usecases/auth"
 
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/insidechurch/passwordhash"
+	"github.com/insidechurch/passwordpolicy"
 )
 
 type AuthService struct {
-	jwtSecret []byte
-	userRepo  interfaces.UserRepository
+	tokenKeys      *authusecase.TokenKeys
+	userRepo       interfaces.UserRepository
+	passwordPolicy *passwordpolicy.Policy
+	passwordHasher *passwordhash.Hasher
 }
 
-func NewAuthService(userRepo interfaces.UserRepository) *AuthService {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		panic("JWT_SECRET não configurado")
+// NewAuthService cria uma nova instância do AuthService. Os tokens são
+// assinados e verificados com tokenKeys, as mesmas chaves do login da API.
+func NewAuthService(userRepo interfaces.UserRepository, tokenKeys *authusecase.TokenKeys) *AuthService {
+	passwordPolicy, err := passwordpolicy.FromEnv()
+	if err != nil {
+		panic(err)
+	}
+	passwordHasher, err := passwordhash.FromEnv()
+	if err != nil {
+		panic(err)
 	}
 	return &AuthService{
-		jwtSecret: []byte(jwtSecret),
-		userRepo:  userRepo,
+		tokenKeys:      tokenKeys,
+		userRepo:       userRepo,
+		passwordPolicy: passwordPolicy,
+		passwordHasher: passwordHasher,
 	}
 }
 
 func (s *AuthService) HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	return s.passwordHasher.Hash(password)
 }
 
 func (s *AuthService) CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	return s.passwordHasher.Verify(password, hash) == nil
 }
 
 func (s *AuthService) GenerateToken(user *domain.User) (string, error) {
@@ -47,47 +57,23 @@ func (s *AuthService) GenerateToken(user *domain.User) (string, error) {
 		"iat":   time.Now().Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.jwtSecret)
+	return s.tokenKeys.Sign(claims)
 }
 
 func (s *AuthService) ValidateToken(tokenString string) (*jwt.Token, error) {
-	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("método de assinatura inválido")
-		}
-		return s.jwtSecret, nil
-	})
+	return s.tokenKeys.Parse(tokenString, jwt.MapClaims{})
 }
 
+// ValidatePassword aplica a política de senhas compartilhada com os casos de
+// uso e o auth-service, listando as regras não atendidas nos detalhes do erro
 func (s *AuthService) ValidatePassword(password string) error {
-	if len(password) < 8 {
-		return errors.New("a senha deve ter pelo menos 8 caracteres")
+	violations := s.passwordPolicy.Validate(password)
+	if len(violations) == 0 {
+		return nil
 	}
-
-	hasUpper := false
-	hasLower := false
-	hasNumber := false
-	hasSpecial := false
-
-	for _, char := range password {
-		switch {
-		case 'A' <= char && char <= 'Z':
-			hasUpper = true
-		case 'a' <= char && char <= 'z':
-			hasLower = true
-		case '0' <= char && char <= '9':
-			hasNumber = true
-		case char == '!' || char == '@' || char == '#' || char == '$' || char == '%' || char == '^' || char == '&' || char == '*':
-			hasSpecial = true
-		}
-	}
-
-	if !hasUpper || !hasLower || !hasNumber || !hasSpecial {
-		return errors.New("a senha deve conter pelo menos uma letra maiúscula, uma minúscula, um número e um caractere especial")
-	}
-
-	return nil
+	return domainerrors.NewInvalidInput(passwordpolicy.Summary(violations), map[string]interface{}{
+		"violations": violations,
+	})
 }
 
 func (s *AuthService) Authenticate(email, password string) (string, error) {
@@ -103,6 +89,26 @@ func (s *AuthService) Authenticate(email, password string) (string, error) {
 	if !s.CheckPasswordHash(password, user.Password) {
 		return "", errors.New("senha inválida")
 	}
+	s.rehash(user, password)
 
 	return s.GenerateToken(user)
 }
+
+// rehash refaz o hash gerado abaixo da configuração atual com a senha recebida
+// no login; o hash antigo continua válido se a atualização falhar
+func (s *AuthService) rehash(user *domain.User, password string) {
+	if !s.passwordHasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := s.HashPassword(password)
+	if err != nil {
+		return
+	}
+
+	previous := user.Password
+	user.Password = hashedPassword
+	if err := s.userRepo.Update(user); err != nil {
+		user.Password = previous
+	}
+}
@@ -2,9 +2,11 @@ package services
 
 import (
 	"insidechurch/backend/internal/core/domain"
+	authusecase "insidechurch/backend/internal/core/usecases/auth"
 	"testing"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/insidechurch/keys"
 	"gorm.io/gorm"
 )
 
@@ -21,18 +23,31 @@ func (m *mockUserRepo) FindByID(id uint) (*domain.User, error) { return nil, nil
 func (m *mockUserRepo) Update(user *domain.User) error         { return nil }
 func (m *mockUserRepo) Delete(id uint) error                   { return nil }
 
+// newTokenKeys cria chaves de assinatura efêmeras para os testes
+func newTokenKeys(t *testing.T) *authusecase.TokenKeys {
+	t.Helper()
+	keySet, err := keys.Generate()
+	if err != nil {
+		t.Fatalf("keys.Generate falhou: %v", err)
+	}
+	return authusecase.NewTokenKeys(keySet, "")
+}
+
 func TestValidatePassword(t *testing.T) {
-	s := NewAuthService(nil)
+	s := NewAuthService(nil, newTokenKeys(t))
 	if err := s.ValidatePassword("123"); err == nil {
 		t.Error("deveria falhar para senha curta")
 	}
-	if err := s.ValidatePassword("123456"); err != nil {
+	if err := s.ValidatePassword("Forte@2023x"); err != nil {
 		t.Error("não deveria falhar para senha válida")
 	}
+	if err := s.ValidatePassword("Jesus@2024"); err == nil {
+		t.Error("deveria falhar para senha comum")
+	}
 }
 
 func TestHashAndCheckPassword(t *testing.T) {
-	s := NewAuthService(nil)
+	s := NewAuthService(nil, newTokenKeys(t))
 	hash, err := s.HashPassword("senha123")
 	if err != nil {
 		t.Fatal(err)
@@ -46,7 +61,7 @@ func TestHashAndCheckPassword(t *testing.T) {
 }
 
 func TestGenerateAndValidateToken(t *testing.T) {
-	s := NewAuthService(nil)
+	s := NewAuthService(nil, newTokenKeys(t))
 	user := &domain.User{Model: gorm.Model{ID: 1}, Email: "a@b.com", Name: "Teste"}
 	token, err := s.GenerateToken(user)
 	if err != nil {
@@ -75,7 +90,7 @@ func TestGenerateAndValidateToken(t *testing.T) {
 
 func TestAuthenticate(t *testing.T) {
 	user := &domain.User{Model: gorm.Model{ID: 1}, Email: "a@b.com", Name: "Teste"}
-	s := NewAuthService(&mockUserRepo{user: user})
+	s := NewAuthService(&mockUserRepo{user: user}, newTokenKeys(t))
 	hash, _ := s.HashPassword("senha123")
 	user.Password = hash
 
@@ -89,7 +104,7 @@ func TestAuthenticate(t *testing.T) {
 		t.Error("autenticação deveria falhar com senha errada")
 	}
 
-	s2 := NewAuthService(&mockUserRepo{user: nil})
+	s2 := NewAuthService(&mockUserRepo{user: nil}, newTokenKeys(t))
 	_, err = s2.Authenticate("naoexiste@b.com", "senha123")
 	if err == nil {
 		t.Error("autenticação deveria falhar para usuário inexistente")
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
+	"github.com/insidechurch/passwordhash"
 )
 
 type User struct {
@@ -28,22 +28,24 @@ type Service interface {
 }
 
 type service struct {
-	repo Repository
+	repo   Repository
+	hasher *passwordhash.Hasher
 }
 
-func NewService(repo Repository) Service {
+func NewService(repo Repository, hasher *passwordhash.Hasher) Service {
 	return &service{
-		repo: repo,
+		repo:   repo,
+		hasher: hasher,
 	}
 }
 
 func (s *service) Create(ctx context.Context, user *User) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hasher.Hash(user.Password)
 	if err != nil {
 		return err
 	}
 
-	user.Password = string(hashedPassword)
+	user.Password = hashedPassword
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
@@ -60,11 +62,11 @@ func (s *service) GetByEmail(ctx context.Context, email string) (*User, error) {
 
 func (s *service) Update(ctx context.Context, user *User) error {
 	if user.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+		hashedPassword, err := s.hasher.Hash(user.Password)
 		if err != nil {
 			return err
 		}
-		user.Password = string(hashedPassword)
+		user.Password = hashedPassword
 	}
 
 	user.UpdatedAt = time.Now()
@@ -81,9 +83,21 @@ func (s *service) ValidateCredentials(ctx context.Context, email, password strin
 		return nil, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	if err := s.hasher.Verify(password, user.Password); err != nil {
 		return nil, errors.New("credenciais inválidas")
 	}
 
+	// Hashes abaixo da configuração atual são refeitos com a senha recebida;
+	// o hash antigo continua válido se a atualização falhar
+	if s.hasher.NeedsRehash(user.Password) {
+		if hashedPassword, err := s.hasher.Hash(password); err == nil {
+			previous := user.Password
+			user.Password = hashedPassword
+			if err := s.repo.Update(ctx, user); err != nil {
+				user.Password = previous
+			}
+		}
+	}
+
 	return user, nil
 }
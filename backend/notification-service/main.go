@@ -1,19 +1,53 @@
 package main
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
+
+	"github.com/insidechurch/observability"
 )
 
 type Notification struct {
 	UserID  string `json:"user_id"`
+	Channel string `json:"channel,omitempty"` // ex.: "email"
+	To      string `json:"to,omitempty"`      // destinatário no canal
+	Subject string `json:"subject,omitempty"`
 	Message string `json:"message"`
+	// Sensitive indica uma mensagem com link de uso único, como os de
+	// redefinição de senha e verificação de email, cujo conteúdo não é guardado
+	Sensitive bool `json:"sensitive,omitempty"`
 }
 
+// sensitiveMessage substitui o conteúdo guardado das notificações sensíveis
+const sensitiveMessage = "Conteúdo omitido: a mensagem contém um link de uso único"
+
 var notifications = []Notification{}
 
+// serviceToken é o token que a API e o auth-service enviam no header
+// Authorization; sem ele configurado, todas as requisições são recusadas
+var serviceToken = os.Getenv("NOTIFICATION_SERVICE_TOKEN")
+
+// requireServiceToken recusa as requisições sem o token do serviço
+func requireServiceToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if serviceToken == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(serviceToken)) != 1 {
+			http.Error(w, "Não autorizado", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
 func notificationHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
@@ -24,6 +58,9 @@ func notificationHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "JSON inválido", http.StatusBadRequest)
 		return
 	}
+	if n.Sensitive {
+		n.Message = sensitiveMessage
+	}
 	notifications = append(notifications, n)
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -44,12 +81,63 @@ func notificationsByUserHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(userNotifications)
 }
 
+// shutdownTimeout limita a espera pelas requisições em andamento e pela
+// exportação dos spans no encerramento do serviço
+const shutdownTimeout = 15 * time.Second
+
+// metricsRoutes são os templates das rotas registradas em main, usados no
+// rótulo path das métricas HTTP e no nome dos spans
+var metricsRoutes = []string{
+	"/notifications",
+	"/notifications/{userID}",
+	"/health",
+	"/metrics",
+}
+
 func main() {
-	http.HandleFunc("/notifications", notificationHandler)
-	http.HandleFunc("/notifications/", notificationsByUserHandler)
+	obs, err := observability.Setup(observability.Config{
+		ServiceName: "notification-service",
+		Routes:      metricsRoutes,
+	})
+	if err != nil {
+		fmt.Printf("Erro ao inicializar a observabilidade: %v\n", err)
+		os.Exit(1)
+	}
+
+	if serviceToken == "" {
+		obs.Logger.Warn("NOTIFICATION_SERVICE_TOKEN não configurado: as rotas de notificação recusarão todas as requisições")
+	}
+
+	http.HandleFunc("/notifications", requireServiceToken(notificationHandler))
+	http.HandleFunc("/notifications/", requireServiceToken(notificationsByUserHandler))
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "ok")
 	})
+	http.Handle("/metrics", obs.Handler())
+
 	fmt.Println("Notification Service rodando na porta 8080")
-	http.ListenAndServe(":8080", nil)
+
+	// Ao receber SIGINT ou SIGTERM, aguardar as requisições em andamento e
+	// exportar os spans pendentes
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Métricas, tracing, log das requisições, recuperação de panics e ID da
+	// requisição valem para todas as rotas
+	server := &http.Server{Addr: ":8080", Handler: obs.Middleware(http.DefaultServeMux)}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			obs.Logger.Error("Erro no servidor HTTP", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		obs.Logger.Error("Erro ao encerrar o servidor HTTP", err)
+	}
+	obs.Shutdown(shutdownCtx)
 }
@@ -83,3 +83,51 @@ func TestNotificationsByUserHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestSensitiveNotificationIsNotStored(t *testing.T) {
+	jsonBody, _ := json.Marshal(Notification{
+		UserID:    "2",
+		Message:   "Acesse https://exemplo.com/reset-password?token=segredo",
+		Sensitive: true,
+	})
+	rec := httptest.NewRecorder()
+	notificationHandler(rec, httptest.NewRequest(http.MethodPost, "/notifications", bytes.NewBuffer(jsonBody)))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Esperava status 204, recebeu %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	notificationsByUserHandler(rec, httptest.NewRequest(http.MethodGet, "/notifications/2", nil))
+	if strings.Contains(rec.Body.String(), "segredo") {
+		t.Errorf("O link de uso único não deveria ser guardado: %s", rec.Body.String())
+	}
+}
+
+func TestRequireServiceToken(t *testing.T) {
+	handler := requireServiceToken(notificationsByUserHandler)
+	request := func(authorization string) int {
+		req := httptest.NewRequest(http.MethodGet, "/notifications/1", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	serviceToken = ""
+	if code := request("Bearer "); code != http.StatusUnauthorized {
+		t.Errorf("Sem token configurado, esperava status 401, recebeu %d", code)
+	}
+
+	serviceToken = "token-do-servico"
+	defer func() { serviceToken = "" }()
+	for _, authorization := range []string{"", "Bearer outro-token", "token-do-servico"} {
+		if code := request(authorization); code != http.StatusUnauthorized {
+			t.Errorf("%q: esperava status 401, recebeu %d", authorization, code)
+		}
+	}
+	if code := request("Bearer token-do-servico"); code != http.StatusOK {
+		t.Errorf("Com o token do serviço, esperava status 200, recebeu %d", code)
+	}
+}
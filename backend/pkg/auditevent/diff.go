@@ -0,0 +1,120 @@
+package auditevent
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Change é a alteração de um campo. Em campos sensíveis, Redacted indica que
+// o valor mudou e Before e After são omitidos.
+type Change struct {
+	Field    string      `json:"field"`
+	Before   interface{} `json:"before"`
+	After    interface{} `json:"after"`
+	Redacted bool        `json:"redacted,omitempty"`
+}
+
+// Diff compara os campos exportados de dois valores do mesmo tipo struct, ou
+// ponteiros para ele, e retorna as alterações na ordem dos campos. O nome de
+// cada campo é o da tag json, e structs embutidas são percorridas. A tag
+// audit:"-" ignora o campo e audit:"redact" registra a alteração sem os
+// valores. Com before nil (criação) ou after nil (remoção), são registrados
+// os campos não vazios do outro valor.
+func Diff(before, after interface{}) []Change {
+	b := indirect(reflect.ValueOf(before))
+	a := indirect(reflect.ValueOf(after))
+
+	var t reflect.Type
+	switch {
+	case a.IsValid():
+		t = a.Type()
+	case b.IsValid():
+		t = b.Type()
+	default:
+		return nil
+	}
+	if t.Kind() != reflect.Struct || (a.IsValid() && b.IsValid() && a.Type() != b.Type()) {
+		return nil
+	}
+
+	var changes []Change
+	diffFields(t, b, a, &changes)
+	return changes
+}
+
+func diffFields(t reflect.Type, before, after reflect.Value, changes *[]Change) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("audit")
+		if tag == "-" {
+			continue
+		}
+
+		b, a := fieldValue(before, i), fieldValue(after, i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			diffFields(field.Type, b, a, changes)
+			continue
+		}
+
+		name, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		bv, av := interfaceOf(b), interfaceOf(a)
+		if !b.IsValid() && (av == nil || a.IsZero()) || !a.IsValid() && (bv == nil || b.IsZero()) {
+			continue
+		}
+		if reflect.DeepEqual(bv, av) {
+			continue
+		}
+
+		if tag == "redact" {
+			*changes = append(*changes, Change{Field: name, Redacted: true})
+			continue
+		}
+		*changes = append(*changes, Change{Field: name, Before: bv, After: av})
+	}
+}
+
+// indirect segue ponteiros até o valor, retornando um Value inválido para nil
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func fieldValue(v reflect.Value, i int) reflect.Value {
+	if !v.IsValid() {
+		return reflect.Value{}
+	}
+	return v.Field(i)
+}
+
+func interfaceOf(v reflect.Value) interface{} {
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// jsonName retorna o nome do campo na tag json e se ele é omitido
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, false
+	}
+	return field.Name, false
+}
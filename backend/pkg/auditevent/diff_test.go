@@ -0,0 +1,78 @@
+package auditevent
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Base struct {
+	ID        uint
+	UpdatedAt time.Time `audit:"-"`
+}
+
+type member struct {
+	Base
+	Name     string     `json:"name"`
+	Email    string     `json:"email"`
+	Password string     `json:"-"`
+	Hash     string     `json:"password_hash" audit:"redact"`
+	Active   bool       `json:"active"`
+	LeftAt   *time.Time `json:"left_at,omitempty"`
+	internal string
+}
+
+func TestDiff(t *testing.T) {
+	left := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	before := member{Base: Base{ID: 7, UpdatedAt: time.Now()}, Name: "Ana", Email: "ana@email.com", Hash: "a", Active: true, internal: "x"}
+	after := before
+	after.UpdatedAt = time.Now().Add(time.Minute)
+	after.Email = "ana.souza@email.com"
+	after.Password = "ignorada"
+	after.Hash = "b"
+	after.Active = false
+	after.LeftAt = &left
+	after.internal = "y"
+
+	want := []Change{
+		{Field: "email", Before: "ana@email.com", After: "ana.souza@email.com"},
+		{Field: "password_hash", Redacted: true},
+		{Field: "active", Before: true, After: false},
+		{Field: "left_at", Before: nil, After: left},
+	}
+	if got := Diff(before, &after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Alterações inesperadas:\n%+v\nesperava\n%+v", got, want)
+	}
+
+	if got := Diff(&before, &before); len(got) != 0 {
+		t.Errorf("Valores iguais não deveriam ter alterações: %+v", got)
+	}
+}
+
+func TestDiffCreationAndRemoval(t *testing.T) {
+	created := Diff(nil, &member{Name: "Ana", Active: true})
+	want := []Change{
+		{Field: "name", Before: nil, After: "Ana"},
+		{Field: "active", Before: nil, After: true},
+	}
+	if !reflect.DeepEqual(created, want) {
+		t.Errorf("Criação deveria registrar os campos preenchidos: %+v", created)
+	}
+
+	var none *member
+	removed := Diff(member{Base: Base{ID: 7}, Email: "ana@email.com"}, none)
+	want = []Change{
+		{Field: "ID", Before: uint(7), After: nil},
+		{Field: "email", Before: "ana@email.com", After: nil},
+	}
+	if !reflect.DeepEqual(removed, want) {
+		t.Errorf("Remoção deveria registrar os campos preenchidos: %+v", removed)
+	}
+
+	if got := Diff(nil, nil); got != nil {
+		t.Errorf("Sem valores não deveria haver alterações: %+v", got)
+	}
+	if got := Diff(member{}, Base{}); got != nil {
+		t.Errorf("Tipos diferentes não deveriam ser comparados: %+v", got)
+	}
+}
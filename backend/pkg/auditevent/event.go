@@ -0,0 +1,161 @@
+// Package auditevent define os eventos de auditoria compartilhados pela API
+// principal e pelo auth-service: o nome das ações, o resultado, a entidade
+// afetada e as alterações de cada campo, além da origem da requisição (quem
+// agiu, de onde e com qual ID de correlação), propagada pelo context.
+package auditevent
+
+import (
+	"context"
+	"time"
+)
+
+// Ações registradas, no formato "entidade.ação"
+const (
+	LoginSucceeded         = "login.succeeded"
+	LoginFailed            = "login.failed"
+	UserRegistered         = "user.registered"
+	EmailVerified          = "email.verified"
+	PasswordChanged        = "password.changed"
+	PasswordResetRequested = "password.reset_requested"
+	MFAEnabled             = "mfa.enabled"
+	MFADisabled            = "mfa.disabled"
+	SessionRevoked         = "session.revoked"
+	SessionsRevoked        = "session.revoked_all"
+	SessionReuseDetected   = "session.reuse_detected"
+	APIKeyCreated          = "api_key.created"
+	APIKeyRevoked          = "api_key.revoked"
+	ImpersonationStarted   = "impersonation.started"
+	ImpersonatedRequest    = "impersonation.request"
+	AccountUnlocked        = "account.unlocked"
+	OAuthCodeIssued        = "oauth.code_issued"
+	OAuthTokenIssued       = "oauth.token_issued"
+	RoleCreated            = "role.created"
+	RoleUpdated            = "role.updated"
+	RoleDeleted            = "role.deleted"
+	RolePermissionGranted  = "role.permission_granted"
+	RolePermissionRevoked  = "role.permission_revoked"
+	MemberCreated          = "member.created"
+	MemberUpdated          = "member.updated"
+	MemberDeleted          = "member.deleted"
+	AuditQueried           = "audit.queried"
+)
+
+// Tipos de entidade usados em Target
+const (
+	TargetUser    = "user"
+	TargetRole    = "role"
+	TargetSession = "session"
+	TargetAPIKey  = "api_key"
+	TargetClient  = "client"
+)
+
+// Motivos de falha e complementos da ação comuns aos dois serviços
+const (
+	ReasonInvalidCredentials = "invalid_credentials"
+	ReasonAccountLocked      = "account_locked"
+	ReasonEmailNotVerified   = "email_not_verified"
+	ReasonPasswordExpired    = "password_expired"
+	ReasonInvalidCode        = "invalid_code"
+	ReasonDeviceMismatch     = "device_mismatch"
+	ReasonMagicLink          = "magic_link"
+	ReasonPasswordReset      = "password_reset"
+	ReasonMFARequired        = "mfa_required"
+)
+
+// Outcome é o resultado da ação
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+	// OutcomeDenied indica uma ação recusada por política ou suspeita de
+	// abuso, como a reutilização de um refresh token
+	OutcomeDenied Outcome = "denied"
+)
+
+// anonymous identifica eventos sem usuário autenticado
+const anonymous = "anonymous"
+
+// Event é um evento de auditoria emitido por um caso de uso. A origem da
+// requisição é obtida do context em NewEntry.
+type Event struct {
+	Action string
+	// UserID identifica quem executou a ação quando a origem ainda não o
+	// identifica, como no login; vazio usa o usuário da origem
+	UserID string
+	// Target é a entidade afetada, no formato de Target
+	Target  string
+	Changes []Change
+	// Outcome vazio equivale a OutcomeSuccess
+	Outcome Outcome
+	// Reason é o motivo da falha, a justificativa informada para a ação ou um
+	// complemento, como o método de login
+	Reason string
+}
+
+// Target identifica uma entidade como "tipo:id"
+func Target(kind, id string) string {
+	if id == "" {
+		return ""
+	}
+	return kind + ":" + id
+}
+
+// Recorder grava os eventos de auditoria. Falhas na gravação são tratadas
+// pelo próprio Recorder e nunca interrompem o caso de uso.
+type Recorder interface {
+	Record(ctx context.Context, event Event)
+}
+
+// Discard descarta os eventos; usado quando a auditoria não está configurada
+var Discard Recorder = discard{}
+
+type discard struct{}
+
+func (discard) Record(context.Context, Event) {}
+
+// Entry é o evento completado com a origem da requisição, no formato
+// gravado pelos Recorders
+type Entry struct {
+	Action        string
+	UserID        string
+	ActorID       string
+	Target        string
+	Changes       []Change
+	Outcome       Outcome
+	Reason        string
+	IP            string
+	UserAgent     string
+	CorrelationID string
+	Timestamp     time.Time
+}
+
+// NewEntry completa o evento com a origem da requisição em ctx. Sem usuário
+// no evento nem na origem, o evento é atribuído a "anonymous".
+func NewEntry(ctx context.Context, event Event, now time.Time) Entry {
+	origin := OriginFrom(ctx)
+
+	entry := Entry{
+		Action:        event.Action,
+		UserID:        event.UserID,
+		ActorID:       origin.ActorID,
+		Target:        event.Target,
+		Changes:       event.Changes,
+		Outcome:       event.Outcome,
+		Reason:        event.Reason,
+		IP:            origin.IP,
+		UserAgent:     origin.UserAgent,
+		CorrelationID: origin.CorrelationID,
+		Timestamp:     now,
+	}
+	if entry.UserID == "" {
+		entry.UserID = origin.UserID
+	}
+	if entry.UserID == "" {
+		entry.UserID = anonymous
+	}
+	if entry.Outcome == "" {
+		entry.Outcome = OutcomeSuccess
+	}
+	return entry
+}
@@ -0,0 +1,56 @@
+package auditevent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewEntry(t *testing.T) {
+	now := time.Now()
+	ctx := WithOrigin(context.Background(), Origin{IP: "10.0.0.1", UserAgent: "curl", CorrelationID: "req-1"})
+
+	// Sem usuário autenticado, o evento é anônimo e bem-sucedido por padrão
+	entry := NewEntry(ctx, Event{Action: LoginFailed, Outcome: OutcomeFailure, Reason: ReasonInvalidCredentials}, now)
+	if entry.UserID != "anonymous" || entry.Outcome != OutcomeFailure || entry.IP != "10.0.0.1" || entry.CorrelationID != "req-1" {
+		t.Errorf("Entrada inesperada: %+v", entry)
+	}
+
+	// O usuário do evento prevalece sobre a origem, que ainda não o conhece no login
+	entry = NewEntry(ctx, Event{Action: LoginSucceeded, UserID: "42", Target: Target(TargetUser, "42")}, now)
+	if entry.UserID != "42" || entry.Target != "user:42" || entry.Outcome != OutcomeSuccess {
+		t.Errorf("Entrada inesperada: %+v", entry)
+	}
+
+	// Na personificação, a origem traz o membro e o administrador
+	impersonated := WithIdentity(ctx, "42", "7")
+	entry = NewEntry(impersonated, Event{Action: SessionRevoked}, now)
+	if entry.UserID != "42" || entry.ActorID != "7" || entry.UserAgent != "curl" {
+		t.Errorf("Entrada inesperada: %+v", entry)
+	}
+	if origin := OriginFrom(ctx); origin.UserID != "" {
+		t.Errorf("WithIdentity não deveria alterar o context original: %+v", origin)
+	}
+
+	if Target(TargetRole, "") != "" {
+		t.Error("Target sem ID deveria ser vazio")
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	if id := CorrelationID("req-123_a.b:c"); id != "req-123_a.b:c" {
+		t.Errorf("ID válido deveria ser mantido, obteve %q", id)
+	}
+
+	for _, header := range []string{"", "com espaço", "quebra\nde linha", strings.Repeat("a", 129)} {
+		id := CorrelationID(header)
+		if id == header || len(id) != 32 {
+			t.Errorf("Esperava novo ID para %q, obteve %q", header, id)
+		}
+	}
+
+	if CorrelationID("") == CorrelationID("") {
+		t.Error("IDs gerados deveriam ser diferentes")
+	}
+}
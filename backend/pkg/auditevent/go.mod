@@ -0,0 +1,3 @@
+module github.com/insidechurch/auditevent
+
+go 1.21
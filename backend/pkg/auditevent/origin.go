@@ -0,0 +1,76 @@
+package auditevent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// CorrelationHeader é o header que transporta o ID de correlação entre os
+// serviços e é devolvido na resposta
+const CorrelationHeader = "X-Request-ID"
+
+// maxCorrelationIDLength limita o ID de correlação recebido de clientes
+const maxCorrelationIDLength = 128
+
+// Origin descreve a requisição que originou os eventos: o usuário
+// autenticado, o administrador que age em seu nome na personificação, o
+// cliente e o ID de correlação
+type Origin struct {
+	UserID        string
+	ActorID       string
+	IP            string
+	UserAgent     string
+	CorrelationID string
+}
+
+type originKey struct{}
+
+// WithOrigin associa a origem da requisição ao context
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+// OriginFrom retorna a origem associada ao context, ou uma origem vazia
+func OriginFrom(ctx context.Context) Origin {
+	origin, _ := ctx.Value(originKey{}).(Origin)
+	return origin
+}
+
+// WithIdentity registra na origem o usuário autenticado e, na
+// personificação, o administrador que age em seu nome
+func WithIdentity(ctx context.Context, userID, actorID string) context.Context {
+	origin := OriginFrom(ctx)
+	origin.UserID = userID
+	origin.ActorID = actorID
+	return WithOrigin(ctx, origin)
+}
+
+// CorrelationID retorna o ID de correlação recebido no header, quando válido,
+// ou um novo ID aleatório. São aceitos até 128 letras, dígitos e "-_.:".
+func CorrelationID(header string) string {
+	if validCorrelationID(header) {
+		return header
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(raw)
+}
+
+func validCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
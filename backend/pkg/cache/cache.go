@@ -1,3 +1,6 @@
+// Package cache define o cache compartilhado pela API principal e pelo
+// auth-service, em memória ou no Redis, usado pela denylist de tokens, pelo
+// bloqueio de conta e pela revogação de sessões.
 package cache
 
 import (
@@ -19,6 +22,10 @@ type Cache interface {
 	// Exists verifica se uma chave existe no cache
 	Exists(ctx context.Context, key string) (bool, error)
 
+	// Incr incrementa de forma atômica o contador da chave, criado com zero
+	// se não existir, renova a sua expiração e retorna o novo valor
+	Incr(ctx context.Context, key string, expiration time.Duration) (int64, error)
+
 	// Flush limpa todo o cache
 	Flush(ctx context.Context) error
 }
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// memoryEntry representa um valor armazenado em memória
+type memoryEntry struct {
+	value     interface{}
+	expiresAt time.Time // zero significa sem expiração
+}
+
+// expired verifica se a entrada já expirou
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryCache implementa a interface Cache em memória, para uso local e em testes
+type MemoryCache struct {
+	mu          sync.Mutex
+	entries     map[string]memoryEntry
+	lastEvicted time.Time
+}
+
+// Intervalo mínimo entre varreduras de entradas expiradas
+const evictionInterval = time.Minute
+
+// NewMemoryCache cria uma nova instância do MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+// Get recupera um valor do cache
+func (c *MemoryCache) Get(ctx context.Context, key string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists {
+		return nil, nil
+	}
+	if entry.expired(time.Now()) {
+		delete(c.entries, key)
+		return nil, nil
+	}
+	return entry.value, nil
+}
+
+// Set armazena um valor no cache
+func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entry := memoryEntry{value: value}
+	if expiration > 0 {
+		entry.expiresAt = now.Add(expiration)
+	}
+
+	c.entries[key] = entry
+	c.evictExpired(now)
+	return nil
+}
+
+// Delete remove um valor do cache
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+	return nil
+}
+
+// Exists verifica se uma chave existe no cache
+func (c *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	value, err := c.Get(ctx, key)
+	return value != nil, err
+}
+
+// Incr incrementa o contador da chave
+func (c *MemoryCache) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	var count int64
+	if entry, exists := c.entries[key]; exists && !entry.expired(now) {
+		current, ok := entry.value.(int64)
+		if !ok {
+			return 0, fmt.Errorf("valor da chave %q não é um contador", key)
+		}
+		count = current
+	}
+	count++
+
+	entry := memoryEntry{value: count}
+	if expiration > 0 {
+		entry.expiresAt = now.Add(expiration)
+	}
+	c.entries[key] = entry
+	c.evictExpired(now)
+	return count, nil
+}
+
+// Flush limpa todo o cache
+func (c *MemoryCache) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]memoryEntry)
+	return nil
+}
+
+// evictExpired remove as entradas expiradas, no máximo uma vez por
+// evictionInterval; deve ser chamado com o lock adquirido
+func (c *MemoryCache) evictExpired(now time.Time) {
+	if now.Sub(c.lastEvicted) < evictionInterval {
+		return
+	}
+	c.lastEvicted = now
+
+	for key, entry := range c.entries {
+		if entry.expired(now) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache()
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "chave", "valor", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := c.Get(ctx, "chave")
+	if err != nil || value != "valor" {
+		t.Errorf("Valor esperado 'valor', obtido %v (erro: %v)", value, err)
+	}
+
+	if err := c.Delete(ctx, "chave"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := c.Exists(ctx, "chave"); exists {
+		t.Error("Chave deveria ter sido removida")
+	}
+}
+
+func TestMemoryCacheExpiration(t *testing.T) {
+	c := NewMemoryCache()
+	ctx := context.Background()
+
+	c.Set(ctx, "temporaria", "valor", 10*time.Millisecond)
+	c.Set(ctx, "permanente", "valor", 0)
+	time.Sleep(20 * time.Millisecond)
+
+	if exists, _ := c.Exists(ctx, "temporaria"); exists {
+		t.Error("Chave deveria ter expirado")
+	}
+	if exists, _ := c.Exists(ctx, "permanente"); !exists {
+		t.Error("Chave sem expiração não deveria expirar")
+	}
+}
+
+func TestMemoryCacheIncr(t *testing.T) {
+	c := NewMemoryCache()
+	ctx := context.Background()
+
+	for want := int64(1); want <= 3; want++ {
+		if count, err := c.Incr(ctx, "contador", time.Minute); err != nil || count != want {
+			t.Errorf("Esperava %d, obteve %d (erro: %v)", want, count, err)
+		}
+	}
+
+	c.Set(ctx, "texto", "valor", time.Minute)
+	if _, err := c.Incr(ctx, "texto", time.Minute); err == nil {
+		t.Error("Incr de um valor que não é contador deveria falhar")
+	}
+
+	c.Incr(ctx, "temporario", 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if count, _ := c.Incr(ctx, "temporario", time.Minute); count != 1 {
+		t.Errorf("Contador expirado deveria recomeçar, obteve %d", count)
+	}
+}
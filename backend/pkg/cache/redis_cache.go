@@ -9,6 +9,17 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// incrScript incrementa o contador e renova a expiração na mesma operação,
+// para que o contador não fique sem expiração se o cliente falhar entre os
+// dois comandos
+var incrScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if tonumber(ARGV[1]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
 // RedisCache implementa a interface Cache usando Redis
 type RedisCache struct {
 	client *redis.Client
@@ -70,6 +81,15 @@ func (c *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
 	return exists > 0, nil
 }
 
+// Incr incrementa o contador da chave
+func (c *RedisCache) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	count, err := incrScript.Run(ctx, c.client, []string{key}, expiration.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao incrementar contador no cache: %w", err)
+	}
+	return count, nil
+}
+
 // Flush limpa todo o cache
 func (c *RedisCache) Flush(ctx context.Context) error {
 	if err := c.client.FlushAll(ctx).Err(); err != nil {
@@ -0,0 +1,105 @@
+// Package denylist guarda no cache compartilhado os access tokens revogados
+// antes da expiração: por jti, por sessão (sid) ou pelo corte de todos os
+// tokens do usuário. É usado pelo auth-service e pela API principal.
+package denylist
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/insidechurch/cache"
+)
+
+// Prefixos das chaves armazenadas no cache
+const (
+	tokenKeyPrefix   = "denylist:jti:"
+	sessionKeyPrefix = "denylist:sid:"
+	userKeyPrefix    = "denylist:user:"
+)
+
+// Denylist mantém os access tokens invalidados antes da expiração. As
+// entradas expiram junto com os tokens que bloqueiam, de modo que o cache
+// não cresce indefinidamente.
+type Denylist struct {
+	cache cache.Cache
+}
+
+// New cria uma nova denylist sobre o cache informado
+func New(c cache.Cache) *Denylist {
+	return &Denylist{cache: c}
+}
+
+// RevokeToken invalida um token específico até a sua expiração
+func (d *Denylist) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	if err := d.cache.Set(ctx, tokenKeyPrefix+jti, "1", ttl); err != nil {
+		return fmt.Errorf("erro ao revogar token: %w", err)
+	}
+	return nil
+}
+
+// RevokeSession invalida os tokens de uma sessão (família de refresh tokens).
+// O ttl deve ser a validade máxima de um access token.
+func (d *Denylist) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	if err := d.cache.Set(ctx, sessionKeyPrefix+sessionID, "1", ttl); err != nil {
+		return fmt.Errorf("erro ao revogar sessão: %w", err)
+	}
+	return nil
+}
+
+// RevokeUser invalida todos os tokens do usuário emitidos até agora. O corte
+// é guardado em milissegundos, para que tokens emitidos logo após a revogação,
+// como o do novo login depois de uma redefinição de senha, continuem válidos;
+// o iat dos tokens deve ter a mesma precisão (jwt.TimePrecision). O ttl deve
+// ser a validade máxima de um access token.
+func (d *Denylist) RevokeUser(ctx context.Context, userID string, ttl time.Duration) error {
+	cutoff := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	if err := d.cache.Set(ctx, userKeyPrefix+userID, cutoff, ttl); err != nil {
+		return fmt.Errorf("erro ao revogar tokens do usuário: %w", err)
+	}
+	return nil
+}
+
+// IsRevoked verifica se o token foi revogado individualmente, junto com a
+// sua sessão ou por uma revogação de todas as sessões do usuário
+func (d *Denylist) IsRevoked(ctx context.Context, jti, sessionID, userID string, issuedAt time.Time) (bool, error) {
+	keys := make([]string, 0, 2)
+	if jti != "" {
+		keys = append(keys, tokenKeyPrefix+jti)
+	}
+	if sessionID != "" {
+		keys = append(keys, sessionKeyPrefix+sessionID)
+	}
+
+	for _, key := range keys {
+		revoked, err := d.cache.Exists(ctx, key)
+		if err != nil {
+			return false, fmt.Errorf("erro ao consultar denylist: %w", err)
+		}
+		if revoked {
+			return true, nil
+		}
+	}
+
+	value, err := d.cache.Get(ctx, userKeyPrefix+userID)
+	if err != nil {
+		return false, fmt.Errorf("erro ao consultar denylist: %w", err)
+	}
+	if value == nil {
+		return false, nil
+	}
+
+	cutoff, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("valor inválido na denylist: %v", value)
+	}
+
+	// Tokens emitidos no mesmo milissegundo da revogação também são bloqueados
+	return issuedAt.UnixMilli() <= cutoff, nil
+}
@@ -0,0 +1,64 @@
+package denylist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/insidechurch/cache"
+)
+
+func TestRevokeToken(t *testing.T) {
+	d := New(cache.NewMemoryCache())
+	ctx := context.Background()
+
+	if err := d.RevokeToken(ctx, "jti-1", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	revoked, err := d.IsRevoked(ctx, "jti-1", "", "1", time.Now())
+	if err != nil || !revoked {
+		t.Errorf("Token deveria estar revogado (erro: %v)", err)
+	}
+
+	revoked, err = d.IsRevoked(ctx, "jti-2", "", "1", time.Now())
+	if err != nil || revoked {
+		t.Errorf("Token não deveria estar revogado (erro: %v)", err)
+	}
+}
+
+func TestRevokeUser(t *testing.T) {
+	d := New(cache.NewMemoryCache())
+	ctx := context.Background()
+
+	issuedBefore := time.Now().Add(-time.Minute)
+	if err := d.RevokeUser(ctx, "1", 15*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked, _ := d.IsRevoked(ctx, "jti-1", "", "1", issuedBefore); !revoked {
+		t.Error("Tokens emitidos antes da revogação deveriam estar revogados")
+	}
+	if revoked, _ := d.IsRevoked(ctx, "jti-2", "", "1", time.Now().Add(2*time.Millisecond)); revoked {
+		t.Error("Tokens emitidos após a revogação não deveriam estar revogados")
+	}
+	if revoked, _ := d.IsRevoked(ctx, "jti-3", "", "2", issuedBefore); revoked {
+		t.Error("Tokens de outros usuários não deveriam estar revogados")
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	d := New(cache.NewMemoryCache())
+	ctx := context.Background()
+
+	if err := d.RevokeSession(ctx, "sessao-1", 15*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked, _ := d.IsRevoked(ctx, "jti-1", "sessao-1", "1", time.Now()); !revoked {
+		t.Error("Tokens da sessão revogada deveriam estar revogados")
+	}
+	if revoked, _ := d.IsRevoked(ctx, "jti-2", "sessao-2", "1", time.Now()); revoked {
+		t.Error("Tokens de outras sessões não deveriam estar revogados")
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/insidechurch/denylist
+
+go 1.21
+
+require github.com/insidechurch/cache v0.0.0
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/redis/go-redis/v9 v9.9.0 // indirect
+)
+
+replace github.com/insidechurch/cache => ../cache
@@ -0,0 +1,5 @@
+module github.com/insidechurch/keys
+
+go 1.21
+
+require github.com/golang-jwt/jwt/v5 v5.0.0
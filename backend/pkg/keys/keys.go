@@ -0,0 +1,239 @@
+// Package keys assina e verifica os tokens JWT com chaves assimétricas (RSA
+// ou Ed25519) identificadas pelo kid publicado no JWKS. É usado pelo
+// auth-service e pela API principal.
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKey        = errors.New("chave de assinatura desconhecida")
+	ErrUnsupportedKey    = errors.New("tipo de chave não suportado")
+	ErrAlgorithmMismatch = errors.New("algoritmo do token não corresponde à chave")
+	ErrNoSigningKey      = errors.New("nenhuma chave de assinatura configurada")
+)
+
+// Key é uma chave assimétrica identificada pelo kid publicado no JWKS
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	Public crypto.PublicKey
+
+	private crypto.Signer
+}
+
+// newKey identifica o algoritmo e calcula o kid de uma chave pública. A chave
+// privada é opcional: chaves anteriores à rotação só verificam assinaturas.
+func newKey(public crypto.PublicKey, private crypto.Signer) (*Key, error) {
+	var method jwt.SigningMethod
+	switch public.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	jwk, err := publicJWK(public)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		ID:      thumbprint(jwk),
+		Method:  method,
+		Public:  public,
+		private: private,
+	}, nil
+}
+
+// JWK representa uma chave pública no formato JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// OKP (Ed25519)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS é o documento publicado em /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK retorna a representação pública da chave
+func (k *Key) JWK() JWK {
+	jwk, _ := publicJWK(k.Public)
+	jwk.Kid = k.ID
+	jwk.Use = "sig"
+	jwk.Alg = k.Method.Alg()
+	return jwk
+}
+
+// publicJWK monta os membros obrigatórios da chave pública
+func publicJWK(public crypto.PublicKey) (JWK, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	}
+	return JWK{}, ErrUnsupportedKey
+}
+
+// thumbprint calcula o JWK Thumbprint (RFC 7638) usado como kid. Os membros
+// obrigatórios são serializados em ordem lexicográfica e sem espaços.
+func thumbprint(jwk JWK) string {
+	var canonical string
+	switch jwk.Kty {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, jwk.Crv, jwk.X)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// KeySet reúne a chave usada para assinar novos tokens e as chaves anteriores,
+// mantidas apenas para verificação até que os tokens emitidos com elas expirem
+type KeySet struct {
+	signing *Key
+	byID    map[string]*Key
+	ordered []*Key
+}
+
+// NewKeySet cria um conjunto de chaves a partir da chave de assinatura e das
+// chaves públicas anteriores à rotação
+func NewKeySet(signer crypto.Signer, previous ...crypto.PublicKey) (*KeySet, error) {
+	if signer == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	signing, err := newKey(signer.Public(), signer)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{
+		signing: signing,
+		byID:    map[string]*Key{signing.ID: signing},
+		ordered: []*Key{signing},
+	}
+
+	for _, public := range previous {
+		key, err := newKey(public, nil)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := ks.byID[key.ID]; exists {
+			continue
+		}
+		ks.byID[key.ID] = key
+		ks.ordered = append(ks.ordered, key)
+	}
+
+	return ks, nil
+}
+
+// NewVerificationSet cria um conjunto apenas para verificação, sem chave de
+// assinatura, usado por ferramentas que conferem tokens e checkpoints já
+// emitidos
+func NewVerificationSet(public ...crypto.PublicKey) (*KeySet, error) {
+	ks := &KeySet{byID: make(map[string]*Key)}
+	for _, key := range public {
+		verification, err := newKey(key, nil)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := ks.byID[verification.ID]; exists {
+			continue
+		}
+		ks.byID[verification.ID] = verification
+		ks.ordered = append(ks.ordered, verification)
+	}
+	return ks, nil
+}
+
+// SigningKey retorna a chave usada para assinar novos tokens, ou nil em um
+// conjunto apenas de verificação
+func (ks *KeySet) SigningKey() *Key {
+	return ks.signing
+}
+
+// Sign assina as claims com a chave atual e inclui o kid no cabeçalho
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if ks.signing == nil {
+		return "", ErrNoSigningKey
+	}
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.private)
+}
+
+// Keyfunc localiza a chave pública pelo kid do token, para uso com jwt.Parse
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, exists := ks.byID[kid]
+	if !exists {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrAlgorithmMismatch
+	}
+	return key.Public, nil
+}
+
+// Has informa se o conjunto contém a chave identificada pelo kid
+func (ks *KeySet) Has(kid string) bool {
+	_, exists := ks.byID[kid]
+	return exists
+}
+
+// Methods retorna os algoritmos aceitos na verificação
+func (ks *KeySet) Methods() []string {
+	seen := make(map[string]bool)
+	var methods []string
+	for _, key := range ks.ordered {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JWKS retorna o documento com todas as chaves públicas do conjunto
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(ks.ordered))}
+	for _, key := range ks.ordered {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+	return set
+}
@@ -0,0 +1,142 @@
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestThumbprintRFC7638(t *testing.T) {
+	// Exemplo da seção 3.1 da RFC 7638
+	jwk := JWK{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	}
+
+	if kid := thumbprint(jwk); kid != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Errorf("Thumbprint incorreto: %s", kid)
+	}
+}
+
+func TestSignAndVerifyWithRotation(t *testing.T) {
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	oldSet, err := NewKeySet(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := jwt.RegisteredClaims{Subject: "1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+	oldToken, err := oldSet.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nova chave RSA assina; a Ed25519 anterior continua aceita na verificação
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := NewKeySet(rsaKey, oldKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newToken, err := ks.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tokenString := range []string{oldToken, newToken} {
+		if _, err := jwt.Parse(tokenString, ks.Keyfunc, jwt.WithValidMethods(ks.Methods())); err != nil {
+			t.Errorf("Token deveria ser válido: %v", err)
+		}
+	}
+
+	parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, &jwt.RegisteredClaims{})
+	if parsed.Header["kid"] != ks.SigningKey().ID || parsed.Method.Alg() != "RS256" {
+		t.Errorf("Cabeçalho inesperado: %v", parsed.Header)
+	}
+
+	if got := len(ks.JWKS().Keys); got != 2 {
+		t.Errorf("Esperava 2 chaves no JWKS, obteve %d", got)
+	}
+
+	// Token de uma chave fora do conjunto é recusado
+	other, _ := Generate()
+	foreign, _ := other.Sign(claims)
+	if _, err := jwt.Parse(foreign, ks.Keyfunc); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Esperava ErrUnknownKey, obteve %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	privateFile := filepath.Join(dir, "private.pem")
+	writePEM(t, privateFile, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	oldPublic, _, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(oldPublic)
+	publicFile := filepath.Join(dir, "previous.pem")
+	writePEM(t, publicFile, "PUBLIC KEY", der)
+
+	ks, err := Load(&Config{PrivateKeyFile: privateFile, PreviousPublicFiles: []string{publicFile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := ks.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Alg != "RS256" || jwks.Keys[1].Alg != "EdDSA" {
+		t.Errorf("JWKS inesperado: %+v", jwks)
+	}
+
+	if _, err := Load(&Config{}); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("Esperava ErrNoSigningKey, obteve %v", err)
+	}
+}
+
+func TestVerificationSet(t *testing.T) {
+	signer, _ := Generate()
+	claims := jwt.RegisteredClaims{Subject: "1"}
+	token, _ := signer.Sign(claims)
+
+	dir := t.TempDir()
+	der, _ := x509.MarshalPKIXPublicKey(signer.SigningKey().Public)
+	publicFile := filepath.Join(dir, "public.pem")
+	writePEM(t, publicFile, "PUBLIC KEY", der)
+
+	public, err := LoadPublicKeys(publicFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := NewVerificationSet(public...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := jwt.Parse(token, ks.Keyfunc, jwt.WithValidMethods(ks.Methods())); err != nil {
+		t.Errorf("Token deveria ser válido: %v", err)
+	}
+	if _, err := ks.Sign(claims); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("Esperava ErrNoSigningKey, obteve %v", err)
+	}
+}
+
+func writePEM(t *testing.T, file, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
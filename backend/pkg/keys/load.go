@@ -0,0 +1,133 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config define a origem das chaves de assinatura
+type Config struct {
+	PrivateKeyFile      string   // chave privada atual (PEM, PKCS#8 ou PKCS#1)
+	PreviousPublicFiles []string // chaves públicas anteriores à rotação (PEM, PKIX)
+}
+
+// NewConfig cria a configuração a partir das variáveis de ambiente
+// JWT_PRIVATE_KEY_FILE e JWT_PREVIOUS_PUBLIC_KEY_FILES (separadas por vírgula)
+func NewConfig() *Config {
+	cfg := &Config{
+		PrivateKeyFile: os.Getenv("JWT_PRIVATE_KEY_FILE"),
+	}
+	for _, file := range strings.Split(os.Getenv("JWT_PREVIOUS_PUBLIC_KEY_FILES"), ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			cfg.PreviousPublicFiles = append(cfg.PreviousPublicFiles, file)
+		}
+	}
+	return cfg
+}
+
+// Load carrega o conjunto de chaves descrito na configuração
+func Load(cfg *Config) (*KeySet, error) {
+	if cfg.PrivateKeyFile == "" {
+		return nil, ErrNoSigningKey
+	}
+
+	signer, err := readPrivateKey(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := LoadPublicKeys(cfg.PreviousPublicFiles...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeySet(signer, previous...)
+}
+
+// LoadPublicKeys carrega chaves públicas RSA ou Ed25519 em PEM
+func LoadPublicKeys(files ...string) ([]crypto.PublicKey, error) {
+	public := make([]crypto.PublicKey, 0, len(files))
+	for _, file := range files {
+		key, err := readPublicKey(file)
+		if err != nil {
+			return nil, err
+		}
+		public = append(public, key)
+	}
+	return public, nil
+}
+
+// Generate cria um conjunto com uma chave Ed25519 efêmera. Os tokens assinados
+// com ela deixam de ser válidos quando o processo reinicia.
+func Generate() (*KeySet, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar chave de assinatura: %w", err)
+	}
+	return NewKeySet(private)
+}
+
+// readPEM lê o primeiro bloco PEM do arquivo
+func readPEM(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler chave %s: %w", file, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("arquivo %s não contém uma chave PEM", file)
+	}
+	return block, nil
+}
+
+// readPrivateKey lê uma chave privada RSA ou Ed25519
+func readPrivateKey(file string) (crypto.Signer, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao interpretar chave %s: %w", file, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+	return signer, nil
+}
+
+// readPublicKey lê uma chave pública RSA ou Ed25519
+func readPublicKey(file string) (crypto.PublicKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao interpretar chave %s: %w", file, err)
+	}
+	return key, nil
+}
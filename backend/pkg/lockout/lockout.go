@@ -0,0 +1,189 @@
+// Package lockout conta as falhas de autenticação por conta e por IP e
+// aplica atrasos crescentes e bloqueios temporários. É usado pelo
+// auth-service e pela API principal.
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/insidechurch/cache"
+)
+
+// Policy define quantas falhas são toleradas e como as tentativas seguintes
+// são atrasadas ou bloqueadas
+type Policy struct {
+	// FreeAttempts é o número de falhas aceitas sem nenhum atraso
+	FreeAttempts int
+	// BaseDelay é o atraso após a primeira falha excedente, dobrado a cada
+	// nova falha até MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockAfter é o número de falhas que bloqueia a chave por LockDuration;
+	// zero desativa o bloqueio, mantendo apenas os atrasos
+	LockAfter    int
+	LockDuration time.Duration
+	// Window é o tempo sem falhas após o qual o contador é zerado
+	Window time.Duration
+}
+
+// Status representa a situação de uma chave após a consulta ou a falha
+type Status struct {
+	Failures int
+	// RetryAfter é o tempo até a próxima tentativa ser aceita; zero indica
+	// que a tentativa pode prosseguir
+	RetryAfter time.Duration
+	// Locked indica um bloqueio temporário, e não apenas um atraso
+	Locked bool
+	// NewlyLocked indica que a falha registrada aplicou o bloqueio
+	NewlyLocked bool
+}
+
+// Tracker conta as falhas de autenticação por chave (conta ou IP). O estado
+// fica no cache, de modo que é compartilhado entre as réplicas quando o
+// cache é o Redis. O contador de falhas é incrementado de forma atômica
+// (Cache.Incr), para que tentativas simultâneas não deixem de ser contadas.
+type Tracker struct {
+	cache  cache.Cache
+	prefix string
+	policy Policy
+	now    func() time.Time
+}
+
+// New cria um novo Tracker. prefix separa as chaves de cada Tracker no cache.
+func New(c cache.Cache, prefix string, policy Policy) *Tracker {
+	return &Tracker{
+		cache:  c,
+		prefix: prefix,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// state é a situação de uma chave: o contador de falhas e o fim do atraso ou
+// do bloqueio, guardados em chaves separadas do cache
+type state struct {
+	failures     int
+	blockedUntil time.Time
+}
+
+// Check retorna a situação da chave sem registrar uma tentativa
+func (t *Tracker) Check(ctx context.Context, key string) (Status, error) {
+	s, err := t.load(ctx, key)
+	if err != nil {
+		return Status{}, err
+	}
+	return t.status(s), nil
+}
+
+// Fail registra uma falha e aplica o atraso ou o bloqueio correspondente
+func (t *Tracker) Fail(ctx context.Context, key string) (Status, error) {
+	previous, err := t.load(ctx, key)
+	if err != nil {
+		return Status{}, err
+	}
+	wasLocked := t.status(previous).Locked
+
+	// O contador dura a janela de falhas e, com o bloqueio ativo, o bloqueio
+	ttl := t.policy.Window
+	if t.policy.LockAfter > 0 && t.policy.LockDuration > ttl {
+		ttl = t.policy.LockDuration
+	}
+	failures, err := t.cache.Incr(ctx, t.failuresKey(key), ttl)
+	if err != nil {
+		return Status{}, fmt.Errorf("erro ao registrar falha de autenticação: %w", err)
+	}
+
+	now := t.now()
+	s := state{failures: int(failures)}
+	switch {
+	case t.policy.LockAfter > 0 && s.failures >= t.policy.LockAfter:
+		s.blockedUntil = now.Add(t.policy.LockDuration)
+	case s.failures > t.policy.FreeAttempts:
+		s.blockedUntil = now.Add(t.delay(s.failures - t.policy.FreeAttempts))
+	}
+
+	if remaining := s.blockedUntil.Sub(now); remaining > 0 {
+		value := strconv.FormatInt(s.blockedUntil.UnixMilli(), 10)
+		if err := t.cache.Set(ctx, t.blockedKey(key), value, remaining); err != nil {
+			return Status{}, fmt.Errorf("erro ao registrar falha de autenticação: %w", err)
+		}
+	}
+
+	status := t.status(s)
+	status.NewlyLocked = status.Locked && !wasLocked
+	return status, nil
+}
+
+// Reset zera as falhas da chave, após um login bem-sucedido ou um desbloqueio
+func (t *Tracker) Reset(ctx context.Context, key string) error {
+	for _, k := range []string{t.failuresKey(key), t.blockedKey(key)} {
+		if err := t.cache.Delete(ctx, k); err != nil {
+			return fmt.Errorf("erro ao limpar falhas de autenticação: %w", err)
+		}
+	}
+	return nil
+}
+
+// delay calcula o atraso exponencial da n-ésima falha excedente
+func (t *Tracker) delay(excess int) time.Duration {
+	delay := t.policy.BaseDelay
+	for i := 1; i < excess && delay < t.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > t.policy.MaxDelay {
+		delay = t.policy.MaxDelay
+	}
+	return delay
+}
+
+func (t *Tracker) status(s state) Status {
+	status := Status{Failures: s.failures}
+	if remaining := s.blockedUntil.Sub(t.now()); remaining > 0 {
+		status.RetryAfter = remaining
+		status.Locked = t.policy.LockAfter > 0 && s.failures >= t.policy.LockAfter
+	}
+	return status
+}
+
+// Chaves do contador de falhas e do fim do atraso ou bloqueio, em ms
+func (t *Tracker) failuresKey(key string) string { return t.prefix + "failures:" + key }
+func (t *Tracker) blockedKey(key string) string  { return t.prefix + "blocked:" + key }
+
+// load lê o contador de falhas e o fim do atraso ou bloqueio da chave
+func (t *Tracker) load(ctx context.Context, key string) (state, error) {
+	failures, err := t.number(ctx, t.failuresKey(key))
+	if err != nil {
+		return state{}, err
+	}
+	blockedUntil, err := t.number(ctx, t.blockedKey(key))
+	if err != nil {
+		return state{}, err
+	}
+
+	s := state{failures: int(failures)}
+	if blockedUntil > 0 {
+		s.blockedUntil = time.UnixMilli(blockedUntil)
+	}
+	return s, nil
+}
+
+// number lê um valor numérico do cache; chaves ausentes valem zero
+func (t *Tracker) number(ctx context.Context, key string) (int64, error) {
+	value, err := t.cache.Get(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao consultar falhas de autenticação: %w", err)
+	}
+	if value == nil {
+		return 0, nil
+	}
+
+	// O RedisCache devolve os números desserializados como float64
+	number, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("valor inválido no controle de falhas: %v", value)
+	}
+	return int64(number), nil
+}
@@ -0,0 +1,120 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/insidechurch/cache"
+)
+
+var testPolicy = Policy{
+	FreeAttempts: 2,
+	BaseDelay:    time.Second,
+	MaxDelay:     4 * time.Second,
+	LockAfter:    6,
+	LockDuration: 15 * time.Minute,
+	Window:       time.Hour,
+}
+
+// newTestTracker cria um Tracker com relógio controlado pelo teste
+func newTestTracker() (*Tracker, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	tracker := New(cache.NewMemoryCache(), "lockout:test:", testPolicy)
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+func TestProgressiveDelay(t *testing.T) {
+	tracker, _ := newTestTracker()
+	ctx := context.Background()
+
+	// Falhas toleradas não geram atraso; as seguintes dobram até MaxDelay
+	expected := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second}
+	for i, want := range expected {
+		status, err := tracker.Fail(ctx, "maria@email.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.RetryAfter != want || status.Locked {
+			t.Errorf("falha %d: esperava atraso %v sem bloqueio, obteve %+v", i+1, want, status)
+		}
+	}
+
+	status, _ := tracker.Check(ctx, "maria@email.com")
+	if status.Failures != 5 || status.RetryAfter != 4*time.Second {
+		t.Errorf("Check deveria refletir a última falha, obteve %+v", status)
+	}
+
+	// Outras chaves não são afetadas
+	if status, _ := tracker.Check(ctx, "joao@email.com"); status.Failures != 0 || status.RetryAfter != 0 {
+		t.Errorf("chave sem falhas não deveria ter atraso: %+v", status)
+	}
+}
+
+func TestLockAndReset(t *testing.T) {
+	tracker, now := newTestTracker()
+	ctx := context.Background()
+
+	var status Status
+	for i := 0; i < testPolicy.LockAfter; i++ {
+		status, _ = tracker.Fail(ctx, "maria@email.com")
+	}
+	if !status.Locked || !status.NewlyLocked || status.RetryAfter != testPolicy.LockDuration {
+		t.Fatalf("esperava bloqueio de %v, obteve %+v", testPolicy.LockDuration, status)
+	}
+
+	// Novas falhas durante o bloqueio não o reaplicam como um novo evento
+	if status, _ := tracker.Fail(ctx, "maria@email.com"); !status.Locked || status.NewlyLocked {
+		t.Errorf("bloqueio deveria continuar sem novo aviso: %+v", status)
+	}
+
+	// O bloqueio expira com o tempo
+	*now = now.Add(testPolicy.LockDuration)
+	if status, _ := tracker.Check(ctx, "maria@email.com"); status.RetryAfter != 0 || status.Locked {
+		t.Errorf("bloqueio deveria ter expirado: %+v", status)
+	}
+
+	tracker.Fail(ctx, "maria@email.com")
+	if err := tracker.Reset(ctx, "maria@email.com"); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := tracker.Check(ctx, "maria@email.com"); status.Failures != 0 || status.RetryAfter != 0 {
+		t.Errorf("Reset deveria zerar as falhas: %+v", status)
+	}
+}
+
+func TestWithoutLock(t *testing.T) {
+	tracker, _ := newTestTracker()
+	tracker.policy.LockAfter = 0
+	ctx := context.Background()
+
+	var status Status
+	for i := 0; i < 20; i++ {
+		status, _ = tracker.Fail(ctx, "10.0.0.1")
+	}
+	if status.Locked || status.RetryAfter != testPolicy.MaxDelay {
+		t.Errorf("sem LockAfter o atraso deveria se limitar a MaxDelay: %+v", status)
+	}
+}
+
+func TestConcurrentFailures(t *testing.T) {
+	tracker, _ := newTestTracker()
+	ctx := context.Background()
+
+	// Falhas simultâneas não podem se sobrepor e deixar de ser contadas
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tracker.Fail(ctx, "maria@email.com")
+		}()
+	}
+	wg.Wait()
+
+	if status, _ := tracker.Check(ctx, "maria@email.com"); status.Failures != 50 || !status.Locked {
+		t.Errorf("esperava 50 falhas e a conta bloqueada, obteve %+v", status)
+	}
+}
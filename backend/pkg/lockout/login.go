@@ -0,0 +1,104 @@
+package lockout
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/insidechurch/cache"
+)
+
+// Políticas de falhas de login, comuns ao auth-service e à API. A conta é
+// bloqueada temporariamente após repetidas senhas incorretas; o IP recebe
+// apenas atrasos crescentes, com limites maiores, já que pode ser
+// compartilhado por vários membros.
+var (
+	AccountPolicy = Policy{
+		FreeAttempts: 3,
+		BaseDelay:    time.Second,
+		MaxDelay:     30 * time.Second,
+		LockAfter:    10,
+		LockDuration: 15 * time.Minute,
+		Window:       time.Hour,
+	}
+	IPPolicy = Policy{
+		FreeAttempts: 20,
+		BaseDelay:    time.Second,
+		MaxDelay:     time.Minute,
+		Window:       time.Hour,
+	}
+)
+
+// Prefixos das chaves de login no cache, os mesmos nos dois serviços
+const (
+	AccountPrefix = "lockout:account:"
+	IPPrefix      = "lockout:ip:"
+)
+
+// Login controla as falhas de login por conta e por IP. Com o mesmo cache,
+// as falhas registradas por um serviço valem também no outro, de modo que
+// trocar de endpoint não contorna o bloqueio.
+type Login struct {
+	Account *Tracker
+	IP      *Tracker
+}
+
+// NewLogin cria uma nova instância do Login com as políticas padrão
+func NewLogin(c cache.Cache) *Login {
+	return &Login{
+		Account: New(c, AccountPrefix, AccountPolicy),
+		IP:      New(c, IPPrefix, IPPolicy),
+	}
+}
+
+// AccountKey normaliza o email usado como chave da conta
+func AccountKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// RetryAfter retorna quanto tempo o login deve aguardar para a conta ou o IP.
+// Em caso de erro, o atraso das consultas que funcionaram é retornado junto.
+func (l *Login) RetryAfter(ctx context.Context, email, ip string) (time.Duration, error) {
+	var retryAfter time.Duration
+	var firstErr error
+	for _, check := range []struct {
+		tracker *Tracker
+		key     string
+	}{{l.Account, AccountKey(email)}, {l.IP, ip}} {
+		if check.key == "" {
+			continue
+		}
+		status, err := check.tracker.Check(ctx, check.key)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if status.RetryAfter > retryAfter {
+			retryAfter = status.RetryAfter
+		}
+	}
+	return retryAfter, firstErr
+}
+
+// Fail registra a falha para o IP e para a conta e retorna a situação da
+// conta. O email é contado mesmo sem conta cadastrada, para não revelar quais
+// emails existem.
+func (l *Login) Fail(ctx context.Context, email, ip string) (Status, error) {
+	var ipErr error
+	if ip != "" {
+		_, ipErr = l.IP.Fail(ctx, ip)
+	}
+	status, err := l.Account.Fail(ctx, AccountKey(email))
+	if err != nil {
+		return status, err
+	}
+	return status, ipErr
+}
+
+// Reset zera as falhas da conta após um login bem-sucedido. As falhas do IP
+// são mantidas, para que uma conta válida não libere o IP.
+func (l *Login) Reset(ctx context.Context, email string) error {
+	return l.Account.Reset(ctx, AccountKey(email))
+}
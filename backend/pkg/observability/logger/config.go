@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/insidechurch/observability/redact"
+)
+
+// Saídas aceitas em LOG_OUTPUT
+const (
+	OutputStdout = "stdout"
+	OutputFile   = "file"
+	OutputBoth   = "both"
+)
+
+// Config contém as configurações do logger
+type Config struct {
+	Level    Level
+	Output   string // stdout, file ou both
+	File     string
+	Rotation Rotation
+	Redactor *redact.Redactor // nil usa redact.Default
+}
+
+// Rotation define quando o arquivo de log é rotacionado e por quanto tempo os
+// arquivos antigos são mantidos. Valores zero desativam o critério.
+type Rotation struct {
+	MaxSize    int64         // tamanho em bytes
+	Interval   time.Duration // idade máxima do arquivo atual
+	MaxBackups int           // quantidade de arquivos antigos mantidos
+	MaxAge     time.Duration // idade máxima dos arquivos antigos
+}
+
+// NewConfig cria a configuração a partir das variáveis de ambiente LOG_LEVEL,
+// LOG_OUTPUT, LOG_FILE, LOG_MAX_SIZE_MB, LOG_ROTATE_INTERVAL, LOG_MAX_BACKUPS
+// e LOG_MAX_AGE
+func NewConfig() (*Config, error) {
+	level, err := ParseLevel(getEnv("LOG_LEVEL", "info"))
+	if err != nil {
+		return nil, err
+	}
+
+	output := getEnv("LOG_OUTPUT", OutputBoth)
+	switch output {
+	case OutputStdout, OutputFile, OutputBoth:
+	default:
+		return nil, fmt.Errorf("saída de log desconhecida: %q", output)
+	}
+
+	maxSize, err := strconv.ParseInt(getEnv("LOG_MAX_SIZE_MB", "100"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("LOG_MAX_SIZE_MB inválido: %w", err)
+	}
+	interval, err := time.ParseDuration(getEnv("LOG_ROTATE_INTERVAL", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("LOG_ROTATE_INTERVAL inválido: %w", err)
+	}
+	maxBackups, err := strconv.Atoi(getEnv("LOG_MAX_BACKUPS", "7"))
+	if err != nil {
+		return nil, fmt.Errorf("LOG_MAX_BACKUPS inválido: %w", err)
+	}
+	maxAge, err := time.ParseDuration(getEnv("LOG_MAX_AGE", "720h"))
+	if err != nil {
+		return nil, fmt.Errorf("LOG_MAX_AGE inválido: %w", err)
+	}
+
+	return &Config{
+		Level:  level,
+		Output: output,
+		File:   getEnv("LOG_FILE", "logs/app.log"),
+		Rotation: Rotation{
+			MaxSize:    maxSize << 20,
+			Interval:   interval,
+			MaxBackups: maxBackups,
+			MaxAge:     maxAge,
+		},
+	}, nil
+}
+
+// getEnv retorna o valor da variável de ambiente ou um valor padrão
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
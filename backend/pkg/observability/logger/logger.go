@@ -0,0 +1,277 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/insidechurch/observability/redact"
+	"github.com/insidechurch/observability/tracing"
+)
+
+// Field representa um campo de log
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// LogEntry representa uma entrada de log
+type LogEntry struct {
+	Timestamp string                 `json:"timestamp"`
+	Level     string                 `json:"level"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// Level é a severidade de uma entrada. Os valores são os mesmos de slog.Level.
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+// String retorna o nome do nível gravado nas entradas
+func (l Level) String() string {
+	switch {
+	case l < LevelInfo:
+		return "DEBUG"
+	case l < LevelWarn:
+		return "INFO"
+	case l < LevelError:
+		return "WARN"
+	default:
+		return "ERROR"
+	}
+}
+
+// ParseLevel converte debug, info, warn (ou warning) e error, sem diferenciar
+// maiúsculas, no nível correspondente
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("nível de log desconhecido: %q", name)
+}
+
+// Logger é a interface para logging
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, err error, fields ...Field)
+
+	// With retorna um Logger que acrescenta fields a todas as entradas
+	With(fields ...Field) Logger
+	// WithContext retorna um Logger que acrescenta os IDs do trace e do span
+	// ativos em ctx
+	WithContext(ctx context.Context) Logger
+}
+
+// JSONLogger implementa Logger gravando cada entrada em uma linha JSON.
+// Entradas abaixo do nível configurado são descartadas. Campos, mensagem e
+// erro passam pelo redact.Redactor antes da gravação.
+type JSONLogger struct {
+	sink   *sink
+	level  Level
+	fields []Field
+}
+
+// sink é o destino compartilhado pelos loggers derivados com With
+type sink struct {
+	mu       sync.Mutex
+	w        io.Writer
+	closers  []io.Closer
+	redactor *redact.Redactor
+}
+
+// New cria um JSONLogger com a saída, o nível e a rotação descritos em cfg
+func New(cfg *Config) (*JSONLogger, error) {
+	var writers []io.Writer
+	var closers []io.Closer
+
+	if cfg.Output == OutputStdout || cfg.Output == OutputBoth {
+		writers = append(writers, os.Stdout)
+	}
+	if cfg.Output == OutputFile || cfg.Output == OutputBoth {
+		file, err := OpenRotatingFile(cfg.File, cfg.Rotation)
+		if err != nil {
+			return nil, err
+		}
+		writers = append(writers, file)
+		closers = append(closers, file)
+	}
+	if len(writers) == 0 {
+		return nil, fmt.Errorf("saída de log desconhecida: %q", cfg.Output)
+	}
+
+	return NewWriterLogger(io.MultiWriter(writers...), cfg.Level, cfg.Redactor, closers...), nil
+}
+
+// NewWriterLogger cria um JSONLogger que grava em w as entradas a partir de
+// level, com a redação de redactor (nil usa redact.Default). closers são
+// fechados em Close.
+func NewWriterLogger(w io.Writer, level Level, redactor *redact.Redactor, closers ...io.Closer) *JSONLogger {
+	return &JSONLogger{
+		sink:  &sink{w: w, closers: closers, redactor: redactor},
+		level: level,
+	}
+}
+
+// Enabled indica se entradas de level são gravadas
+func (l *JSONLogger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
+// Debug registra uma mensagem de nível DEBUG
+func (l *JSONLogger) Debug(msg string, fields ...Field) {
+	l.log(LevelDebug, msg, nil, fields...)
+}
+
+// Info registra uma mensagem de nível INFO
+func (l *JSONLogger) Info(msg string, fields ...Field) {
+	l.log(LevelInfo, msg, nil, fields...)
+}
+
+// Warn registra uma mensagem de nível WARN
+func (l *JSONLogger) Warn(msg string, fields ...Field) {
+	l.log(LevelWarn, msg, nil, fields...)
+}
+
+// Error registra uma mensagem de nível ERROR
+func (l *JSONLogger) Error(msg string, err error, fields ...Field) {
+	l.log(LevelError, msg, err, fields...)
+}
+
+// With retorna um Logger que acrescenta fields a todas as entradas
+func (l *JSONLogger) With(fields ...Field) Logger {
+	return l.with(fields...)
+}
+
+// WithContext retorna um Logger que acrescenta trace_id e span_id do span
+// ativo em ctx; sem span, retorna o próprio logger
+func (l *JSONLogger) WithContext(ctx context.Context) Logger {
+	return l.withContext(ctx)
+}
+
+func (l *JSONLogger) with(fields ...Field) *JSONLogger {
+	if len(fields) == 0 {
+		return l
+	}
+	merged := make([]Field, 0, len(l.fields)+len(fields))
+	merged = append(merged, l.fields...)
+	merged = append(merged, fields...)
+	return &JSONLogger{sink: l.sink, level: l.level, fields: merged}
+}
+
+func (l *JSONLogger) withContext(ctx context.Context) *JSONLogger {
+	traceID, spanID := tracing.IDs(ctx)
+	if traceID == "" {
+		return l
+	}
+	return l.with(String("trace_id", traceID), String("span_id", spanID))
+}
+
+// log registra uma entrada de log
+func (l *JSONLogger) log(level Level, msg string, err error, fields ...Field) {
+	if !l.Enabled(level) {
+		return
+	}
+	l.write(time.Now(), level, msg, err, fields)
+}
+
+// write grava a entrada em uma única linha. Os campos de With vêm antes dos
+// da chamada, que prevalecem em caso de chave repetida.
+func (l *JSONLogger) write(now time.Time, level Level, msg string, err error, fields []Field) {
+	redactor := l.sink.redactor
+	entry := LogEntry{
+		Timestamp: now.Format(time.RFC3339Nano),
+		Level:     level.String(),
+		Message:   redactor.Scrub(msg),
+	}
+
+	if len(l.fields)+len(fields) > 0 {
+		entry.Fields = make(map[string]interface{}, len(l.fields)+len(fields))
+		for _, group := range [][]Field{l.fields, fields} {
+			for _, field := range group {
+				value, ok := redactor.Value(field.Key, field.Value)
+				if !ok {
+					delete(entry.Fields, field.Key)
+					continue
+				}
+				entry.Fields[field.Key] = value
+			}
+		}
+	}
+
+	if err != nil {
+		entry.Error = redactor.Scrub(err.Error())
+	}
+
+	line, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao serializar log: %v\n", marshalErr)
+		return
+	}
+	line = append(line, '\n')
+
+	l.sink.mu.Lock()
+	defer l.sink.mu.Unlock()
+	if _, err := l.sink.w.Write(line); err != nil {
+		fmt.Fprintf(os.Stderr, "Erro ao escrever log: %v\n", err)
+	}
+}
+
+// Close fecha os arquivos de log
+func (l *JSONLogger) Close() error {
+	l.sink.mu.Lock()
+	defer l.sink.mu.Unlock()
+
+	var first error
+	for _, closer := range l.sink.closers {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Funções auxiliares para criar campos
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Float(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value.Format(time.RFC3339)}
+}
+
+func Error(err error) Field {
+	return Field{Key: "error", Value: err.Error()}
+}
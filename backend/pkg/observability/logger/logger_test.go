@@ -0,0 +1,211 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// entries decodifica a saída do logger, uma entrada por linha
+func entries(t *testing.T, buf *bytes.Buffer) []LogEntry {
+	t.Helper()
+	var result []LogEntry
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry LogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Linha não é JSON: %q (%v)", line, err)
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+func TestLevelFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWriterLogger(&buf, LevelWarn, nil)
+
+	log.Debug("debug")
+	log.Info("info")
+	log.Warn("warn", String("chave", "valor"))
+	log.Error("error", errors.New("falhou"))
+
+	got := entries(t, &buf)
+	if len(got) != 2 {
+		t.Fatalf("Esperadas 2 entradas, obtidas %d: %s", len(got), buf.String())
+	}
+	if got[0].Level != "WARN" || got[0].Fields["chave"] != "valor" {
+		t.Errorf("Entrada WARN inesperada: %+v", got[0])
+	}
+	if got[1].Level != "ERROR" || got[1].Error != "falhou" {
+		t.Errorf("Entrada ERROR inesperada: %+v", got[1])
+	}
+	if strings.Count(buf.String(), "\n") != 2 {
+		t.Errorf("Cada entrada deveria ocupar uma linha: %q", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]Level{"debug": LevelDebug, "INFO": LevelInfo, "warning": LevelWarn, "error": LevelError} {
+		if got, err := ParseLevel(name); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; esperado %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Nível desconhecido deveria falhar")
+	}
+}
+
+func TestWithAndWithContext(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWriterLogger(&buf, LevelInfo, nil)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	log.With(String("componente", "teste")).WithContext(ctx).Info("com contexto", String("componente", "sobrescrito"))
+	log.WithContext(context.Background()).Info("sem contexto")
+
+	got := entries(t, &buf)
+	if len(got) != 2 {
+		t.Fatalf("Esperadas 2 entradas, obtidas %d", len(got))
+	}
+	if got[0].Fields["trace_id"] != traceID.String() || got[0].Fields["span_id"] != spanID.String() {
+		t.Errorf("IDs do trace ausentes: %+v", got[0].Fields)
+	}
+	if got[0].Fields["componente"] != "sobrescrito" {
+		t.Errorf("Campo da chamada deveria prevalecer: %+v", got[0].Fields)
+	}
+	if got[1].Fields != nil {
+		t.Errorf("Entrada sem span não deveria ter campos: %+v", got[1].Fields)
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewSlogHandler(NewWriterLogger(&buf, LevelInfo, nil)))
+
+	log.Debug("descartado")
+	log.With("servico", "api").WithGroup("http").Warn("lento",
+		"status", 200,
+		"duracao", 1500*time.Millisecond,
+		"erro", errors.New("timeout"),
+	)
+
+	got := entries(t, &buf)
+	if len(got) != 1 {
+		t.Fatalf("Esperada 1 entrada, obtidas %d: %s", len(got), buf.String())
+	}
+	entry := got[0]
+	if entry.Level != "WARN" || entry.Message != "lento" || entry.Error != "timeout" {
+		t.Errorf("Entrada inesperada: %+v", entry)
+	}
+	if entry.Fields["servico"] != "api" || entry.Fields["http.status"] != float64(200) || entry.Fields["http.duracao"] != "1.5s" {
+		t.Errorf("Campos inesperados: %+v", entry.Fields)
+	}
+}
+
+func TestRotatingFileSizeAndRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := OpenRotatingFile(path, Rotation{MaxSize: 10, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)
+	file.now = func() time.Time { now = now.Add(time.Second); return now }
+
+	for i := 0; i < 5; i++ {
+		if _, err := file.Write([]byte("12345678\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Errorf("Esperados 2 arquivos rotacionados, obtidos %v", backups)
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != "12345678\n" {
+		t.Errorf("Arquivo atual deveria ter só a última entrada: %q", content)
+	}
+}
+
+func TestRotatingFileInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := OpenRotatingFile(path, Rotation{Interval: time.Hour, MaxAge: 90 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)
+	file.now = func() time.Time { return now }
+	file.openedAt = now
+
+	write := func() {
+		if _, err := file.Write([]byte("linha\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write()
+	now = now.Add(30 * time.Minute)
+	write()
+	if backups, _ := filepath.Glob(path + ".*"); len(backups) != 0 {
+		t.Fatalf("Arquivo não deveria ter sido rotacionado: %v", backups)
+	}
+
+	now = now.Add(time.Hour)
+	write()
+	if backups, _ := filepath.Glob(path + ".*"); len(backups) != 1 {
+		t.Fatalf("Esperado 1 arquivo rotacionado, obtidos %v", backups)
+	}
+
+	// O primeiro arquivo rotacionado expira com MaxAge na rotação seguinte
+	now = now.Add(2 * time.Hour)
+	write()
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], now.Format(backupTimeFormat)) {
+		t.Errorf("Apenas o arquivo mais recente deveria ser mantido: %v", backups)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWriterLogger(&buf, LevelInfo, nil)
+
+	log.With(String("ip", "10.1.2.3:4567")).Error("Falha no login de ana@email.com", errors.New("senha incorreta para ana@email.com"),
+		String("email", "ana@email.com"),
+		String("password", "s3nh@"),
+		String("user_id", "7"),
+	)
+
+	got := entries(t, &buf)
+	if len(got) != 1 {
+		t.Fatalf("Esperada 1 entrada, obtidas %d", len(got))
+	}
+	if strings.Contains(buf.String(), "ana@email.com") || strings.Contains(buf.String(), "s3nh@") {
+		t.Errorf("Dados pessoais ou segredos gravados: %s", buf.String())
+	}
+	fields := got[0].Fields
+	if fields["email"] != "a***@email.com" || fields["ip"] != "10.1.2.0" || fields["password"] != "[REDACTED]" || fields["user_id"] != "7" {
+		t.Errorf("Campos inesperados: %+v", fields)
+	}
+}
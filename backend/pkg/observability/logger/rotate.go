@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat compõe o sufixo dos arquivos rotacionados; a ordem
+// lexicográfica dos nomes é a ordem cronológica
+const backupTimeFormat = "20060102-150405.000"
+
+// RotatingFile é um arquivo de log que, ao exceder o tamanho ou a idade
+// configurados, é renomeado para <caminho>.<data> e substituído por um novo.
+// Arquivos antigos além de MaxBackups ou mais velhos que MaxAge são removidos.
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	rotation Rotation
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	now func() time.Time
+}
+
+// OpenRotatingFile abre (ou cria) o arquivo de log em path
+func OpenRotatingFile(path string, rotation Rotation) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, rotation: rotation, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write grava p no arquivo atual, rotacionando antes se necessário. Cada
+// chamada vai inteira para um único arquivo.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close fecha o arquivo atual
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *RotatingFile) shouldRotate(next int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.rotation.MaxSize > 0 && f.size+next > f.rotation.MaxSize {
+		return true
+	}
+	return f.rotation.Interval > 0 && f.now().Sub(f.openedAt) >= f.rotation.Interval
+}
+
+// open abre o arquivo no caminho configurado. Um arquivo existente continua
+// sendo usado e sua idade conta a partir da última modificação.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de logs: %w", err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("erro ao consultar arquivo de log: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.now()
+	if f.size > 0 {
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("erro ao fechar arquivo de log: %w", err)
+	}
+	f.file = nil
+
+	backup := f.path + "." + f.now().Format(backupTimeFormat)
+	if err := os.Rename(f.path, backup); err != nil {
+		return fmt.Errorf("erro ao rotacionar arquivo de log: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	return f.prune()
+}
+
+// prune remove os arquivos rotacionados além de MaxBackups ou mais velhos que
+// MaxAge
+func (f *RotatingFile) prune() error {
+	backups, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return err
+	}
+	// Mais recentes primeiro
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	prefix := f.path + "."
+	for i, backup := range backups {
+		taken, err := time.ParseInLocation(backupTimeFormat, strings.TrimPrefix(backup, prefix), time.Local)
+		if err != nil {
+			continue
+		}
+		expired := f.rotation.MaxAge > 0 && f.now().Sub(taken) > f.rotation.MaxAge
+		excess := f.rotation.MaxBackups > 0 && i >= f.rotation.MaxBackups
+		if expired || excess {
+			if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("erro ao remover arquivo de log antigo: %w", err)
+			}
+		}
+	}
+	return nil
+}
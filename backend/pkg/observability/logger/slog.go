@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SlogHandler adapta um JSONLogger à interface slog.Handler, para que código
+// baseado em log/slog grave no mesmo formato e destino
+type SlogHandler struct {
+	logger *JSONLogger
+	prefix string
+}
+
+// NewSlogHandler cria uma nova instância do SlogHandler
+func NewSlogHandler(l *JSONLogger) *SlogHandler {
+	return &SlogHandler{logger: l}
+}
+
+// Enabled indica se o nível é gravado pelo logger
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Enabled(Level(level))
+}
+
+// Handle grava o registro com os IDs do trace ativo em ctx. Um atributo cujo
+// valor é um error vai para o campo error da entrada.
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var err error
+	fields := make([]Field, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		if e, ok := attr.Value.Any().(error); ok && err == nil {
+			err = e
+			return true
+		}
+		fields = appendAttr(fields, h.prefix, attr)
+		return true
+	})
+
+	now := record.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	h.logger.withContext(ctx).write(now, Level(record.Level), record.Message, err, fields)
+	return nil
+}
+
+// WithAttrs retorna um handler que acrescenta attrs a todos os registros
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []Field
+	for _, attr := range attrs {
+		fields = appendAttr(fields, h.prefix, attr)
+	}
+	return &SlogHandler{logger: h.logger.with(fields...), prefix: h.prefix}
+}
+
+// WithGroup retorna um handler cujos atributos seguintes recebem o prefixo
+// "<name>."
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{logger: h.logger, prefix: h.prefix + name + "."}
+}
+
+// appendAttr converte attr em campos, achatando grupos em chaves com ponto
+func appendAttr(fields []Field, prefix string, attr slog.Attr) []Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			fields = appendAttr(fields, groupPrefix, member)
+		}
+		return fields
+	}
+
+	var value interface{}
+	switch attr.Value.Kind() {
+	case slog.KindDuration:
+		value = attr.Value.Duration().String()
+	case slog.KindTime:
+		value = attr.Value.Time().Format(time.RFC3339Nano)
+	default:
+		value = attr.Value.Any()
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+	}
+	return append(fields, Field{Key: prefix + attr.Key, Value: value})
+}
@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics reúne os coletores comuns aos serviços, registrados no Registerer
+// recebido em New: as métricas RED (taxa, erros e duração) das requisições
+// HTTP e as de banco, cache, APIs externas, eventos, notificações e ações de
+// usuário. Os rótulos têm conjuntos de valores limitados: caminhos HTTP são
+// reduzidos ao template da rota e nenhum rótulo identifica usuários. Métricas
+// específicas de um serviço são registradas por ele no mesmo Registerer.
+type Metrics struct {
+	// Métricas HTTP
+	HTTPRequestsInFlight prometheus.Gauge
+	HTTPDuration         *prometheus.HistogramVec // path, method, status
+	HTTPRequestsTotal    *prometheus.CounterVec   // path, method, status
+	HTTPErrors           *prometheus.CounterVec   // path, method, status
+
+	// Métricas de Banco de Dados
+	dbDuration *prometheus.HistogramVec
+	dbErrors   *prometheus.CounterVec
+
+	// Métricas de Cache
+	cacheDuration *prometheus.HistogramVec
+	cacheErrors   *prometheus.CounterVec
+
+	// Métricas de API Externa
+	externalAPIDuration *prometheus.HistogramVec
+	externalAPIErrors   *prometheus.CounterVec
+
+	// Métricas de Eventos
+	eventProcessingDuration *prometheus.HistogramVec
+	eventErrors             *prometheus.CounterVec
+
+	// Métricas de Notificações
+	notificationsSent  *prometheus.CounterVec
+	notificationErrors *prometheus.CounterVec
+
+	// Métricas de Usuário
+	userActions *prometheus.CounterVec
+	userErrors  *prometheus.CounterVec
+
+	routes *Routes
+}
+
+// New cria uma nova instância de Metrics e registra os coletores em reg.
+// routes são os templates de rota usados no rótulo path (ver Routes). Um
+// mesmo Registerer aceita apenas uma instância.
+func New(reg prometheus.Registerer, routes ...string) *Metrics {
+	factory := promauto.With(reg)
+
+	return &Metrics{
+		HTTPRequestsInFlight: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Número de requisições HTTP em andamento",
+		}),
+		HTTPDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duração das requisições HTTP em segundos",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"path", "method", "status"}),
+		HTTPRequestsTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total de requisições HTTP",
+		}, []string{"path", "method", "status"}),
+		HTTPErrors: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_errors_total",
+			Help: "Total de respostas HTTP com erro do servidor (5xx)",
+		}, []string{"path", "method", "status"}),
+
+		dbDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "db_operation_duration_seconds",
+			Help: "Duração das operações no banco de dados em segundos",
+		}, []string{"database", "operation"}),
+		dbErrors: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_errors_total",
+			Help: "Total de erros no banco de dados",
+		}, []string{"database", "operation"}),
+
+		cacheDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "cache_operation_duration_seconds",
+			Help: "Duração das operações no cache em segundos",
+		}, []string{"cache", "operation"}),
+		cacheErrors: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_errors_total",
+			Help: "Total de erros no cache",
+		}, []string{"cache", "operation"}),
+
+		externalAPIDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "external_api_duration_seconds",
+			Help: "Duração das chamadas à API externa em segundos",
+		}, []string{"api", "method"}),
+		externalAPIErrors: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "external_api_errors_total",
+			Help: "Total de erros em chamadas à API externa",
+		}, []string{"api", "method"}),
+
+		eventProcessingDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "event_processing_duration_seconds",
+			Help: "Duração do processamento de eventos em segundos",
+		}, []string{"event"}),
+		eventErrors: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "event_errors_total",
+			Help: "Total de erros no processamento de eventos",
+		}, []string{"event"}),
+
+		notificationsSent: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "notifications_sent_total",
+			Help: "Total de notificações enviadas",
+		}, []string{"notification", "channel"}),
+		notificationErrors: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "notification_errors_total",
+			Help: "Total de erros no envio de notificações",
+		}, []string{"notification", "channel"}),
+
+		userActions: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "user_actions_total",
+			Help: "Total de ações de usuário",
+		}, []string{"action"}),
+		userErrors: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "user_errors_total",
+			Help: "Total de erros em ações de usuário",
+		}, []string{"action"}),
+
+		routes: NewRoutes(routes...),
+	}
+}
+
+// ObserveHTTP registra uma requisição concluída na rota (já reduzida ao
+// template): duração, total e, para respostas 5xx, erro
+func (m *Metrics) ObserveHTTP(route, method string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.HTTPDuration.WithLabelValues(route, method, code).Observe(duration.Seconds())
+	m.HTTPRequestsTotal.WithLabelValues(route, method, code).Inc()
+	if status >= 500 {
+		m.HTTPErrors.WithLabelValues(route, method, code).Inc()
+	}
+}
+
+// Funções para registrar métricas HTTP; path é reduzido ao template da rota
+func (m *Metrics) RecordHTTPDuration(path, method string, status int, duration time.Duration) {
+	m.HTTPDuration.WithLabelValues(m.routes.Template(path), method, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+func (m *Metrics) RecordHTTPRequest(path, method string, status int) {
+	m.HTTPRequestsTotal.WithLabelValues(m.routes.Template(path), method, strconv.Itoa(status)).Inc()
+}
+
+func (m *Metrics) RecordHTTPError(path, method string, status int) {
+	m.HTTPErrors.WithLabelValues(m.routes.Template(path), method, strconv.Itoa(status)).Inc()
+}
+
+// Funções para registrar métricas de banco de dados
+func (m *Metrics) RecordDatabaseOperation(database, operation string, duration time.Duration) {
+	m.dbDuration.WithLabelValues(database, operation).Observe(duration.Seconds())
+}
+
+func (m *Metrics) RecordDatabaseError(database, operation string) {
+	m.dbErrors.WithLabelValues(database, operation).Inc()
+}
+
+// Funções para registrar métricas de cache
+func (m *Metrics) RecordCacheOperation(cache, operation string, duration time.Duration) {
+	m.cacheDuration.WithLabelValues(cache, operation).Observe(duration.Seconds())
+}
+
+func (m *Metrics) RecordCacheError(cache, operation string) {
+	m.cacheErrors.WithLabelValues(cache, operation).Inc()
+}
+
+// Funções para registrar métricas de API externa
+func (m *Metrics) RecordExternalAPICall(api, method string, duration time.Duration) {
+	m.externalAPIDuration.WithLabelValues(api, method).Observe(duration.Seconds())
+}
+
+func (m *Metrics) RecordExternalAPIError(api, method string) {
+	m.externalAPIErrors.WithLabelValues(api, method).Inc()
+}
+
+// Funções para registrar métricas de eventos
+func (m *Metrics) RecordEventProcessing(event string, duration time.Duration) {
+	m.eventProcessingDuration.WithLabelValues(event).Observe(duration.Seconds())
+}
+
+func (m *Metrics) RecordEventError(event string) {
+	m.eventErrors.WithLabelValues(event).Inc()
+}
+
+// Funções para registrar métricas de notificações
+func (m *Metrics) RecordNotificationSent(notification, channel string) {
+	m.notificationsSent.WithLabelValues(notification, channel).Inc()
+}
+
+func (m *Metrics) RecordNotificationError(notification, channel string) {
+	m.notificationErrors.WithLabelValues(notification, channel).Inc()
+}
+
+// Funções para registrar métricas de usuário, agregadas por ação; o usuário
+// fica no log de auditoria
+func (m *Metrics) RecordUserAction(action string) {
+	m.userActions.WithLabelValues(action).Inc()
+}
+
+func (m *Metrics) RecordUserError(action string) {
+	m.userErrors.WithLabelValues(action).Inc()
+}
@@ -0,0 +1,189 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/expfmt"
+)
+
+// newTestMetrics cria as métricas em um registro próprio do teste
+func newTestMetrics(routes ...string) (*Metrics, *prometheus.Registry) {
+	reg := prometheus.NewRegistry()
+	return New(reg, routes...), reg
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	m, _ := newTestMetrics("/events/{id}")
+
+	// Criar um handler de teste
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/events/falha" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := m.Middleware(testHandler)
+
+	for _, path := range []string{"/events/1", "/events/2", "/events/falha", "/desconhecido"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	}
+
+	if got := testutil.ToFloat64(m.HTTPRequestsTotal.WithLabelValues("/events/{id}", "GET", "200")); got != 2 {
+		t.Errorf("Esperadas 2 requisições no template da rota, obtidas %v", got)
+	}
+	if got := testutil.ToFloat64(m.HTTPRequestsTotal.WithLabelValues(OtherRoute, "GET", "200")); got != 1 {
+		t.Errorf("Caminho desconhecido deveria ser contado em %q, obtido %v", OtherRoute, got)
+	}
+	if got := testutil.ToFloat64(m.HTTPErrors.WithLabelValues("/events/{id}", "GET", "500")); got != 1 {
+		t.Errorf("Esperado 1 erro, obtido %v", got)
+	}
+	if got := testutil.ToFloat64(m.HTTPRequestsInFlight); got != 0 {
+		t.Errorf("Nenhuma requisição deveria estar em andamento, obtido %v", got)
+	}
+}
+
+func TestRoutesTemplate(t *testing.T) {
+	routes := NewRoutes("/auth/sessions", "/auth/sessions/{id}", "/auth/admin/users/{userID}/sessions/{id}", "/")
+
+	cases := map[string]string{
+		"/auth/sessions":                       "/auth/sessions",
+		"/auth/sessions/":                      "/auth/sessions",
+		"/auth/sessions/abc-123":               "/auth/sessions/{id}",
+		"/auth/sessions/abc/extra":             OtherRoute,
+		"/auth/admin/users/42/sessions/s1":     "/auth/admin/users/{userID}/sessions/{id}",
+		"/auth/admin/users/42/api-keys/k1":     OtherRoute,
+		"/":                                    "/",
+		"/events/9f8e7d6c-aaaa-bbbb-cccc-1234": OtherRoute,
+	}
+	for path, want := range cases {
+		if got := routes.Template(path); got != want {
+			t.Errorf("Template(%q) = %q, esperado %q", path, got, want)
+		}
+	}
+}
+
+func TestSetRoutePrevailsOverTemplates(t *testing.T) {
+	m, reg := newTestMetrics("/events/{id}")
+
+	handler := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetRoute(r.Context(), "/api/v1/events/:id")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/events/42", nil))
+
+	expected := `
+		# HELP http_requests_total Total de requisições HTTP
+		# TYPE http_requests_total counter
+		http_requests_total{method="GET",path="/api/v1/events/:id",status="200"} 1
+	`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "http_requests_total"); err != nil {
+		t.Error(err)
+	}
+
+	// Sem WithRoute no context, SetRoute não tem efeito
+	SetRoute(context.Background(), "/ignorada")
+}
+
+func TestNewWithSeparateRegistries(t *testing.T) {
+	// Cada registro recebe seus próprios coletores, sem conflito de nomes
+	newTestMetrics()
+	newTestMetrics()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Registrar duas instâncias no mesmo registro deveria falhar")
+		}
+	}()
+	reg := prometheus.NewRegistry()
+	New(reg)
+	New(reg)
+}
+
+// TestScrape exercita todas as métricas, coleta a saída de /metrics e
+// confere que todas estão presentes, válidas e com rótulos limitados
+func TestScrape(t *testing.T) {
+	m, reg := newTestMetrics("/events/{id}")
+
+	handler := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/events/123", nil))
+
+	m.RecordDatabaseOperation("postgres", "SELECT", 100*time.Millisecond)
+	m.RecordDatabaseError("postgres", "SELECT")
+	m.RecordCacheOperation("redis", "GET", 50*time.Millisecond)
+	m.RecordCacheError("redis", "GET")
+	m.RecordExternalAPICall("notification", "POST", 200*time.Millisecond)
+	m.RecordExternalAPIError("notification", "POST")
+	m.RecordEventProcessing("user.created", 150*time.Millisecond)
+	m.RecordEventError("user.created")
+	m.RecordNotificationSent("verification", "email")
+	m.RecordNotificationError("verification", "email")
+	m.RecordUserAction("login")
+	m.RecordUserError("login")
+
+	server := httptest.NewServer(promhttp.HandlerFor(reg, promhttp.HandlerOpts{ErrorHandling: promhttp.HTTPErrorOnError}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Coleta falhou com status %d", resp.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		t.Fatalf("Saída de /metrics inválida: %v", err)
+	}
+
+	expected := []string{
+		"http_requests_in_flight", "http_request_duration_seconds", "http_requests_total", "http_errors_total",
+		"db_operation_duration_seconds", "db_errors_total", "cache_operation_duration_seconds", "cache_errors_total",
+		"external_api_duration_seconds", "external_api_errors_total", "event_processing_duration_seconds", "event_errors_total",
+		"notifications_sent_total", "notification_errors_total", "user_actions_total", "user_errors_total",
+	}
+	for _, name := range expected {
+		family, ok := families[name]
+		if !ok || len(family.GetMetric()) == 0 {
+			t.Errorf("Métrica %s ausente na coleta", name)
+			continue
+		}
+		if family.GetHelp() == "" {
+			t.Errorf("Métrica %s sem descrição", name)
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "user" {
+					t.Errorf("Métrica %s não deveria ter o rótulo user", name)
+				}
+				if label.GetName() == "path" && label.GetValue() != "/events/{id}" {
+					t.Errorf("Métrica %s com path fora do template: %q", name, label.GetValue())
+				}
+			}
+		}
+	}
+	if len(families) != len(expected) {
+		t.Errorf("Esperadas %d métricas, coletadas %d", len(expected), len(families))
+	}
+
+	problems, err := testutil.GatherAndLint(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, problem := range problems {
+		t.Errorf("%s: %s", problem.Metric, problem.Text)
+	}
+}
@@ -2,16 +2,16 @@ package metrics
 
 import (
 	"net/http"
-	"strconv"
 	"time"
 )
 
-// MetricsMiddleware é um middleware que coleta métricas do Prometheus
-func MetricsMiddleware(next http.Handler) http.Handler {
+// Middleware é um middleware que coleta as métricas HTTP do Prometheus,
+// rotuladas com o template da rota
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Incrementar contador de requisições em andamento
-		HTTPRequestsInFlight.Inc()
-		defer HTTPRequestsInFlight.Dec()
+		m.HTTPRequestsInFlight.Inc()
+		defer m.HTTPRequestsInFlight.Dec()
 
 		// Criar um ResponseWriter personalizado para capturar o status code
 		rw := newResponseWriter(w)
@@ -19,15 +19,12 @@ func MetricsMiddleware(next http.Handler) http.Handler {
 		// Registrar início da requisição
 		start := time.Now()
 
-		// Chamar o próximo handler
+		// Chamar o próximo handler, que pode informar a rota em SetRoute
+		r = r.WithContext(WithRoute(r.Context()))
 		next.ServeHTTP(rw, r)
 
-		// Registrar duração da requisição
-		duration := time.Since(start).Seconds()
-		HTTPDuration.WithLabelValues(r.URL.Path, r.Method, strconv.Itoa(rw.statusCode)).Observe(duration)
-
-		// Incrementar contador total de requisições
-		HTTPRequestsTotal.WithLabelValues(r.URL.Path, r.Method, strconv.Itoa(rw.statusCode)).Inc()
+		// Registrar duração, total e erros da rota
+		m.ObserveHTTP(m.Route(r), r.Method, rw.statusCode, time.Since(start))
 	})
 }
 
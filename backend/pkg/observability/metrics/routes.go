@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// OtherRoute é o template dos caminhos que não correspondem a nenhuma rota
+const OtherRoute = "other"
+
+// Routes reduz caminhos de requisição a templates de rota, como
+// /auth/sessions/{id}, para que o rótulo path tenha um conjunto limitado de
+// valores. Um segmento entre chaves corresponde a qualquer segmento não
+// vazio; os demais precisam ser iguais.
+type Routes struct {
+	templates [][]string
+	names     []string
+}
+
+// NewRoutes cria uma nova instância de Routes. Na dúvida entre templates, vale
+// o primeiro informado.
+func NewRoutes(templates ...string) *Routes {
+	r := &Routes{}
+	for _, template := range templates {
+		r.templates = append(r.templates, segments(template))
+		r.names = append(r.names, template)
+	}
+	return r
+}
+
+// Template retorna o template correspondente a path, ou OtherRoute
+func (r *Routes) Template(path string) string {
+	parts := segments(path)
+	for i, template := range r.templates {
+		if matches(template, parts) {
+			return r.names[i]
+		}
+	}
+	return OtherRoute
+}
+
+func matches(template, parts []string) bool {
+	if len(template) != len(parts) {
+		return false
+	}
+	for i, segment := range template {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if parts[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != parts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// segments divide o caminho, ignorando a barra final
+func segments(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+type routeKey struct{}
+
+// routeHolder guarda o template informado pelo roteador durante a requisição
+type routeHolder struct {
+	route string
+}
+
+// WithRoute prepara o context da requisição para receber o template da rota
+// em SetRoute
+func WithRoute(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(routeKey{}).(*routeHolder); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, routeKey{}, &routeHolder{})
+}
+
+// SetRoute registra o template da rota resolvido pelo roteador, como o
+// FullPath do Gin; prevalece sobre os templates de Routes. Sem WithRoute no
+// context, não tem efeito.
+func SetRoute(ctx context.Context, route string) {
+	if holder, ok := ctx.Value(routeKey{}).(*routeHolder); ok {
+		holder.route = route
+	}
+}
+
+// Route retorna o template da rota da requisição: o informado em SetRoute ou,
+// na falta dele, o correspondente em Routes
+func (m *Metrics) Route(r *http.Request) string {
+	if holder, ok := r.Context().Value(routeKey{}).(*routeHolder); ok && holder.route != "" {
+		return holder.route
+	}
+	return m.routes.Template(r.URL.Path)
+}
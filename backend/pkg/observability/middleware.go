@@ -0,0 +1,197 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/observability/logger"
+	"github.com/insidechurch/observability/metrics"
+	"github.com/insidechurch/observability/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type requestIDKey struct{}
+
+// RequestID retorna o ID da requisição registrado por Middleware, ou uma
+// string vazia
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// SetRoute registra o template da rota resolvido pelo roteador, como o
+// FullPath do Gin, para as métricas, o span e o log da requisição
+func SetRoute(ctx context.Context, route string) {
+	metrics.SetRoute(ctx, route)
+}
+
+// Middleware aplica a cada requisição, nesta ordem:
+//   - ID da requisição: o recebido em X-Request-ID ou um novo, devolvido na
+//     resposta e repassado no header da requisição aos handlers seguintes;
+//   - span do servidor, continuando o trace recebido nos headers;
+//   - métricas RED rotuladas com o template da rota;
+//   - recuperação de panics, registrados no log e respondidos com 500;
+//   - uma linha de log por requisição, com nível de erro para respostas 5xx.
+func (o *Observability) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := auditevent.CorrelationID(r.Header.Get(auditevent.CorrelationHeader))
+		r.Header.Set(auditevent.CorrelationHeader, requestID)
+		w.Header().Set(auditevent.CorrelationHeader, requestID)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, requestID)
+		ctx = metrics.WithRoute(ctx)
+		r = r.WithContext(ctx)
+
+		ctx, span := tracing.StartServerSpan(r)
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		o.Metrics.HTTPRequestsInFlight.Inc()
+		defer o.Metrics.HTTPRequestsInFlight.Dec()
+
+		rw := newResponseWriter(w)
+		o.serve(rw, r, next)
+
+		status := rw.Status()
+		route := o.Metrics.Route(r)
+		duration := time.Since(start)
+
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(
+			attribute.String("http.route", route),
+			attribute.Int("http.status_code", status),
+		)
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		o.Metrics.ObserveHTTP(route, r.Method, status, duration)
+
+		log := o.Logger.WithContext(ctx)
+		fields := []logger.Field{
+			logger.String("service", o.ServiceName),
+			logger.String("method", r.Method),
+			logger.String("route", route),
+			logger.Int("status", status),
+			logger.Float("duration_ms", float64(duration.Microseconds())/1000),
+			logger.String("request_id", requestID),
+			logger.String("ip", remoteIP(r)),
+			logger.String("user_agent", r.UserAgent()),
+		}
+		if status >= 500 {
+			log.Error("requisição HTTP", nil, fields...)
+			return
+		}
+		log.Info("requisição HTTP", fields...)
+	})
+}
+
+// serve chama o próximo handler, convertendo panics em respostas 500. O
+// http.ErrAbortHandler é relançado para que o servidor interrompa a resposta.
+func (o *Observability) serve(rw *responseWriter, r *http.Request, next http.Handler) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		if recovered == http.ErrAbortHandler {
+			panic(recovered)
+		}
+
+		o.Logger.WithContext(r.Context()).Error("panic ao processar requisição", fmt.Errorf("%v", recovered),
+			logger.String("service", o.ServiceName),
+			logger.String("request_id", RequestID(r.Context())),
+			logger.String("stack", string(debug.Stack())),
+		)
+		// Se a resposta já começou, o erro fica apenas nas métricas e no log
+		if !rw.Written() {
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		rw.panicked = true
+	}()
+	next.ServeHTTP(rw, r)
+}
+
+// remoteIP retorna o IP da conexão, sem a porta
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ginWriter é implementado pelo ResponseWriter do Gin, que continua sendo
+// usado pelos handlers do Gin em vez do wrapper
+type ginWriter interface {
+	Status() int
+	Written() bool
+}
+
+// responseWriter é um wrapper para http.ResponseWriter que captura o status code
+type responseWriter struct {
+	http.ResponseWriter
+	status   int
+	written  bool
+	panicked bool
+}
+
+// newResponseWriter cria um novo responseWriter
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+// WriteHeader sobrescreve o método WriteHeader para capturar o status code
+func (rw *responseWriter) WriteHeader(code int) {
+	if !rw.written {
+		rw.status = code
+		rw.written = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Write marca a resposta como iniciada com o status atual
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.written = true
+	return rw.ResponseWriter.Write(b)
+}
+
+// Status retorna o status da resposta, preferindo o do ResponseWriter do Gin;
+// requisições interrompidas por panic contam como 500
+func (rw *responseWriter) Status() int {
+	if rw.panicked {
+		return http.StatusInternalServerError
+	}
+	if gw, ok := rw.ResponseWriter.(ginWriter); ok {
+		return gw.Status()
+	}
+	return rw.status
+}
+
+// Written informa se a resposta já começou a ser enviada
+func (rw *responseWriter) Written() bool {
+	if gw, ok := rw.ResponseWriter.(ginWriter); ok && gw.Written() {
+		return true
+	}
+	return rw.written
+}
+
+// Flush repassa o flush para respostas em streaming
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap expõe o ResponseWriter original ao http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
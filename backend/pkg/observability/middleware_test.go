@@ -0,0 +1,174 @@
+package observability
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/insidechurch/observability/logger"
+	"github.com/insidechurch/observability/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// newTestObservability configura a instrumentação com registro próprio e
+// logger em memória
+func newTestObservability(t *testing.T, routes ...string) (*Observability, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	obs, err := Setup(Config{
+		ServiceName: "test-service",
+		Routes:      routes,
+		Registry:    prometheus.NewRegistry(),
+		Logger:      logger.NewWriterLogger(&buf, logger.LevelDebug, nil),
+		Tracing:     &tracing.Config{ServiceName: "test-service", Exporter: tracing.ExporterNone, SampleRatio: 1},
+	})
+	if err != nil {
+		t.Fatalf("Setup falhou: %v", err)
+	}
+	return obs, &buf
+}
+
+// logEntries decodifica as linhas de log gravadas
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Linha de log inválida %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestMiddlewareRequestID(t *testing.T) {
+	obs, _ := newTestObservability(t)
+
+	var seen string
+	handler := obs.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+		if got := r.Header.Get("X-Request-ID"); got != seen {
+			t.Errorf("Header da requisição = %q, esperado %q", got, seen)
+		}
+	}))
+
+	// ID recebido é mantido
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if seen != "req-123" || w.Header().Get("X-Request-ID") != "req-123" {
+		t.Errorf("ID recebido não foi mantido: contexto %q, resposta %q", seen, w.Header().Get("X-Request-ID"))
+	}
+
+	// Sem ID, um novo é gerado e devolvido
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if seen == "" || seen == "req-123" || w.Header().Get("X-Request-ID") != seen {
+		t.Errorf("ID gerado inválido: contexto %q, resposta %q", seen, w.Header().Get("X-Request-ID"))
+	}
+}
+
+func TestMiddlewareMetricsAndLog(t *testing.T) {
+	obs, buf := newTestObservability(t, "/events/{id}")
+
+	handler := obs.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/events/falha" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	for _, path := range []string{"/events/1", "/events/falha"} {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("User-Agent", "test-agent/1.0")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if got := testutil.ToFloat64(obs.Metrics.HTTPRequestsTotal.WithLabelValues("/events/{id}", "POST", "201")); got != 1 {
+		t.Errorf("Esperada 1 requisição no template da rota, obtidas %v", got)
+	}
+	if got := testutil.ToFloat64(obs.Metrics.HTTPErrors.WithLabelValues("/events/{id}", "POST", "503")); got != 1 {
+		t.Errorf("Esperado 1 erro, obtido %v", got)
+	}
+	if got := testutil.ToFloat64(obs.Metrics.HTTPRequestsInFlight); got != 0 {
+		t.Errorf("Nenhuma requisição deveria estar em andamento, obtido %v", got)
+	}
+
+	entries := logEntries(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("Esperada uma linha de log por requisição, obtidas %d", len(entries))
+	}
+	ok, failed := entries[0], entries[1]
+	if ok["level"] != "INFO" || failed["level"] != "ERROR" {
+		t.Errorf("Níveis inesperados: %v e %v", ok["level"], failed["level"])
+	}
+	fields, _ := ok["fields"].(map[string]interface{})
+	for key, want := range map[string]interface{}{
+		"service": "test-service",
+		"method":  "POST",
+		"route":   "/events/{id}",
+		"status":  float64(201),
+	} {
+		if fields[key] != want {
+			t.Errorf("Campo %s = %v, esperado %v", key, fields[key], want)
+		}
+	}
+	for _, key := range []string{"duration_ms", "request_id", "ip", "user_agent"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("Campo %s ausente no log da requisição", key)
+		}
+	}
+}
+
+func TestMiddlewareRecoversPanic(t *testing.T) {
+	obs, buf := newTestObservability(t, "/panic")
+
+	handler := obs.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("falha inesperada")
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Status = %d, esperado 500", w.Code)
+	}
+	if got := testutil.ToFloat64(obs.Metrics.HTTPErrors.WithLabelValues("/panic", "GET", "500")); got != 1 {
+		t.Errorf("Panic deveria ser contado como erro, obtido %v", got)
+	}
+	if !strings.Contains(buf.String(), "falha inesperada") || !strings.Contains(buf.String(), "stack") {
+		t.Errorf("Panic não registrado no log com a pilha: %s", buf.String())
+	}
+}
+
+func TestMiddlewareAbortHandlerIsRethrown(t *testing.T) {
+	obs, _ := newTestObservability(t)
+
+	handler := obs.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if recover() != http.ErrAbortHandler {
+			t.Error("http.ErrAbortHandler deveria ser relançado")
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestMiddlewareSetRoute(t *testing.T) {
+	obs, _ := newTestObservability(t)
+
+	handler := obs.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetRoute(r.Context(), "/api/v1/events/:id")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/events/42", nil))
+
+	if got := testutil.ToFloat64(obs.Metrics.HTTPRequestsTotal.WithLabelValues("/api/v1/events/:id", "GET", "200")); got != 1 {
+		t.Errorf("Rota informada pelo roteador não foi usada, obtido %v", got)
+	}
+}
@@ -0,0 +1,122 @@
+// Package observability reúne a instrumentação comum aos serviços: logs
+// estruturados, métricas do Prometheus, tracing e redação de dados pessoais,
+// além da cadeia de middlewares HTTP que os aplica a cada requisição.
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/insidechurch/observability/logger"
+	"github.com/insidechurch/observability/metrics"
+	"github.com/insidechurch/observability/redact"
+	"github.com/insidechurch/observability/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+)
+
+// Config define a instrumentação de um serviço
+type Config struct {
+	ServiceName string
+
+	// Routes são os templates de rota usados no rótulo path das métricas e
+	// no nome dos spans (ver metrics.Routes)
+	Routes []string
+
+	// Registry recebe as métricas e é exposto em Handler; nil usa o registro
+	// padrão do Prometheus
+	Registry *prometheus.Registry
+
+	// Logger substitui o logger configurado pelas variáveis de ambiente
+	Logger *logger.JSONLogger
+
+	// Tracing substitui a configuração de tracing das variáveis de ambiente
+	// (ver tracing.NewConfig)
+	Tracing *tracing.Config
+}
+
+// Observability é a instrumentação configurada de um serviço
+type Observability struct {
+	ServiceName string
+	Logger      *logger.JSONLogger
+	Metrics     *metrics.Metrics
+	Redactor    *redact.Redactor
+
+	gatherer prometheus.Gatherer
+}
+
+// Setup configura a redação de dados pessoais (PII_REDACTION*), o logger
+// (LOG_*), o tracing (OTEL_*) e as métricas do serviço, e aplica a redação aos
+// atributos dos spans. Um mesmo registro aceita apenas uma chamada. O tracing
+// não depende do coletor estar disponível: falhas de exportação são
+// registradas no log.
+func Setup(cfg Config) (*Observability, error) {
+	redactCfg, err := redact.NewConfig()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao configurar a redação de dados pessoais: %v", err)
+	}
+	redactor, err := redact.New(redactCfg)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao configurar a redação de dados pessoais: %v", err)
+	}
+	tracing.SetRedactor(redactor)
+
+	log := cfg.Logger
+	if log == nil {
+		logCfg, err := logger.NewConfig()
+		if err != nil {
+			return nil, fmt.Errorf("falha ao configurar o logger: %v", err)
+		}
+		logCfg.Redactor = redactor
+		if log, err = logger.New(logCfg); err != nil {
+			return nil, fmt.Errorf("falha ao inicializar o logger: %v", err)
+		}
+	}
+
+	tracingCfg := cfg.Tracing
+	if tracingCfg == nil {
+		if tracingCfg, err = tracing.NewConfig(cfg.ServiceName); err != nil {
+			return nil, fmt.Errorf("falha ao configurar o tracing: %v", err)
+		}
+	}
+	if err := tracing.Init(tracingCfg); err != nil {
+		return nil, fmt.Errorf("falha ao inicializar o tracing: %v", err)
+	}
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		log.Error("Erro no tracing", err)
+	}))
+
+	var registerer prometheus.Registerer = prometheus.DefaultRegisterer
+	var gatherer prometheus.Gatherer = prometheus.DefaultGatherer
+	if cfg.Registry != nil {
+		registerer, gatherer = cfg.Registry, cfg.Registry
+	}
+
+	return &Observability{
+		ServiceName: cfg.ServiceName,
+		Logger:      log,
+		Metrics:     metrics.New(registerer, cfg.Routes...),
+		Redactor:    redactor,
+		gatherer:    gatherer,
+	}, nil
+}
+
+// Handler expõe as métricas do registro configurado, para a rota /metrics
+func (o *Observability) Handler() http.Handler {
+	return promhttp.HandlerFor(o.gatherer, promhttp.HandlerOpts{})
+}
+
+// Shutdown exporta os spans pendentes, respeitando o prazo de ctx, e fecha os
+// arquivos de log
+func (o *Observability) Shutdown(ctx context.Context) error {
+	err := tracing.Shutdown(ctx)
+	if err != nil {
+		o.Logger.Error("Erro ao encerrar o tracing", err)
+	}
+	if closeErr := o.Logger.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ActionSecret, usado apenas em Config.Rules, classifica um campo como
+// segredo
+const ActionSecret Action = "secret"
+
+// ErrSecretField indica uma regra que tentaria registrar uma senha ou token
+var ErrSecretField = errors.New("segredos não podem ser registrados")
+
+// Config contém as configurações de redação
+type Config struct {
+	Mode    Action            // tratamento padrão dos dados pessoais
+	Rules   map[string]Action // tratamento por nome de campo
+	HashKey []byte            // chave do HMAC; vazia gera uma chave por processo
+}
+
+// NewConfig cria a configuração a partir das variáveis de ambiente
+// PII_REDACTION (keep, mask, hash ou drop), PII_REDACTION_RULES (lista
+// campo=ação separada por vírgula) e PII_HASH_KEY
+func NewConfig() (*Config, error) {
+	cfg := &Config{
+		Mode:    Action(strings.ToLower(getEnv("PII_REDACTION", string(ActionMask)))),
+		Rules:   make(map[string]Action),
+		HashKey: []byte(os.Getenv("PII_HASH_KEY")),
+	}
+
+	for _, rule := range strings.Split(os.Getenv("PII_REDACTION_RULES"), ",") {
+		if rule = strings.TrimSpace(rule); rule == "" {
+			continue
+		}
+		key, action, ok := strings.Cut(rule, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("regra de redação inválida: %q", rule)
+		}
+		cfg.Rules[strings.TrimSpace(key)] = Action(strings.ToLower(strings.TrimSpace(action)))
+	}
+	return cfg, nil
+}
+
+// getEnv retorna o valor da variável de ambiente ou um valor padrão
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,356 @@
+// Package redact aplica a política de dados pessoais (LGPD) aos logs, aos
+// registros de auditoria e aos atributos dos traces. Cada campo é
+// classificado pelo nome; dados pessoais (email, telefone, IP, nome) são
+// mascarados, substituídos por um hash ou mantidos conforme a configuração, e
+// segredos (senhas e tokens) nunca são registrados.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Kind é a classificação de um campo, que determina como ele é mascarado
+type Kind string
+
+const (
+	KindEmail     Kind = "email"
+	KindPhone     Kind = "phone"
+	KindIP        Kind = "ip"
+	KindName      Kind = "name"
+	KindUserAgent Kind = "user_agent"
+	KindURL       Kind = "url"
+	KindGeneric   Kind = "generic"
+	KindSecret    Kind = "secret"
+)
+
+// Action é o tratamento aplicado a um campo com dado pessoal
+type Action string
+
+const (
+	ActionKeep Action = "keep" // registra o valor original
+	ActionMask Action = "mask" // registra o valor parcialmente ocultado
+	ActionHash Action = "hash" // registra um HMAC do valor, que permite correlacionar sem revelar
+	ActionDrop Action = "drop" // omite o campo
+)
+
+// Placeholder substitui o valor de segredos
+const Placeholder = "[REDACTED]"
+
+// Rule define a classificação e o tratamento de um campo
+type Rule struct {
+	Kind   Kind
+	Action Action
+}
+
+// defaultKinds classifica os nomes de campo conhecidos. Os nomes são
+// comparados sem diferenciar maiúsculas, inteiros ou pelo último segmento
+// após o ponto (http.remote_addr usa a regra de remote_addr).
+var defaultKinds = map[string]Kind{
+	"email":       KindEmail,
+	"admin_email": KindEmail,
+	"phone":       KindPhone,
+	"telefone":    KindPhone,
+	"ip":          KindIP,
+	"remote_addr": KindIP,
+	"client_ip":   KindIP,
+	"name":        KindName,
+	"nome":        KindName,
+	"full_name":   KindName,
+	"user_agent":  KindUserAgent,
+	"url":         KindURL,
+
+	"password":      KindSecret,
+	"senha":         KindSecret,
+	"token":         KindSecret,
+	"access_token":  KindSecret,
+	"refresh_token": KindSecret,
+	"id_token":      KindSecret,
+	"client_secret": KindSecret,
+	"secret":        KindSecret,
+	"api_key":       KindSecret,
+	"authorization": KindSecret,
+	"cookie":        KindSecret,
+	"code":          KindSecret,
+	"code_verifier": KindSecret,
+	"totp_code":     KindSecret,
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	jwtPattern   = regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+)
+
+// Redactor aplica as regras de redação. Um Redactor nil usa Default.
+type Redactor struct {
+	rules   map[string]Rule
+	hashKey []byte
+}
+
+// New cria um Redactor com a configuração informada. Regras de cfg.Rules
+// substituem o tratamento do campo; campos desconhecidos passam a ser
+// tratados como dado pessoal genérico, ou segredo com ActionSecret.
+func New(cfg *Config) (*Redactor, error) {
+	switch cfg.Mode {
+	case ActionKeep, ActionMask, ActionHash, ActionDrop:
+	default:
+		return nil, fmt.Errorf("modo de redação desconhecido: %q", cfg.Mode)
+	}
+
+	rules := make(map[string]Rule, len(defaultKinds)+len(cfg.Rules))
+	for key, kind := range defaultKinds {
+		rules[key] = Rule{Kind: kind, Action: cfg.Mode}
+	}
+	for key, action := range cfg.Rules {
+		key = normalize(key)
+		rule, known := rules[key]
+		if !known {
+			rule.Kind = KindGeneric
+		}
+		if action == ActionSecret {
+			rule.Kind = KindSecret
+			rules[key] = rule
+			continue
+		}
+		if rule.Kind == KindSecret {
+			return nil, fmt.Errorf("%w: %s", ErrSecretField, key)
+		}
+		switch action {
+		case ActionKeep, ActionMask, ActionHash, ActionDrop:
+		default:
+			return nil, fmt.Errorf("ação de redação desconhecida para %s: %q", key, action)
+		}
+		rule.Action = action
+		rules[key] = rule
+	}
+
+	hashKey := cfg.HashKey
+	if len(hashKey) == 0 {
+		hashKey = make([]byte, 32)
+		if _, err := rand.Read(hashKey); err != nil {
+			return nil, fmt.Errorf("erro ao gerar chave de hash: %w", err)
+		}
+	}
+
+	return &Redactor{rules: rules, hashKey: hashKey}, nil
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultRedactor *Redactor
+)
+
+// Default retorna o Redactor com as regras padrão: dados pessoais mascarados
+// e segredos omitidos. A chave de hash é gerada por processo.
+func Default() *Redactor {
+	defaultOnce.Do(func() {
+		r, err := New(&Config{Mode: ActionMask})
+		if err != nil {
+			panic(err)
+		}
+		defaultRedactor = r
+	})
+	return defaultRedactor
+}
+
+// Rule retorna a regra aplicada ao campo key; ok é false para campos sem
+// dados pessoais
+func (r *Redactor) Rule(key string) (rule Rule, ok bool) {
+	if r == nil {
+		r = Default()
+	}
+	key = normalize(key)
+	if rule, ok := r.rules[key]; ok {
+		return rule, true
+	}
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		if rule, ok := r.rules[key[i+1:]]; ok {
+			return rule, true
+		}
+	}
+	if strings.Contains(key, "password") || strings.Contains(key, "secret") || strings.HasSuffix(key, "_token") {
+		return Rule{Kind: KindSecret}, true
+	}
+	return Rule{}, false
+}
+
+// String aplica ao valor a regra do campo key. ok é false quando o campo
+// deve ser omitido. Campos sem dados pessoais passam por Scrub.
+func (r *Redactor) String(key, value string) (string, bool) {
+	if r == nil {
+		r = Default()
+	}
+	rule, classified := r.Rule(key)
+	if !classified {
+		return r.Scrub(value), true
+	}
+	return r.apply(rule, value)
+}
+
+// Value aplica a regra do campo key a um valor qualquer. Valores de campos
+// classificados são convertidos em texto; os demais são mantidos, exceto
+// textos, que passam por Scrub.
+func (r *Redactor) Value(key string, value interface{}) (interface{}, bool) {
+	if r == nil {
+		r = Default()
+	}
+	rule, classified := r.Rule(key)
+	if !classified {
+		if text, ok := value.(string); ok {
+			return r.Scrub(text), true
+		}
+		return value, true
+	}
+	if value == nil {
+		return nil, true
+	}
+	if rule.Kind == KindSecret {
+		return Placeholder, true
+	}
+	text, ok := value.(string)
+	if !ok {
+		text = fmt.Sprint(value)
+	}
+	return r.apply(rule, text)
+}
+
+// Scrub oculta emails e JWTs encontrados em um texto livre, como mensagens
+// de erro e descrições
+func (r *Redactor) Scrub(text string) string {
+	if r == nil {
+		r = Default()
+	}
+	if !strings.Contains(text, "@") && !strings.Contains(text, "eyJ") {
+		return text
+	}
+	text = jwtPattern.ReplaceAllString(text, Placeholder)
+	rule := r.rules["email"]
+	return emailPattern.ReplaceAllStringFunc(text, func(email string) string {
+		redacted, ok := r.apply(rule, email)
+		if !ok {
+			return Placeholder
+		}
+		return redacted
+	})
+}
+
+func (r *Redactor) apply(rule Rule, value string) (string, bool) {
+	if rule.Kind == KindSecret {
+		return Placeholder, true
+	}
+	if value == "" {
+		return value, true
+	}
+	switch rule.Action {
+	case ActionKeep:
+		return value, true
+	case ActionHash:
+		if rule.Kind == KindIP {
+			value = hostOnly(value)
+		}
+		return r.hash(value), true
+	case ActionDrop:
+		return "", false
+	}
+	return mask(rule.Kind, value), true
+}
+
+// hash retorna o HMAC-SHA256 do valor, truncado. O valor é normalizado para
+// que o mesmo email ou IP gere sempre o mesmo hash.
+func (r *Redactor) hash(value string) string {
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(value))))
+	return "hash:" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// mask oculta a parte identificadora do valor, mantendo o suficiente para
+// diagnóstico
+func mask(kind Kind, value string) string {
+	switch kind {
+	case KindEmail:
+		local, domain, ok := strings.Cut(value, "@")
+		if !ok || local == "" {
+			return maskGeneric(value)
+		}
+		return firstRune(local) + "***@" + domain
+	case KindIP:
+		return maskIP(value)
+	case KindPhone:
+		return maskPhone(value)
+	case KindName:
+		words := strings.Fields(value)
+		for i, word := range words {
+			words[i] = firstRune(word) + "***"
+		}
+		return strings.Join(words, " ")
+	case KindUserAgent:
+		// Mantém o primeiro produto (ex.: Mozilla/5.0), sem a plataforma e
+		// as versões que ajudam a identificar o dispositivo
+		product, _, _ := strings.Cut(value, " ")
+		return product
+	case KindURL:
+		if i := strings.IndexAny(value, "?#"); i >= 0 {
+			return value[:i]
+		}
+		return value
+	}
+	return maskGeneric(value)
+}
+
+// maskIP zera o último octeto do IPv4 ou mantém apenas o prefixo /48 do
+// IPv6; a porta é descartada
+func maskIP(value string) string {
+	ip := net.ParseIP(hostOnly(value))
+	if ip == nil {
+		return maskGeneric(value)
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// maskPhone mantém apenas os dois últimos dígitos
+func maskPhone(value string) string {
+	var digits []rune
+	for _, c := range value {
+		if unicode.IsDigit(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits) <= 2 {
+		return maskGeneric(value)
+	}
+	return strings.Repeat("*", len(digits)-2) + string(digits[len(digits)-2:])
+}
+
+// hostOnly descarta a porta de um endereço host:porta
+func hostOnly(value string) string {
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		return host
+	}
+	return value
+}
+
+func maskGeneric(value string) string {
+	return firstRune(value) + "***"
+}
+
+func firstRune(value string) string {
+	for _, c := range value {
+		return string(c)
+	}
+	return ""
+}
+
+func normalize(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Exportadores de spans aceitos em Config.Exporter
+const (
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterOTLPHTTP = "otlp-http"
+	ExporterStdout   = "stdout"
+	ExporterNone     = "none"
+)
+
+// Config define como os spans de um serviço são amostrados e exportados
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+
+	// Exporter é um dos Exporter*; com ExporterNone os spans continuam sendo
+	// criados e propagados, para correlacionar logs, mas não são exportados
+	Exporter string
+
+	// Endpoint é o host:porta do coletor OTLP; vazio usa a porta padrão do
+	// protocolo em localhost
+	Endpoint string
+	Insecure bool
+
+	// SampleRatio é a fração, entre 0 e 1, dos traces iniciados no serviço que
+	// são amostrados; traces recebidos seguem a decisão de quem os iniciou
+	SampleRatio float64
+
+	// Stdout é o destino de ExporterStdout; nil usa os.Stdout
+	Stdout io.Writer
+}
+
+// NewConfig cria a configuração a partir das variáveis de ambiente
+// OTEL_TRACES_EXPORTER (otlp, stdout ou none), OTEL_EXPORTER_OTLP_PROTOCOL
+// (grpc ou http/protobuf), OTEL_EXPORTER_OTLP_ENDPOINT,
+// OTEL_EXPORTER_OTLP_INSECURE, OTEL_TRACES_SAMPLER_ARG e OTEL_SERVICE_VERSION.
+// Sem OTEL_TRACES_EXPORTER, os spans são exportados por OTLP apenas se houver
+// um endpoint configurado.
+func NewConfig(serviceName string) (*Config, error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+
+	defaultExporter := "none"
+	if endpoint != "" {
+		defaultExporter = "otlp"
+	}
+
+	var exporter string
+	switch name := getEnv("OTEL_TRACES_EXPORTER", defaultExporter); name {
+	case "otlp":
+		switch protocol := getEnv("OTEL_EXPORTER_OTLP_PROTOCOL", "grpc"); protocol {
+		case "grpc":
+			exporter = ExporterOTLPGRPC
+		case "http/protobuf", "http":
+			exporter = ExporterOTLPHTTP
+		default:
+			return nil, fmt.Errorf("protocolo OTLP desconhecido: %q", protocol)
+		}
+	case "stdout", "console":
+		exporter = ExporterStdout
+	case "none":
+		exporter = ExporterNone
+	default:
+		return nil, fmt.Errorf("exportador de traces desconhecido: %q", name)
+	}
+
+	// O endpoint pode vir como URL, como no padrão do OpenTelemetry
+	insecure := true
+	if strings.HasPrefix(endpoint, "https://") {
+		insecure = false
+	}
+	endpoint = strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://")
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	if value := os.Getenv("OTEL_EXPORTER_OTLP_INSECURE"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("OTEL_EXPORTER_OTLP_INSECURE inválido: %v", err)
+		}
+		insecure = parsed
+	}
+
+	ratio, err := strconv.ParseFloat(getEnv("OTEL_TRACES_SAMPLER_ARG", "1"), 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG deve ser um número entre 0 e 1: %q", os.Getenv("OTEL_TRACES_SAMPLER_ARG"))
+	}
+
+	return &Config{
+		ServiceName:    serviceName,
+		ServiceVersion: getEnv("OTEL_SERVICE_VERSION", "1.0.0"),
+		Exporter:       exporter,
+		Endpoint:       endpoint,
+		Insecure:       insecure,
+		SampleRatio:    ratio,
+	}, nil
+}
+
+// getEnv retorna o valor da variável de ambiente ou um valor padrão
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists && value != "" {
+		return value
+	}
+	return defaultValue
+}
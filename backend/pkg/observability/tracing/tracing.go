@@ -0,0 +1,286 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/insidechurch/observability/redact"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifica os spans criados por este pacote
+const instrumentationName = "github.com/insidechurch/observability/tracing"
+
+// propagator lê e grava o contexto do trace nos headers (W3C traceparent e
+// baggage), mesmo antes de InitTracer
+var propagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+var (
+	// mu protege tracer e provider, trocados por Init e Shutdown
+	mu sync.RWMutex
+
+	// provider é o provedor criado em Init, encerrado em Shutdown
+	provider *sdktrace.TracerProvider
+
+	// tracer usa o provedor global: sem InitTracer, os spans não são
+	// exportados, mas o contexto recebido continua sendo propagado
+	tracer trace.Tracer = otel.Tracer(instrumentationName)
+
+	// redactor trata os dados pessoais dos atributos; nil usa redact.Default
+	redactor *redact.Redactor
+)
+
+// currentTracer retorna o tracer do provedor configurado
+func currentTracer() trace.Tracer {
+	mu.RLock()
+	defer mu.RUnlock()
+	return tracer
+}
+
+// SetRedactor define a redação aplicada aos atributos dos spans
+func SetRedactor(r *redact.Redactor) {
+	redactor = r
+}
+
+// redactedAttributes converte os atributos aplicando a redação; atributos
+// omitidos pela configuração não são registrados
+func redactedAttributes(attributes map[string]string) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attributes))
+	for k, v := range attributes {
+		if v, ok := redactor.String(k, v); ok {
+			kvs = append(kvs, attribute.String(k, v))
+		}
+	}
+	return kvs
+}
+
+// InitTracer inicializa o tracer do OpenTelemetry com a configuração das
+// variáveis de ambiente (ver NewConfig)
+func InitTracer(serviceName string) error {
+	cfg, err := NewConfig(serviceName)
+	if err != nil {
+		return err
+	}
+	return Init(cfg)
+}
+
+// Init configura o provedor global de traces. Não bloqueia: a conexão com o
+// coletor é feita em segundo plano e, enquanto ele estiver indisponível, os
+// lotes de spans são descartados após as tentativas de envio. Um provedor
+// configurado antes é substituído sem ser encerrado.
+func Init(cfg *Config) error {
+	ctx := context.Background()
+
+	// Criar o recurso com informações do serviço
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceNameKey.String(cfg.ServiceName),
+			semconv.ServiceVersionKey.String(cfg.ServiceVersion),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("falha ao criar recurso: %v", err)
+	}
+
+	// Amostrar a fração configurada dos traces iniciados aqui, respeitando a
+	// decisão de quem iniciou os traces recebidos
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(res),
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+
+	// Configurar propagação de contexto
+	otel.SetTextMapPropagator(propagator)
+
+	// Configurar o tracer global
+	otel.SetTracerProvider(tp)
+
+	mu.Lock()
+	provider = tp
+	tracer = tp.Tracer(cfg.ServiceName)
+	mu.Unlock()
+
+	return nil
+}
+
+// newExporter cria o exportador configurado; ExporterNone não tem exportador
+func newExporter(ctx context.Context, cfg *Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterOTLPGRPC:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpointOrDefault(cfg.Endpoint, "localhost:4317"))}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao criar exportador OTLP: %v", err)
+		}
+		return exporter, nil
+	case ExporterOTLPHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpointOrDefault(cfg.Endpoint, "localhost:4318"))}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao criar exportador OTLP: %v", err)
+		}
+		return exporter, nil
+	case ExporterStdout:
+		w := cfg.Stdout
+		if w == nil {
+			w = os.Stdout
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, fmt.Errorf("falha ao criar exportador stdout: %v", err)
+		}
+		return exporter, nil
+	case ExporterNone, "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("exportador de traces desconhecido: %q", cfg.Exporter)
+	}
+}
+
+func endpointOrDefault(endpoint, defaultEndpoint string) string {
+	if endpoint == "" {
+		return defaultEndpoint
+	}
+	return endpoint
+}
+
+// Shutdown exporta os spans pendentes e encerra o provedor configurado em
+// Init, respeitando o prazo de ctx. Sem Init, não tem efeito.
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	tp := provider
+	provider = nil
+	mu.Unlock()
+
+	if tp == nil {
+		return nil
+	}
+	if err := tp.Shutdown(ctx); err != nil {
+		return fmt.Errorf("falha ao encerrar o provedor de traces: %v", err)
+	}
+	return nil
+}
+
+// StartServerSpan inicia o span de uma requisição recebida, continuando o
+// trace informado nos headers. O nome inicial é o caminho; quem conhece o
+// template da rota deve renomear o span com ele.
+func StartServerSpan(r *http.Request) (context.Context, trace.Span) {
+	ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return currentTracer().Start(ctx, r.URL.Path,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(redactedAttributes(map[string]string{
+			"http.method":      r.Method,
+			"http.url":         r.URL.String(),
+			"http.user_agent":  r.UserAgent(),
+			"http.remote_addr": r.RemoteAddr,
+		})...),
+	)
+}
+
+// TracedHandler é um middleware que adiciona tracing às requisições HTTP
+func TracedHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Criar um novo span, continuando o trace da requisição
+		ctx, span := StartServerSpan(r)
+		defer span.End()
+
+		// Criar um ResponseWriter personalizado para capturar o status code
+		rw := newResponseWriter(w)
+
+		// Chamar o próximo handler
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		// Adicionar o status code ao span
+		span.SetAttributes(attribute.Int("http.status_code", rw.statusCode))
+	})
+}
+
+// responseWriter é um wrapper para http.ResponseWriter que captura o status code
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+// newResponseWriter cria um novo responseWriter
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{w, http.StatusOK}
+}
+
+// WriteHeader sobrescreve o método WriteHeader para capturar o status code
+func (rw *responseWriter) WriteHeader(code int) {
+	rw.statusCode = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// TraceSpan cria um novo span para uma operação
+func TraceSpan(ctx context.Context, name string, fn func(context.Context) error) error {
+	ctx, span := currentTracer().Start(ctx, name)
+	defer span.End()
+
+	err := fn(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// TraceSpanWithAttributes cria um novo span com atributos para uma operação
+func TraceSpanWithAttributes(ctx context.Context, name string, attributes map[string]string, fn func(context.Context) error) error {
+	ctx, span := currentTracer().Start(ctx, name)
+	defer span.End()
+
+	// Adicionar atributos ao span, sem dados pessoais
+	span.SetAttributes(redactedAttributes(attributes)...)
+
+	err := fn(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// IDs retorna os identificadores do trace e do span ativos em ctx, ou strings
+// vazias se não houver span válido
+func IDs(ctx context.Context) (traceID, spanID string) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", ""
+	}
+	return sc.TraceID().String(), sc.SpanID().String()
+}
@@ -0,0 +1,295 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInitTracer(t *testing.T) {
+	// Teste com endpoint padrão
+	err := InitTracer("test-service")
+	if err != nil {
+		t.Errorf("InitTracer falhou com endpoint padrão: %v", err)
+	}
+
+	// Teste com endpoint personalizado
+	os.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4318")
+	err = InitTracer("test-service")
+	if err != nil {
+		t.Errorf("InitTracer falhou com endpoint personalizado: %v", err)
+	}
+}
+
+func TestTracedHandler(t *testing.T) {
+	// Inicializar o tracer
+	err := InitTracer("test-service")
+	if err != nil {
+		t.Fatalf("Falha ao inicializar tracer: %v", err)
+	}
+
+	// Criar um handler de teste
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// Criar um request de teste
+	req := httptest.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+
+	// Executar o handler com tracing
+	handler := TracedHandler(testHandler)
+	handler.ServeHTTP(w, req)
+
+	// Verificar o status code
+	if w.Code != http.StatusOK {
+		t.Errorf("Status code esperado %d, obtido %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestTraceSpan(t *testing.T) {
+	// Inicializar o tracer
+	err := InitTracer("test-service")
+	if err != nil {
+		t.Fatalf("Falha ao inicializar tracer: %v", err)
+	}
+
+	// Teste com função que retorna erro
+	err = TraceSpan(context.Background(), "test-error", func(ctx context.Context) error {
+		return fmt.Errorf("erro de teste")
+	})
+	if err == nil {
+		t.Error("Esperava erro, obteve nil")
+	}
+
+	// Teste com função que não retorna erro
+	err = TraceSpan(context.Background(), "test-success", func(ctx context.Context) error {
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Não esperava erro, obteve: %v", err)
+	}
+}
+
+func TestTraceSpanWithAttributes(t *testing.T) {
+	// Inicializar o tracer
+	err := InitTracer("test-service")
+	if err != nil {
+		t.Fatalf("Falha ao inicializar tracer: %v", err)
+	}
+
+	// Teste com atributos e função que retorna erro
+	attributes := map[string]string{
+		"test_key": "test_value",
+	}
+	err = TraceSpanWithAttributes(context.Background(), "test-error", attributes, func(ctx context.Context) error {
+		return fmt.Errorf("erro de teste")
+	})
+	if err == nil {
+		t.Error("Esperava erro, obteve nil")
+	}
+
+	// Teste com atributos e função que não retorna erro
+	err = TraceSpanWithAttributes(context.Background(), "test-success", attributes, func(ctx context.Context) error {
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Não esperava erro, obteve: %v", err)
+	}
+}
+
+func TestResponseWriter(t *testing.T) {
+	// Criar um ResponseWriter de teste
+	w := httptest.NewRecorder()
+	rw := newResponseWriter(w)
+
+	// Testar WriteHeader
+	statusCode := http.StatusNotFound
+	rw.WriteHeader(statusCode)
+	if rw.statusCode != statusCode {
+		t.Errorf("Status code esperado %d, obtido %d", statusCode, rw.statusCode)
+	}
+
+	// Testar Write
+	testData := []byte("test data")
+	n, err := rw.Write(testData)
+	if err != nil {
+		t.Errorf("Erro ao escrever: %v", err)
+	}
+	if n != len(testData) {
+		t.Errorf("Bytes escritos esperados %d, obtidos %d", len(testData), n)
+	}
+}
+
+func TestRedactedAttributes(t *testing.T) {
+	kvs := redactedAttributes(map[string]string{
+		"http.url":         "/auth/verify?token=abc",
+		"http.remote_addr": "10.0.0.7:5000",
+		"email":            "ana@email.com",
+		"method":           "POST",
+	})
+
+	got := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		got[string(kv.Key)] = kv.Value.AsString()
+	}
+	if got["http.url"] != "/auth/verify" || got["http.remote_addr"] != "10.0.0.0" ||
+		got["email"] != "a***@email.com" || got["method"] != "POST" {
+		t.Errorf("Atributos inesperados: %v", got)
+	}
+}
+
+func TestNewConfig(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "")
+
+	// Sem endpoint nem exportador, os spans não são exportados
+	cfg, err := NewConfig("svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Exporter != ExporterNone || cfg.SampleRatio != 1 {
+		t.Errorf("Configuração padrão inesperada: %+v", cfg)
+	}
+
+	// Com endpoint, OTLP por gRPC; o endpoint pode vir como URL
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://collector:4317/")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	cfg, err = NewConfig("svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Exporter != ExporterOTLPGRPC || cfg.Endpoint != "collector:4317" || cfg.Insecure || cfg.SampleRatio != 0.25 {
+		t.Errorf("Configuração OTLP inesperada: %+v", cfg)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+	if cfg, _ = NewConfig("svc"); cfg.Exporter != ExporterOTLPHTTP {
+		t.Errorf("Esperado %s, obtido %s", ExporterOTLPHTTP, cfg.Exporter)
+	}
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "stdout")
+	if cfg, _ = NewConfig("svc"); cfg.Exporter != ExporterStdout {
+		t.Errorf("Esperado %s, obtido %s", ExporterStdout, cfg.Exporter)
+	}
+
+	for key, value := range map[string]string{
+		"OTEL_TRACES_EXPORTER":        "jaeger",
+		"OTEL_TRACES_SAMPLER_ARG":     "1.5",
+		"OTEL_EXPORTER_OTLP_INSECURE": "talvez",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := NewConfig("svc"); err == nil {
+				t.Errorf("%s=%s deveria ser recusado", key, value)
+			}
+		})
+	}
+}
+
+func TestInitDoesNotBlockWithoutCollector(t *testing.T) {
+	for _, exporter := range []string{ExporterOTLPGRPC, ExporterOTLPHTTP} {
+		done := make(chan error, 1)
+		go func() {
+			done <- Init(&Config{ServiceName: "test-service", Exporter: exporter, Endpoint: "127.0.0.1:1", Insecure: true, SampleRatio: 1})
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Init com %s falhou: %v", exporter, err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Init com %s bloqueou sem coletor", exporter)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		Shutdown(ctx)
+		cancel()
+	}
+}
+
+// initStdout configura o tracing exportando para buf
+func initStdout(t *testing.T, buf *bytes.Buffer, ratio float64) {
+	t.Helper()
+	if err := Init(&Config{ServiceName: "test-service", Exporter: ExporterStdout, Stdout: buf, SampleRatio: ratio}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Shutdown(context.Background()) })
+}
+
+func TestShutdownFlushesSpans(t *testing.T) {
+	var buf bytes.Buffer
+	initStdout(t, &buf, 1)
+
+	TraceSpan(context.Background(), "operacao-pendente", func(ctx context.Context) error { return nil })
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "operacao-pendente") {
+		t.Errorf("Span pendente não foi exportado no Shutdown: %s", buf.String())
+	}
+	if err := Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown repetido deveria ser ignorado: %v", err)
+	}
+}
+
+func TestParentBasedSampling(t *testing.T) {
+	var buf bytes.Buffer
+	initStdout(t, &buf, 0)
+
+	// Trace iniciado aqui: a fração 0 descarta
+	req := httptest.NewRequest("GET", "/", nil)
+	_, span := StartServerSpan(req)
+	if span.SpanContext().IsSampled() {
+		t.Error("Com fração 0, traces iniciados aqui não deveriam ser amostrados")
+	}
+	span.End()
+
+	// Trace recebido já amostrado: a decisão do pai prevalece
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span = StartServerSpan(req)
+	if !span.SpanContext().IsSampled() || span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Trace recebido deveria ser continuado e amostrado: %v", span.SpanContext())
+	}
+	span.End()
+}
+
+func TestTransportPropagatesContext(t *testing.T) {
+	var buf bytes.Buffer
+	initStdout(t, &buf, 1)
+
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := StartServerSpan(r)
+		defer span.End()
+		received, _ = IDs(ctx)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	ctx, parent := currentTracer().Start(context.Background(), "chamada")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/notifications", nil)
+	resp, err := NewClient(time.Second).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	parent.End()
+
+	if want := parent.SpanContext().TraceID().String(); received != want {
+		t.Errorf("Serviço chamado deveria continuar o trace %s, obteve %q", want, received)
+	}
+	if req.Header.Get("traceparent") != "" {
+		t.Error("A requisição original não deveria ser alterada")
+	}
+}
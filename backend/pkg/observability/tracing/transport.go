@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport é um http.RoundTripper que cria um span de cliente para cada
+// requisição e injeta o contexto do trace nos headers, para que o serviço
+// chamado continue o mesmo trace. O span é filho do span ativo no context da
+// requisição, então as chamadas devem usar http.NewRequestWithContext.
+type Transport struct {
+	base http.RoundTripper
+}
+
+// NewTransport cria uma nova instância de Transport sobre base; nil usa
+// http.DefaultTransport
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{base: base}
+}
+
+// NewClient cria um http.Client com Transport e o timeout informado
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: NewTransport(nil), Timeout: timeout}
+}
+
+// RoundTrip executa a requisição dentro de um span de cliente
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, span := currentTracer().Start(r.Context(), "HTTP "+r.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(redactedAttributes(map[string]string{
+			"http.method":   r.Method,
+			"http.url":      r.URL.String(),
+			"net.peer.name": r.URL.Hostname(),
+		})...),
+	)
+	defer span.End()
+
+	// RoundTrip não deve alterar a requisição recebida
+	r = r.Clone(ctx)
+	propagator.Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
@@ -0,0 +1,267 @@
+// Package passwordhash gera e verifica os hashes de senha da API principal e
+// do auth-service. Os hashes Argon2id usam o formato PHC
+// ($argon2id$v=19$m=...,t=...,p=...$salt$hash) e os hashes bcrypt mantêm o
+// formato modular ($2a$...), de modo que as senhas já cadastradas continuam
+// válidas. Cada hash carrega o algoritmo e os parâmetros usados, o que permite
+// atualizá-lo no próximo login quando a configuração ficar mais forte.
+package passwordhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algoritmos suportados
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+var (
+	// ErrMismatchedPassword indica que a senha não corresponde ao hash
+	ErrMismatchedPassword = errors.New("senha não corresponde ao hash")
+
+	// ErrUnsupportedHash indica um hash em formato desconhecido ou corrompido
+	ErrUnsupportedHash = errors.New("formato de hash de senha não suportado")
+)
+
+// Argon2Params são os parâmetros do Argon2id
+type Argon2Params struct {
+	// Memory é a memória usada, em KiB
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// Hasher gera os hashes com o algoritmo configurado e verifica hashes de
+// qualquer algoritmo suportado
+type Hasher struct {
+	Algorithm  string
+	BcryptCost int
+	Argon2     Argon2Params
+}
+
+// Default retorna a configuração padrão: Argon2id com 19 MiB, 2 iterações e
+// paralelismo 1, conforme a recomendação da OWASP, e bcrypt com custo 12
+// quando esse algoritmo é escolhido
+func Default() *Hasher {
+	return &Hasher{
+		Algorithm:  AlgorithmArgon2id,
+		BcryptCost: 12,
+		Argon2: Argon2Params{
+			Memory:      19 * 1024,
+			Iterations:  2,
+			Parallelism: 1,
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+	}
+}
+
+// FromEnv monta a configuração a partir das variáveis de ambiente, partindo
+// da configuração padrão:
+//
+//	PASSWORD_HASH_ALGORITHM       algoritmo dos novos hashes (argon2id/bcrypt)
+//	PASSWORD_BCRYPT_COST          custo do bcrypt
+//	PASSWORD_ARGON2_MEMORY_KIB    memória do Argon2id, em KiB
+//	PASSWORD_ARGON2_ITERATIONS    iterações do Argon2id
+//	PASSWORD_ARGON2_PARALLELISM   paralelismo do Argon2id
+func FromEnv() (*Hasher, error) {
+	h := Default()
+
+	if algorithm := os.Getenv("PASSWORD_HASH_ALGORITHM"); algorithm != "" {
+		h.Algorithm = strings.ToLower(algorithm)
+	}
+
+	parallelism := uint32(h.Argon2.Parallelism)
+	for _, param := range []struct {
+		key   string
+		value *uint32
+	}{
+		{"PASSWORD_ARGON2_MEMORY_KIB", &h.Argon2.Memory},
+		{"PASSWORD_ARGON2_ITERATIONS", &h.Argon2.Iterations},
+		{"PASSWORD_ARGON2_PARALLELISM", &parallelism},
+	} {
+		if err := envUint32(param.key, param.value); err != nil {
+			return nil, err
+		}
+	}
+	if parallelism > 255 {
+		return nil, fmt.Errorf("valor inválido para PASSWORD_ARGON2_PARALLELISM: %d", parallelism)
+	}
+	h.Argon2.Parallelism = uint8(parallelism)
+
+	if raw := os.Getenv("PASSWORD_BCRYPT_COST"); raw != "" {
+		cost, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("valor inválido para PASSWORD_BCRYPT_COST: %q", raw)
+		}
+		h.BcryptCost = cost
+	}
+
+	if err := h.Validate(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Validate verifica se a configuração pode gerar hashes
+func (h *Hasher) Validate() error {
+	switch h.Algorithm {
+	case AlgorithmBcrypt, AlgorithmArgon2id:
+	default:
+		return fmt.Errorf("algoritmo de hash de senha não suportado: %q", h.Algorithm)
+	}
+	if h.BcryptCost < bcrypt.MinCost || h.BcryptCost > bcrypt.MaxCost {
+		return fmt.Errorf("custo do bcrypt deve estar entre %d e %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	if h.Argon2.Memory == 0 || h.Argon2.Iterations == 0 || h.Argon2.Parallelism == 0 ||
+		h.Argon2.SaltLength == 0 || h.Argon2.KeyLength == 0 {
+		return errors.New("parâmetros do Argon2id devem ser maiores que zero")
+	}
+	return nil
+}
+
+// Hash gera o hash da senha com o algoritmo configurado
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.Algorithm == AlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, h.Argon2.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Argon2.Iterations, h.Argon2.Memory, h.Argon2.Parallelism, h.Argon2.KeyLength)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id, argon2.Version,
+		h.Argon2.Memory, h.Argon2.Iterations, h.Argon2.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify confere a senha com o hash armazenado, qualquer que seja o algoritmo
+// usado para gerá-lo. Retorna ErrMismatchedPassword quando a senha não
+// corresponde.
+func (h *Hasher) Verify(password, encoded string) error {
+	if isBcrypt(encoded) {
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrMismatchedPassword
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnsupportedHash, err)
+		}
+		return nil
+	}
+
+	params, salt, key, err := decodeArgon2(encoded)
+	if err != nil {
+		return err
+	}
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(candidate, key) != 1 {
+		return ErrMismatchedPassword
+	}
+	return nil
+}
+
+// NeedsRehash indica se o hash armazenado está abaixo da configuração atual:
+// outro algoritmo ou parâmetros menores que os configurados. Deve ser
+// consultado após um login bem-sucedido, quando a senha em texto está
+// disponível para gerar o novo hash.
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	if isBcrypt(encoded) {
+		if h.Algorithm != AlgorithmBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost < h.BcryptCost
+	}
+
+	if h.Algorithm != AlgorithmArgon2id {
+		return true
+	}
+	params, _, _, err := decodeArgon2(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Memory < h.Argon2.Memory ||
+		params.Iterations < h.Argon2.Iterations ||
+		params.Parallelism < h.Argon2.Parallelism ||
+		params.KeyLength < h.Argon2.KeyLength
+}
+
+// isBcrypt identifica os prefixos do formato modular do bcrypt
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+// decodeArgon2 lê um hash Argon2id no formato PHC
+func decodeArgon2(encoded string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != AlgorithmArgon2id {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnsupportedHash
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
+
+// envUint32 lê uma variável de ambiente inteira positiva, mantendo o valor
+// atual quando ela não está definida
+func envUint32(key string, value *uint32) error {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || parsed == 0 {
+		return fmt.Errorf("valor inválido para %s: %q", key, raw)
+	}
+	*value = uint32(parsed)
+	return nil
+}
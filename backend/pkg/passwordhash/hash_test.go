@@ -0,0 +1,136 @@
+package passwordhash
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fastArgon2 reduz os parâmetros do Argon2id para os testes
+func fastArgon2() *Hasher {
+	h := Default()
+	h.Argon2.Memory = 1024
+	h.Argon2.Iterations = 1
+	return h
+}
+
+func TestHashAndVerifyArgon2id(t *testing.T) {
+	h := fastArgon2()
+
+	hash, err := h.Hash("Culto#Domingo9")
+	if err != nil {
+		t.Fatalf("erro ao gerar hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("hash fora do formato PHC: %s", hash)
+	}
+	if err := h.Verify("Culto#Domingo9", hash); err != nil {
+		t.Errorf("senha correta deveria ser aceita: %v", err)
+	}
+	if err := h.Verify("errada", hash); !errors.Is(err, ErrMismatchedPassword) {
+		t.Errorf("esperava ErrMismatchedPassword, obteve %v", err)
+	}
+
+	other, _ := h.Hash("Culto#Domingo9")
+	if other == hash {
+		t.Error("hashes da mesma senha deveriam usar salts diferentes")
+	}
+}
+
+func TestVerifyLegacyBcrypt(t *testing.T) {
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+
+	h := fastArgon2()
+	if err := h.Verify("senha123", string(legacy)); err != nil {
+		t.Errorf("hash $2a$ existente deveria continuar válido: %v", err)
+	}
+	if err := h.Verify("errada", string(legacy)); !errors.Is(err, ErrMismatchedPassword) {
+		t.Errorf("esperava ErrMismatchedPassword, obteve %v", err)
+	}
+}
+
+func TestVerifyUnsupportedHash(t *testing.T) {
+	h := fastArgon2()
+	for _, encoded := range []string{
+		"",
+		"texto-puro",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$aGFzaA",
+	} {
+		if err := h.Verify("senha", encoded); !errors.Is(err, ErrUnsupportedHash) {
+			t.Errorf("%q: esperava ErrUnsupportedHash, obteve %v", encoded, err)
+		}
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	h := fastArgon2()
+	current, _ := h.Hash("senha")
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("senha"), bcrypt.MinCost)
+
+	if h.NeedsRehash(current) {
+		t.Error("hash com a configuração atual não precisa ser refeito")
+	}
+	if !h.NeedsRehash(string(legacy)) {
+		t.Error("hash bcrypt deveria migrar para Argon2id")
+	}
+
+	stronger := fastArgon2()
+	stronger.Argon2.Iterations = 2
+	if !stronger.NeedsRehash(current) {
+		t.Error("hash com menos iterações deveria ser refeito")
+	}
+
+	weaker := fastArgon2()
+	weaker.Argon2.Memory = 512
+	if weaker.NeedsRehash(current) {
+		t.Error("hash acima da configuração não deveria ser rebaixado")
+	}
+
+	bcryptHasher := fastArgon2()
+	bcryptHasher.Algorithm = AlgorithmBcrypt
+	bcryptHasher.BcryptCost = bcrypt.MinCost
+	if bcryptHasher.NeedsRehash(string(legacy)) {
+		t.Error("hash bcrypt com o custo atual não precisa ser refeito")
+	}
+	bcryptHasher.BcryptCost = bcrypt.MinCost + 1
+	if !bcryptHasher.NeedsRehash(string(legacy)) {
+		t.Error("hash bcrypt com custo menor deveria ser refeito")
+	}
+	if !bcryptHasher.NeedsRehash(current) {
+		t.Error("hash Argon2id deveria migrar para o algoritmo configurado")
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "bcrypt")
+	t.Setenv("PASSWORD_BCRYPT_COST", "11")
+	t.Setenv("PASSWORD_ARGON2_MEMORY_KIB", "65536")
+	t.Setenv("PASSWORD_ARGON2_PARALLELISM", "4")
+
+	h, err := FromEnv()
+	if err != nil {
+		t.Fatalf("erro ao carregar configuração: %v", err)
+	}
+	if h.Algorithm != AlgorithmBcrypt || h.BcryptCost != 11 || h.Argon2.Memory != 65536 || h.Argon2.Parallelism != 4 {
+		t.Errorf("configuração inesperada: %+v", h)
+	}
+
+	for key, value := range map[string]string{
+		"PASSWORD_HASH_ALGORITHM":     "md5",
+		"PASSWORD_BCRYPT_COST":        "2",
+		"PASSWORD_ARGON2_ITERATIONS":  "0",
+		"PASSWORD_ARGON2_PARALLELISM": "300",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := FromEnv(); err == nil {
+				t.Errorf("%s=%s deveria ser recusado", key, value)
+			}
+		})
+	}
+}
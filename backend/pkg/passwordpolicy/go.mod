@@ -0,0 +1,3 @@
+module github.com/insidechurch/passwordpolicy
+
+go 1.21
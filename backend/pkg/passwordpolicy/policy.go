@@ -0,0 +1,243 @@
+// Package passwordpolicy implementa a política de senhas compartilhada pela
+// API principal e pelo auth-service: tamanho mínimo, classes de caracteres,
+// validade máxima e a recusa de senhas vazadas ou comuns.
+package passwordpolicy
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Regras verificadas por Validate, usadas pelo frontend para indicar cada
+// requisito não atendido
+const (
+	RuleMinLength = "min_length"
+	RuleUpper     = "uppercase"
+	RuleLower     = "lowercase"
+	RuleDigit     = "digit"
+	RuleSpecial   = "special"
+	RuleBreached  = "breached"
+)
+
+// commonPasswords é a lista embutida de senhas comuns, sempre recusadas
+//
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// Violation descreve uma regra não atendida pela senha
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Policy define os requisitos das senhas. As classes de caracteres seguem as
+// categorias Unicode, de modo que letras acentuadas contam como maiúsculas
+// ou minúsculas.
+type Policy struct {
+	MinLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSpecial bool
+	// MaxAge é a validade da senha; zero indica que a senha não expira
+	MaxAge time.Duration
+
+	breached map[string]struct{}
+}
+
+// Default retorna a política padrão: 8 caracteres com maiúscula, minúscula,
+// número e caractere especial, sem expiração
+func Default() *Policy {
+	p := &Policy{
+		MinLength:      8,
+		RequireUpper:   true,
+		RequireLower:   true,
+		RequireDigit:   true,
+		RequireSpecial: true,
+		breached:       make(map[string]struct{}),
+	}
+	p.LoadList(strings.NewReader(commonPasswords))
+	return p
+}
+
+// FromEnv monta a política a partir das variáveis de ambiente, partindo da
+// política padrão:
+//
+//	PASSWORD_MIN_LENGTH          tamanho mínimo, em caracteres
+//	PASSWORD_REQUIRE_UPPER       exige letra maiúscula (true/false)
+//	PASSWORD_REQUIRE_LOWER       exige letra minúscula (true/false)
+//	PASSWORD_REQUIRE_DIGIT       exige número (true/false)
+//	PASSWORD_REQUIRE_SPECIAL     exige caractere especial (true/false)
+//	PASSWORD_MAX_AGE_DAYS        validade da senha em dias; 0 desativa
+//	PASSWORD_BREACHED_LIST_FILE  arquivo local com senhas vazadas, uma por linha
+func FromEnv() (*Policy, error) {
+	p := Default()
+
+	if err := envInt("PASSWORD_MIN_LENGTH", &p.MinLength); err != nil {
+		return nil, err
+	}
+	for _, flag := range []struct {
+		key   string
+		value *bool
+	}{
+		{"PASSWORD_REQUIRE_UPPER", &p.RequireUpper},
+		{"PASSWORD_REQUIRE_LOWER", &p.RequireLower},
+		{"PASSWORD_REQUIRE_DIGIT", &p.RequireDigit},
+		{"PASSWORD_REQUIRE_SPECIAL", &p.RequireSpecial},
+	} {
+		if err := envBool(flag.key, flag.value); err != nil {
+			return nil, err
+		}
+	}
+
+	var maxAgeDays int
+	if err := envInt("PASSWORD_MAX_AGE_DAYS", &maxAgeDays); err != nil {
+		return nil, err
+	}
+	p.MaxAge = time.Duration(maxAgeDays) * 24 * time.Hour
+
+	if path := os.Getenv("PASSWORD_BREACHED_LIST_FILE"); path != "" {
+		if err := p.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// LoadFile acrescenta à lista de senhas recusadas as senhas do arquivo
+func (p *Policy) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir lista de senhas vazadas: %w", err)
+	}
+	defer file.Close()
+
+	if err := p.LoadList(file); err != nil {
+		return fmt.Errorf("erro ao ler lista de senhas vazadas %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadList acrescenta à lista de senhas recusadas as senhas lidas, uma por
+// linha. Linhas vazias e iniciadas por "#" são ignoradas e a comparação não
+// diferencia maiúsculas de minúsculas.
+func (p *Policy) LoadList(r io.Reader) error {
+	if p.breached == nil {
+		p.breached = make(map[string]struct{})
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.breached[strings.ToLower(line)] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+// Validate retorna as regras não atendidas pela senha; a lista vazia indica
+// uma senha válida
+func (p *Policy) Validate(password string) []Violation {
+	var violations []Violation
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("a senha deve ter pelo menos %d caracteres", p.MinLength),
+		})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char) || unicode.IsSpace(char):
+			hasSpecial = true
+		}
+	}
+
+	for _, class := range []struct {
+		required bool
+		present  bool
+		rule     string
+		message  string
+	}{
+		{p.RequireUpper, hasUpper, RuleUpper, "a senha deve conter uma letra maiúscula"},
+		{p.RequireLower, hasLower, RuleLower, "a senha deve conter uma letra minúscula"},
+		{p.RequireDigit, hasDigit, RuleDigit, "a senha deve conter um número"},
+		{p.RequireSpecial, hasSpecial, RuleSpecial, "a senha deve conter um caractere especial"},
+	} {
+		if class.required && !class.present {
+			violations = append(violations, Violation{Rule: class.rule, Message: class.message})
+		}
+	}
+
+	if _, found := p.breached[strings.ToLower(password)]; found {
+		violations = append(violations, Violation{
+			Rule:    RuleBreached,
+			Message: "a senha é muito comum ou apareceu em vazamentos de dados",
+		})
+	}
+
+	return violations
+}
+
+// Expired indica se a senha definida em changedAt já passou da validade
+func (p *Policy) Expired(changedAt, now time.Time) bool {
+	return p.MaxAge > 0 && !changedAt.IsZero() && now.Sub(changedAt) > p.MaxAge
+}
+
+// Summary junta as mensagens das violações em uma única frase, para clientes
+// que exibem apenas uma mensagem de erro
+func Summary(violations []Violation) string {
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// envInt lê uma variável de ambiente inteira, mantendo o valor atual quando
+// ela não está definida
+func envInt(key string, value *int) error {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil || parsed < 0 {
+		return fmt.Errorf("valor inválido para %s: %q", key, raw)
+	}
+	*value = parsed
+	return nil
+}
+
+// envBool lê uma variável de ambiente booleana, mantendo o valor atual quando
+// ela não está definida
+func envBool(key string, value *bool) error {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseBool(raw)
+	if err != nil {
+		return fmt.Errorf("valor inválido para %s: %q", key, raw)
+	}
+	*value = parsed
+	return nil
+}
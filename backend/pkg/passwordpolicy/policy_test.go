@@ -0,0 +1,103 @@
+package passwordpolicy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rules extrai as regras das violações
+func rules(violations []Violation) []string {
+	names := make([]string, len(violations))
+	for i, violation := range violations {
+		names[i] = violation.Rule
+	}
+	return names
+}
+
+func TestValidate(t *testing.T) {
+	p := Default()
+
+	cases := map[string][]string{
+		"Forte@2023x":  nil,
+		"fraca":        {RuleMinLength, RuleUpper, RuleDigit, RuleSpecial},
+		"Coração#2023": nil,
+		"ÁGUAVIVA@12":  {RuleLower},
+		"Senha@123":    {RuleBreached},
+		"Jesus@2024":   {RuleBreached},
+		"jesus@2024":   {RuleUpper, RuleBreached},
+	}
+	for password, want := range cases {
+		got := rules(p.Validate(password))
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%q: esperava %v, obteve %v", password, want, got)
+		}
+	}
+}
+
+func TestValidateCountsCharacters(t *testing.T) {
+	p := &Policy{MinLength: 8}
+
+	// 8 caracteres, mas mais de 8 bytes
+	if got := p.Validate("ççççãããa"); len(got) != 0 {
+		t.Errorf("Senha com 8 caracteres deveria ser aceita, obteve %v", got)
+	}
+	if got := rules(p.Validate("ççççããã")); len(got) != 1 || got[0] != RuleMinLength {
+		t.Errorf("Esperava apenas %s, obteve %v", RuleMinLength, got)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "vazadas.txt")
+	if err := os.WriteFile(list, []byte("# vazamento de teste\nCulto@Domingo1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PASSWORD_MIN_LENGTH", "12")
+	t.Setenv("PASSWORD_REQUIRE_SPECIAL", "false")
+	t.Setenv("PASSWORD_MAX_AGE_DAYS", "90")
+	t.Setenv("PASSWORD_BREACHED_LIST_FILE", list)
+
+	p, err := FromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.MinLength != 12 || p.RequireSpecial || !p.RequireUpper || p.MaxAge != 90*24*time.Hour {
+		t.Fatalf("Política inesperada: %+v", p)
+	}
+	if got := rules(p.Validate("Louvor2023")); len(got) != 1 || got[0] != RuleMinLength {
+		t.Errorf("Esperava apenas %s, obteve %v", RuleMinLength, got)
+	}
+	if got := rules(p.Validate("culto@domingo1")); got[len(got)-1] != RuleBreached {
+		t.Errorf("Senha da lista deveria ser recusada, obteve %v", got)
+	}
+	if got := rules(p.Validate("123456")); got[len(got)-1] != RuleBreached {
+		t.Errorf("A lista embutida deveria continuar valendo, obteve %v", got)
+	}
+
+	t.Setenv("PASSWORD_MIN_LENGTH", "doze")
+	if _, err := FromEnv(); err == nil {
+		t.Error("Valor inválido deveria falhar")
+	}
+	t.Setenv("PASSWORD_MIN_LENGTH", "")
+	t.Setenv("PASSWORD_BREACHED_LIST_FILE", filepath.Join(t.TempDir(), "inexistente.txt"))
+	if _, err := FromEnv(); err == nil {
+		t.Error("Arquivo inexistente deveria falhar")
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+	p := &Policy{MaxAge: 90 * 24 * time.Hour}
+
+	if p.Expired(now.Add(-30*24*time.Hour), now) {
+		t.Error("Senha recente não deveria expirar")
+	}
+	if !p.Expired(now.Add(-91*24*time.Hour), now) {
+		t.Error("Senha antiga deveria expirar")
+	}
+	if (&Policy{}).Expired(now.Add(-365*24*time.Hour), now) {
+		t.Error("Sem validade máxima a senha não expira")
+	}
+}
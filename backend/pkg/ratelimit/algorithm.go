@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Os cálculos abaixo são comuns ao MemoryStore e aos scripts do RedisStore:
+// os scripts apenas atualizam o estado de forma atômica e devolvem as
+// contagens, das quais o Result é derivado aqui.
+
+// tokenInterval é o tempo para repor uma ficha do token bucket
+func tokenInterval(limit Limit) time.Duration {
+	return limit.Window / time.Duration(limit.Requests)
+}
+
+// refill retorna as fichas disponíveis após elapsed, limitadas à capacidade
+func refill(limit Limit, tokens float64, elapsed time.Duration) float64 {
+	tokens += float64(elapsed) / float64(tokenInterval(limit))
+	return math.Min(tokens, float64(limit.capacity()))
+}
+
+// bucketResult descreve o token bucket com tokens fichas restantes, depois de
+// consumida a ficha da requisição, se aceita
+func bucketResult(limit Limit, tokens float64, allowed bool) Result {
+	capacity := float64(limit.capacity())
+	interval := float64(tokenInterval(limit))
+
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit.capacity(),
+		Window:    time.Duration(capacity * interval),
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((capacity - tokens) * interval),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration(math.Ceil((1 - tokens) * interval))
+	}
+	return res
+}
+
+// windowAllows informa se a janela deslizante aceita mais uma requisição,
+// com prev requisições na janela anterior, cur na atual e elapsed decorrido
+// da janela atual
+func windowAllows(limit Limit, prev, cur int64, elapsed time.Duration) bool {
+	return windowCount(limit, prev, cur, elapsed)+1 <= float64(limit.Requests)
+}
+
+// windowCount estima as requisições no último intervalo Window, ponderando a
+// janela anterior pela fração que ainda se sobrepõe a ele
+func windowCount(limit Limit, prev, cur int64, elapsed time.Duration) float64 {
+	weight := float64(limit.Window-elapsed) / float64(limit.Window)
+	return float64(prev)*weight + float64(cur)
+}
+
+// windowResult descreve a janela deslizante depois de contada a requisição,
+// se aceita
+func windowResult(limit Limit, prev, cur int64, elapsed time.Duration, allowed bool) Result {
+	window := float64(limit.Window)
+	requests := float64(limit.Requests)
+
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Window:    limit.Window,
+		Remaining: int(math.Max(0, math.Floor(requests-windowCount(limit, prev, cur, elapsed)))),
+		Reset:     limit.Window - elapsed,
+	}
+	if allowed {
+		return res
+	}
+
+	// Enquanto a janela atual não fecha, a contagem só diminui com o peso da
+	// anterior; se a atual já esgotou a cota, é preciso aguardar a próxima
+	// janela, em que a atual passa a ser a anterior
+	free := requests - float64(cur) - 1
+	if free >= 0 && prev > 0 {
+		res.RetryAfter = time.Duration(math.Ceil(window*(1-free/float64(prev)))) - elapsed
+	} else {
+		res.RetryAfter = limit.Window - elapsed
+		if cur > 0 && requests-1 < float64(cur) {
+			res.RetryAfter += time.Duration(math.Ceil(window * (1 - (requests-1)/float64(cur))))
+		}
+	}
+	if res.RetryAfter < 0 {
+		res.RetryAfter = 0
+	}
+	return res
+}
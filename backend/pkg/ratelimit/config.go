@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FromEnv lê as políticas de RATE_LIMIT_POLICIES ou, se a variável não
+// estiver definida, de defaults. Ver ParsePolicies para o formato.
+func FromEnv(defaults string) ([]Policy, error) {
+	spec := defaults
+	if value, exists := os.LookupEnv("RATE_LIMIT_POLICIES"); exists {
+		spec = value
+	}
+
+	policies, err := ParsePolicies(spec)
+	if err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_POLICIES inválido: %w", err)
+	}
+	return policies, nil
+}
+
+// ParsePolicies lê políticas separadas por vírgula, no formato
+//
+//	rotas=escopo:requisições/janela[:algoritmo[:burst]]
+//
+// em que rotas são separadas por "|", o escopo é ip ou user, a janela é uma
+// duração como 10s ou 1m e o algoritmo é token_bucket (padrão) ou
+// sliding_window. Por exemplo, "*=ip:100/1m,/auth/login=ip:5/1m:sliding_window"
+// limita cada IP a 100 requisições por minuto em todas as rotas e a 5 no login.
+// Uma especificação vazia não define políticas.
+func ParsePolicies(spec string) ([]Policy, error) {
+	var policies []Policy
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		policy, err := parsePolicy(entry)
+		if err != nil {
+			return nil, fmt.Errorf("política %q: %w", entry, err)
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// parsePolicy lê uma política no formato descrito em ParsePolicies
+func parsePolicy(entry string) (Policy, error) {
+	routes, rule, ok := strings.Cut(entry, "=")
+	if !ok || strings.TrimSpace(routes) == "" {
+		return Policy{}, fmt.Errorf("formato esperado rotas=escopo:requisições/janela")
+	}
+
+	policy := Policy{Name: strings.TrimSpace(routes)}
+	for _, route := range strings.Split(routes, "|") {
+		if route = strings.TrimSpace(route); route != "" {
+			policy.Routes = append(policy.Routes, route)
+		}
+	}
+
+	parts := strings.Split(strings.TrimSpace(rule), ":")
+	if len(parts) < 2 || len(parts) > 4 {
+		return Policy{}, fmt.Errorf("formato esperado escopo:requisições/janela[:algoritmo[:burst]]")
+	}
+
+	switch scope := Scope(parts[0]); scope {
+	case ScopeIP, ScopeUser:
+		policy.Scope = scope
+	default:
+		return Policy{}, fmt.Errorf("escopo desconhecido %q", parts[0])
+	}
+
+	requests, window, ok := strings.Cut(parts[1], "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("formato esperado requisições/janela, obtido %q", parts[1])
+	}
+	var err error
+	if policy.Limit.Requests, err = strconv.Atoi(requests); err != nil {
+		return Policy{}, fmt.Errorf("quantidade de requisições inválida %q", requests)
+	}
+	if policy.Limit.Window, err = time.ParseDuration(window); err != nil {
+		return Policy{}, fmt.Errorf("janela inválida %q", window)
+	}
+
+	policy.Limit.Algorithm = TokenBucket
+	if len(parts) > 2 {
+		policy.Limit.Algorithm = parts[2]
+	}
+	if len(parts) > 3 {
+		if policy.Limit.Burst, err = strconv.Atoi(parts[3]); err != nil {
+			return Policy{}, fmt.Errorf("burst inválido %q", parts[3])
+		}
+	}
+
+	if err := policy.Limit.Validate(); err != nil {
+		return Policy{}, err
+	}
+	return policy, nil
+}
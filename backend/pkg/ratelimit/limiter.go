@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+)
+
+// Scope define a quem o limite de uma política se aplica
+type Scope string
+
+const (
+	// ScopeIP limita cada IP de origem
+	ScopeIP Scope = "ip"
+
+	// ScopeUser limita cada usuário autenticado; requisições anônimas não
+	// são limitadas pela política
+	ScopeUser Scope = "user"
+)
+
+// Policy aplica um limite às rotas informadas. Uma rota "*" inclui todas as
+// rotas e uma rota terminada em "*", as que começam pelo prefixo.
+type Policy struct {
+	// Name identifica a política nas chaves do Store; políticas com o mesmo
+	// nome e escopo compartilham o limite
+	Name   string
+	Routes []string
+	Scope  Scope
+	Limit  Limit
+}
+
+// Matches informa se a política se aplica à rota
+func (p Policy) Matches(route string) bool {
+	for _, pattern := range p.Routes {
+		if pattern == "*" || pattern == route {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(route, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Request identifica a requisição a ser limitada. Route é o template da
+// rota, como /users/:id, e não o caminho da requisição, para que as
+// políticas se apliquem a todos os caminhos da rota.
+type Request struct {
+	Route  string
+	IP     string
+	UserID string
+}
+
+// Limiter aplica as políticas às requisições
+type Limiter struct {
+	store    Store
+	policies []Policy
+}
+
+// keyPrefix separa as chaves dos limites das demais chaves do Redis
+const keyPrefix = "ratelimit:"
+
+// New cria uma nova instância do Limiter
+func New(store Store, policies ...Policy) *Limiter {
+	return &Limiter{
+		store:    store,
+		policies: policies,
+	}
+}
+
+// Allow consome a requisição em cada política do escopo que se aplica à
+// rota, na ordem configurada, e para na primeira que a recusar. O resultado
+// é o da política recusada ou, se todas aceitarem, o da que tiver menos
+// requisições restantes; sem políticas aplicáveis, Limit é zero. Em caso de
+// erro do Store, a requisição é aceita e o erro, retornado.
+func (l *Limiter) Allow(ctx context.Context, req Request, scope Scope) (Result, error) {
+	res := Result{Allowed: true}
+
+	var id string
+	switch scope {
+	case ScopeIP:
+		id = req.IP
+	case ScopeUser:
+		id = req.UserID
+	}
+	if id == "" {
+		return res, nil
+	}
+
+	for _, policy := range l.policies {
+		if policy.Scope != scope || !policy.Matches(req.Route) {
+			continue
+		}
+
+		key := keyPrefix + policy.Name + ":" + string(scope) + ":" + id
+		current, err := l.store.Allow(ctx, key, policy.Limit)
+		if err != nil {
+			return Result{Allowed: true}, err
+		}
+		if !current.Allowed {
+			return current, nil
+		}
+		if res.Limit == 0 || current.Remaining < res.Remaining {
+			res = current
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryEntry é o estado de uma chave: as fichas do token bucket ou as
+// contagens da janela deslizante
+type memoryEntry struct {
+	tokens float64
+	last   time.Time
+
+	window    int64
+	prev, cur int64
+
+	expiresAt time.Time
+}
+
+// MemoryStore implementa Store em memória. O limite vale apenas para o
+// processo; com várias réplicas, use o RedisStore.
+type MemoryStore struct {
+	mu          sync.Mutex
+	entries     map[string]*memoryEntry
+	lastEvicted time.Time
+
+	// now permite controlar o relógio nos testes
+	now func() time.Time
+}
+
+// Intervalo mínimo entre varreduras de chaves expiradas
+const evictionInterval = time.Minute
+
+// NewMemoryStore cria uma nova instância do MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Allow consome uma requisição da chave, se o limite permitir
+func (s *MemoryStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.evictExpired(now)
+
+	entry, exists := s.entries[key]
+	if !exists || !now.Before(entry.expiresAt) {
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+
+	if limit.Algorithm == SlidingWindow {
+		return s.slidingWindow(entry, limit, now), nil
+	}
+	return s.tokenBucket(entry, limit, now), nil
+}
+
+// tokenBucket aplica o token bucket; o balde de uma chave nova começa cheio
+func (s *MemoryStore) tokenBucket(entry *memoryEntry, limit Limit, now time.Time) Result {
+	if entry.last.IsZero() {
+		entry.tokens = float64(limit.capacity())
+	} else {
+		entry.tokens = refill(limit, entry.tokens, now.Sub(entry.last))
+	}
+	entry.last = now
+
+	allowed := entry.tokens >= 1
+	if allowed {
+		entry.tokens--
+	}
+
+	res := bucketResult(limit, entry.tokens, allowed)
+	entry.expiresAt = now.Add(res.Reset)
+	return res
+}
+
+// slidingWindow aplica a janela deslizante; as janelas são alinhadas ao
+// relógio, como no RedisStore
+func (s *MemoryStore) slidingWindow(entry *memoryEntry, limit Limit, now time.Time) Result {
+	nanos := now.UnixNano()
+	window := nanos / int64(limit.Window)
+	elapsed := time.Duration(nanos - window*int64(limit.Window))
+
+	switch entry.window {
+	case window:
+	case window - 1:
+		entry.prev, entry.cur = entry.cur, 0
+	default:
+		entry.prev, entry.cur = 0, 0
+	}
+	entry.window = window
+
+	allowed := windowAllows(limit, entry.prev, entry.cur, elapsed)
+	if allowed {
+		entry.cur++
+	}
+
+	// A janela atual deixa de pesar quando a seguinte termina
+	entry.expiresAt = now.Add(2*limit.Window - elapsed)
+	return windowResult(limit, entry.prev, entry.cur, elapsed, allowed)
+}
+
+// evictExpired remove as chaves expiradas, no máximo uma vez por
+// evictionInterval; deve ser chamado com o lock adquirido
+func (s *MemoryStore) evictExpired(now time.Time) {
+	if now.Sub(s.lastEvicted) < evictionInterval {
+		return
+	}
+	s.lastEvicted = now
+
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Package ratelimit implementa a limitação de requisições compartilhada pela
+// API principal e pelo auth-service: algoritmos de token bucket e de janela
+// deslizante, armazenados em memória ou no Redis, para que o limite valha
+// para todas as réplicas, e políticas por rota, usuário e IP.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Algoritmos aceitos em Limit.Algorithm
+const (
+	// TokenBucket repõe Requests fichas a cada Window, acumulando até Burst;
+	// permite rajadas curtas mantendo a taxa média
+	TokenBucket = "token_bucket"
+
+	// SlidingWindow aceita até Requests requisições em qualquer intervalo de
+	// duração Window, estimado pela média ponderada da janela anterior e da
+	// atual
+	SlidingWindow = "sliding_window"
+)
+
+// ErrInvalidLimit indica um limite com algoritmo, quantidade ou janela inválidos
+var ErrInvalidLimit = errors.New("limite de requisições inválido")
+
+// Limit define quantas requisições uma chave pode fazer
+type Limit struct {
+	Algorithm string
+	Requests  int
+	Window    time.Duration
+
+	// Burst é a capacidade do token bucket; zero usa Requests. Ignorado na
+	// janela deslizante.
+	Burst int
+}
+
+// Validate verifica se o limite pode ser aplicado
+func (l Limit) Validate() error {
+	switch l.Algorithm {
+	case TokenBucket, SlidingWindow:
+	default:
+		return fmt.Errorf("%w: algoritmo desconhecido %q", ErrInvalidLimit, l.Algorithm)
+	}
+	if l.Requests <= 0 {
+		return fmt.Errorf("%w: a quantidade de requisições deve ser positiva", ErrInvalidLimit)
+	}
+	if l.Window < time.Millisecond {
+		return fmt.Errorf("%w: a janela deve ser de pelo menos 1ms", ErrInvalidLimit)
+	}
+	if l.Burst < 0 {
+		return fmt.Errorf("%w: o burst não pode ser negativo", ErrInvalidLimit)
+	}
+	return nil
+}
+
+// capacity retorna a capacidade do token bucket
+func (l Limit) capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// Result é a decisão sobre uma requisição. Limit e Window descrevem a cota
+// anunciada nos headers: para o token bucket, a capacidade e o tempo para
+// enchê-la; para a janela deslizante, Requests e Window.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Window    time.Duration
+	Remaining int
+
+	// Reset é o tempo até a cota ser restabelecida por completo
+	Reset time.Duration
+
+	// RetryAfter é o tempo até a próxima requisição ser aceita, quando
+	// Allowed é falso
+	RetryAfter time.Duration
+}
+
+// Store guarda o estado dos limites. Allow consome uma requisição da chave,
+// se o limite permitir, de forma atômica entre as réplicas que compartilham o
+// Store.
+type Store interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// WriteHeaders escreve os headers RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset e RateLimit-Policy (draft-ietf-httpapi-ratelimit-headers)
+// e, se a requisição foi recusada, Retry-After. Um resultado sem limite não
+// escreve nada.
+func WriteHeaders(h http.Header, res Result) {
+	if res.Limit == 0 {
+		return
+	}
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", res.Limit, seconds(res.Window)))
+	if !res.Allowed {
+		retryAfter := seconds(res.RetryAfter)
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		h.Set("Retry-After", strconv.Itoa(retryAfter))
+	}
+}
+
+// seconds arredonda a duração para cima, em segundos
+func seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,239 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTestStore cria um MemoryStore com o relógio controlado pelo teste
+func newTestStore(now *time.Time) *MemoryStore {
+	s := NewMemoryStore()
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+// allowN consome n requisições e retorna quantas foram aceitas
+func allowN(t *testing.T, s Store, key string, limit Limit, n int) int {
+	t.Helper()
+	allowed := 0
+	for i := 0; i < n; i++ {
+		res, err := s.Allow(context.Background(), key, limit)
+		if err != nil {
+			t.Fatalf("Allow falhou: %v", err)
+		}
+		if res.Allowed {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := newTestStore(&now)
+	limit := Limit{Algorithm: TokenBucket, Requests: 60, Window: time.Minute, Burst: 10}
+
+	if got := allowN(t, s, "k", limit, 15); got != 10 {
+		t.Errorf("Esperava 10 requisições aceitas no burst, obteve %d", got)
+	}
+
+	res, _ := s.Allow(context.Background(), "k", limit)
+	if res.Allowed || res.RetryAfter != time.Second {
+		t.Errorf("Esperava recusa com RetryAfter de 1s, obteve %+v", res)
+	}
+	if res.Limit != 10 || res.Window != 10*time.Second || res.Reset != 10*time.Second {
+		t.Errorf("Cota anunciada inesperada: %+v", res)
+	}
+
+	now = now.Add(3 * time.Second)
+	if got := allowN(t, s, "k", limit, 5); got != 3 {
+		t.Errorf("Esperava 3 fichas repostas em 3s, obteve %d", got)
+	}
+	if got := allowN(t, s, "outra", limit, 1); got != 1 {
+		t.Error("Outra chave não deveria compartilhar o limite")
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	now := time.Unix(1700000000, 0).Truncate(time.Minute)
+	s := newTestStore(&now)
+	limit := Limit{Algorithm: SlidingWindow, Requests: 10, Window: time.Minute}
+
+	if got := allowN(t, s, "k", limit, 12); got != 10 {
+		t.Errorf("Esperava 10 requisições aceitas na janela, obteve %d", got)
+	}
+	res, _ := s.Allow(context.Background(), "k", limit)
+	// Na janela seguinte, a atual ainda pesa 10 requisições no início
+	if res.Allowed || res.Remaining != 0 || res.RetryAfter != 66*time.Second {
+		t.Errorf("Esperava RetryAfter de 66s, obteve %+v", res)
+	}
+
+	// Na metade da janela seguinte, a anterior pesa 5 requisições
+	now = now.Add(90 * time.Second)
+	if got := allowN(t, s, "k", limit, 10); got != 5 {
+		t.Errorf("Esperava 5 requisições aceitas com a janela anterior ponderada, obteve %d", got)
+	}
+	res, _ = s.Allow(context.Background(), "k", limit)
+	if res.Allowed || res.RetryAfter != 6*time.Second {
+		t.Errorf("Esperava RetryAfter de 6s, obteve %+v", res)
+	}
+
+	// Duas janelas depois, a contagem recomeça
+	now = now.Add(2 * time.Minute)
+	if got := allowN(t, s, "k", limit, 10); got != 10 {
+		t.Errorf("Esperava a contagem zerada, obteve %d aceitas", got)
+	}
+}
+
+func TestMemoryStoreEvictsExpiredKeys(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := newTestStore(&now)
+	limit := Limit{Algorithm: TokenBucket, Requests: 10, Window: time.Second}
+
+	for _, key := range []string{"a", "b", "c"} {
+		allowN(t, s, key, limit, 1)
+	}
+	now = now.Add(evictionInterval)
+	allowN(t, s, "d", limit, 1)
+
+	if len(s.entries) != 1 {
+		t.Errorf("Chaves expiradas deveriam ser removidas, restam %d", len(s.entries))
+	}
+}
+
+func TestInvalidLimit(t *testing.T) {
+	for _, limit := range []Limit{
+		{Algorithm: "fixed", Requests: 1, Window: time.Second},
+		{Algorithm: TokenBucket, Requests: 0, Window: time.Second},
+		{Algorithm: SlidingWindow, Requests: 1, Window: time.Microsecond},
+		{Algorithm: TokenBucket, Requests: 1, Window: time.Second, Burst: -1},
+	} {
+		if _, err := NewMemoryStore().Allow(context.Background(), "k", limit); !errors.Is(err, ErrInvalidLimit) {
+			t.Errorf("%+v: esperava ErrInvalidLimit, obteve %v", limit, err)
+		}
+	}
+}
+
+func TestWriteHeaders(t *testing.T) {
+	h := http.Header{}
+	WriteHeaders(h, Result{Allowed: false, Limit: 10, Window: time.Minute, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: 200 * time.Millisecond})
+
+	want := map[string]string{
+		"RateLimit-Limit":     "10",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "2",
+		"RateLimit-Policy":    "10;w=60",
+		"Retry-After":         "1",
+	}
+	for name, value := range want {
+		if got := h.Get(name); got != value {
+			t.Errorf("%s = %q, esperado %q", name, got, value)
+		}
+	}
+
+	h = http.Header{}
+	WriteHeaders(h, Result{Allowed: true})
+	if len(h) != 0 {
+		t.Errorf("Resultado sem limite não deveria escrever headers: %v", h)
+	}
+}
+
+func TestLimiterAppliesMatchingPolicies(t *testing.T) {
+	policies, err := ParsePolicies("*=ip:100/1m:token_bucket:100, /auth/login=ip:2/1m, /api/*=user:1/1m")
+	if err != nil {
+		t.Fatalf("ParsePolicies falhou: %v", err)
+	}
+	l := New(NewMemoryStore(), policies...)
+	ctx := context.Background()
+
+	login := Request{Route: "/auth/login", IP: "198.51.100.7"}
+	for i := 0; i < 2; i++ {
+		res, _ := l.Allow(ctx, login, ScopeIP)
+		if !res.Allowed || res.Limit != 2 {
+			t.Errorf("Esperava a política mais restritiva do login, obteve %+v", res)
+		}
+	}
+	if res, _ := l.Allow(ctx, login, ScopeIP); res.Allowed {
+		t.Error("Terceiro login deveria ser recusado")
+	}
+	if res, _ := l.Allow(ctx, Request{Route: "/auth/refresh", IP: "198.51.100.7"}, ScopeIP); !res.Allowed || res.Limit != 100 {
+		t.Errorf("Outras rotas deveriam seguir apenas a política global, obteve %+v", res)
+	}
+
+	anonymous := Request{Route: "/api/users/me", IP: "198.51.100.7"}
+	if res, _ := l.Allow(ctx, anonymous, ScopeUser); !res.Allowed || res.Limit != 0 {
+		t.Errorf("Requisição anônima não deveria ser limitada por usuário, obteve %+v", res)
+	}
+	user := Request{Route: "/api/users/me", IP: "198.51.100.7", UserID: "42"}
+	l.Allow(ctx, user, ScopeUser)
+	if res, _ := l.Allow(ctx, user, ScopeUser); res.Allowed {
+		t.Error("Segunda requisição do usuário deveria ser recusada")
+	}
+}
+
+// failingStore simula um Store indisponível
+type failingStore struct{}
+
+func (failingStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	return Result{}, errors.New("conexão recusada")
+}
+
+func TestLimiterAllowsOnStoreError(t *testing.T) {
+	policies, _ := ParsePolicies("*=ip:1/1m")
+	res, err := New(failingStore{}, policies...).Allow(context.Background(), Request{Route: "/", IP: "198.51.100.7"}, ScopeIP)
+	if err == nil || !res.Allowed {
+		t.Errorf("Falha do Store deveria aceitar a requisição e retornar o erro, obteve %+v, %v", res, err)
+	}
+}
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies("/a|/b/*=user:5/10s:sliding_window, *=ip:1/1m:token_bucket:100")
+	if err != nil {
+		t.Fatalf("ParsePolicies falhou: %v", err)
+	}
+	if len(policies) != 2 {
+		t.Fatalf("Esperava 2 políticas, obteve %d", len(policies))
+	}
+
+	p := policies[0]
+	if p.Name != "/a|/b/*" || p.Scope != ScopeUser || p.Limit != (Limit{Algorithm: SlidingWindow, Requests: 5, Window: 10 * time.Second}) {
+		t.Errorf("Política inesperada: %+v", p)
+	}
+	if !p.Matches("/a") || !p.Matches("/b/1") || p.Matches("/ab") {
+		t.Error("Rotas da política não correspondem ao esperado")
+	}
+	if policies[1].Limit.Burst != 100 || !policies[1].Matches("/qualquer") {
+		t.Errorf("Política global inesperada: %+v", policies[1])
+	}
+
+	for _, spec := range []string{
+		"/a",
+		"/a=host:1/1m",
+		"/a=ip:1",
+		"/a=ip:x/1m",
+		"/a=ip:1/minuto",
+		"/a=ip:1/1m:fixed",
+		"/a=ip:1/1m:token_bucket:x",
+		"=ip:1/1m",
+	} {
+		if _, err := ParsePolicies(spec); err == nil {
+			t.Errorf("%q deveria ser recusada", spec)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("RATE_LIMIT_POLICIES", "")
+	policies, err := FromEnv("*=ip:1/1m")
+	if err != nil || len(policies) != 0 {
+		t.Errorf("RATE_LIMIT_POLICIES vazio deveria desativar os limites, obteve %v, %v", policies, err)
+	}
+
+	t.Setenv("RATE_LIMIT_POLICIES", "*=ip:0/1m")
+	if _, err := FromEnv(""); err == nil {
+		t.Error("Política inválida deveria ser recusada")
+	}
+}
@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript consome uma ficha do balde em KEYS[1], um hash com as
+// fichas e o instante da última atualização. ARGV[1] é a capacidade e ARGV[2]
+// o tempo, em milissegundos, para repor uma ficha. Usa o relógio do Redis,
+// para que as réplicas concordem sobre o tempo decorrido.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+else
+	tokens = math.min(capacity, tokens + math.max(0, now - ts) / interval)
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', string.format('%.6f', tokens), 'ts', string.format('%.0f', now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) * interval) + 1)
+return {allowed, string.format('%.6f', tokens)}
+`)
+
+// slidingWindowScript conta a requisição na janela em KEYS[1], um hash com o
+// índice da janela atual e as contagens da atual e da anterior. ARGV[1] é o
+// limite e ARGV[2] a janela, em milissegundos.
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local index = math.floor(now / window)
+local elapsed = now - index * window
+
+local state = redis.call('HMGET', KEYS[1], 'window', 'prev', 'cur')
+local stored = tonumber(state[1])
+local prev, cur = 0, 0
+if stored == index then
+	prev = tonumber(state[2]) or 0
+	cur = tonumber(state[3]) or 0
+elseif stored == index - 1 then
+	prev = tonumber(state[3]) or 0
+end
+
+local allowed = 0
+if prev * (window - elapsed) / window + cur + 1 <= limit then
+	cur = cur + 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'window', string.format('%.0f', index), 'prev', prev, 'cur', cur)
+redis.call('PEXPIRE', KEYS[1], 2 * window - elapsed)
+return {allowed, prev, cur, elapsed}
+`)
+
+// RedisStore implementa Store no Redis, compartilhando os limites entre as
+// réplicas. Cada chave é atualizada por um script Lua, de forma atômica.
+type RedisStore struct {
+	client redis.Scripter
+}
+
+// NewRedisStore cria uma nova instância do RedisStore
+func NewRedisStore(client redis.Scripter) *RedisStore {
+	return &RedisStore{
+		client: client,
+	}
+}
+
+// Allow consome uma requisição da chave, se o limite permitir
+func (s *RedisStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	if limit.Algorithm == SlidingWindow {
+		return s.slidingWindow(ctx, key, limit)
+	}
+	return s.tokenBucket(ctx, key, limit)
+}
+
+// tokenBucket executa o token bucket no Redis
+func (s *RedisStore) tokenBucket(ctx context.Context, key string, limit Limit) (Result, error) {
+	interval := float64(tokenInterval(limit)) / float64(time.Millisecond)
+
+	values, err := tokenBucketScript.Run(ctx, s.client, []string{key},
+		limit.capacity(), strconv.FormatFloat(interval, 'f', -1, 64)).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("erro ao consultar limite de requisições: %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("resposta inesperada do limite de requisições: %v", values)
+	}
+
+	tokens, err := strconv.ParseFloat(fmt.Sprint(values[1]), 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("resposta inesperada do limite de requisições: %w", err)
+	}
+	return bucketResult(limit, tokens, values[0] == int64(1)), nil
+}
+
+// slidingWindow executa a janela deslizante no Redis
+func (s *RedisStore) slidingWindow(ctx context.Context, key string, limit Limit) (Result, error) {
+	values, err := slidingWindowScript.Run(ctx, s.client, []string{key},
+		limit.Requests, limit.Window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("erro ao consultar limite de requisições: %w", err)
+	}
+	if len(values) != 4 {
+		return Result{}, fmt.Errorf("resposta inesperada do limite de requisições: %v", values)
+	}
+
+	// A janela em milissegundos, a mesma usada pelo script
+	limit.Window = time.Duration(limit.Window.Milliseconds()) * time.Millisecond
+	elapsed := time.Duration(values[3]) * time.Millisecond
+	return windowResult(limit, values[1], values[2], elapsed, values[0] == 1), nil
+}
@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisStore cria um RedisStore sobre um miniredis cujo relógio, usado
+// pelo TIME dos scripts, é controlado pelo teste. advance avança o relógio e
+// os TTLs das chaves.
+func newTestRedisStore(t *testing.T, now time.Time) (*RedisStore, *miniredis.Miniredis, func(time.Duration)) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	mr.SetTime(now)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	advance := func(d time.Duration) {
+		now = now.Add(d)
+		mr.SetTime(now)
+		mr.FastForward(d)
+	}
+	return NewRedisStore(client), mr, advance
+}
+
+func TestRedisTokenBucket(t *testing.T) {
+	s, mr, advance := newTestRedisStore(t, time.Unix(1700000000, 0))
+	limit := Limit{Algorithm: TokenBucket, Requests: 60, Window: time.Minute, Burst: 10}
+
+	if got := allowN(t, s, "k", limit, 15); got != 10 {
+		t.Errorf("Esperava 10 requisições aceitas no burst, obteve %d", got)
+	}
+
+	res, err := s.Allow(context.Background(), "k", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.RetryAfter != time.Second {
+		t.Errorf("Esperava recusa com RetryAfter de 1s, obteve %+v", res)
+	}
+	if res.Limit != 10 || res.Window != 10*time.Second || res.Reset != 10*time.Second {
+		t.Errorf("Cota anunciada inesperada: %+v", res)
+	}
+
+	// A chave expira quando o balde estaria cheio de novo
+	if ttl := mr.TTL("k"); ttl != 10*time.Second+time.Millisecond {
+		t.Errorf("Esperava TTL de 10,001s com o balde vazio, obteve %v", ttl)
+	}
+
+	advance(3 * time.Second)
+	if got := allowN(t, s, "k", limit, 5); got != 3 {
+		t.Errorf("Esperava 3 fichas repostas em 3s, obteve %d", got)
+	}
+	if got := allowN(t, s, "outra", limit, 1); got != 1 {
+		t.Error("Outra chave não deveria compartilhar o limite")
+	}
+
+	advance(10*time.Second + time.Millisecond)
+	if mr.Exists("k") {
+		t.Error("Chave deveria expirar após repor todas as fichas")
+	}
+	if got := allowN(t, s, "k", limit, 15); got != 10 {
+		t.Errorf("Esperava o balde cheio após a expiração, obteve %d aceitas", got)
+	}
+}
+
+func TestRedisSlidingWindow(t *testing.T) {
+	s, mr, advance := newTestRedisStore(t, time.Unix(1700000000, 0).Truncate(time.Minute))
+	limit := Limit{Algorithm: SlidingWindow, Requests: 10, Window: time.Minute}
+
+	if got := allowN(t, s, "k", limit, 12); got != 10 {
+		t.Errorf("Esperava 10 requisições aceitas na janela, obteve %d", got)
+	}
+	res, err := s.Allow(context.Background(), "k", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Na janela seguinte, a atual ainda pesa 10 requisições no início
+	if res.Allowed || res.Remaining != 0 || res.RetryAfter != 66*time.Second {
+		t.Errorf("Esperava RetryAfter de 66s, obteve %+v", res)
+	}
+
+	// A chave dura até o fim da janela seguinte, enquanto ainda pesa
+	if ttl := mr.TTL("k"); ttl != 2*time.Minute {
+		t.Errorf("Esperava TTL de 2min no início da janela, obteve %v", ttl)
+	}
+
+	// Na metade da janela seguinte, a anterior pesa 5 requisições
+	advance(90 * time.Second)
+	if got := allowN(t, s, "k", limit, 10); got != 5 {
+		t.Errorf("Esperava 5 requisições aceitas com a janela anterior ponderada, obteve %d", got)
+	}
+	res, _ = s.Allow(context.Background(), "k", limit)
+	if res.Allowed || res.RetryAfter != 6*time.Second {
+		t.Errorf("Esperava RetryAfter de 6s, obteve %+v", res)
+	}
+	if ttl := mr.TTL("k"); ttl != 90*time.Second {
+		t.Errorf("Esperava TTL de 90s na metade da janela, obteve %v", ttl)
+	}
+
+	// Duas janelas depois, a chave expirou e a contagem recomeça
+	advance(2 * time.Minute)
+	if mr.Exists("k") {
+		t.Error("Chave deveria expirar após duas janelas")
+	}
+	if got := allowN(t, s, "k", limit, 10); got != 10 {
+		t.Errorf("Esperava a contagem zerada, obteve %d aceitas", got)
+	}
+}
+
+func TestRedisSlidingWindowDropsStaleWindow(t *testing.T) {
+	s, _, advance := newTestRedisStore(t, time.Unix(1700000000, 0).Truncate(time.Minute))
+	limit := Limit{Algorithm: SlidingWindow, Requests: 10, Window: time.Minute}
+
+	allowN(t, s, "k", limit, 10)
+
+	// Mesmo sem a expiração, uma janela mais antiga que a anterior não pesa
+	s.client.(*redis.Client).Persist(context.Background(), "k")
+	advance(2*time.Minute + 30*time.Second)
+	if got := allowN(t, s, "k", limit, 10); got != 10 {
+		t.Errorf("Janela antiga não deveria pesar na contagem, obteve %d aceitas", got)
+	}
+}
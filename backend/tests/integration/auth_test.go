@@ -10,17 +10,35 @@ import (
 	"insidechurch/backend/internal/adapters/handlers"
 	"insidechurch/backend/internal/adapters/repositories"
 	"insidechurch/backend/internal/core/domain"
+	"insidechurch/backend/internal/core/domain/entities"
+	"insidechurch/backend/internal/core/ports"
 	"insidechurch/backend/internal/core/usecases/auth"
 	"insidechurch/backend/internal/core/usecases/user"
+	"insidechurch/backend/internal/domain/services"
 	"insidechurch/backend/internal/middleware"
 	"insidechurch/backend/internal/routes"
 
 	"github.com/gin-gonic/gin"
+	"github.com/insidechurch/auditevent"
+	"github.com/insidechurch/keys"
+	"github.com/insidechurch/passwordhash"
+	"github.com/insidechurch/passwordpolicy"
+	"github.com/insidechurch/ratelimit"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// nopNotifier descarta as notificações enviadas durante os testes
+type nopNotifier struct{}
+
+func (nopNotifier) Send(ports.Notification) error { return nil }
+
+// nopRevoker ignora o encerramento de sessões durante os testes
+type nopRevoker struct{}
+
+func (nopRevoker) RevokeUserSessions(uint) error { return nil }
+
 func setupTestRouter() *gin.Engine {
 	// Configurar banco de dados de teste
 	dsn := "host=localhost user=postgres password=postgres dbname=insidechurch_test port=5432 sslmode=disable"
@@ -31,7 +49,7 @@ func setupTestRouter() *gin.Engine {
 
 	// Limpar tabelas antes dos testes
 	db.Exec("DROP TABLE IF EXISTS users CASCADE")
-	db.AutoMigrate(&domain.User{})
+	db.AutoMigrate(&domain.User{}, &entities.UserToken{}, &entities.RecoveryCode{}, &entities.APIKey{})
 
 	// Inicializa o router
 	router := gin.Default()
@@ -39,21 +57,34 @@ func setupTestRouter() *gin.Engine {
 	// Inicializa os repositórios
 	userRepo := repositories.NewUserRepository(db)
 
-	// Inicializa os casos de uso
-	loginUseCase := auth.NewLoginUseCase(userRepo)
-	registerUseCase := auth.NewRegisterUseCase(userRepo)
+	// Inicializa os casos de uso; o login dispensa a verificação de email e
+	// assina os tokens com chaves efêmeras
+	keySet, err := keys.Generate()
+	if err != nil {
+		panic("failed to generate signing keys")
+	}
+	emailVerificationUseCase := auth.NewEmailVerificationUseCase(userRepo, repositories.NewUserTokenRepository(db), nopNotifier{}, "", auditevent.Discard)
+	loginUseCase := auth.NewLoginUseCase(userRepo, auth.UnverifiedLoginAllow, nil, nil, passwordhash.Default(), auditevent.Discard, auth.NewTokenKeys(keySet, ""), nil)
+	registerUseCase := auth.NewRegisterUseCase(userRepo, emailVerificationUseCase, passwordpolicy.Default(), passwordhash.Default(), auditevent.Discard)
 	getUserUseCase := user.NewGetUserUseCase(userRepo)
+	mfaUseCase := auth.NewMFAUseCase(userRepo, repositories.NewRecoveryCodeRepository(db), loginUseCase, "InsideChurch", auditevent.Discard)
 
 	// Inicializa os middlewares
-	authMiddleware := middleware.NewAuthMiddleware(loginUseCase)
-	securityMiddleware := middleware.NewSecurityMiddleware()
+	apiKeyUseCase := auth.NewAPIKeyUseCase(repositories.NewAPIKeyRepository(db))
+	authMiddleware := middleware.NewAuthMiddleware(loginUseCase, apiKeyUseCase, nil, nil)
+	roleService := services.NewRoleService(repositories.NewRoleRepository(db), auditevent.Discard)
+	permissionMiddleware := middleware.NewPermissionMiddleware(userRepo, roleService)
+	securityMiddleware := middleware.NewSecurityMiddleware(ratelimit.New(ratelimit.NewMemoryStore()))
 
 	// Inicializa os handlers
-	authHandler := handlers.NewAuthHandler(loginUseCase, registerUseCase)
+	authHandler := handlers.NewAuthHandler(loginUseCase, registerUseCase, nil, emailVerificationUseCase)
 	userHandler := handlers.NewUserHandler(getUserUseCase)
+	mfaHandler := handlers.NewMFAHandler(mfaUseCase)
+	memberHandler := handlers.NewMemberHandler(user.NewMemberUseCase(userRepo, nopRevoker{}, auditevent.Discard))
+	roleHandler := handlers.NewRoleHandler(roleService)
 
 	// Configura as rotas
-	routes.SetupRoutes(router, authHandler, userHandler, authMiddleware, securityMiddleware)
+	routes.SetupRoutes(router, authHandler, userHandler, mfaHandler, memberHandler, roleHandler, authMiddleware, permissionMiddleware, securityMiddleware)
 
 	return router
 }